@@ -24,6 +24,9 @@ func boot(flags AppFlags) api.Api {
 	// Create base url
 	baseUrl, port := getBaseUrl(core.Config)
 
+	// Set reverse proxies that are trusted to forward client address
+	setTrustedProxies(core.Config)
+
 	// Init app
 	app := api.Api{
 		BaseUrl:     baseUrl,
@@ -42,8 +45,11 @@ func boot(flags AppFlags) api.Api {
 			MilestoneService: new(service.MilestoneService),
 			Credit:           new(service.CreditService),
 			Initiative:       new(service.Initiative),
+			Organization:     new(service.Organization),
+			Advertiser:       new(service.Advertiser),
 			SubscriptionPlan: new(service.SubscriptionService),
 			SiteSetting:      new(service.SiteSettingService),
+			Admin:            new(service.Admin),
 		},
 	}
 
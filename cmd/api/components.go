@@ -6,6 +6,7 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/pkg/ncore"
 	"github.com/diarikom/running-app/running-app-api/internal/pkg/nfacebook"
 	"github.com/diarikom/running-app/running-app-api/internal/pkg/njwt"
+	"github.com/diarikom/running-app/running-app-api/internal/pkg/noidc"
 	"github.com/diarikom/running-app/running-app-api/pkg/nmailgun"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
@@ -40,6 +41,14 @@ func initComponents(opt ncore.BootOpt, config *viper.Viper) api.Components {
 	fb := initFacebook(config)
 	log.Debug("Components.Facebook initiated")
 
+	// Init google
+	google := initGoogle(config)
+	log.Debug("Components.Google initiated")
+
+	// Init apple
+	apple := initApple(config)
+	log.Debug("Components.Apple initiated")
+
 	// Create app components
 	return api.Components{
 		Errors:    errUtil,
@@ -47,6 +56,8 @@ func initComponents(opt ncore.BootOpt, config *viper.Viper) api.Components {
 		JWTIssuer: jwtIssuer,
 		Mailer:    mailer,
 		Facebook:  fb,
+		Google:    google,
+		Apple:     apple,
 	}
 }
 
@@ -55,11 +66,34 @@ func initJwtIssuer(config *viper.Viper) *njwt.Issuer {
 	authKey := config.GetString(api.ConfJWTAuthKey)
 	defaultLifetime := config.GetInt(api.ConfJWTDefaultLifetime)
 	issuer := config.GetString(api.ConfJWTIssuer)
+	rejectAuthKey := config.GetBool(api.ConfJWTRejectAuthKey)
+
+	// Load asymmetric keys
+	var keyOpts []njwt.KeyOpt
+	err := mapstructure.Decode(config.Get(api.ConfJWTKeys), &keyOpts)
+	if err != nil {
+		panic(fmt.Errorf("running-app-api: unable to retrieve keys config for Components.JWTIssuer (%s)", err))
+	}
+
+	keys := make([]*njwt.Key, len(keyOpts))
+	for i, opt := range keyOpts {
+		keys[i], err = njwt.NewKey(opt)
+		if err != nil {
+			panic(fmt.Errorf("running-app-api: unable to load key for Components.JWTIssuer (%s)", err))
+		}
+	}
+	njwt.SortKeys(keys)
+
+	if authKey == "" && len(keys) == 0 {
+		panic(fmt.Errorf("running-app-api: auth_key or keys is required for Components.JWTIssuer"))
+	}
 
 	// Init issuer
 	return &njwt.Issuer{
 		Key:             []byte(authKey),
-		DefaultLifetime: time.Duration(defaultLifetime) & time.Minute,
+		Keys:            keys,
+		RejectSharedKey: rejectAuthKey,
+		DefaultLifetime: time.Duration(defaultLifetime) * time.Minute,
 		Issuer:          issuer,
 	}
 }
@@ -103,3 +137,23 @@ func initFacebook(config *viper.Viper) *nfacebook.Provider {
 
 	return provider
 }
+
+func initGoogle(config *viper.Viper) *noidc.Provider {
+	// Init google
+	provider, err := noidc.NewGoogleProvider(config.GetStringSlice(api.ConfGoogleClientIds))
+	if err != nil {
+		panic(fmt.Errorf("running-app-api: unable to init Components.Google (%s)", err))
+	}
+
+	return provider
+}
+
+func initApple(config *viper.Viper) *noidc.Provider {
+	// Init apple
+	provider, err := noidc.NewAppleProvider(config.GetStringSlice(api.ConfAppleClientIds))
+	if err != nil {
+		panic(fmt.Errorf("running-app-api: unable to init Components.Apple (%s)", err))
+	}
+
+	return provider
+}
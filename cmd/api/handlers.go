@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/service"
@@ -10,15 +11,20 @@ import (
 
 type Handlers struct {
 	ApiStatus        nhttp.HandlerFunc
+	JWKS             nhttp.HandlerFunc
 	Asset            *service.AssetHandler
 	User             *service.UserHandler
 	Run              *service.RunHandler
 	DiscoverContent  *service.DiscoverContentHandler
 	AdTag            *service.AdTagHandler
 	MilestoneHandler *service.MilestoneHandler
+	Credit           *service.CreditHandler
 	Initiative       *service.InitiativeHandler
+	Organization     *service.OrganizationHandler
+	Advertiser       *service.AdvertiserHandler
 	SubscriptionPlan *service.SubscriptionPlanHandler
 	SiteSetting      *service.SiteSettingHandler
+	Admin            *service.AdminHandler
 }
 
 func initHandlers(app *api.Api) Handlers {
@@ -28,21 +34,48 @@ func initHandlers(app *api.Api) Handlers {
 	discoverContent := service.NewDiscoverContentHandler(app)
 	tag := service.NewTagHandler(app)
 	milestoneHandler := service.NewMilestoneHandler(app)
+	credit := service.NewCreditHandler(app)
 	initiative := service.NewInitiativeHandler(app)
+	organization := service.NewOrganizationHandler(app)
+	advertiser := service.NewAdvertiserHandler(app)
 	subscriptionPlan := service.NewSubscriptionPlanHandler(app)
 	siteSetting := service.NewSiteSettingHandler(app)
+	admin := service.NewAdminHandler(app)
 
 	return Handlers{
 		ApiStatus:        newApiStatusHandler(app),
+		JWKS:             newJWKSHandler(app),
 		Asset:            &asset,
 		User:             &user,
 		Run:              &run,
 		DiscoverContent:  &discoverContent,
 		AdTag:            &tag,
 		MilestoneHandler: &milestoneHandler,
+		Credit:           &credit,
 		Initiative:       &initiative,
+		Organization:     &organization,
+		Advertiser:       &advertiser,
 		SubscriptionPlan: &subscriptionPlan,
 		SiteSetting:      &siteSetting,
+		Admin:            &admin,
+	}
+}
+
+func newJWKSHandler(app *api.Api) nhttp.HandlerFunc {
+	return func(_ *http.Request) (*nhttp.Success, error) {
+		content, err := json.Marshal(app.Components.JWTIssuer.JWKS())
+		if err != nil {
+			return nil, err
+		}
+
+		// Send key set as is, so it can be read by standard JWKS clients
+		res := &nhttp.Success{
+			File: &nhttp.File{
+				ContentType: nhttp.ContentTypeJSON,
+				Content:     content,
+			},
+		}
+		return res, nil
 	}
 }
 
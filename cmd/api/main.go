@@ -53,6 +53,7 @@ func start(app *api.Api) {
 
 	// Init api router
 	router := mux.NewRouter()
+	initWellKnownRoutes(router, handlers, app.Logger)
 	apiRouter := nhttp.NewApiRouter(nhttp.RouterOpt{
 		RootRouter: router,
 		BasePath:   app.BaseUrl.Path,
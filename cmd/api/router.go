@@ -35,3 +35,10 @@ func getBaseUrl(config *viper.Viper) (*url.URL, string) {
 
 	return nhttp.BuildUrl(urlConf), port
 }
+
+func setTrustedProxies(config *viper.Viper) {
+	err := nhttp.SetTrustedProxies(config.GetStringSlice(api.ConfServerTrustedProxies))
+	if err != nil {
+		panic(fmt.Errorf("running-app-api: unable to parse server.trusted_proxies value (%s)", err))
+	}
+}
@@ -3,28 +3,74 @@ package main
 import (
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/gorilla/mux"
 )
 
 const (
 	// Middlewares
-	AuthClientMiddleware          = "auth.client"
-	AuthClientDashboardMiddleware = "auth.client.dash"
-	AuthUserMiddleware            = "auth.user"
-	ResetPasswordMiddleware       = "auth.one_time.reset_password"
-	VerifyEmailMiddleware         = "auth.one_time.verify_email"
+	AuthClientMiddleware            = "auth.client"
+	AuthUserMiddleware              = "auth.user"
+	AuthOrganizationMiddleware      = "auth.organization"
+	AuthAdvertiserMiddleware        = "auth.advertiser"
+	ResetPasswordMiddleware         = "auth.one_time.reset_password"
+	VerifyEmailMiddleware           = "auth.one_time.verify_email"
+	ChangeEmailMiddleware           = "auth.one_time.change_email"
+	TwoFactorUserMiddleware         = "auth.one_time.two_factor.user"
+	TwoFactorOrganizationMiddleware = "auth.one_time.two_factor.organization"
+	TwoFactorAdvertiserMiddleware   = "auth.one_time.two_factor.advertiser"
+	TwoFactorAdminMiddleware        = "auth.one_time.two_factor.admin"
+
+	// Admin permission middlewares
+	AdminMiddlewarePrefix        = "auth.admin."
+	AdminManageMiddleware        = AdminMiddlewarePrefix + api.PermissionAdminManage
+	AdvertiserManageMiddleware   = AdminMiddlewarePrefix + api.PermissionAdvertiserManage
+	ContentManageMiddleware      = AdminMiddlewarePrefix + api.PermissionContentManage
+	CreditAdjustMiddleware       = AdminMiddlewarePrefix + api.PermissionCreditAdjust
+	CreditApproveMiddleware      = AdminMiddlewarePrefix + api.PermissionCreditApprove
+	CreditReadMiddleware         = AdminMiddlewarePrefix + api.PermissionCreditRead
+	DonationRefundMiddleware     = AdminMiddlewarePrefix + api.PermissionDonationRefund
+	InitiativeManageMiddleware   = AdminMiddlewarePrefix + api.PermissionInitiativeManage
+	OrganizationManageMiddleware = AdminMiddlewarePrefix + api.PermissionOrganizationManage
+	PayoutManageMiddleware       = AdminMiddlewarePrefix + api.PermissionPayoutManage
+	UserManageMiddleware         = AdminMiddlewarePrefix + api.PermissionUserManage
 )
 
 func initMiddlewares(router *nhttp.Router, services *api.Services) {
 	router.RegisterMiddleware(AuthClientMiddleware, nhttp.NewClientAuthMiddleware(services.Auth.ValidateClient,
 		nhttp.KeyAuthorization, log))
-	router.RegisterMiddleware(AuthClientDashboardMiddleware, nhttp.NewClientAuthMiddleware(services.Auth.ValidateClientDashboard,
-		nhttp.KeyAuthorization, log))
 	router.RegisterMiddleware(AuthUserMiddleware, nhttp.NewUserAuthMiddleware(services.Auth.ValidateUserAccess,
 		services.User.ValidateSession, nhttp.KeyAuthorization, log))
+	router.RegisterMiddleware(AuthOrganizationMiddleware, api.NewOrganizationSessionMiddleware(
+		services.Auth.ValidateOrganizationAccess, services.Organization.ValidateAdmin, nhttp.KeyAuthorization, log))
+	router.RegisterMiddleware(AuthAdvertiserMiddleware, api.NewAdvertiserSessionMiddleware(
+		services.Auth.ValidateAdvertiserAccess, services.Advertiser.ValidateAdvertiser, nhttp.KeyAuthorization, log))
 	router.RegisterMiddleware(ResetPasswordMiddleware, api.NewResetPasswordSessionMiddleware(
 		services.Auth.ValidateResetPasswordToken, services.User.ValidateResetPasswordSignature, nhttp.KeyAuthorization, log))
 	router.RegisterMiddleware(VerifyEmailMiddleware, api.NewVerifyEmailSessionMiddleware(
 		services.Auth.ValidateVerifyEmailToken, services.User.ValidateVerifyEmailSignature, nhttp.KeyAuthorization, log))
+	router.RegisterMiddleware(ChangeEmailMiddleware, api.NewChangeEmailSessionMiddleware(
+		services.Auth.ValidateChangeEmailToken, services.User.ValidateChangeEmailSignature, nhttp.KeyAuthorization, log))
+	router.RegisterMiddleware(TwoFactorUserMiddleware, api.NewTwoFactorSessionMiddleware(
+		services.Auth.ValidateTwoFactorToken, api.TwoFactorTargetUser, nhttp.KeyAuthorization, log))
+	router.RegisterMiddleware(TwoFactorOrganizationMiddleware, api.NewTwoFactorSessionMiddleware(
+		services.Auth.ValidateTwoFactorToken, api.TwoFactorTargetOrganization, nhttp.KeyAuthorization, log))
+	router.RegisterMiddleware(TwoFactorAdvertiserMiddleware, api.NewTwoFactorSessionMiddleware(
+		services.Auth.ValidateTwoFactorToken, api.TwoFactorTargetAdvertiser, nhttp.KeyAuthorization, log))
+	router.RegisterMiddleware(TwoFactorAdminMiddleware, api.NewTwoFactorSessionMiddleware(
+		services.Auth.ValidateTwoFactorToken, api.TwoFactorTargetAdmin, nhttp.KeyAuthorization, log))
+
+	// Register a middleware for each admin permission
+	for _, p := range api.AdminPermissions {
+		router.RegisterMiddleware(AdminMiddlewarePrefix+p, nhttp.NewPermissionMiddleware(services.Auth.ValidateAdminAccess,
+			services.Admin.Authorize, services.Admin.Audit, p, nhttp.KeyAuthorization, log))
+	}
+}
+
+// initWellKnownRoutes register routes that are served from host root regardless of base path. It must be called
+// before api router is registered, so the routes are not captured by api router
+func initWellKnownRoutes(root *mux.Router, handlers Handlers, logger nlog.Logger) {
+	root.Handle("/.well-known/jwks.json", nhttp.Handler{Logger: logger, Fn: handlers.JWKS}).Methods("GET")
 }
 
 func initRoutes(router *nhttp.Router, handlers Handlers) {
@@ -45,18 +91,46 @@ func initRoutes(router *nhttp.Router, handlers Handlers) {
 	// Users
 	router.Handle("/users/register", handlers.User.PostRegister).Methods("POST")
 	router.Handle("/users/log-in", handlers.User.PostLogin).Methods("POST")
+	router.HandleWithMiddleware("/users/log-in/2fa", TwoFactorUserMiddleware, handlers.User.PostLoginTwoFactor).Methods("POST")
 	router.Handle("/users/email", handlers.User.GetCheckEmail).Methods("GET")
 	router.Handle("/users/reset-password", handlers.User.PostResetPassword).Methods("POST")
 	router.Handle("/users/reset-password", handlers.User.GetResetPassword).Methods("GET")
 	router.Handle("/users/verify-email", handlers.User.GetVerifyEmail).Methods("GET")
+	router.Handle("/users/email-change", handlers.User.GetChangeEmail).Methods("GET")
+	router.Handle("/users/refresh-session", handlers.User.PostRefreshToken).Methods("PUT")
 	router.HandleWithMiddleware("/users/log-out", AuthUserMiddleware, handlers.User.DeleteLogout).Methods("DELETE")
 	router.HandleWithMiddleware("/users/profile", AuthUserMiddleware, handlers.User.GetProfile).Methods("GET")
 	router.HandleWithMiddleware("/users/change-password", AuthUserMiddleware, handlers.User.PutChangePassword).Methods("PUT")
 	router.HandleWithMiddleware("/users/reset-password", ResetPasswordMiddleware, handlers.User.PutResetPassword).Methods("PUT")
 	router.HandleWithMiddleware("/users/verify-email", VerifyEmailMiddleware, handlers.User.PutVerifyEmail).Methods("PUT")
-	router.HandleWithMiddleware("/users/refresh-session", AuthUserMiddleware, handlers.User.PostRefreshToken).Methods("PUT")
+	router.HandleWithMiddleware("/users/email-change", AuthUserMiddleware, handlers.User.PostChangeEmail).Methods("POST")
+	router.HandleWithMiddleware("/users/email-change", ChangeEmailMiddleware, handlers.User.PutChangeEmail).Methods("PUT")
 	router.HandleWithMiddleware("/users/credits", AuthUserMiddleware, handlers.User.GetCreditBalance).Methods("GET")
+	router.HandleWithMiddleware("/users/credits/transactions", AuthUserMiddleware, handlers.Credit.GetTrxHistory).Methods("GET")
+	router.HandleWithMiddleware("/users/credits/transfers", AuthUserMiddleware, handlers.Credit.PostTransfer).Methods("POST")
 	router.HandleWithMiddleware("/users/donations", AuthUserMiddleware, handlers.Initiative.ListUserDonation).Methods("GET")
+	router.HandleWithMiddleware("/users/donations/{id}/receipt", AuthUserMiddleware, handlers.Initiative.GetDonationReceipt).Methods("GET")
+	router.HandleWithMiddleware("/users/donations/pledges", AuthUserMiddleware, handlers.Initiative.PostPledge).Methods("POST")
+	router.HandleWithMiddleware("/users/donations/pledges", AuthUserMiddleware, handlers.Initiative.ListPledges).Methods("GET")
+	router.HandleWithMiddleware("/users/donations/pledges/{id}", AuthUserMiddleware, handlers.Initiative.DeletePledge).Methods("DELETE")
+	router.HandleWithMiddleware("/users/donations/pledges/{id}/pause", AuthUserMiddleware, handlers.Initiative.PutPausePledge).Methods("PUT")
+	router.HandleWithMiddleware("/users/donations/pledges/{id}/resume", AuthUserMiddleware, handlers.Initiative.PutResumePledge).Methods("PUT")
+	router.HandleWithMiddleware("/users/donations/pledges/{id}/runs", AuthUserMiddleware, handlers.Initiative.ListPledgeRuns).Methods("GET")
+	router.HandleWithMiddleware("/users/ad-tags", AuthUserMiddleware, handlers.AdTag.PutFollowedTags).Methods("PUT")
+	router.HandleWithMiddleware("/users/auth-providers", AuthUserMiddleware, handlers.User.GetAuthProviders).Methods("GET")
+	router.HandleWithMiddleware("/users/auth-providers", AuthUserMiddleware, handlers.User.PostLinkAuthProvider).Methods("POST")
+	router.HandleWithMiddleware("/users/auth-providers/{providerId}", AuthUserMiddleware, handlers.User.DeleteUnlinkAuthProvider).Methods("DELETE")
+	router.HandleWithMiddleware("/users/2fa", AuthUserMiddleware, handlers.User.GetTwoFactor).Methods("GET")
+	router.HandleWithMiddleware("/users/2fa", AuthUserMiddleware, handlers.User.PostEnrollTwoFactor).Methods("POST")
+	router.HandleWithMiddleware("/users/2fa", AuthUserMiddleware, handlers.User.PutEnableTwoFactor).Methods("PUT")
+	router.HandleWithMiddleware("/users/2fa", AuthUserMiddleware, handlers.User.DeleteTwoFactor).Methods("DELETE")
+	router.HandleWithMiddleware("/users/2fa/recovery-codes", AuthUserMiddleware, handlers.User.PostRecoveryCodes).Methods("POST")
+	router.HandleWithMiddleware("/users/sessions", AuthUserMiddleware, handlers.User.GetSessions).Methods("GET")
+	router.HandleWithMiddleware("/users/sessions/others", AuthUserMiddleware, handlers.User.DeleteOtherSessions).Methods("DELETE")
+	router.HandleWithMiddleware("/users/sessions/{id}", AuthUserMiddleware, handlers.User.DeleteSession).Methods("DELETE")
+	router.HandleWithMiddleware("/users/data-export", AuthUserMiddleware, handlers.User.PostDataExport).Methods("POST")
+	router.HandleWithMiddleware("/users/deletion", AuthUserMiddleware, handlers.User.PostDeletion).Methods("POST")
+	router.HandleWithMiddleware("/users/deletion", AuthUserMiddleware, handlers.User.DeleteDeletion).Methods("DELETE")
 	router.HandleWithMiddleware("/users/providers/{providerId}/ref-id", AuthUserMiddleware, handlers.User.GetUserProviderRefId).Methods("GET")
 	router.HandleWithMiddleware("/users/subscriptions", AuthUserMiddleware, handlers.User.PostUserSubscribe).Methods("POST")
 	router.HandleWithMiddleware("/users/subscriptions", AuthUserMiddleware, handlers.User.DeleteUserCancelSubscription).Methods("DELETE")
@@ -65,14 +139,92 @@ func initRoutes(router *nhttp.Router, handlers Handlers) {
 	router.HandleWithMiddleware("/users", AuthUserMiddleware, handlers.User.PutUpdateProfile).Methods("PUT")
 	router.HandleWithMiddleware("/milestones/current", AuthUserMiddleware, handlers.MilestoneHandler.Current).Methods("GET")
 
-	router.HandleWithMiddleware("/admin/users/milestones/check-achievements", AuthClientDashboardMiddleware, handlers.MilestoneHandler.CheckChallengeAchieve).Methods("PUT")
-	router.HandleWithMiddleware("/admin/users/milestones/reload", AuthClientDashboardMiddleware, handlers.MilestoneHandler.ReloadMilestone).Methods("PUT")
-	router.HandleWithMiddleware("/admin/advertisers/resend-activation", AuthClientDashboardMiddleware, handlers.User.PostSendAdvertiserActivation).Methods("POST")
+	// Admin
+	router.Handle("/admin/log-in", handlers.Admin.PostLogin).Methods("POST")
+	router.HandleWithMiddleware("/admin/log-in/2fa", TwoFactorAdminMiddleware, handlers.Admin.PostLoginTwoFactor).Methods("POST")
+	router.Handle("/admin/invitations/accept", handlers.Admin.PostAcceptInvitation).Methods("POST")
+	router.HandleWithMiddleware("/admin/admins", AdminManageMiddleware, handlers.Admin.GetAdmins).Methods("GET")
+	router.HandleWithMiddleware("/admin/admins/invitations", AdminManageMiddleware, handlers.Admin.PostInvitation).Methods("POST")
+	router.HandleWithMiddleware("/admin/admins/{userId}/roles", AdminManageMiddleware, handlers.Admin.PutRoles).Methods("PUT")
+	router.HandleWithMiddleware("/admin/admins/{userId}", AdminManageMiddleware, handlers.Admin.DeleteAdmin).Methods("DELETE")
+	router.HandleWithMiddleware("/admin/audit-logs", AdminManageMiddleware, handlers.Admin.GetAuditLogs).Methods("GET")
+
+	router.HandleWithMiddleware("/admin/users/milestones/check-achievements", ContentManageMiddleware, handlers.MilestoneHandler.CheckChallengeAchieve).Methods("PUT")
+	router.HandleWithMiddleware("/admin/users/milestones/reload", ContentManageMiddleware, handlers.MilestoneHandler.ReloadMilestone).Methods("PUT")
+	router.HandleWithMiddleware("/admin/users/deletions/run", UserManageMiddleware, handlers.User.PutRunDeletions).Methods("PUT")
+	router.HandleWithMiddleware("/admin/users/{userId}/credits/transactions/export", CreditReadMiddleware, handlers.Credit.GetExportTrxHistory).Methods("GET")
+	router.HandleWithMiddleware("/admin/credits/expire", CreditAdjustMiddleware, handlers.Credit.PutExpireCredits).Methods("PUT")
+	router.HandleWithMiddleware("/admin/credits/adjustments", CreditAdjustMiddleware, handlers.Credit.PostAdjustment).Methods("POST")
+	router.HandleWithMiddleware("/admin/credits/adjustments", CreditReadMiddleware, handlers.Credit.GetAdjustments).Methods("GET")
+	router.HandleWithMiddleware("/admin/credits/adjustments/{id}/approve", CreditApproveMiddleware, handlers.Credit.PutApproveAdjustment).Methods("PUT")
+	router.HandleWithMiddleware("/admin/credits/adjustments/{id}/reject", CreditApproveMiddleware, handlers.Credit.PutRejectAdjustment).Methods("PUT")
+	router.HandleWithMiddleware("/admin/initiatives/close-expired", InitiativeManageMiddleware, handlers.Initiative.PutCloseExpired).Methods("PUT")
+	router.HandleWithMiddleware("/admin/initiatives/pledges/run", InitiativeManageMiddleware, handlers.Initiative.PutRunPledges).Methods("PUT")
+	router.HandleWithMiddleware("/admin/initiatives/donations/{id}/refund", DonationRefundMiddleware, handlers.Initiative.PutRefundDonation).Methods("PUT")
+	router.HandleWithMiddleware("/admin/initiatives/{id}/funding", InitiativeManageMiddleware, handlers.Initiative.PutFundingGoal).Methods("PUT")
+	router.HandleWithMiddleware("/admin/organizations", OrganizationManageMiddleware, handlers.Organization.PostOrganization).Methods("POST")
+	router.HandleWithMiddleware("/admin/organizations", OrganizationManageMiddleware, handlers.Organization.GetOrganizations).Methods("GET")
+	router.HandleWithMiddleware("/admin/organizations/payouts", PayoutManageMiddleware, handlers.Organization.PostGeneratePayouts).Methods("POST")
+	router.HandleWithMiddleware("/admin/organizations/payouts/{id}/export", PayoutManageMiddleware, handlers.Organization.GetExportPayout).Methods("GET")
+	router.HandleWithMiddleware("/admin/organizations/{id}", OrganizationManageMiddleware, handlers.Organization.GetOrganization).Methods("GET")
+	router.HandleWithMiddleware("/admin/organizations/{id}", OrganizationManageMiddleware, handlers.Organization.PutOrganization).Methods("PUT")
+	router.HandleWithMiddleware("/admin/organizations/{id}", OrganizationManageMiddleware, handlers.Organization.DeleteOrganization).Methods("DELETE")
+	router.HandleWithMiddleware("/admin/organizations/{id}/verification", OrganizationManageMiddleware, handlers.Organization.PutVerification).Methods("PUT")
+	router.HandleWithMiddleware("/admin/organizations/{id}/members", OrganizationManageMiddleware, handlers.Organization.PostMember).Methods("POST")
+	router.HandleWithMiddleware("/admin/organizations/{id}/members/{userId}", OrganizationManageMiddleware, handlers.Organization.DeleteMember).Methods("DELETE")
+	router.HandleWithMiddleware("/admin/organizations/{id}/payouts", PayoutManageMiddleware, handlers.Organization.GetPayouts).Methods("GET")
+	router.HandleWithMiddleware("/admin/discover-contents", ContentManageMiddleware, handlers.DiscoverContent.PostContent).Methods("POST")
+	router.HandleWithMiddleware("/admin/discover-contents", ContentManageMiddleware, handlers.DiscoverContent.GetAdminContents).Methods("GET")
+	router.HandleWithMiddleware("/admin/discover-contents/images", ContentManageMiddleware, handlers.DiscoverContent.PostImage).Methods("POST")
+	router.HandleWithMiddleware("/admin/discover-contents/{id}", ContentManageMiddleware, handlers.DiscoverContent.GetAdminContent).Methods("GET")
+	router.HandleWithMiddleware("/admin/discover-contents/{id}", ContentManageMiddleware, handlers.DiscoverContent.PutContent).Methods("PUT")
+	router.HandleWithMiddleware("/admin/discover-contents/{id}", ContentManageMiddleware, handlers.DiscoverContent.DeleteContent).Methods("DELETE")
+	router.HandleWithMiddleware("/admin/advertisers/resend-activation", AdvertiserManageMiddleware, handlers.User.PostSendAdvertiserActivation).Methods("POST")
+	router.HandleWithMiddleware("/admin/ads/stats/rollup", AdvertiserManageMiddleware, handlers.Advertiser.PutRollupStats).Methods("PUT")
 	router.HandleWithMiddleware("/challenges/{id}/claim", AuthUserMiddleware, handlers.User.GetClaimCredit).Methods("POST")
 
 	// Initiatives
 	router.HandleWithMiddleware("/initiatives/{id}/donate", AuthUserMiddleware, handlers.Initiative.Donate).Methods("POST")
 	router.HandleWithMiddleware("/initiatives", AuthUserMiddleware, handlers.Initiative.List).Methods("GET")
+	router.HandleWithMiddleware("/initiatives/tags", AuthUserMiddleware, handlers.Initiative.ListTagFacets).Methods("GET")
+
+	// Organizations
+	router.Handle("/organizations/log-in", handlers.Organization.PostLogin).Methods("POST")
+	router.HandleWithMiddleware("/organizations/log-in/2fa", TwoFactorOrganizationMiddleware, handlers.Organization.PostLoginTwoFactor).Methods("POST")
+	router.HandleWithMiddleware("/organizations/2fa", AuthOrganizationMiddleware, handlers.User.GetTwoFactor).Methods("GET")
+	router.HandleWithMiddleware("/organizations/2fa", AuthOrganizationMiddleware, handlers.User.PostEnrollTwoFactor).Methods("POST")
+	router.HandleWithMiddleware("/organizations/2fa", AuthOrganizationMiddleware, handlers.User.PutEnableTwoFactor).Methods("PUT")
+	router.HandleWithMiddleware("/organizations/2fa", AuthOrganizationMiddleware, handlers.User.DeleteTwoFactor).Methods("DELETE")
+	router.HandleWithMiddleware("/organizations/2fa/recovery-codes", AuthOrganizationMiddleware, handlers.User.PostRecoveryCodes).Methods("POST")
+	router.HandleWithMiddleware("/organizations/donations", AuthOrganizationMiddleware, handlers.Organization.GetAdminDonations).Methods("GET")
+	router.HandleWithMiddleware("/organizations/payouts", AuthOrganizationMiddleware, handlers.Organization.GetAdminPayouts).Methods("GET")
+	router.HandleWithMiddleware("/organizations/payouts/{id}/export", AuthOrganizationMiddleware, handlers.Organization.GetAdminExportPayout).Methods("GET")
+
+	// Advertisers
+	router.Handle("/advertisers/log-in", handlers.Advertiser.PostLogin).Methods("POST")
+	router.HandleWithMiddleware("/advertisers/log-in/2fa", TwoFactorAdvertiserMiddleware, handlers.Advertiser.PostLoginTwoFactor).Methods("POST")
+	router.HandleWithMiddleware("/advertisers/2fa", AuthAdvertiserMiddleware, handlers.User.GetTwoFactor).Methods("GET")
+	router.HandleWithMiddleware("/advertisers/2fa", AuthAdvertiserMiddleware, handlers.User.PostEnrollTwoFactor).Methods("POST")
+	router.HandleWithMiddleware("/advertisers/2fa", AuthAdvertiserMiddleware, handlers.User.PutEnableTwoFactor).Methods("PUT")
+	router.HandleWithMiddleware("/advertisers/2fa", AuthAdvertiserMiddleware, handlers.User.DeleteTwoFactor).Methods("DELETE")
+	router.HandleWithMiddleware("/advertisers/2fa/recovery-codes", AuthAdvertiserMiddleware, handlers.User.PostRecoveryCodes).Methods("POST")
+	router.HandleWithMiddleware("/advertisers/campaigns", AuthAdvertiserMiddleware, handlers.Advertiser.PostCampaign).Methods("POST")
+	router.HandleWithMiddleware("/advertisers/campaigns", AuthAdvertiserMiddleware, handlers.Advertiser.GetCampaigns).Methods("GET")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}", AuthAdvertiserMiddleware, handlers.Advertiser.GetCampaign).Methods("GET")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}", AuthAdvertiserMiddleware, handlers.Advertiser.PutCampaign).Methods("PUT")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}", AuthAdvertiserMiddleware, handlers.Advertiser.DeleteCampaign).Methods("DELETE")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}/pause", AuthAdvertiserMiddleware, handlers.Advertiser.PutPauseCampaign).Methods("PUT")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}/resume", AuthAdvertiserMiddleware, handlers.Advertiser.PutResumeCampaign).Methods("PUT")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}/report", AuthAdvertiserMiddleware, handlers.Advertiser.GetCampaignReport).Methods("GET")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}/report/export", AuthAdvertiserMiddleware, handlers.Advertiser.GetExportCampaignReport).Methods("GET")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}/creatives", AuthAdvertiserMiddleware, handlers.Advertiser.PostCreative).Methods("POST")
+	router.HandleWithMiddleware("/advertisers/campaigns/{id}/creatives/{creativeId}", AuthAdvertiserMiddleware, handlers.Advertiser.DeleteCreative).Methods("DELETE")
+	router.HandleWithMiddleware("/advertisers/creatives/images", AuthAdvertiserMiddleware, handlers.Advertiser.PostCreativeImage).Methods("POST")
+
+	// Ads
+	router.HandleWithMiddleware("/ads", AuthUserMiddleware, handlers.Advertiser.GetServeAd).Methods("GET")
+	router.HandleWithMiddleware("/ads/{id}/impressions", AuthUserMiddleware, handlers.Advertiser.PostImpression).Methods("POST")
+	router.HandleWithMiddleware("/ads/{id}/clicks", AuthUserMiddleware, handlers.Advertiser.PostClick).Methods("POST")
 
 	// Subscription plans
 	router.HandleWithMiddleware("/subscriptions/plans", AuthUserMiddleware, handlers.SubscriptionPlan.List).Methods("GET")
@@ -50,6 +50,8 @@ func InitApi() Api {
 			JWTIssuer: &mocks.JWTIssuerComponent{},
 			Mailer:    &mocks.MailerComponent{},
 			Facebook:  &mocks.FacebookProviderComponent{},
+			Google:    &mocks.IdTokenProviderComponent{},
+			Apple:     &mocks.IdTokenProviderComponent{},
 		},
 		Logger: logger,
 		Core:   core,
@@ -29,6 +29,8 @@ type Components struct {
 	JWTIssuer JWTIssuerComponent
 	Mailer    MailerComponent
 	Facebook  FacebookProviderComponent
+	Google    IdTokenProviderComponent
+	Apple     IdTokenProviderComponent
 }
 
 type Services struct {
@@ -41,6 +43,9 @@ type Services struct {
 	MilestoneService MilestoneService
 	Credit           CreditService
 	Initiative       InitiativeService
+	Organization     OrganizationService
+	Advertiser       AdvertiserService
 	SubscriptionPlan SubscriptionPlanService
 	SiteSetting      SiteSettingService
+	Admin            AdminService
 }
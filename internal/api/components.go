@@ -3,12 +3,14 @@ package api
 import (
 	"github.com/diarikom/running-app/running-app-api/internal/pkg/nfacebook"
 	"github.com/diarikom/running-app/running-app-api/internal/pkg/njwt"
+	"github.com/diarikom/running-app/running-app-api/internal/pkg/noidc"
 	"github.com/diarikom/running-app/running-app-api/pkg/nmailgun"
 )
 
 type JWTIssuerComponent interface {
 	New(opt njwt.ClaimOpt) (*njwt.Token, error)
 	Verify(input string) (*njwt.Claim, error)
+	JWKS() *njwt.JWKS
 }
 
 type MailerComponent interface {
@@ -21,3 +23,7 @@ type FacebookProviderComponent interface {
 	GetUrl(path string) string
 	InspectToken(token string) (*nfacebook.TokenData, error)
 }
+
+type IdTokenProviderComponent interface {
+	VerifyIdToken(token string, nonce string) (*noidc.IdToken, error)
+}
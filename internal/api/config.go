@@ -6,21 +6,43 @@ const (
 	ConfServerPort     = "server.port"
 	ConfServerScheme   = "server.scheme"
 
+	ConfServerTrustedProxies = "server.trusted_proxies"
+
 	ConfAppClientSecret                   = "auth.app_client_secret"
-	ConfDashboardClientSecret             = "auth.dashboard_client_secret"
 	ConfUserAccessLifetime                = "auth.token_lifetime.user_access"
+	ConfUserRefreshLifetime               = "auth.token_lifetime.user_refresh"
 	ConfResetPasswordTokenLifetime        = "auth.token_lifetime.reset_password"
+	ConfOrganizationAccessLifetime        = "auth.token_lifetime.organization_access"
+	ConfAdvertiserAccessLifetime          = "auth.token_lifetime.advertiser_access"
+	ConfAdminAccessLifetime               = "auth.token_lifetime.admin_access"
 	ConfVerifyEmailTokenLifetime          = "auth.token_lifetime.verify_email"
+	ConfTwoFactorTokenLifetime            = "auth.token_lifetime.two_factor"
+	ConfChangeEmailTokenLifetime          = "auth.token_lifetime.change_email"
 	ConfSignatureSaltResetPasswordSubject = "auth.signature_salt.reset_password_subject"
 	ConfSignatureSaltEmailVerifySubject   = "auth.signature_salt.verify_email_subject"
+	ConfSignatureSaltChangeEmailSubject   = "auth.signature_salt.change_email_subject"
+	ConfTwoFactorIssuer                   = "auth.two_factor.issuer"
+
+	ConfThrottleFreeAttempts    = "auth.throttle.free_attempts"
+	ConfThrottleBaseDelay       = "auth.throttle.base_delay"
+	ConfThrottleMaxDelay        = "auth.throttle.max_delay"
+	ConfThrottleWindow          = "auth.throttle.window"
+	ConfThrottleLockoutAttempts = "auth.throttle.lockout_attempts"
+	ConfThrottleLockoutDuration = "auth.throttle.lockout_duration"
+	ConfThrottlePurgeInterval   = "auth.throttle.purge_interval"
 
 	ConfJWTAuthKey         = "components.njwt.auth_key"
 	ConfJWTDefaultLifetime = "components.njwt.default_lifetime"
 	ConfJWTIssuer          = "components.njwt.issuer"
+	ConfJWTKeys            = "components.njwt.keys"
+	ConfJWTRejectAuthKey   = "components.njwt.reject_auth_key"
 
 	ConfFacebookAppId     = "components.facebook.app_id"
 	ConfFacebookAppSecret = "components.facebook.app_secret"
 
+	ConfGoogleClientIds = "components.google.client_ids"
+	ConfAppleClientIds  = "components.apple.client_ids"
+
 	ConfAssetEndpoint        = "datasources.asset.endpoint"
 	ConfAssetUseSSL          = "datasources.asset.use_ssl"
 	ConfAssetAccessKeyId     = "datasources.asset.access_key_id"
@@ -33,16 +55,44 @@ const (
 
 	ConfDashboardUrl                 = "components.dashboard.url"
 	ConfAdvertiserActivationLifetime = "components.dashboard.advertiser_activation_lifetime"
+	ConfAdminInvitationLifetime      = "components.dashboard.admin_invitation_lifetime"
+
+	ConfUserDeletionGracePeriod = "user.deletion_grace_period"
+	ConfUserDeletionInterval    = "user.deletion_interval"
+	ConfUserDataExportLifetime  = "user.data_export_lifetime"
+	ConfUserDataExportCooldown  = "user.data_export_cooldown"
+
+	ConfDiscoverRegionHeader = "discover.region_header"
+
+	ConfCreditExpiryInterval      = "credit.expiry_interval"
+	ConfCreditTransferDailyLimit  = "credit.transfer_daily_limit"
+	ConfCreditTransferDailyCount  = "credit.transfer_daily_count"
+	ConfCreditAdjustmentThreshold = "credit.adjustment_approval_threshold"
+
+	ConfInitiativeCloseInterval  = "initiative.close_interval"
+	ConfInitiativePledgeInterval = "initiative.pledge_interval"
+
+	ConfOrganizationPayoutInterval = "organization.payout_interval"
+
+	ConfAdminSuperAdminEmails = "admin.super_admin_emails"
+
+	ConfAdvertiserStatInterval       = "advertiser.stat_interval"
+	ConfAdvertiserImpressionDailyCap = "advertiser.impression_daily_cap"
 )
 
 var RequiredConfig = []string{
 	// Authentication
 	ConfAppClientSecret,
-	ConfDashboardClientSecret,
 	ConfUserAccessLifetime,
+	ConfUserRefreshLifetime,
 	ConfResetPasswordTokenLifetime,
+	ConfTwoFactorTokenLifetime,
 	ConfSignatureSaltResetPasswordSubject,
 	ConfSignatureSaltEmailVerifySubject,
+	ConfSignatureSaltChangeEmailSubject,
+	ConfThrottleFreeAttempts,
+	ConfThrottleBaseDelay,
+	ConfThrottleWindow,
 
 	// Datasources.Db
 	"datasources.db.driver",
@@ -60,7 +110,6 @@ var RequiredConfig = []string{
 	ConfAssetBaseUrl,
 
 	// Components.JWTIssuer
-	ConfJWTAuthKey,
 	ConfJWTDefaultLifetime,
 	ConfJWTIssuer,
 
@@ -68,6 +117,12 @@ var RequiredConfig = []string{
 	ConfFacebookAppId,
 	ConfFacebookAppSecret,
 
+	// Components.Google
+	ConfGoogleClientIds,
+
+	// Components.Apple
+	ConfAppleClientIds,
+
 	// Components.Mailer
 	"components.nmailgun.domain",
 	"components.nmailgun.private_api_key",
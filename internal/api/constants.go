@@ -6,6 +6,11 @@ const (
 
 	UserActive    = 1
 	UserSuspended = 2
+	UserLocked    = 3
+	UserDeleted   = 4
+
+	DeletionScheduled  = 1
+	DeletionAnonymized = 2
 
 	RunSummaryStored = 1
 	RunDetailsStored = 2
@@ -13,10 +18,27 @@ const (
 	AccessTokenKey    = "X-Access-Token"
 	AccessTokenExpKey = "X-Access-Token-Expiry"
 
-	JWTAudienceUser = "RunningApp.User"
-	JWTAudienceApp  = "RunningApp.App"
+	RefreshTokenKey    = "X-Refresh-Token"
+	RefreshTokenExpKey = "X-Refresh-Token-Expiry"
+
+	TwoFactorTokenKey    = "X-Two-Factor-Token"
+	TwoFactorTokenExpKey = "X-Two-Factor-Token-Expiry"
+
+	JWTAudienceUser         = "RunningApp.User"
+	JWTAudienceApp          = "RunningApp.App"
+	JWTAudienceOrganization = "RunningApp.Organization"
+	JWTAudienceAdvertiser   = "RunningApp.Advertiser"
+	JWTAudienceAdmin        = "RunningApp.Admin"
 
-	UserSignatureKey = "user_signature"
+	UserSignatureKey   = "user_signature"
+	TwoFactorTargetKey = "two_factor_target"
+	NewEmailKey        = "new_email"
+
+	OrganizationIdKey = "organization_id"
+	KeyOrganizationId = "AUTH_ORGANIZATION_ID"
+	AuthProviderIdKey = "auth_provider_id"
+	KeyAuthProviderId = "AUTH_PROVIDER_ID"
+	KeyNewEmail       = "AUTH_NEW_EMAIL"
 )
 
 const (
@@ -29,12 +51,31 @@ const (
 	JWTApp
 	JWTPurposeResetPassword
 	JWTPurposeVerifyEmail
+	JWTOrganizationAdmin
+	JWTAdvertiser
+	JWTPurposeTwoFactor
+	JWTAdmin
+	JWTPurposeChangeEmail
 )
 
 const (
-	AppAuthProvider = 1
-	// GoogleAuthProvider
+	TwoFactorPending = iota + 1
+	TwoFactorEnabled
+)
+
+// Two factor challenge target, determines which session is issued once challenge is completed
+const (
+	TwoFactorTargetUser         = "user"
+	TwoFactorTargetAdvertiser   = "advertiser"
+	TwoFactorTargetOrganization = "organization"
+	TwoFactorTargetAdmin        = "admin"
+)
+
+const (
+	AppAuthProvider      = 1
+	GoogleAuthProvider   = 2
 	FacebookAuthProvider = 3
+	AppleAuthProvider    = 4
 )
 
 const (
@@ -42,12 +83,16 @@ const (
 	AssetAvatarProfile
 	AssetInitiative
 	AssetDiscoverContent
+	AssetAdCreative
+	AssetDataExport
 )
 
 var AssetDirs = map[int]string{
 	AssetAvatarProfile:   "avatars",
 	AssetInitiative:      "initiatives",
 	AssetDiscoverContent: "discover-contents",
+	AssetAdCreative:      "ad-creatives",
+	AssetDataExport:      "data-exports",
 }
 
 const (
@@ -75,6 +120,9 @@ const (
 	InitEntryType = iota + 1
 	Debit
 	Credit
+	TransferIn
+	TransferOut
+	Refund
 )
 
 const (
@@ -95,7 +143,54 @@ var CurrencyName = map[int8]string{
 }
 
 const (
-	ActiveInitiative = 2
+	ActiveInitiative   = 2
+	InactiveInitiative = 3
+	ClosedInitiative   = 4
+)
+
+const (
+	InitiativeSortPopular   = "popular"
+	InitiativeSortNewest    = "newest"
+	InitiativeSortRelevance = "relevance"
+)
+
+const (
+	PledgeFixedAmount = iota + 1
+	PledgePercentage
+)
+
+const (
+	PledgeWeekly = iota + 1
+	PledgeMonthly
+)
+
+const (
+	PledgeActive = iota + 1
+	PledgePaused
+	PledgeCancelled
+)
+
+const (
+	PledgeRunExecuted = iota + 1
+	PledgeRunSkipped
+	PledgeRunFailed
+	PledgeRunPending
+)
+
+const (
+	OrganizationActive = iota + 1
+	OrganizationInactive
+)
+
+const (
+	OrganizationUnverified = iota + 1
+	OrganizationVerified
+	OrganizationRejected
+)
+
+const (
+	OrganizationAdminRole = iota + 1
+	OrganizationStaffRole
 )
 
 const (
@@ -106,12 +201,127 @@ const (
 	DonationCreated        = 1
 	DonationPaymentPending = 3
 	DonationPaymentOK      = 4
+	DonationRefunded       = 5
+	DonationCancelled      = 6
 )
 
 const (
-	ModifierUser = "USER"
+	DiscoverContentActive = iota + 1
+	DiscoverContentInactive
+	DiscoverContentDeleted
 )
 
+const (
+	AudienceAll int8 = iota
+	AudiencePremium
+	AudienceFree
+)
+
+const (
+	CampaignActive = iota + 1
+	CampaignPaused
+	CampaignEnded
+)
+
+const (
+	CreativeActive = iota + 1
+	CreativeInactive
+)
+
+const (
+	AdImpression = iota + 1
+	AdClick
+)
+
+const (
+	SegmentPremium     = "premium"
+	SegmentFree        = "free"
+	SegmentRunInactive = "run_inactive"
+	SegmentRunCasual   = "run_casual"
+	SegmentRunActive   = "run_active"
+)
+
+const (
+	ModifierUser  = "USER"
+	ModifierAdmin = "ADMIN"
+)
+
+// Admin permissions, checked per route on admin endpoints
+const (
+	PermissionAdminManage        = "admin.manage"
+	PermissionAdvertiserManage   = "advertiser.manage"
+	PermissionContentManage      = "content.manage"
+	PermissionCreditAdjust       = "credit.adjust"
+	PermissionCreditApprove      = "credit.approve"
+	PermissionCreditRead         = "credit.read"
+	PermissionDonationRefund     = "donation.refund"
+	PermissionInitiativeManage   = "initiative.manage"
+	PermissionOrganizationManage = "organization.manage"
+	PermissionPayoutManage       = "payout.manage"
+	PermissionUserManage         = "user.manage"
+)
+
+// AdminPermissions is the list of all admin permissions
+var AdminPermissions = []string{
+	PermissionAdminManage,
+	PermissionAdvertiserManage,
+	PermissionContentManage,
+	PermissionCreditAdjust,
+	PermissionCreditApprove,
+	PermissionCreditRead,
+	PermissionDonationRefund,
+	PermissionInitiativeManage,
+	PermissionOrganizationManage,
+	PermissionPayoutManage,
+	PermissionUserManage,
+}
+
+// Admin roles
+const (
+	AdminRoleSuperAdmin    = "super_admin"
+	AdminRoleContentEditor = "content_editor"
+	AdminRoleFinance       = "finance"
+	AdminRoleSupport       = "support"
+)
+
+// AdminRolePermissions maps admin role to permissions granted by the role
+var AdminRolePermissions = map[string][]string{
+	AdminRoleSuperAdmin: AdminPermissions,
+	AdminRoleContentEditor: {
+		PermissionContentManage,
+		PermissionInitiativeManage,
+	},
+	AdminRoleFinance: {
+		PermissionCreditAdjust,
+		PermissionCreditApprove,
+		PermissionCreditRead,
+		PermissionDonationRefund,
+		PermissionPayoutManage,
+	},
+	AdminRoleSupport: {
+		PermissionAdvertiserManage,
+		PermissionCreditAdjust,
+		PermissionCreditRead,
+		PermissionOrganizationManage,
+		PermissionUserManage,
+	},
+}
+
+const (
+	AdjustmentPendingApproval = iota + 1
+	AdjustmentApplied
+	AdjustmentRejected
+)
+
+// AdjustmentReasonCodes is the list of reason code allowed on manual credit adjustment
+var AdjustmentReasonCodes = []string{
+	"COMPENSATION",
+	"GOODWILL",
+	"CORRECTION",
+	"FRAUD_CLAWBACK",
+	"REWARD_REVERSAL",
+}
+
 const (
 	ProviderStripe int8 = 1
 )
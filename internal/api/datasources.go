@@ -1,11 +1,17 @@
 package api
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 const (
 	AssetsPublicScope = iota
+	AssetsPrivateScope
 )
 
 type S3Provider interface {
 	Upload(file io.Reader, contentType, dest string, scope int) error
+	GetPresignedUrl(dest string, lifetime time.Duration) (string, error)
+	Delete(dest string) error
 }
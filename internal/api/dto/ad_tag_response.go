@@ -1,5 +1,11 @@
 package dto
 
 type AdTagResp struct {
-	Tags []string `json:"tags"`
+	Tags     []string `json:"tags"`
+	Followed []string `json:"followed"`
+}
+
+type AdTagFollowReq struct {
+	UserId string   `json:"-" validate:"required"`
+	Tags   []string `json:"tags" validate:"max=50,dive,required"`
 }
@@ -0,0 +1,33 @@
+package dto
+
+type AdminLoginReq struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	ClientIp string `json:"-"`
+}
+
+type AdminInvitationReq struct {
+	Email     string      `json:"email" validate:"required,email"`
+	Roles     []string    `json:"roles" validate:"required,min=1,dive,required"`
+	InvitedBy ModifierReq `json:"-"`
+}
+
+type AdminAcceptInvitationReq struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type AdminRolesReq struct {
+	UserId     string      `json:"-" validate:"required"`
+	Roles      []string    `json:"roles" validate:"required,min=1,dive,required"`
+	ModifiedBy ModifierReq `json:"-"`
+}
+
+type AdminDeleteReq struct {
+	UserId     string      `json:"-" validate:"required"`
+	ModifiedBy ModifierReq `json:"-"`
+}
+
+type AdminAuditLogListReq struct {
+	PageReq
+	UserId string
+}
@@ -0,0 +1,38 @@
+package dto
+
+type AdminLoginResp struct {
+	UserId            string   `json:"user_id"`
+	FullName          string   `json:"full_name"`
+	Roles             []string `json:"roles"`
+	Permissions       []string `json:"permissions"`
+	TwoFactorRequired bool     `json:"two_factor_required"`
+}
+
+type AdminResp struct {
+	UserId      string        `json:"user_id"`
+	Email       string        `json:"email"`
+	FullName    string        `json:"full_name"`
+	Roles       []string      `json:"roles"`
+	Permissions []string      `json:"permissions"`
+	CreatedAt   int64         `json:"created_at"`
+	UpdatedAt   int64         `json:"updated_at"`
+	ModifiedBy  *ModifierResp `json:"modified_by"`
+}
+
+type AdminInvitationResp struct {
+	Id        string   `json:"id"`
+	Email     string   `json:"email"`
+	Roles     []string `json:"roles"`
+	ExpiredAt int64    `json:"expired_at"`
+}
+
+type AdminAuditLogResp struct {
+	Id         string        `json:"id"`
+	Method     string        `json:"method"`
+	Path       string        `json:"path"`
+	Permission string        `json:"permission"`
+	StatusCode int           `json:"status_code"`
+	ClientIp   string        `json:"client_ip"`
+	CreatedBy  *ModifierResp `json:"created_by"`
+	CreatedAt  int64         `json:"created_at"`
+}
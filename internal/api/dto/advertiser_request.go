@@ -0,0 +1,59 @@
+package dto
+
+type AdvertiserLoginReq struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	ClientIp string `json:"-"`
+}
+
+type AdCampaignReq struct {
+	Id           string  `json:"-"`
+	AdvertiserId string  `json:"-"`
+	Name         string  `json:"name" validate:"required,max=255"`
+	TargetTagIds []int64 `json:"target_tag_ids" validate:"dive,gte=1"`
+	Budget       float64 `json:"budget" validate:"gt=0"`
+	CostPerMille float64 `json:"cost_per_mille" validate:"gt=0"`
+	StartAt      int64   `json:"start_at" validate:"gte=0"`
+	EndAt        int64   `json:"end_at" validate:"gte=0"`
+	Version      int64   `json:"version"`
+}
+
+type AdCampaignListReq struct {
+	PageReq
+	AdvertiserId string
+	StatusId     int8
+}
+
+type AdCampaignStatusReq struct {
+	Id           string `json:"-" validate:"required"`
+	AdvertiserId string `json:"-" validate:"required"`
+	StatusId     int8   `json:"-" validate:"oneof=1 2 3"`
+	Version      int64  `json:"version" validate:"required"`
+}
+
+type AdCreativeReq struct {
+	Id           string `json:"-"`
+	CampaignId   string `json:"-" validate:"required"`
+	AdvertiserId string `json:"-" validate:"required"`
+	ImageFile    string `json:"image_file" validate:"required"`
+	Headline     string `json:"headline" validate:"max=255"`
+	ClickUrl     string `json:"click_url" validate:"required,url"`
+}
+
+type AdServeReq struct {
+	UserId string
+}
+
+type AdEventReq struct {
+	ServeId     string `json:"serve_id"`
+	CreativeId  string `json:"-"`
+	UserId      string `json:"-"`
+	EventTypeId int8   `json:"-"`
+}
+
+type AdReportReq struct {
+	CampaignId   string
+	AdvertiserId string
+	StartAt      int64
+	EndAt        int64
+}
@@ -0,0 +1,76 @@
+package dto
+
+type AdvertiserLoginResp struct {
+	UserId            string `json:"user_id"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+}
+
+type AdCampaignResp struct {
+	Id           string           `json:"id"`
+	Name         string           `json:"name"`
+	TargetTagIds []int64          `json:"target_tag_ids"`
+	Budget       float64          `json:"budget"`
+	CostPerMille float64          `json:"cost_per_mille"`
+	Spent        float64          `json:"spent"`
+	StartAt      int64            `json:"start_at"`
+	EndAt        int64            `json:"end_at"`
+	StatusId     int8             `json:"status_id"`
+	Creatives    []AdCreativeResp `json:"creatives,omitempty"`
+	CreatedAt    int64            `json:"created_at"`
+	UpdatedAt    int64            `json:"updated_at"`
+	Version      int64            `json:"version"`
+}
+
+type AdCreativeResp struct {
+	Id         string `json:"id"`
+	CampaignId string `json:"campaign_id"`
+	ImageFile  string `json:"image_file"`
+	ImageUrl   string `json:"image_url"`
+	Headline   string `json:"headline"`
+	ClickUrl   string `json:"click_url"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type AdServeResp struct {
+	ServeId    string `json:"serve_id"`
+	CreativeId string `json:"creative_id"`
+	CampaignId string `json:"campaign_id"`
+	ImageUrl   string `json:"image_url"`
+	Headline   string `json:"headline"`
+	ClickUrl   string `json:"click_url"`
+}
+
+type AdReportResp struct {
+	CampaignId  string             `json:"campaign_id"`
+	StartAt     int64              `json:"start_at"`
+	EndAt       int64              `json:"end_at"`
+	Impressions int64              `json:"impressions"`
+	Clicks      int64              `json:"clicks"`
+	CTR         float64            `json:"ctr"`
+	Reach       int64              `json:"reach"`
+	Spent       float64            `json:"spent"`
+	Hourly      []AdHourlyStatResp `json:"hourly"`
+	Tags        []AdTagStatResp    `json:"tags"`
+}
+
+type AdHourlyStatResp struct {
+	HourAt      int64   `json:"hour_at"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	CTR         float64 `json:"ctr"`
+	Reach       int64   `json:"reach"`
+}
+
+type AdTagStatResp struct {
+	AdTagId     int64   `json:"ad_tag_id"`
+	AdTagName   string  `json:"ad_tag_name"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	CTR         float64 `json:"ctr"`
+}
+
+type AdStatRollupResp struct {
+	StartAt  int64 `json:"start_at"`
+	EndAt    int64 `json:"end_at"`
+	RowCount int64 `json:"row_count"`
+}
@@ -1,9 +1,21 @@
 package dto
 
-import "github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+import (
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"io"
+	"time"
+)
 
 type UploadReq struct {
 	AssetType int
 	File      nhttp.MultipartFile
 	DestDir   string
 }
+
+type PrivateUploadReq struct {
+	AssetType   int
+	FileName    string
+	ContentType string
+	File        io.Reader
+	Lifetime    time.Duration
+}
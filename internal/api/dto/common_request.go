@@ -9,3 +9,8 @@ type UserResourcesReq struct {
 	PageReq
 	UserId string
 }
+
+type ModifierReq struct {
+	Id       string `json:"id" validate:"required"`
+	FullName string `json:"full_name" validate:"required"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type ModifierResp struct {
+	Id       string `json:"id"`
+	Role     string `json:"role"`
+	FullName string `json:"full_name"`
+}
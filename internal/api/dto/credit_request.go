@@ -1,17 +1,33 @@
 package dto
 
 import (
+	"github.com/jmoiron/sqlx"
 	"time"
 )
 
 type CreditSettleOpt struct {
+	Tx        *sqlx.Tx
 	TrxId     string
 	Notes     string
 	ExpiredAt *time.Time
 	Timestamp *time.Time
 }
 
+type CreditRefundOpt struct {
+	Tx        *sqlx.Tx
+	TrxId     string
+	Notes     string
+	Timestamp *time.Time
+}
+
+type CreditEarnedOpt struct {
+	UserId  string
+	StartAt time.Time
+	EndAt   time.Time
+}
+
 type CreditTrxOpt struct {
+	Tx        *sqlx.Tx
 	Id        string
 	WalletId  string
 	Amount    float64
@@ -22,7 +38,50 @@ type CreditTrxOpt struct {
 }
 
 type CreditChargeOpt struct {
+	Tx            *sqlx.Tx
 	UserId        string
 	Amount        float64
 	WalletVersion int
 }
+
+type CreditExpireOpt struct {
+	Timestamp *time.Time
+}
+
+type CreditTrxHistoryReq struct {
+	PageReq
+	UserId      string
+	StatusId    int8
+	EntryTypeId int8
+	StartAt     int64
+	EndAt       int64
+}
+
+type CreditTransferReq struct {
+	UserId         string  `json:"-" validate:"required"`
+	RecipientEmail string  `json:"recipient_email" validate:"required,email"`
+	Amount         float64 `json:"amount" validate:"gt=0"`
+	Message        string  `json:"message" validate:"max=255"`
+}
+
+type CreditAdjustmentReq struct {
+	UserId      string      `json:"user_id" validate:"required"`
+	EntryTypeId int8        `json:"entry_type_id" validate:"oneof=2 3"`
+	Amount      float64     `json:"amount" validate:"gt=0"`
+	ReasonCode  string      `json:"reason_code" validate:"required"`
+	Notes       string      `json:"notes" validate:"max=255"`
+	ExpiredAt   int64       `json:"expired_at"`
+	RequestedBy ModifierReq `json:"-"`
+}
+
+type CreditAdjustmentReviewReq struct {
+	Id         string      `json:"-" validate:"required"`
+	Approved   bool        `json:"-"`
+	Notes      string      `json:"notes" validate:"max=255"`
+	ReviewedBy ModifierReq `json:"-"`
+}
+
+type CreditAdjustmentListReq struct {
+	PageReq
+	StatusId int8
+}
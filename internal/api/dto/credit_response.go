@@ -6,3 +6,53 @@ type UserCreditBalanceResp struct {
 	ExpiringBalance float64 `json:"expiring_balance"`
 	ExpireTime      int64   `json:"expire_time"`
 }
+
+type CreditExpireResp struct {
+	ExpiredPendingTrx int     `json:"expired_pending_trx"`
+	ExpiredWallet     int     `json:"expired_wallet"`
+	ExpiredAmount     float64 `json:"expired_amount"`
+}
+
+type CreditTrxHistoryResp struct {
+	Id             string  `json:"id"`
+	Amount         float64 `json:"amount"`
+	Balance        float64 `json:"balance"`
+	BalancePending float64 `json:"balance_pending"`
+	EntryTypeId    int8    `json:"entry_type_id"`
+	StatusId       int8    `json:"status_id"`
+	Description    string  `json:"description"`
+	ChallengeId    string  `json:"challenge_id,omitempty"`
+	DonationId     string  `json:"donation_id,omitempty"`
+	CreatedAt      int64   `json:"created_at"`
+	ExpiredAt      int64   `json:"expired_at"`
+}
+
+type CreditTrxHistoryListResp struct {
+	Transactions []CreditTrxHistoryResp `json:"transactions"`
+	Count        int64                  `json:"count"`
+}
+
+type CreditTransferResp struct {
+	Id            string  `json:"id"`
+	RecipientName string  `json:"recipient_name"`
+	Amount        float64 `json:"amount"`
+	Balance       float64 `json:"balance"`
+}
+
+type CreditAdjustmentResp struct {
+	Id          string        `json:"id"`
+	UserId      string        `json:"user_id"`
+	EntryTypeId int8          `json:"entry_type_id"`
+	Amount      float64       `json:"amount"`
+	ReasonCode  string        `json:"reason_code"`
+	Notes       string        `json:"notes"`
+	ExpiredAt   int64         `json:"expired_at"`
+	StatusId    int8          `json:"status_id"`
+	TrxId       string        `json:"trx_id"`
+	RequestedBy *ModifierResp `json:"requested_by"`
+	ReviewedBy  *ModifierResp `json:"reviewed_by"`
+	ReviewNotes string        `json:"review_notes"`
+	CreatedAt   int64         `json:"created_at"`
+	UpdatedAt   int64         `json:"updated_at"`
+	Version     int           `json:"version"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"encoding/json"
+)
+
+type DiscoverContentReq struct {
+	Id              string               `json:"-"`
+	OrganizationId  string               `json:"organization_id" validate:"required"`
+	ContentBody     string               `json:"content_body" validate:"required"`
+	Headline        string               `json:"headline" validate:"max=255"`
+	LogoFile        string               `json:"logo_file"`
+	ExternalUrl     json.RawMessage      `json:"external_url"`
+	ImageFiles      DiscoverImageFileReq `json:"image_files"`
+	TagIds          []int64              `json:"tag_ids" validate:"unique"`
+	Sort            int                  `json:"sort"`
+	StatusId        int                  `json:"status_id" validate:"omitempty,oneof=1 2"`
+	PublishAt       int64                `json:"publish_at" validate:"gte=0"`
+	UnpublishAt     int64                `json:"unpublish_at" validate:"gte=0"`
+	AudiencePremium int8                 `json:"audience_premium" validate:"oneof=0 1 2"`
+	AudienceRegions []string             `json:"audience_regions" validate:"dive,len=2,alpha"`
+	Version         int                  `json:"version"`
+	ModifiedBy      ModifierReq          `json:"-"`
+}
+
+type DiscoverImageFileReq struct {
+	Thumbnail  string `json:"thumbnail"`
+	DetailPage string `json:"detail_page"`
+}
+
+type DiscoverContentQueryReq struct {
+	UserResourcesReq
+	Region string
+}
+
+type DiscoverContentListReq struct {
+	PageReq
+	StatusId int8
+}
+
+type DiscoverContentDeleteReq struct {
+	Id         string      `json:"-" validate:"required"`
+	Version    int         `json:"version" validate:"required"`
+	ModifiedBy ModifierReq `json:"-"`
+}
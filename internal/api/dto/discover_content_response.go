@@ -19,7 +19,7 @@ type DiscoverContentItem struct {
 	CreatedAt   int64                 `json:"created_at"`
 	UpdatedAt   int64                 `json:"updated_at"`
 	Version     int                   `json:"version"`
-	ModifiedBy  json.RawMessage       `json:"modified_by"`
+	ModifiedBy  *ModifierResp         `json:"modified_by"`
 	ImageFiles  DiscoverImageFileResp `json:"image_files"`
 	Headline    string                `json:"headline"`
 	Tags        []string              `json:"tags"`
@@ -29,3 +29,15 @@ type DiscoverImageFileResp struct {
 	Thumbnail  string `json:"thumbnail"`
 	DetailPage string `json:"detail_page"`
 }
+
+type DiscoverContentDetailResp struct {
+	DiscoverContentItem
+	OrganizationId  string               `json:"organization_id"`
+	TagIds          []int64              `json:"tag_ids"`
+	LogoFile        string               `json:"logo_file"`
+	ImageFileNames  DiscoverImageFileReq `json:"image_file_names"`
+	PublishAt       int64                `json:"publish_at"`
+	UnpublishAt     int64                `json:"unpublish_at"`
+	AudiencePremium int8                 `json:"audience_premium"`
+	AudienceRegions []string             `json:"audience_regions"`
+}
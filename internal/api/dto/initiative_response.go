@@ -16,6 +16,26 @@ type InitiativeResp struct {
 	UpdatedAt          int64                   `json:"updated_at"`
 	Version            int64                   `json:"version"`
 	Headline           string                  `json:"headline"`
+	StatusId           int8                    `json:"status_id"`
+	FundingGoal        float64                 `json:"funding_goal"`
+	RaisedAmount       float64                 `json:"raised_amount"`
+	Progress           float64                 `json:"progress"`
+	DonationCount      int64                   `json:"donation_count"`
+	DeadlineAt         int64                   `json:"deadline_at"`
+}
+
+type InitiativeListReq struct {
+	PageReq
+	Query          string
+	Tag            string
+	OrganizationId string
+	StatusId       int8   `validate:"omitempty,oneof=2 4"`
+	SortBy         string `validate:"omitempty,oneof=popular newest"`
+}
+
+type InitiativeTagFacetResp struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
 }
 
 type InitiativeImageFileResp struct {
@@ -48,3 +68,103 @@ type DonationHistoryResp struct {
 	CreatedAt              int64   `json:"created_at"`
 	UpdatedAt              int64   `json:"updated_at"`
 }
+
+type InitiativeFundingReq struct {
+	Id          string  `json:"-" validate:"required"`
+	FundingGoal float64 `json:"funding_goal" validate:"gte=0"`
+	DeadlineAt  int64   `json:"deadline_at" validate:"gte=0"`
+	Version     int64   `json:"version" validate:"required"`
+}
+
+type InitiativeCloseResp struct {
+	Closed int64 `json:"closed"`
+}
+
+type DonationRefundReq struct {
+	Id         string      `json:"-" validate:"required"`
+	Reason     string      `json:"reason" validate:"required"`
+	ModifiedBy ModifierReq `json:"-"`
+}
+
+type DonationRefundResp struct {
+	Id             string  `json:"id"`
+	StatusId       int8    `json:"status_id"`
+	RefundedAmount float64 `json:"refunded_amount"`
+	UpdatedAt      int64   `json:"updated_at"`
+}
+
+type DonationReceiptReq struct {
+	UserId     string `validate:"required"`
+	DonationId string `validate:"required"`
+}
+
+type DonationReceiptResp struct {
+	ReceiptNo          string  `json:"receipt_no"`
+	DonationId         string  `json:"donation_id"`
+	DonorName          string  `json:"donor_name"`
+	DonorEmail         string  `json:"donor_email"`
+	InitiativeId       string  `json:"initiative_id"`
+	InitiativeName     string  `json:"initiative_name"`
+	DonationConversion string  `json:"donation_conversion"`
+	ItemPrice          float64 `json:"item_price"`
+	Quantity           int     `json:"quantity"`
+	TotalDonation      float64 `json:"total_donation"`
+	CurrencyName       string  `json:"currency_name"`
+	Notes              string  `json:"notes"`
+	DonatedAt          int64   `json:"donated_at"`
+}
+
+type DonationPledgeReq struct {
+	UserId       string  `json:"-" validate:"required"`
+	InitiativeId string  `json:"initiative_id" validate:"required"`
+	AmountTypeId int8    `json:"amount_type_id" validate:"oneof=1 2"`
+	Amount       float64 `json:"amount" validate:"gt=0"`
+	PeriodId     int8    `json:"period_id" validate:"oneof=1 2"`
+	Notes        string  `json:"notes"`
+}
+
+type DonationPledgeResp struct {
+	Id           string  `json:"id"`
+	InitiativeId string  `json:"initiative_id"`
+	AmountTypeId int8    `json:"amount_type_id"`
+	Amount       float64 `json:"amount"`
+	PeriodId     int8    `json:"period_id"`
+	StatusId     int8    `json:"status_id"`
+	Notes        string  `json:"notes"`
+	NextRunAt    int64   `json:"next_run_at"`
+	LastRunAt    int64   `json:"last_run_at"`
+	CreatedAt    int64   `json:"created_at"`
+	UpdatedAt    int64   `json:"updated_at"`
+	Version      int64   `json:"version"`
+}
+
+type DonationPledgeStatusReq struct {
+	Id       string `validate:"required"`
+	UserId   string `validate:"required"`
+	StatusId int8   `validate:"oneof=1 2 3"`
+}
+
+type DonationPledgeRunListReq struct {
+	PageReq
+	UserId   string `validate:"required"`
+	PledgeId string `validate:"required"`
+}
+
+type DonationPledgeRunResp struct {
+	Id            string  `json:"id"`
+	PeriodStart   int64   `json:"period_start"`
+	PeriodEnd     int64   `json:"period_end"`
+	EarnedCredit  float64 `json:"earned_credit"`
+	Amount        float64 `json:"amount"`
+	Quantity      int     `json:"qty"`
+	TotalDonation float64 `json:"total_donation"`
+	StatusId      int8    `json:"status_id"`
+	Reason        string  `json:"reason"`
+	CreatedAt     int64   `json:"created_at"`
+}
+
+type DonationPledgeExecResp struct {
+	Executed int64 `json:"executed"`
+	Skipped  int64 `json:"skipped"`
+	Failed   int64 `json:"failed"`
+}
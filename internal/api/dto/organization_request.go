@@ -0,0 +1,81 @@
+package dto
+
+type OrganizationContact struct {
+	Name     string `json:"name" validate:"required,max=255"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	Phone    string `json:"phone" validate:"max=32"`
+	Position string `json:"position" validate:"max=255"`
+}
+
+type OrganizationReq struct {
+	Id          string                `json:"-"`
+	Name        string                `json:"name" validate:"required,max=255"`
+	OrgTypeId   int8                  `json:"org_type_id" validate:"gte=1"`
+	Description string                `json:"description"`
+	LogoFile    string                `json:"logo_file"`
+	Contacts    []OrganizationContact `json:"contacts" validate:"dive"`
+	StatusId    int8                  `json:"status_id" validate:"omitempty,oneof=1 2"`
+	Version     int64                 `json:"version"`
+	ModifiedBy  ModifierReq           `json:"-"`
+}
+
+type OrganizationListReq struct {
+	PageReq
+	StatusId             int8
+	VerificationStatusId int8
+}
+
+type OrganizationDeleteReq struct {
+	Id         string      `json:"-" validate:"required"`
+	Version    int64       `json:"version" validate:"required"`
+	ModifiedBy ModifierReq `json:"-"`
+}
+
+type OrganizationVerificationReq struct {
+	Id                   string      `json:"-" validate:"required"`
+	VerificationStatusId int8        `json:"verification_status_id" validate:"oneof=1 2 3"`
+	Notes                string      `json:"notes" validate:"max=255"`
+	Version              int64       `json:"version" validate:"required"`
+	ModifiedBy           ModifierReq `json:"-"`
+}
+
+type OrganizationMemberReq struct {
+	OrganizationId string `json:"-" validate:"required"`
+	UserId         string `json:"-"`
+	Email          string `json:"email" validate:"omitempty,email"`
+	MemberRoleId   int8   `json:"member_role_id" validate:"omitempty,oneof=1 2"`
+}
+
+type OrganizationLoginReq struct {
+	Email          string `json:"email" validate:"required,email"`
+	Password       string `json:"password" validate:"required"`
+	OrganizationId string `json:"organization_id"`
+	ClientIp       string `json:"-"`
+}
+
+type OrganizationSession struct {
+	UserId         string
+	OrganizationId string
+}
+
+type OrganizationDonationListReq struct {
+	PageReq
+	OrganizationId string
+	StartAt        int64
+	EndAt          int64
+}
+
+type OrganizationPayoutListReq struct {
+	PageReq
+	OrganizationId string
+}
+
+type OrganizationPayoutReq struct {
+	Id             string
+	OrganizationId string
+}
+
+type OrganizationPayoutGenerateReq struct {
+	Month      string       `json:"month"`
+	ModifiedBy *ModifierReq `json:"-"`
+}
@@ -0,0 +1,74 @@
+package dto
+
+type OrganizationResp struct {
+	Id                   string                `json:"id"`
+	Name                 string                `json:"name"`
+	OrgTypeId            int8                  `json:"org_type_id"`
+	Description          string                `json:"description"`
+	LogoFile             string                `json:"logo_file"`
+	Contacts             []OrganizationContact `json:"contacts"`
+	VerificationStatusId int8                  `json:"verification_status_id"`
+	VerificationNotes    string                `json:"verification_notes"`
+	VerifiedAt           int64                 `json:"verified_at"`
+	VerifiedBy           *ModifierResp         `json:"verified_by"`
+	StatusId             int8                  `json:"status_id"`
+	CreatedAt            int64                 `json:"created_at"`
+	UpdatedAt            int64                 `json:"updated_at"`
+	ModifiedBy           *ModifierResp         `json:"modified_by"`
+	Version              int64                 `json:"version"`
+}
+
+type OrganizationLoginResp struct {
+	OrganizationId    string `json:"organization_id"`
+	OrganizationName  string `json:"organization_name"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+}
+
+type OrganizationDonationResp struct {
+	Id             string  `json:"id"`
+	InitiativeId   string  `json:"initiative_id"`
+	InitiativeName string  `json:"initiative_name"`
+	DonorName      string  `json:"donor_name"`
+	Quantity       int     `json:"quantity"`
+	TotalDonation  float64 `json:"total_donation"`
+	CurrencyName   string  `json:"currency_name"`
+	Notes          string  `json:"notes"`
+	CreatedAt      int64   `json:"created_at"`
+}
+
+type OrganizationPayoutItemResp struct {
+	InitiativeId       string  `json:"initiative_id"`
+	InitiativeName     string  `json:"initiative_name"`
+	DonationConversion string  `json:"donation_conversion"`
+	DonationCount      int64   `json:"donation_count"`
+	Quantity           int64   `json:"quantity"`
+	TotalCredit        float64 `json:"total_credit"`
+	ConversionRate     float64 `json:"conversion_rate"`
+	CurrencyCode       string  `json:"currency_code"`
+	Amount             float64 `json:"amount"`
+}
+
+type OrganizationPayoutTotalResp struct {
+	CurrencyCode string  `json:"currency_code"`
+	Amount       float64 `json:"amount"`
+}
+
+type OrganizationPayoutResp struct {
+	Id             string                        `json:"id"`
+	OrganizationId string                        `json:"organization_id"`
+	PeriodStart    int64                         `json:"period_start"`
+	PeriodEnd      int64                         `json:"period_end"`
+	TotalCredit    float64                       `json:"total_credit"`
+	Totals         []OrganizationPayoutTotalResp `json:"totals"`
+	Items          []OrganizationPayoutItemResp  `json:"items"`
+	CreatedAt      int64                         `json:"created_at"`
+	CreatedBy      *ModifierResp                 `json:"created_by"`
+}
+
+type OrganizationPayoutGenerateResp struct {
+	PeriodStart int64 `json:"period_start"`
+	PeriodEnd   int64 `json:"period_end"`
+	Generated   int   `json:"generated"`
+	Skipped     int   `json:"skipped"`
+	Failed      int   `json:"failed"`
+}
@@ -15,6 +15,7 @@ type UserProfileReq struct {
 	AvatarFile      UploadResp `json:"avatar_file"`
 	AuthProviderId  int        `json:"auth_provider_id"`
 	ThirdPartyToken string     `json:"third_party_token"`
+	Nonce           string     `json:"nonce"`
 }
 
 type UserLoginReq struct {
@@ -28,12 +29,38 @@ type UserLoginReq struct {
 	NotificationToken   string `json:"notification_token"`
 	AuthProviderId      int    `json:"auth_provider_id"`
 	ThirdPartyToken     string `json:"third_party_token"`
+	Nonce               string `json:"nonce"`
+	ClientIp            string `json:"-"`
+}
+
+type UserAuthProviderReq struct {
+	UserId          string `json:"-"`
+	AuthProviderId  int    `json:"auth_provider_id"`
+	ThirdPartyToken string `json:"third_party_token"`
+	Nonce           string `json:"nonce"`
 }
 
 type UserRefreshSession struct {
-	SessionId  string       `json:"session_id"`
-	UserId     string       `json:"user_id"`
-	DeviceInfo UserLoginReq `json:"device_info"`
+	RefreshToken string       `json:"refresh_token"`
+	DeviceInfo   UserLoginReq `json:"device_info"`
+}
+
+type UserTwoFactorLoginReq struct {
+	UserId         string       `json:"-"`
+	AuthProviderId int          `json:"-"`
+	Code           string       `json:"code"`
+	DeviceInfo     UserLoginReq `json:"device_info"`
+}
+
+type UserRevokeSessionReq struct {
+	Id     string `json:"-"`
+	UserId string `json:"-"`
+}
+
+type TwoFactorReq struct {
+	UserId         string `json:"-"`
+	OrganizationId string `json:"-"`
+	Code           string `json:"code"`
 }
 
 type JWTOptReq struct {
@@ -68,6 +95,21 @@ type VerifyEmailSession struct {
 	UserSignature string
 }
 
+type ChangeEmailSession struct {
+	RequestId     string
+	UserId        string
+	NewEmail      string
+	UserSignature string
+}
+
+type TwoFactorSession struct {
+	RequestId      string
+	UserId         string
+	Target         string
+	OrganizationId string
+	AuthProviderId int
+}
+
 type UserSubscriptionReq struct {
 	ProviderId             int8                          `json:"provider_id"`
 	SubscriptionPlanTypeId int8                          `json:"subscription_plan_type_id"`
@@ -84,3 +126,19 @@ type StripeSubscriptionPaymentReq struct {
 type AdvertiserActivationReq struct {
 	UserId string `json:"user_id"`
 }
+
+type UserDataExportReq struct {
+	UserId string `json:"user_id"`
+}
+
+type UserDeletionReq struct {
+	UserId   string `json:"-"`
+	Password string `json:"password"`
+}
+
+type UserChangeEmailReq struct {
+	UserId   string `json:"-"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	ClientIp string `json:"-"`
+}
@@ -18,6 +18,41 @@ type UserProfileResp struct {
 	UpdatedAt     int64  `json:"updated_at"`
 }
 
+type UserAuthProvidersResp struct {
+	HasPassword bool                   `json:"has_password"`
+	Providers   []UserAuthProviderResp `json:"providers"`
+}
+
+type UserAuthProviderResp struct {
+	AuthProviderId int   `json:"auth_provider_id"`
+	LinkedAt       int64 `json:"linked_at"`
+}
+
+type UserSessionResp struct {
+	Id                 string `json:"id"`
+	AuthProviderId     int    `json:"auth_provider_id"`
+	DevicePlatformId   int    `json:"device_platform_id"`
+	DeviceManufacturer string `json:"device_manufacturer"`
+	DeviceModel        string `json:"device_model"`
+	IsCurrent          bool   `json:"is_current"`
+	CreatedAt          int64  `json:"created_at"`
+	LastSeenAt         int64  `json:"last_seen_at"`
+}
+
+type UserTwoFactorResp struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+type UserTwoFactorEnrollResp struct {
+	Secret          string `json:"secret"`
+	ProvisioningUri string `json:"provisioning_uri"`
+}
+
+type UserRecoveryCodesResp struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 type UserSubscriptionRequestResp struct {
 	Stripe json.RawMessage `json:"stripe"`
 }
@@ -47,3 +82,37 @@ type SubscriptionVoucherResp struct {
 	ValueType      string  `json:"value_type"`
 	RecurringMonth int64   `json:"recurring_month"`
 }
+
+type UserDeletionResp struct {
+	ScheduledAt int64 `json:"scheduled_at"`
+}
+
+type UserChallengeExportResp struct {
+	Id              string          `json:"id"`
+	MilestoneId     string          `json:"milestone_id"`
+	Milestone       json.RawMessage `json:"milestone"`
+	ChallengeId     string          `json:"challenge_id"`
+	Challenge       json.RawMessage `json:"challenge"`
+	ChallengeResult json.RawMessage `json:"challenge_result"`
+	Reward          json.RawMessage `json:"reward"`
+	RewardTypeId    int8            `json:"reward_type_id"`
+	RewardValue     float64         `json:"reward_value"`
+	StatusId        int8            `json:"status_id"`
+	UpdatedAt       int64           `json:"updated_at"`
+}
+
+type UserSubscriptionExportResp struct {
+	Id          string `json:"id"`
+	PlanTypeId  int8   `json:"plan_type_id"`
+	ProviderId  int8   `json:"provider_id"`
+	PeriodStart int64  `json:"period_start"`
+	PeriodEnd   int64  `json:"period_end"`
+	StatusId    int8   `json:"status_id"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+type UserDeletionRunResp struct {
+	Deleted int `json:"deleted"`
+	Failed  int `json:"failed"`
+}
@@ -4,3 +4,16 @@ type AccessToken struct {
 	Token     string
 	ExpiredAt int64
 }
+
+// ThirdPartyIdentity represents third party account that its ownership has been verified
+type ThirdPartyIdentity struct {
+	AccessKey     string
+	Email         string
+	EmailVerified bool
+}
+
+type SessionToken struct {
+	AccessToken
+	RefreshToken     string
+	RefreshExpiredAt int64
+}
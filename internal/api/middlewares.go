@@ -5,6 +5,7 @@ import (
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"net/http"
+	"strconv"
 )
 
 type ValidateResetPasswordTokenFn func(token string) (*dto.ResetPasswordSession, error)
@@ -80,3 +81,153 @@ func NewVerifyEmailSessionMiddleware(vFn ValidateVerifyEmailTokenFn, uFn Validat
 		return nhttp.Handler{Fn: fn, Logger: logger}
 	}
 }
+
+type ValidateChangeEmailTokenFn func(token string) (*dto.ChangeEmailSession, error)
+type ValidateChangeEmailUserFn func(session *dto.ChangeEmailSession) (string, error)
+
+// / NewChangeEmailSessionMiddleware creates a middleware that validate a one time token for
+// / email change before calling handler function
+func NewChangeEmailSessionMiddleware(vFn ValidateChangeEmailTokenFn, uFn ValidateChangeEmailUserFn, authKey string,
+	logger nlog.Logger) nhttp.Middleware {
+	// Return Middleware
+	return func(next nhttp.Handler) nhttp.Handler {
+		// Prepare function for email change handling
+		fn := func(r *http.Request) (*nhttp.Success, error) {
+			// Get token
+			authValue := r.Header.Get(authKey)
+
+			// Validate token and get session claims
+			session, err := vFn(authValue)
+			if err != nil {
+				return nil, err
+			}
+
+			// Validate user signature and get user id
+			userId, err := uFn(session)
+			if err != nil {
+				return nil, err
+			}
+
+			// Set user id, new email to header
+			r.Header.Set(nhttp.KeyUserId, userId)
+			r.Header.Set(KeyNewEmail, session.NewEmail)
+
+			// Call next handler
+			return next.Fn(r)
+		}
+
+		return nhttp.Handler{Fn: fn, Logger: logger}
+	}
+}
+
+type ValidateOrganizationTokenFn func(token string) (*dto.OrganizationSession, error)
+type ValidateOrganizationAdminFn func(session *dto.OrganizationSession) error
+
+// / NewOrganizationSessionMiddleware creates a middleware that validate organization admin access token before
+// / calling handler function
+func NewOrganizationSessionMiddleware(vFn ValidateOrganizationTokenFn, uFn ValidateOrganizationAdminFn, authKey string,
+	logger nlog.Logger) nhttp.Middleware {
+	// Return Middleware
+	return func(next nhttp.Handler) nhttp.Handler {
+		// Prepare function for organization admin auth handling
+		fn := func(r *http.Request) (*nhttp.Success, error) {
+			// Get token
+			authValue := r.Header.Get(authKey)
+
+			// Validate token and get session claims
+			session, err := vFn(authValue)
+			if err != nil {
+				return nil, err
+			}
+
+			// Validate user is still an admin of organization
+			err = uFn(session)
+			if err != nil {
+				return nil, err
+			}
+
+			// Set user id, organization id to header
+			r.Header.Set(nhttp.KeyUserId, session.UserId)
+			r.Header.Set(KeyOrganizationId, session.OrganizationId)
+
+			// Call next handler
+			return next.Fn(r)
+		}
+
+		return nhttp.Handler{Fn: fn, Logger: logger}
+	}
+}
+
+type ValidateAdvertiserTokenFn func(token string) (string, error)
+type ValidateAdvertiserFn func(userId string) error
+
+// / NewAdvertiserSessionMiddleware creates a middleware that validate advertiser access token before
+// / calling handler function
+func NewAdvertiserSessionMiddleware(vFn ValidateAdvertiserTokenFn, uFn ValidateAdvertiserFn, authKey string,
+	logger nlog.Logger) nhttp.Middleware {
+	// Return Middleware
+	return func(next nhttp.Handler) nhttp.Handler {
+		// Prepare function for advertiser auth handling
+		fn := func(r *http.Request) (*nhttp.Success, error) {
+			// Get token
+			authValue := r.Header.Get(authKey)
+
+			// Validate token and get advertiser user id
+			userId, err := vFn(authValue)
+			if err != nil {
+				return nil, err
+			}
+
+			// Validate user still has an active advertiser subscription
+			err = uFn(userId)
+			if err != nil {
+				return nil, err
+			}
+
+			// Set user id to header
+			r.Header.Set(nhttp.KeyUserId, userId)
+
+			// Call next handler
+			return next.Fn(r)
+		}
+
+		return nhttp.Handler{Fn: fn, Logger: logger}
+	}
+}
+
+type ValidateTwoFactorTokenFn func(token string) (*dto.TwoFactorSession, error)
+
+// / NewTwoFactorSessionMiddleware creates a middleware that validate a one time token for two factor challenge
+// / before calling handler function. Token is only accepted if issued for the given target
+func NewTwoFactorSessionMiddleware(vFn ValidateTwoFactorTokenFn, target string, authKey string,
+	logger nlog.Logger) nhttp.Middleware {
+	// Return Middleware
+	return func(next nhttp.Handler) nhttp.Handler {
+		// Prepare function for two factor challenge handling
+		fn := func(r *http.Request) (*nhttp.Success, error) {
+			// Get token
+			authValue := r.Header.Get(authKey)
+
+			// Validate token and get session claims
+			session, err := vFn(authValue)
+			if err != nil {
+				return nil, err
+			}
+
+			// Validate challenge target
+			if session.Target != target {
+				return nil, nhttp.ErrUnauthorized
+			}
+
+			// Set user id, organization id and auth provider id to header
+			r.Header.Set(nhttp.KeyUserId, session.UserId)
+			r.Header.Set(KeyOrganizationId, session.OrganizationId)
+			r.Header.Set(KeyAuthProviderId, strconv.Itoa(session.AuthProviderId))
+
+			// Call next handler
+			return next.Fn(r)
+		}
+
+		return nhttp.Handler{Fn: fn, Logger: logger}
+	}
+}
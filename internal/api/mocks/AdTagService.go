@@ -12,13 +12,13 @@ type AdTagService struct {
 	mock.Mock
 }
 
-// GetAdTags provides a mock function with given fields:
-func (_m *AdTagService) GetAdTags() (*dto.AdTagResp, error) {
-	ret := _m.Called()
+// FollowTags provides a mock function with given fields: req
+func (_m *AdTagService) FollowTags(req dto.AdTagFollowReq) (*dto.AdTagResp, error) {
+	ret := _m.Called(req)
 
 	var r0 *dto.AdTagResp
-	if rf, ok := ret.Get(0).(func() *dto.AdTagResp); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(dto.AdTagFollowReq) *dto.AdTagResp); ok {
+		r0 = rf(req)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*dto.AdTagResp)
@@ -26,8 +26,31 @@ func (_m *AdTagService) GetAdTags() (*dto.AdTagResp, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(dto.AdTagFollowReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAdTags provides a mock function with given fields: userId
+func (_m *AdTagService) GetAdTags(userId string) (*dto.AdTagResp, error) {
+	ret := _m.Called(userId)
+
+	var r0 *dto.AdTagResp
+	if rf, ok := ret.Get(0).(func(string) *dto.AdTagResp); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.AdTagResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -14,6 +14,20 @@ type AssetService struct {
 	mock.Mock
 }
 
+// DeleteFile provides a mock function with given fields: assetType, fileName
+func (_m *AssetService) DeleteFile(assetType int, fileName string) error {
+	ret := _m.Called(assetType, fileName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, string) error); ok {
+		r0 = rf(assetType, fileName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetPublicUrl provides a mock function with given fields: assetType, fileName
 func (_m *AssetService) GetPublicUrl(assetType int, fileName string) string {
 	ret := _m.Called(assetType, fileName)
@@ -51,6 +65,20 @@ func (_m *AssetService) GetUploadRule(assetType int) (*nhttp.UploadRule, error)
 	return r0, r1
 }
 
+// NewImageError provides a mock function with given fields: err
+func (_m *AssetService) NewImageError(err error) error {
+	ret := _m.Called(err)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(error) error); ok {
+		r0 = rf(err)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UploadFile provides a mock function with given fields: req
 func (_m *AssetService) UploadFile(req dto.UploadReq) (*dto.UploadResp, error) {
 	ret := _m.Called(req)
@@ -73,3 +101,26 @@ func (_m *AssetService) UploadFile(req dto.UploadReq) (*dto.UploadResp, error) {
 
 	return r0, r1
 }
+
+// UploadPrivateFile provides a mock function with given fields: req
+func (_m *AssetService) UploadPrivateFile(req dto.PrivateUploadReq) (*dto.UploadResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UploadResp
+	if rf, ok := ret.Get(0).(func(dto.PrivateUploadReq) *dto.UploadResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UploadResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.PrivateUploadReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
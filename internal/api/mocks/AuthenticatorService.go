@@ -37,6 +37,52 @@ func (_m *AuthenticatorService) NewAccessToken(req dto.JWTOptReq) (*entity.Acces
 	return r0, r1
 }
 
+// NewAdminAccessToken provides a mock function with given fields: req
+func (_m *AuthenticatorService) NewAdminAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
+	ret := _m.Called(req)
+
+	var r0 *entity.AccessToken
+	if rf, ok := ret.Get(0).(func(dto.JWTOptReq) *entity.AccessToken); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.AccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.JWTOptReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAdvertiserAccessToken provides a mock function with given fields: req
+func (_m *AuthenticatorService) NewAdvertiserAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
+	ret := _m.Called(req)
+
+	var r0 *entity.AccessToken
+	if rf, ok := ret.Get(0).(func(dto.JWTOptReq) *entity.AccessToken); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.AccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.JWTOptReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewOneTimeToken provides a mock function with given fields: req
 func (_m *AuthenticatorService) NewOneTimeToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
 	ret := _m.Called(req)
@@ -60,6 +106,29 @@ func (_m *AuthenticatorService) NewOneTimeToken(req dto.JWTOptReq) (*entity.Acce
 	return r0, r1
 }
 
+// NewOrganizationAccessToken provides a mock function with given fields: req
+func (_m *AuthenticatorService) NewOrganizationAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
+	ret := _m.Called(req)
+
+	var r0 *entity.AccessToken
+	if rf, ok := ret.Get(0).(func(dto.JWTOptReq) *entity.AccessToken); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.AccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.JWTOptReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SignMd5 provides a mock function with given fields: req
 func (_m *AuthenticatorService) SignMd5(req dto.SignatureReq) (string, error) {
 	ret := _m.Called(req)
@@ -81,6 +150,78 @@ func (_m *AuthenticatorService) SignMd5(req dto.SignatureReq) (string, error) {
 	return r0, r1
 }
 
+// ValidateAdminAccess provides a mock function with given fields: bearer
+func (_m *AuthenticatorService) ValidateAdminAccess(bearer string) (string, string, error) {
+	ret := _m.Called(bearer)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(bearer)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(string) string); ok {
+		r1 = rf(bearer)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(bearer)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ValidateAdvertiserAccess provides a mock function with given fields: bearer
+func (_m *AuthenticatorService) ValidateAdvertiserAccess(bearer string) (string, error) {
+	ret := _m.Called(bearer)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(bearer)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(bearer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateChangeEmailToken provides a mock function with given fields: token
+func (_m *AuthenticatorService) ValidateChangeEmailToken(token string) (*dto.ChangeEmailSession, error) {
+	ret := _m.Called(token)
+
+	var r0 *dto.ChangeEmailSession
+	if rf, ok := ret.Get(0).(func(string) *dto.ChangeEmailSession); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ChangeEmailSession)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ValidateClient provides a mock function with given fields: secret
 func (_m *AuthenticatorService) ValidateClient(secret string) error {
 	ret := _m.Called(secret)
@@ -95,18 +236,27 @@ func (_m *AuthenticatorService) ValidateClient(secret string) error {
 	return r0
 }
 
-// ValidateClientDashboard provides a mock function with given fields: secret
-func (_m *AuthenticatorService) ValidateClientDashboard(secret string) error {
-	ret := _m.Called(secret)
+// ValidateOrganizationAccess provides a mock function with given fields: bearer
+func (_m *AuthenticatorService) ValidateOrganizationAccess(bearer string) (*dto.OrganizationSession, error) {
+	ret := _m.Called(bearer)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(secret)
+	var r0 *dto.OrganizationSession
+	if rf, ok := ret.Get(0).(func(string) *dto.OrganizationSession); ok {
+		r0 = rf(bearer)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.OrganizationSession)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(bearer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // ValidateResetPasswordToken provides a mock function with given fields: token
@@ -132,6 +282,29 @@ func (_m *AuthenticatorService) ValidateResetPasswordToken(token string) (*dto.R
 	return r0, r1
 }
 
+// ValidateTwoFactorToken provides a mock function with given fields: token
+func (_m *AuthenticatorService) ValidateTwoFactorToken(token string) (*dto.TwoFactorSession, error) {
+	ret := _m.Called(token)
+
+	var r0 *dto.TwoFactorSession
+	if rf, ok := ret.Get(0).(func(string) *dto.TwoFactorSession); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TwoFactorSession)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ValidateUserAccess provides a mock function with given fields: bearer
 func (_m *AuthenticatorService) ValidateUserAccess(bearer string) (string, string, error) {
 	ret := _m.Called(bearer)
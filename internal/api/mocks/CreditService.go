@@ -56,6 +56,52 @@ func (_m *CreditService) CheckChargeAmount(opt dto.CreditChargeOpt) (int, error)
 	return r0, r1
 }
 
+// ExpireCredits provides a mock function with given fields: opt
+func (_m *CreditService) ExpireCredits(opt dto.CreditExpireOpt) (*dto.CreditExpireResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.CreditExpireResp
+	if rf, ok := ret.Get(0).(func(dto.CreditExpireOpt) *dto.CreditExpireResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CreditExpireResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditExpireOpt) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportTrxHistory provides a mock function with given fields: opt
+func (_m *CreditService) ExportTrxHistory(opt dto.CreditTrxHistoryReq) ([]byte, error) {
+	ret := _m.Called(opt)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(dto.CreditTrxHistoryReq) []byte); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditTrxHistoryReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserBalance provides a mock function with given fields: userId
 func (_m *CreditService) GetUserBalance(userId string) (*dto.UserCreditBalanceResp, error) {
 	ret := _m.Called(userId)
@@ -123,6 +169,121 @@ func (_m *CreditService) InsertPendingTrx(opt dto.CreditTrxOpt) (string, error)
 	return r0, r1
 }
 
+// ListAdjustments provides a mock function with given fields: opt
+func (_m *CreditService) ListAdjustments(opt dto.CreditAdjustmentListReq) ([]dto.CreditAdjustmentResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 []dto.CreditAdjustmentResp
+	if rf, ok := ret.Get(0).(func(dto.CreditAdjustmentListReq) []dto.CreditAdjustmentResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.CreditAdjustmentResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditAdjustmentListReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTrxHistory provides a mock function with given fields: opt
+func (_m *CreditService) ListTrxHistory(opt dto.CreditTrxHistoryReq) (*dto.CreditTrxHistoryListResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.CreditTrxHistoryListResp
+	if rf, ok := ret.Get(0).(func(dto.CreditTrxHistoryReq) *dto.CreditTrxHistoryListResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CreditTrxHistoryListResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditTrxHistoryReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefundTrx provides a mock function with given fields: opt
+func (_m *CreditService) RefundTrx(opt dto.CreditRefundOpt) (*model.UserCreditWalletTrx, error) {
+	ret := _m.Called(opt)
+
+	var r0 *model.UserCreditWalletTrx
+	if rf, ok := ret.Get(0).(func(dto.CreditRefundOpt) *model.UserCreditWalletTrx); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserCreditWalletTrx)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditRefundOpt) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestAdjustment provides a mock function with given fields: opt
+func (_m *CreditService) RequestAdjustment(opt dto.CreditAdjustmentReq) (*dto.CreditAdjustmentResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.CreditAdjustmentResp
+	if rf, ok := ret.Get(0).(func(dto.CreditAdjustmentReq) *dto.CreditAdjustmentResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CreditAdjustmentResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditAdjustmentReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewAdjustment provides a mock function with given fields: opt
+func (_m *CreditService) ReviewAdjustment(opt dto.CreditAdjustmentReviewReq) (*dto.CreditAdjustmentResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.CreditAdjustmentResp
+	if rf, ok := ret.Get(0).(func(dto.CreditAdjustmentReviewReq) *dto.CreditAdjustmentResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CreditAdjustmentResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditAdjustmentReviewReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SettlePendingTrx provides a mock function with given fields: opt
 func (_m *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 	ret := _m.Called(opt)
@@ -136,3 +297,47 @@ func (_m *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 
 	return r0
 }
+
+// SumEarnedCredit provides a mock function with given fields: opt
+func (_m *CreditService) SumEarnedCredit(opt dto.CreditEarnedOpt) (float64, error) {
+	ret := _m.Called(opt)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(dto.CreditEarnedOpt) float64); ok {
+		r0 = rf(opt)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditEarnedOpt) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Transfer provides a mock function with given fields: opt
+func (_m *CreditService) Transfer(opt dto.CreditTransferReq) (*dto.CreditTransferResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.CreditTransferResp
+	if rf, ok := ret.Get(0).(func(dto.CreditTransferReq) *dto.CreditTransferResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CreditTransferResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.CreditTransferReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
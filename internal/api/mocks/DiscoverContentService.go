@@ -5,6 +5,8 @@ package mocks
 import (
 	dto "github.com/diarikom/running-app/running-app-api/internal/api/dto"
 	mock "github.com/stretchr/testify/mock"
+
+	nhttp "github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 )
 
 // DiscoverContentService is an autogenerated mock type for the DiscoverContentService type
@@ -12,13 +14,73 @@ type DiscoverContentService struct {
 	mock.Mock
 }
 
-// GetContents provides a mock function with given fields: skip, limit
-func (_m *DiscoverContentService) GetContents(skip int64, limit int8) (*dto.DiscoverContentResp, error) {
-	ret := _m.Called(skip, limit)
+// Create provides a mock function with given fields: opt
+func (_m *DiscoverContentService) Create(opt dto.DiscoverContentReq) (*dto.DiscoverContentDetailResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.DiscoverContentDetailResp
+	if rf, ok := ret.Get(0).(func(dto.DiscoverContentReq) *dto.DiscoverContentDetailResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DiscoverContentDetailResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DiscoverContentReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: opt
+func (_m *DiscoverContentService) Delete(opt dto.DiscoverContentDeleteReq) error {
+	ret := _m.Called(opt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.DiscoverContentDeleteReq) error); ok {
+		r0 = rf(opt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: id
+func (_m *DiscoverContentService) Get(id string) (*dto.DiscoverContentDetailResp, error) {
+	ret := _m.Called(id)
+
+	var r0 *dto.DiscoverContentDetailResp
+	if rf, ok := ret.Get(0).(func(string) *dto.DiscoverContentDetailResp); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DiscoverContentDetailResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetContents provides a mock function with given fields: opt
+func (_m *DiscoverContentService) GetContents(opt dto.DiscoverContentQueryReq) (*dto.DiscoverContentResp, error) {
+	ret := _m.Called(opt)
 
 	var r0 *dto.DiscoverContentResp
-	if rf, ok := ret.Get(0).(func(int64, int8) *dto.DiscoverContentResp); ok {
-		r0 = rf(skip, limit)
+	if rf, ok := ret.Get(0).(func(dto.DiscoverContentQueryReq) *dto.DiscoverContentResp); ok {
+		r0 = rf(opt)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*dto.DiscoverContentResp)
@@ -26,8 +88,77 @@ func (_m *DiscoverContentService) GetContents(skip int64, limit int8) (*dto.Disc
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int64, int8) error); ok {
-		r1 = rf(skip, limit)
+	if rf, ok := ret.Get(1).(func(dto.DiscoverContentQueryReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: opt
+func (_m *DiscoverContentService) List(opt dto.DiscoverContentListReq) ([]dto.DiscoverContentDetailResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 []dto.DiscoverContentDetailResp
+	if rf, ok := ret.Get(0).(func(dto.DiscoverContentListReq) []dto.DiscoverContentDetailResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.DiscoverContentDetailResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DiscoverContentListReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: opt
+func (_m *DiscoverContentService) Update(opt dto.DiscoverContentReq) (*dto.DiscoverContentDetailResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.DiscoverContentDetailResp
+	if rf, ok := ret.Get(0).(func(dto.DiscoverContentReq) *dto.DiscoverContentDetailResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DiscoverContentDetailResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DiscoverContentReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UploadImage provides a mock function with given fields: file
+func (_m *DiscoverContentService) UploadImage(file nhttp.MultipartFile) (*dto.UploadResp, error) {
+	ret := _m.Called(file)
+
+	var r0 *dto.UploadResp
+	if rf, ok := ret.Get(0).(func(nhttp.MultipartFile) *dto.UploadResp); ok {
+		r0 = rf(file)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UploadResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(nhttp.MultipartFile) error); ok {
+		r1 = rf(file)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -12,6 +12,52 @@ type InitiativeService struct {
 	mock.Mock
 }
 
+// CloseExpired provides a mock function with given fields:
+func (_m *InitiativeService) CloseExpired() (*dto.InitiativeCloseResp, error) {
+	ret := _m.Called()
+
+	var r0 *dto.InitiativeCloseResp
+	if rf, ok := ret.Get(0).(func() *dto.InitiativeCloseResp); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.InitiativeCloseResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePledge provides a mock function with given fields: opt
+func (_m *InitiativeService) CreatePledge(opt dto.DonationPledgeReq) (*dto.DonationPledgeResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.DonationPledgeResp
+	if rf, ok := ret.Get(0).(func(dto.DonationPledgeReq) *dto.DonationPledgeResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DonationPledgeResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DonationPledgeReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Donate provides a mock function with given fields: opt
 func (_m *InitiativeService) Donate(opt dto.DonateReq) (*dto.DonateResp, error) {
 	ret := _m.Called(opt)
@@ -35,12 +81,58 @@ func (_m *InitiativeService) Donate(opt dto.DonateReq) (*dto.DonateResp, error)
 	return r0, r1
 }
 
+// ExportDonationReceipt provides a mock function with given fields: opt
+func (_m *InitiativeService) ExportDonationReceipt(opt dto.DonationReceiptReq) ([]byte, error) {
+	ret := _m.Called(opt)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(dto.DonationReceiptReq) []byte); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DonationReceiptReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDonationReceipt provides a mock function with given fields: opt
+func (_m *InitiativeService) GetDonationReceipt(opt dto.DonationReceiptReq) (*dto.DonationReceiptResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.DonationReceiptResp
+	if rf, ok := ret.Get(0).(func(dto.DonationReceiptReq) *dto.DonationReceiptResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DonationReceiptResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DonationReceiptReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: opt
-func (_m *InitiativeService) List(opt dto.PageReq) ([]dto.InitiativeResp, error) {
+func (_m *InitiativeService) List(opt dto.InitiativeListReq) ([]dto.InitiativeResp, error) {
 	ret := _m.Called(opt)
 
 	var r0 []dto.InitiativeResp
-	if rf, ok := ret.Get(0).(func(dto.PageReq) []dto.InitiativeResp); ok {
+	if rf, ok := ret.Get(0).(func(dto.InitiativeListReq) []dto.InitiativeResp); ok {
 		r0 = rf(opt)
 	} else {
 		if ret.Get(0) != nil {
@@ -49,7 +141,76 @@ func (_m *InitiativeService) List(opt dto.PageReq) ([]dto.InitiativeResp, error)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(dto.PageReq) error); ok {
+	if rf, ok := ret.Get(1).(func(dto.InitiativeListReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPledgeRuns provides a mock function with given fields: opt
+func (_m *InitiativeService) ListPledgeRuns(opt dto.DonationPledgeRunListReq) ([]dto.DonationPledgeRunResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 []dto.DonationPledgeRunResp
+	if rf, ok := ret.Get(0).(func(dto.DonationPledgeRunListReq) []dto.DonationPledgeRunResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.DonationPledgeRunResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DonationPledgeRunListReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPledges provides a mock function with given fields: opt
+func (_m *InitiativeService) ListPledges(opt dto.UserResourcesReq) ([]dto.DonationPledgeResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 []dto.DonationPledgeResp
+	if rf, ok := ret.Get(0).(func(dto.UserResourcesReq) []dto.DonationPledgeResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.DonationPledgeResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserResourcesReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTagFacets provides a mock function with given fields: opt
+func (_m *InitiativeService) ListTagFacets(opt dto.InitiativeListReq) ([]dto.InitiativeTagFacetResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 []dto.InitiativeTagFacetResp
+	if rf, ok := ret.Get(0).(func(dto.InitiativeListReq) []dto.InitiativeTagFacetResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.InitiativeTagFacetResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.InitiativeListReq) error); ok {
 		r1 = rf(opt)
 	} else {
 		r1 = ret.Error(1)
@@ -80,3 +241,86 @@ func (_m *InitiativeService) ListUserDonation(opt dto.UserResourcesReq) ([]dto.D
 
 	return r0, r1
 }
+
+// RefundDonation provides a mock function with given fields: opt
+func (_m *InitiativeService) RefundDonation(opt dto.DonationRefundReq) (*dto.DonationRefundResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.DonationRefundResp
+	if rf, ok := ret.Get(0).(func(dto.DonationRefundReq) *dto.DonationRefundResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DonationRefundResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DonationRefundReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunPledges provides a mock function with given fields:
+func (_m *InitiativeService) RunPledges() (*dto.DonationPledgeExecResp, error) {
+	ret := _m.Called()
+
+	var r0 *dto.DonationPledgeExecResp
+	if rf, ok := ret.Get(0).(func() *dto.DonationPledgeExecResp); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DonationPledgeExecResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateFundingGoal provides a mock function with given fields: opt
+func (_m *InitiativeService) UpdateFundingGoal(opt dto.InitiativeFundingReq) error {
+	ret := _m.Called(opt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.InitiativeFundingReq) error); ok {
+		r0 = rf(opt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePledgeStatus provides a mock function with given fields: opt
+func (_m *InitiativeService) UpdatePledgeStatus(opt dto.DonationPledgeStatusReq) (*dto.DonationPledgeResp, error) {
+	ret := _m.Called(opt)
+
+	var r0 *dto.DonationPledgeResp
+	if rf, ok := ret.Get(0).(func(dto.DonationPledgeStatusReq) *dto.DonationPledgeResp); ok {
+		r0 = rf(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DonationPledgeResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.DonationPledgeStatusReq) error); ok {
+		r1 = rf(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -12,6 +12,22 @@ type JWTIssuerComponent struct {
 	mock.Mock
 }
 
+// JWKS provides a mock function with given fields:
+func (_m *JWTIssuerComponent) JWKS() *njwt.JWKS {
+	ret := _m.Called()
+
+	var r0 *njwt.JWKS
+	if rf, ok := ret.Get(0).(func() *njwt.JWKS); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*njwt.JWKS)
+		}
+	}
+
+	return r0
+}
+
 // New provides a mock function with given fields: opt
 func (_m *JWTIssuerComponent) New(opt njwt.ClaimOpt) (*njwt.Token, error) {
 	ret := _m.Called(opt)
@@ -6,6 +6,8 @@ import (
 	io "io"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // S3Provider is an autogenerated mock type for the S3Provider type
@@ -13,6 +15,41 @@ type S3Provider struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: dest
+func (_m *S3Provider) Delete(dest string) error {
+	ret := _m.Called(dest)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(dest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPresignedUrl provides a mock function with given fields: dest, lifetime
+func (_m *S3Provider) GetPresignedUrl(dest string, lifetime time.Duration) (string, error) {
+	ret := _m.Called(dest, lifetime)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, time.Duration) string); ok {
+		r0 = rf(dest, lifetime)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Duration) error); ok {
+		r1 = rf(dest, lifetime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Upload provides a mock function with given fields: file, contentType, dest, scope
 func (_m *S3Provider) Upload(file io.Reader, contentType string, dest string, scope int) error {
 	ret := _m.Called(file, contentType, dest, scope)
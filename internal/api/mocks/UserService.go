@@ -14,6 +14,48 @@ type UserService struct {
 	mock.Mock
 }
 
+// CancelDeletion provides a mock function with given fields: userId
+func (_m *UserService) CancelDeletion(userId string) error {
+	ret := _m.Called(userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CancelSubscription provides a mock function with given fields: req
+func (_m *UserService) CancelSubscription(req dto.UserSubscriptionReq) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.UserSubscriptionReq) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ChangeEmail provides a mock function with given fields: req
+func (_m *UserService) ChangeEmail(req dto.UserChangeEmailReq) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.UserChangeEmailReq) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ChangePassword provides a mock function with given fields: req
 func (_m *UserService) ChangePassword(req dto.ChangePasswordReq) error {
 	ret := _m.Called(req)
@@ -28,6 +70,89 @@ func (_m *UserService) ChangePassword(req dto.ChangePasswordReq) error {
 	return r0
 }
 
+// DeleteDueAccounts provides a mock function with given fields:
+func (_m *UserService) DeleteDueAccounts() (*dto.UserDeletionRunResp, error) {
+	ret := _m.Called()
+
+	var r0 *dto.UserDeletionRunResp
+	if rf, ok := ret.Get(0).(func() *dto.UserDeletionRunResp); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserDeletionRunResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DisableTwoFactor provides a mock function with given fields: req
+func (_m *UserService) DisableTwoFactor(req dto.TwoFactorReq) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.TwoFactorReq) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableTwoFactor provides a mock function with given fields: req
+func (_m *UserService) EnableTwoFactor(req dto.TwoFactorReq) (*dto.UserRecoveryCodesResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UserRecoveryCodesResp
+	if rf, ok := ret.Get(0).(func(dto.TwoFactorReq) *dto.UserRecoveryCodesResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserRecoveryCodesResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.TwoFactorReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnrollTwoFactor provides a mock function with given fields: userId
+func (_m *UserService) EnrollTwoFactor(userId string) (*dto.UserTwoFactorEnrollResp, error) {
+	ret := _m.Called(userId)
+
+	var r0 *dto.UserTwoFactorEnrollResp
+	if rf, ok := ret.Get(0).(func(string) *dto.UserTwoFactorEnrollResp); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserTwoFactorEnrollResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProfile provides a mock function with given fields: userId
 func (_m *UserService) GetProfile(userId string) (*dto.UserProfileResp, error) {
 	ret := _m.Called(userId)
@@ -74,22 +199,202 @@ func (_m *UserService) GetProfileSnapshot(userId string) (*model.UserSnapshot, e
 	return r0, r1
 }
 
-// IsEmailExists provides a mock function with given fields: email
-func (_m *UserService) IsEmailExists(email string) (interface{}, error) {
-	ret := _m.Called(email)
+// GetSubscriptionDetail provides a mock function with given fields: args
+func (_m *UserService) GetSubscriptionDetail(args dto.UserSubscriptionReq) (*dto.UserSubscribeResp, error) {
+	ret := _m.Called(args)
+
+	var r0 *dto.UserSubscribeResp
+	if rf, ok := ret.Get(0).(func(dto.UserSubscriptionReq) *dto.UserSubscribeResp); ok {
+		r0 = rf(args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserSubscribeResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserSubscriptionReq) error); ok {
+		r1 = rf(args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTwoFactor provides a mock function with given fields: userId
+func (_m *UserService) GetTwoFactor(userId string) (*dto.UserTwoFactorResp, error) {
+	ret := _m.Called(userId)
+
+	var r0 *dto.UserTwoFactorResp
+	if rf, ok := ret.Get(0).(func(string) *dto.UserTwoFactorResp); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserTwoFactorResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserProviderRefId provides a mock function with given fields: req
+func (_m *UserService) GetUserProviderRefId(req dto.UserSubscriptionReq) (*dto.UserSubscriptionRequestResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UserSubscriptionRequestResp
+	if rf, ok := ret.Get(0).(func(dto.UserSubscriptionReq) *dto.UserSubscriptionRequestResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserSubscriptionRequestResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserSubscriptionReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsEmailExists provides a mock function with given fields: email, clientIp
+func (_m *UserService) IsEmailExists(email string, clientIp string) (interface{}, error) {
+	ret := _m.Called(email, clientIp)
 
 	var r0 interface{}
-	if rf, ok := ret.Get(0).(func(string) interface{}); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(string, string) interface{}); ok {
+		r0 = rf(email, clientIp)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(interface{})
 		}
 	}
 
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(email, clientIp)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsPremiumRunner provides a mock function with given fields: userId
+func (_m *UserService) IsPremiumRunner(userId string) (bool, error) {
+	ret := _m.Called(userId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsTwoFactorEnabled provides a mock function with given fields: userId
+func (_m *UserService) IsTwoFactorEnabled(userId string) (bool, error) {
+	ret := _m.Called(userId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LinkAuthProvider provides a mock function with given fields: req
+func (_m *UserService) LinkAuthProvider(req dto.UserAuthProviderReq) (*dto.UserAuthProvidersResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UserAuthProvidersResp
+	if rf, ok := ret.Get(0).(func(dto.UserAuthProviderReq) *dto.UserAuthProvidersResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserAuthProvidersResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserAuthProviderReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAuthProviders provides a mock function with given fields: userId
+func (_m *UserService) ListAuthProviders(userId string) (*dto.UserAuthProvidersResp, error) {
+	ret := _m.Called(userId)
+
+	var r0 *dto.UserAuthProvidersResp
+	if rf, ok := ret.Get(0).(func(string) *dto.UserAuthProvidersResp); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserAuthProvidersResp)
+		}
+	}
+
 	var r1 error
 	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSessions provides a mock function with given fields: sessionId, userId
+func (_m *UserService) ListSessions(sessionId string, userId string) ([]dto.UserSessionResp, error) {
+	ret := _m.Called(sessionId, userId)
+
+	var r0 []dto.UserSessionResp
+	if rf, ok := ret.Get(0).(func(string, string) []dto.UserSessionResp); ok {
+		r0 = rf(sessionId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.UserSessionResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(sessionId, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -120,6 +425,29 @@ func (_m *UserService) Login(req dto.UserLoginReq) (map[string]string, error) {
 	return r0, r1
 }
 
+// LoginByApple provides a mock function with given fields: req
+func (_m *UserService) LoginByApple(req dto.UserLoginReq) (map[string]string, error) {
+	ret := _m.Called(req)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(dto.UserLoginReq) map[string]string); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserLoginReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LoginByFacebook provides a mock function with given fields: req
 func (_m *UserService) LoginByFacebook(req dto.UserLoginReq) (map[string]string, error) {
 	ret := _m.Called(req)
@@ -143,13 +471,59 @@ func (_m *UserService) LoginByFacebook(req dto.UserLoginReq) (map[string]string,
 	return r0, r1
 }
 
-// Logout provides a mock function with given fields: userId
-func (_m *UserService) Logout(userId string) error {
-	ret := _m.Called(userId)
+// LoginByGoogle provides a mock function with given fields: req
+func (_m *UserService) LoginByGoogle(req dto.UserLoginReq) (map[string]string, error) {
+	ret := _m.Called(req)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(dto.UserLoginReq) map[string]string); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserLoginReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoginByTwoFactor provides a mock function with given fields: req
+func (_m *UserService) LoginByTwoFactor(req dto.UserTwoFactorLoginReq) (map[string]string, error) {
+	ret := _m.Called(req)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(dto.UserTwoFactorLoginReq) map[string]string); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserTwoFactorLoginReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Logout provides a mock function with given fields: sessionId, userId
+func (_m *UserService) Logout(sessionId string, userId string) error {
+	ret := _m.Called(sessionId, userId)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(userId)
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(sessionId, userId)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -157,6 +531,29 @@ func (_m *UserService) Logout(userId string) error {
 	return r0
 }
 
+// NewTwoFactorChallenge provides a mock function with given fields: session
+func (_m *UserService) NewTwoFactorChallenge(session dto.TwoFactorSession) (map[string]string, error) {
+	ret := _m.Called(session)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(dto.TwoFactorSession) map[string]string); ok {
+		r0 = rf(session)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.TwoFactorSession) error); ok {
+		r1 = rf(session)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RefreshSession provides a mock function with given fields: req
 func (_m *UserService) RefreshSession(req dto.UserRefreshSession) (map[string]string, error) {
 	ret := _m.Called(req)
@@ -180,6 +577,29 @@ func (_m *UserService) RefreshSession(req dto.UserRefreshSession) (map[string]st
 	return r0, r1
 }
 
+// RegenerateRecoveryCodes provides a mock function with given fields: req
+func (_m *UserService) RegenerateRecoveryCodes(req dto.TwoFactorReq) (*dto.UserRecoveryCodesResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UserRecoveryCodesResp
+	if rf, ok := ret.Get(0).(func(dto.TwoFactorReq) *dto.UserRecoveryCodesResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserRecoveryCodesResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.TwoFactorReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Register provides a mock function with given fields: req
 func (_m *UserService) Register(req dto.UserProfileReq) error {
 	ret := _m.Called(req)
@@ -194,13 +614,129 @@ func (_m *UserService) Register(req dto.UserProfileReq) error {
 	return r0
 }
 
-// RequestResetPassword provides a mock function with given fields: email
-func (_m *UserService) RequestResetPassword(email string) error {
-	ret := _m.Called(email)
+// RequestDataExport provides a mock function with given fields: userId
+func (_m *UserService) RequestDataExport(userId string) error {
+	ret := _m.Called(userId)
 
 	var r0 error
 	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(email)
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RequestDeletion provides a mock function with given fields: req
+func (_m *UserService) RequestDeletion(req dto.UserDeletionReq) (*dto.UserDeletionResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UserDeletionResp
+	if rf, ok := ret.Get(0).(func(dto.UserDeletionReq) *dto.UserDeletionResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserDeletionResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserDeletionReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestEmailChange provides a mock function with given fields: req
+func (_m *UserService) RequestEmailChange(req dto.UserChangeEmailReq) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.UserChangeEmailReq) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RequestResetPassword provides a mock function with given fields: email, clientIp
+func (_m *UserService) RequestResetPassword(email string, clientIp string) error {
+	ret := _m.Called(email, clientIp)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(email, clientIp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeOtherSessions provides a mock function with given fields: sessionId, userId
+func (_m *UserService) RevokeOtherSessions(sessionId string, userId string) error {
+	ret := _m.Called(sessionId, userId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(sessionId, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeSession provides a mock function with given fields: req
+func (_m *UserService) RevokeSession(req dto.UserRevokeSessionReq) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.UserRevokeSessionReq) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields: req
+func (_m *UserService) Subscribe(req dto.UserSubscriptionReq) (*dto.UserSubscribeResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UserSubscribeResp
+	if rf, ok := ret.Get(0).(func(dto.UserSubscriptionReq) *dto.UserSubscribeResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserSubscribeResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserSubscriptionReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TriggerSendAdvertiserActivation provides a mock function with given fields: req
+func (_m *UserService) TriggerSendAdvertiserActivation(req dto.AdvertiserActivationReq) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.AdvertiserActivationReq) error); ok {
+		r0 = rf(req)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -208,6 +744,29 @@ func (_m *UserService) RequestResetPassword(email string) error {
 	return r0
 }
 
+// UnlinkAuthProvider provides a mock function with given fields: req
+func (_m *UserService) UnlinkAuthProvider(req dto.UserAuthProviderReq) (*dto.UserAuthProvidersResp, error) {
+	ret := _m.Called(req)
+
+	var r0 *dto.UserAuthProvidersResp
+	if rf, ok := ret.Get(0).(func(dto.UserAuthProviderReq) *dto.UserAuthProvidersResp); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.UserAuthProvidersResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserAuthProviderReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateProfile provides a mock function with given fields: req
 func (_m *UserService) UpdateProfile(req dto.UserUpdateProfileReq) error {
 	ret := _m.Called(req)
@@ -236,6 +795,27 @@ func (_m *UserService) UpdateVerifyEmail(userId string) error {
 	return r0
 }
 
+// ValidateChangeEmailSignature provides a mock function with given fields: session
+func (_m *UserService) ValidateChangeEmailSignature(session *dto.ChangeEmailSession) (string, error) {
+	ret := _m.Called(session)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*dto.ChangeEmailSession) string); ok {
+		r0 = rf(session)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dto.ChangeEmailSession) error); ok {
+		r1 = rf(session)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ValidateResetPasswordSignature provides a mock function with given fields: session
 func (_m *UserService) ValidateResetPasswordSignature(session *dto.ResetPasswordSession) (string, error) {
 	ret := _m.Called(session)
@@ -291,3 +871,63 @@ func (_m *UserService) ValidateVerifyEmailSignature(session *dto.VerifyEmailSess
 
 	return r0, r1
 }
+
+// ValidateVoucher provides a mock function with given fields: args
+func (_m *UserService) ValidateVoucher(args dto.UserSubscriptionReq) (*dto.SubscriptionVoucherResp, error) {
+	ret := _m.Called(args)
+
+	var r0 *dto.SubscriptionVoucherResp
+	if rf, ok := ret.Get(0).(func(dto.UserSubscriptionReq) *dto.SubscriptionVoucherResp); ok {
+		r0 = rf(args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.SubscriptionVoucherResp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserSubscriptionReq) error); ok {
+		r1 = rf(args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyPassword provides a mock function with given fields: req
+func (_m *UserService) VerifyPassword(req dto.UserLoginReq) (*model.UserAuth, error) {
+	ret := _m.Called(req)
+
+	var r0 *model.UserAuth
+	if rf, ok := ret.Get(0).(func(dto.UserLoginReq) *model.UserAuth); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserAuth)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(dto.UserLoginReq) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyTwoFactor provides a mock function with given fields: req
+func (_m *UserService) VerifyTwoFactor(req dto.TwoFactorReq) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dto.TwoFactorReq) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
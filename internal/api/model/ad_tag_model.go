@@ -1,11 +1,19 @@
 package model
 
 import (
+	"database/sql"
 	"time"
 )
 
 type AdTag struct {
-	Id        int       `db:"id" diff:"id"`
-	Name      string    `db:"name"`
-	UpdatedAt time.Time `db:"updated_at" diff:"required"`
+	Id        int            `db:"id" diff:"id"`
+	Name      string         `db:"name"`
+	Segment   sql.NullString `db:"segment"`
+	UpdatedAt time.Time      `db:"updated_at" diff:"required"`
+}
+
+type UserAdTag struct {
+	UserId    string    `db:"user_id"`
+	AdTagId   int       `db:"ad_tag_id"`
+	CreatedAt time.Time `db:"created_at"`
 }
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+type Admin struct {
+	UserId     string         `db:"user_id"`
+	Roles      pq.StringArray `db:"roles"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"`
+	ModifiedBy *ModifierMeta  `db:"modified_by"`
+}
+
+type AdminDetail struct {
+	UserId       string         `db:"user_id"`
+	Email        string         `db:"email"`
+	Password     string         `db:"password"`
+	UserStatusId int            `db:"user_status_id"`
+	FullName     string         `db:"full_name"`
+	Roles        pq.StringArray `db:"roles"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+	ModifiedBy   *ModifierMeta  `db:"modified_by"`
+}
+
+type AdminAuditLog struct {
+	Id         string       `db:"id"`
+	UserId     string       `db:"user_id"`
+	Method     string       `db:"method"`
+	Path       string       `db:"path"`
+	Permission string       `db:"permission"`
+	StatusCode int          `db:"status_code"`
+	ClientIp   string       `db:"client_ip"`
+	ModifiedBy ModifierMeta `db:"modified_by"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+type AdCampaign struct {
+	Id             string        `db:"id"`
+	AdvertiserId   string        `db:"advertiser_id"`
+	Name           string        `db:"name"`
+	TargetTagIds   pq.Int64Array `db:"target_tag_ids"`
+	Budget         float64       `db:"budget"`
+	CostPerMille   float64       `db:"cost_per_mille"`
+	Spent          float64       `db:"spent"`
+	StartAt        time.Time     `db:"start_at"`
+	EndAt          pq.NullTime   `db:"end_at"`
+	StatusId       int8          `db:"status_id"`
+	CreatedAt      time.Time     `db:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at"`
+	Version        int64         `db:"version"`
+	CurrentVersion int64         `db:"current_version"`
+}
+
+type AdCreative struct {
+	Id         string    `db:"id"`
+	CampaignId string    `db:"campaign_id"`
+	ImageFile  string    `db:"image_file"`
+	Headline   string    `db:"headline"`
+	ClickUrl   string    `db:"click_url"`
+	StatusId   int8      `db:"status_id"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// AdServedCreative is a creative picked for user with the target tag that matches user the most
+type AdServedCreative struct {
+	AdCreative
+	AdTagId int64 `db:"ad_tag_id"`
+}
+
+type AdServe struct {
+	Id         string    `db:"id"`
+	UserId     string    `db:"user_id"`
+	CampaignId string    `db:"campaign_id"`
+	CreativeId string    `db:"creative_id"`
+	AdTagId    int64     `db:"ad_tag_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type AdEvent struct {
+	Id          string    `db:"id"`
+	EventTypeId int8      `db:"event_type_id"`
+	ServeId     string    `db:"serve_id"`
+	CampaignId  string    `db:"campaign_id"`
+	CreativeId  string    `db:"creative_id"`
+	AdTagId     int64     `db:"ad_tag_id"`
+	UserId      string    `db:"user_id"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type AdStat struct {
+	CampaignId  string    `db:"campaign_id"`
+	AdTagId     int64     `db:"ad_tag_id"`
+	HourAt      time.Time `db:"hour_at"`
+	Impressions int64     `db:"impressions"`
+	Clicks      int64     `db:"clicks"`
+	Reach       int64     `db:"reach"`
+}
+
+type AdTagStat struct {
+	AdTagId     int64  `db:"ad_tag_id"`
+	AdTagName   string `db:"ad_tag_name"`
+	Impressions int64  `db:"impressions"`
+	Clicks      int64  `db:"clicks"`
+}
@@ -5,24 +5,37 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/lib/pq"
 	"time"
 )
 
 type DiscoverContent struct {
-	Id          string             `db:"id" diff:"id"`
-	Title       string             `db:"title"`
-	ContentBody string             `db:"content_body"`
-	LogoFile    sql.NullString     `db:"logo_file"`
-	ExternalUrl json.RawMessage    `db:"external_url"`
-	StatusId    int                `db:"status_id"`
-	Sort        int                `db:"sort"`
-	CreatedAt   time.Time          `db:"created_at" diff:"-"`
-	UpdatedAt   time.Time          `db:"updated_at" diff:"required"`
-	Version     int                `db:"version" diff:"required"`
-	ModifiedBy  json.RawMessage    `db:"modified_by"`
-	ImageFiles  DiscoverImageFiles `db:"image_files"`
-	Headline    sql.NullString     `db:"headline"`
-	Tags        sql.NullString     `db:"tags"`
+	Id              string             `db:"id" diff:"id"`
+	OrganizationId  string             `db:"organization_id"`
+	Title           string             `db:"title" diff:"-"`
+	ContentBody     string             `db:"content_body"`
+	LogoFile        sql.NullString     `db:"logo_file"`
+	ExternalUrl     json.RawMessage    `db:"external_url"`
+	StatusId        int                `db:"status_id"`
+	Sort            int                `db:"sort"`
+	CreatedAt       time.Time          `db:"created_at" diff:"-"`
+	UpdatedAt       time.Time          `db:"updated_at" diff:"required"`
+	Version         int                `db:"version" diff:"required,cc"`
+	ModifiedBy      *ModifierMeta      `db:"modified_by" diff:"required"`
+	ImageFiles      DiscoverImageFiles `db:"image_files"`
+	Headline        sql.NullString     `db:"headline"`
+	Tags            sql.NullString     `db:"tags" diff:"-"`
+	TagIds          pq.Int64Array      `db:"tag_ids"`
+	PublishAt       pq.NullTime        `db:"publish_at"`
+	UnpublishAt     pq.NullTime        `db:"unpublish_at"`
+	AudiencePremium int8               `db:"audience_premium"`
+	AudienceRegions pq.StringArray     `db:"audience_regions"`
+}
+
+// DiscoverAudience is the audience attributes of user that are matched against content targeting
+type DiscoverAudience struct {
+	Premium int8
+	Region  string
 }
 
 type DiscoverImageFiles struct {
@@ -7,6 +7,7 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/entity"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
 	"github.com/jinzhu/copier"
+	"github.com/lib/pq"
 	"time"
 )
 
@@ -23,12 +24,28 @@ type Initiative struct {
 	StatusId           int8                `db:"status_id" json:"status_id"`
 	Tags               string              `db:"tags" json:"tags"`
 	StatDonationCount  int64               `db:"stat_donation_count" json:"stat_donation_count"`
+	FundingGoal        float64             `db:"funding_goal" json:"funding_goal"`
+	RaisedAmount       float64             `db:"raised_amount" json:"raised_amount"`
+	DeadlineAt         pq.NullTime         `db:"deadline_at" json:"-"`
 	CreatedAt          time.Time           `db:"created_at" json:"-"`
 	UpdatedAt          time.Time           `db:"updated_at" json:"-"`
 	Version            int64               `db:"version" json:"version"`
 	Headline           sql.NullString      `db:"headline" json:"-"`
 }
 
+type InitiativeFilter struct {
+	Query          string
+	Tag            string
+	OrganizationId string
+	StatusId       int8
+	SortBy         string
+}
+
+type InitiativeTagFacet struct {
+	Tag   string `db:"tag"`
+	Count int64  `db:"count"`
+}
+
 type ExternalURLArray []entity.ExternalURL
 
 func (e *ExternalURLArray) Scan(src interface{}) error {
@@ -96,6 +113,12 @@ type Donation struct {
 	Version            int64               `db:"version"  diff:"required,cc"`
 }
 
+type DonationRefund struct {
+	TrxId      string `json:"refund_trx_id"`
+	Reason     string `json:"reason"`
+	RefundedAt int64  `json:"refunded_at"`
+}
+
 func CopyDonation(d *Donation) (*Donation, error) {
 	// Duplicate donation
 	oldDonation := Donation{
@@ -127,3 +150,38 @@ type DonationLog struct {
 	ModifiedBy      *ModifierMeta   `db:"modified_by"`
 	Version         int64           `db:"version"`
 }
+
+type DonationPledge struct {
+	Id             string         `db:"id"`
+	UserId         string         `db:"user_id"`
+	InitiativeId   string         `db:"initiative_id"`
+	AmountTypeId   int8           `db:"amount_type_id"`
+	Amount         float64        `db:"amount"`
+	PeriodId       int8           `db:"period_id"`
+	StatusId       int8           `db:"status_id"`
+	Notes          sql.NullString `db:"notes"`
+	NextRunAt      time.Time      `db:"next_run_at"`
+	LastRunAt      pq.NullTime    `db:"last_run_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+	Version        int64          `db:"version"`
+	CurrentVersion int64          `db:"current_version"`
+}
+
+type DonationPledgeRun struct {
+	Id             string         `db:"id"`
+	PledgeId       string         `db:"pledge_id"`
+	PeriodStart    time.Time      `db:"period_start"`
+	PeriodEnd      time.Time      `db:"period_end"`
+	EarnedCredit   float64        `db:"earned_credit"`
+	Amount         float64        `db:"amount"`
+	Quantity       int            `db:"qty"`
+	TotalDonation  float64        `db:"total_donation"`
+	StatusId       int8           `db:"status_id"`
+	Reason         sql.NullString `db:"reason"`
+	Attempts       int            `db:"attempts"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+	Version        int64          `db:"version"`
+	CurrentVersion int64          `db:"current_version"`
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/lib/pq"
+	"time"
+)
+
+type Organization struct {
+	Id                   string                   `db:"id"`
+	Name                 string                   `db:"name"`
+	OrgTypeId            int8                     `db:"org_type_id"`
+	Description          sql.NullString           `db:"description"`
+	LogoFile             sql.NullString           `db:"logo_file"`
+	Contacts             OrganizationContactArray `db:"contacts"`
+	VerificationStatusId int8                     `db:"verification_status_id"`
+	VerificationNotes    sql.NullString           `db:"verification_notes"`
+	VerifiedAt           pq.NullTime              `db:"verified_at"`
+	VerifiedBy           *ModifierMeta            `db:"verified_by"`
+	StatusId             int8                     `db:"status_id"`
+	CreatedAt            time.Time                `db:"created_at"`
+	UpdatedAt            time.Time                `db:"updated_at"`
+	ModifiedBy           *ModifierMeta            `db:"modified_by"`
+	Version              int64                    `db:"version"`
+	CurrentVersion       int64                    `db:"current_version"`
+}
+
+type OrganizationContact struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Position string `json:"position"`
+}
+
+type OrganizationContactArray []OrganizationContact
+
+func (o *OrganizationContactArray) Scan(src interface{}) error {
+	return nsql.ScanJSON(src, o)
+}
+
+func (o OrganizationContactArray) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+type OrganizationMember struct {
+	Id             string    `db:"id"`
+	OrganizationId string    `db:"organization_id"`
+	UserProfileId  string    `db:"user_profile_id"`
+	MemberRoleId   int8      `db:"member_role_id"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+type OrganizationAdmin struct {
+	UserId               string `db:"user_id"`
+	Password             string `db:"password"`
+	UserStatusId         int    `db:"user_status_id"`
+	OrganizationId       string `db:"organization_id"`
+	OrganizationName     string `db:"organization_name"`
+	OrganizationStatusId int8   `db:"organization_status_id"`
+}
+
+type OrganizationDonationSummary struct {
+	InitiativeId       string  `db:"initiative_id"`
+	InitiativeName     string  `db:"initiative_name"`
+	DonationConversion string  `db:"donation_conversion"`
+	DonationCount      int64   `db:"donation_count"`
+	Quantity           int64   `db:"qty"`
+	TotalCredit        float64 `db:"total_credit"`
+}
+
+type OrganizationPayout struct {
+	Id             string           `db:"id"`
+	OrganizationId string           `db:"organization_id"`
+	PeriodStart    time.Time        `db:"period_start"`
+	PeriodEnd      time.Time        `db:"period_end"`
+	TotalCredit    float64          `db:"total_credit"`
+	Totals         PayoutTotalArray `db:"totals"`
+	Items          PayoutItemArray  `db:"items"`
+	CreatedAt      time.Time        `db:"created_at"`
+	ModifiedBy     *ModifierMeta    `db:"modified_by"`
+}
+
+type PayoutItem struct {
+	InitiativeId       string  `json:"initiative_id"`
+	InitiativeName     string  `json:"initiative_name"`
+	DonationConversion string  `json:"donation_conversion"`
+	DonationCount      int64   `json:"donation_count"`
+	Quantity           int64   `json:"qty"`
+	TotalCredit        float64 `json:"total_credit"`
+	ConversionRate     float64 `json:"conversion_rate"`
+	CurrencyCode       string  `json:"currency_code"`
+	Amount             float64 `json:"amount"`
+}
+
+type PayoutItemArray []PayoutItem
+
+func (p *PayoutItemArray) Scan(src interface{}) error {
+	return nsql.ScanJSON(src, p)
+}
+
+func (p PayoutItemArray) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+type PayoutTotal struct {
+	CurrencyCode string  `json:"currency_code"`
+	Amount       float64 `json:"amount"`
+}
+
+type PayoutTotalArray []PayoutTotal
+
+func (p *PayoutTotalArray) Scan(src interface{}) error {
+	return nsql.ScanJSON(src, p)
+}
+
+func (p PayoutTotalArray) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
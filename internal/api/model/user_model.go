@@ -52,11 +52,55 @@ type UserSession struct {
 	NotificationChannelId int       `db:"notification_channel_id"`
 	NotificationToken     string    `db:"notification_token"`
 	Signature             string    `db:"signature"`
+	FamilyId              string    `db:"family_id"`
+	RefreshTokenHash      string    `db:"refresh_token_hash"`
 	ExpiredAt             time.Time `db:"expired_at"`
+	LastSeenAt            time.Time `db:"last_seen_at"`
 	CreatedAt             time.Time `db:"created_at"`
 	UpdatedAt             time.Time `db:"updated_at"`
 }
 
+type UserDevice struct {
+	UserId             string    `db:"user_id"`
+	Signature          string    `db:"signature"`
+	DevicePlatformId   int       `db:"device_platform_id"`
+	DeviceManufacturer string    `db:"device_manufacturer"`
+	DeviceModel        string    `db:"device_model"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+type UserDeletion struct {
+	UserId      string    `db:"user_id"`
+	StatusId    int8      `db:"status_id"`
+	ScheduledAt time.Time `db:"scheduled_at"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type UserRefreshToken struct {
+	TokenHash string    `db:"token_hash"`
+	FamilyId  string    `db:"family_id"`
+	UserId    string    `db:"user_id"`
+	ExpiredAt time.Time `db:"expired_at"`
+	UsedAt    time.Time `db:"used_at"`
+}
+
+type UserTwoFactor struct {
+	UserId       string    `db:"user_id"`
+	Secret       string    `db:"secret"`
+	StatusId     int8      `db:"status_id"`
+	LastUsedStep int64     `db:"last_used_step"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+type UserRecoveryCode struct {
+	Id        string      `db:"id"`
+	UserId    string      `db:"user_id"`
+	CodeHash  string      `db:"code_hash"`
+	UsedAt    pq.NullTime `db:"used_at"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
 type UserChallenge struct {
 	Id                      string          `db:"id" diff:"id"`
 	UserId                  string          `db:"user_id" diff:"-"`
@@ -103,6 +147,48 @@ type UserCreditWalletTrx struct {
 	Version            int            `db:"version"`
 }
 
+type UserCreditWalletTrxFilter struct {
+	UserId      string
+	StatusId    int8
+	EntryTypeId int8
+	StartAt     pq.NullTime
+	EndAt       pq.NullTime
+}
+
+type UserCreditWalletTrxDetail struct {
+	UserCreditWalletTrx
+	ChallengeId     sql.NullString `db:"challenge_id"`
+	ChallengeTitle  sql.NullString `db:"challenge_title"`
+	DonationId      sql.NullString `db:"donation_id"`
+	InitiativeName  sql.NullString `db:"initiative_name"`
+	CounterpartName sql.NullString `db:"counterpart_name"`
+}
+
+type CreditAdjustment struct {
+	Id             string         `db:"id"`
+	UserId         string         `db:"user_id"`
+	TrxEntryTypeId int8           `db:"trx_entry_type_id"`
+	Amount         float64        `db:"amount"`
+	ReasonCode     string         `db:"reason_code"`
+	Notes          sql.NullString `db:"notes"`
+	ExpiredAt      pq.NullTime    `db:"expired_at"`
+	StatusId       int8           `db:"status_id"`
+	TrxRefId       sql.NullString `db:"trx_ref_id"`
+	RequestedBy    *ModifierMeta  `db:"requested_by"`
+	ReviewedBy     *ModifierMeta  `db:"reviewed_by"`
+	ReviewNotes    sql.NullString `db:"review_notes"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+	ModifiedBy     *ModifierMeta  `db:"modified_by"`
+	Version        int            `db:"version"`
+	CurrentVersion int            `db:"current_version"`
+}
+
+type UserCreditTrxSummary struct {
+	Amount float64 `db:"amount"`
+	Count  int     `db:"count"`
+}
+
 type UserSnapshot struct {
 	*UserProfile
 	AvatarFile string `json:"avatar_file"`
@@ -161,10 +247,13 @@ type StripeSubscriptionSnapshot struct {
 }
 
 type AdminInvitation struct {
-	Id        string    `db:"id"`
-	UserId    string    `db:"user_id"`
-	Email     string    `db:"email"`
-	Token     string    `db:"token"`
-	ExpiredAt time.Time `db:"expired_at"`
-	CreatedAt time.Time `db:"created_at"`
+	Id         string         `db:"id"`
+	UserId     string         `db:"user_id"`
+	Email      string         `db:"email"`
+	Token      string         `db:"token"`
+	Roles      pq.StringArray `db:"roles"`
+	InvitedBy  *ModifierMeta  `db:"invited_by"`
+	AcceptedAt pq.NullTime    `db:"accepted_at"`
+	ExpiredAt  time.Time      `db:"expired_at"`
+	CreatedAt  time.Time      `db:"created_at"`
 }
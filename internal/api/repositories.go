@@ -2,12 +2,21 @@ package api
 
 import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"time"
 )
 
 type DiscoverContentRepository interface {
-	CountContents() (total int, err error)
-	FindContents(limit int8, skip int64) (result []model.DiscoverContent, err error)
+	CountContents(audience model.DiscoverAudience) (total int, err error)
+	FindAll(statusId int8, skip int64, limit int8) ([]model.DiscoverContent, error)
+	FindById(id string) (*model.DiscoverContent, error)
+	FindContents(audience model.DiscoverAudience, limit int8, skip int64) (result []model.DiscoverContent, err error)
+	FindPersonalizedContents(userId string, segments []string, audience model.DiscoverAudience, limit int8, skip int64) (result []model.DiscoverContent, err error)
+	FindTags(ids []int64) ([]model.AdTag, error)
+	Insert(content *model.DiscoverContent) error
+	IsExistOrganization(organizationId string) (bool, error)
+	Update(oldContent, newContent model.DiscoverContent, changelog []string) error
 }
 
 type RunRepository interface {
@@ -21,17 +30,24 @@ type RunRepository interface {
 type UserRepository interface {
 	DeleteAllSession(userId string) error
 	DeleteSessionById(id string) error
+	DeleteSessionFamily(familyId string) error
+	DeleteThirdParty(userId string, providerId int) (bool, error)
 	FindAuthByEmail(email string) (*model.UserAuth, error)
 	FindAuthById(userId string) (*model.UserAuth, error)
 	FindAuthByThirdParty(userId string, providerId int) (*model.UserAuth, error)
 	FindProfileById(userId string) (*model.UserProfile, error)
 	FindProfileByEmail(userId string) (*model.UserProfile, error)
 	FindSessionById(sessionId string) (*model.UserSession, error)
+	FindSessionByRefreshToken(tokenHash string) (*model.UserSession, error)
+	FindSpentRefreshToken(tokenHash string) (*model.UserRefreshToken, error)
+	FindThirdPartiesByUser(userId string) ([]model.UserAuthThirdParty, error)
 	Insert(userProfile model.UserProfile, userAuth model.UserAuth) error
 	InsertWithThirdParty(userProfile model.UserProfile, userAuth model.UserAuth, userAuthThirdParty model.UserAuthThirdParty) error
 	InsertSession(userSession model.UserSession) error
+	InsertThirdParty(thirdParty model.UserAuthThirdParty) error
 	IsExistByEmail(email string) (bool, error)
 	IsExistBy3rdPartyAcc(authProviderId int, accessKey string) (bool, error)
+	RotateSession(oldSession model.UserSession, newSession model.UserSession, usedAt time.Time) error
 	UpdateVerifyEmail(userId string, isVerified bool, timestamp time.Time) error
 	UpdatePassword(userId, password string, timestamp time.Time) error
 	UpdateProfile(user, newUser model.UserProfile, changes []string) error
@@ -45,10 +61,37 @@ type UserRepository interface {
 	UpdateSubscriptionStatus(subscription model.UserSubscription) error
 	InsertAdminInvitation(invitation model.AdminInvitation) error
 	IsUserHasSubscribed(userId string, providerId int8) (bool, error)
+	FindTwoFactor(userId string) (*model.UserTwoFactor, error)
+	UpsertTwoFactor(twoFactor model.UserTwoFactor) error
+	EnableTwoFactor(twoFactor model.UserTwoFactor, codes []model.UserRecoveryCode) error
+	ReplaceRecoveryCodes(userId string, codes []model.UserRecoveryCode) error
+	DeleteTwoFactor(userId string) error
+	UpdateTwoFactorStep(userId string, step int64, timestamp time.Time) (bool, error)
+	UseRecoveryCode(userId, codeHash string, usedAt time.Time) (bool, error)
+	CountRecoveryCodes(userId string) (int, error)
+	UpdateAuthStatus(userId string, fromStatusId, toStatusId int) (bool, error)
+	CountDevices(userId string) (int, error)
+	DeleteDeviceSession(userId, deviceId string) error
+	DeleteOtherSessions(userId, familyId string) error
+	DeleteUserSessionFamily(userId, familyId string) (bool, error)
+	FindSessionsByUser(userId string, now time.Time) ([]model.UserSession, error)
+	InsertDevice(device model.UserDevice) (bool, error)
+	UpdateSessionLastSeen(sessionId string, timestamp time.Time) error
+	AnonymizeUser(profile model.UserProfile, auth model.UserAuth, snapshot model.UserSnapshot) error
+	DeleteUserDeletion(userId string, statusId int8) (bool, error)
+	FindChallengesByUser(userId string) ([]model.UserChallenge, error)
+	FindDueDeletions(now time.Time, limit int) ([]model.UserDeletion, error)
+	FindSubscriptionsByUser(userId string) ([]model.UserSubscription, error)
+	InsertUserDeletion(deletion model.UserDeletion) (bool, error)
+	RescheduleUserDeletion(userId string, scheduledAt time.Time) error
+	UpdateEmail(userId, email string, timestamp time.Time) error
 }
 
 type AdTagRepository interface {
+	FindFollowedTags(userId string) (result []model.AdTag, err error)
+	FindTagsByName(names []string) (result []model.AdTag, err error)
 	GetAdTags() (result []model.AdTag, err error)
+	UpdateFollowedTags(userId string, tags []model.UserAdTag) error
 }
 
 type MilestoneRepository interface {
@@ -56,8 +99,8 @@ type MilestoneRepository interface {
 	FindChallengeById(id string) (*model.Challenge, error)
 	Current(now time.Time, status int) (result model.Milestone, err error)
 	FindUserChallenge(userID string, challengeID string) (*model.UserChallenge, error)
-	InsertUserChallenge(uc model.UserChallenge) error
-	UpdateUserChallenge(o, n model.UserChallenge, changes []string) error
+	InsertUserChallenge(tx *sqlx.Tx, uc model.UserChallenge) error
+	UpdateUserChallenge(tx *sqlx.Tx, o, n model.UserChallenge, changes []string) error
 	FindCurrentChallenges() ([]model.Challenge, error)
 	GetChallengesByStatus(userID string, milestoneID string, status int) ([]model.Challenge, error)
 	GetChallengesIn(challengesID string) ([]model.Challenge, error)
@@ -66,22 +109,101 @@ type MilestoneRepository interface {
 }
 
 type CreditRepository interface {
-	FindTrxById(trxId string) (*model.UserCreditWalletTrx, error)
-	FindWalletById(walletId string) (*model.UserCreditWallet, error)
-	FindWalletByTrx(trxId string) (*model.UserCreditWallet, error)
-	FindWalletByUser(userId string) (*model.UserCreditWallet, error)
-	IsExistTrxRef(walletId, trxId string) (bool, error)
+	CountTrxHistory(filter model.UserCreditWalletTrxFilter) (int64, error)
+	FindActiveCreditLots(tx *sqlx.Tx, walletId string, now time.Time) ([]model.UserCreditWalletTrx, error)
+	FindActiveUserByEmail(email string) (*model.UserProfile, error)
+	FindAdjustmentById(tx *sqlx.Tx, id string) (*model.CreditAdjustment, error)
+	FindAdjustments(statusId int8, skip int64, limit int8) ([]model.CreditAdjustment, error)
+	FindExpiredPendingTrx(now time.Time, cursor string, limit int) ([]model.UserCreditWalletTrx, error)
+	FindExpiringWallets(now time.Time, cursor string, limit int) ([]model.UserCreditWallet, error)
+	FindTrxById(tx *sqlx.Tx, trxId string) (*model.UserCreditWalletTrx, error)
+	FindTrxByRef(tx *sqlx.Tx, walletId, refId string) ([]model.UserCreditWalletTrx, error)
+	FindTrxHistory(filter model.UserCreditWalletTrxFilter, skip int64, limit int) ([]model.UserCreditWalletTrxDetail, error)
+	FindWalletById(tx *sqlx.Tx, walletId string) (*model.UserCreditWallet, error)
+	FindWalletByTrx(tx *sqlx.Tx, trxId string) (*model.UserCreditWallet, error)
+	FindWalletByUser(tx *sqlx.Tx, userId string) (*model.UserCreditWallet, error)
+	IsExistTrxRef(tx *sqlx.Tx, walletId, trxId string) (bool, error)
 	IsExistWalletByUser(userId string) (bool, error)
-	InsertWallet(wallet *model.UserCreditWallet, trx *model.UserCreditWalletTrx) error
-	InsertTrx(wallet *model.UserCreditWallet, newTrx *model.UserCreditWalletTrx) error
+	InsertAdjustment(tx *sqlx.Tx, adjustment *model.CreditAdjustment) error
+	InsertWallet(tx *sqlx.Tx, wallet *model.UserCreditWallet, trx *model.UserCreditWalletTrx) error
+	InsertTrx(tx *sqlx.Tx, wallet *model.UserCreditWallet, newTrx *model.UserCreditWalletTrx) error
+	ReleasePendingTrx(tx *sqlx.Tx, wallet *model.UserCreditWallet, pendingTrx, releaseTrx *model.UserCreditWalletTrx) error
+	SumEarnedCredit(walletId string, startAt, endAt time.Time) (float64, error)
+	SumNonExpiringCredit(tx *sqlx.Tx, walletId string) (float64, error)
+	SumTransferOut(walletId string, since time.Time) (*model.UserCreditTrxSummary, error)
+	Transfer(sender, recipient *model.UserCreditWallet, senderTrx, recipientTrx *model.UserCreditWalletTrx) error
+	UpdateAdjustment(tx *sqlx.Tx, adjustment *model.CreditAdjustment) error
+	UpdateWallet(wallet *model.UserCreditWallet) error
 }
 
 type InitiativeRepository interface {
-	FindActive(skip int64, limit int8) ([]model.Initiative, error)
+	AddDonationStat(tx *sqlx.Tx, id string, amount float64, timestamp time.Time) (int8, error)
+	CloseExpired(timestamp time.Time) (int64, error)
+	FindActivePledge(id string) (*model.DonationPledge, error)
 	FindById(id string) (*model.Initiative, error)
+	FindDonationById(id, userId string) (*model.Donation, error)
 	FindDonationByUser(userId string, skip int64, limit int8) ([]model.Donation, error)
-	Insert(donation model.Donation, donationLog model.DonationLog) error
-	UpdateDonation(oldDonation, newDonation model.Donation, changelog []string) error
+	FindDonationLocked(tx *sqlx.Tx, id string) (*model.Donation, error)
+	FindDuePledges(timestamp time.Time, cursor string, limit int) ([]model.DonationPledge, error)
+	FindInitiatives(filter model.InitiativeFilter, skip int64, limit int8) ([]model.Initiative, error)
+	FindPledgeById(id, userId string) (*model.DonationPledge, error)
+	FindPledgeRuns(pledgeId string, skip int64, limit int8) ([]model.DonationPledgeRun, error)
+	FindPledgesByUser(userId string, skip int64, limit int8) ([]model.DonationPledge, error)
+	FindRetryPledgeRuns(updatedBefore time.Time, maxAttempts int, cursor string, limit int) ([]model.DonationPledgeRun, error)
+	FindTagFacets(filter model.InitiativeFilter) ([]model.InitiativeTagFacet, error)
+	HasDonationPayout(tx *sqlx.Tx, initiativeId string, createdAt time.Time) (bool, error)
+	Insert(tx *sqlx.Tx, donation model.Donation, donationLog model.DonationLog) error
+	InsertPledge(pledge *model.DonationPledge) error
+	InsertPledgeRun(tx *sqlx.Tx, run *model.DonationPledgeRun) error
+	SubtractDonationStat(tx *sqlx.Tx, id string, amount float64, timestamp time.Time) error
+	UpdateDonation(tx *sqlx.Tx, oldDonation, newDonation model.Donation, changelog []string) error
+	UpdateFundingGoal(initiative *model.Initiative, timestamp time.Time) error
+	UpdatePledge(tx *sqlx.Tx, pledge *model.DonationPledge) error
+	UpdatePledgeRun(tx *sqlx.Tx, run *model.DonationPledgeRun) error
+}
+
+type AdvertiserRepository interface {
+	CountReach(campaignId string, startAt, endAt time.Time) (int64, error)
+	CountTagsById(ids []int64) (int, error)
+	CountUserImpressions(campaignId, userId string, since time.Time) (int, error)
+	DeleteCreative(id, campaignId string, timestamp time.Time) (int64, error)
+	FindAuthById(userId string) (*model.UserAuth, error)
+	FindCampaignByCreative(creativeId string) (*model.AdCampaign, error)
+	FindCampaignById(id, advertiserId string) (*model.AdCampaign, error)
+	FindCampaigns(advertiserId string, statusId int8, skip int64, limit int8) ([]model.AdCampaign, error)
+	FindCreatives(campaignId string) ([]model.AdCreative, error)
+	FindServe(id, userId string) (*model.AdServe, error)
+	FindServedCreative(userId string, segments []string, dailyCap int, dayStart time.Time) (*model.AdServedCreative,
+		error)
+	FindStats(campaignId string, startAt, endAt time.Time) ([]model.AdStat, error)
+	FindTagStats(campaignId string, startAt, endAt time.Time) ([]model.AdTagStat, error)
+	InsertCampaign(campaign *model.AdCampaign) error
+	InsertCreative(creative *model.AdCreative) error
+	InsertEvent(event *model.AdEvent, cost float64) (bool, error)
+	InsertServe(serve *model.AdServe) error
+	IsAdvertiser(userId string) (bool, error)
+	RollupStats(startAt, endAt time.Time) (int64, error)
+	UpdateCampaign(campaign *model.AdCampaign) error
+}
+
+type OrganizationRepository interface {
+	DeleteMember(organizationId, userId string) (int64, error)
+	FindActiveIds() ([]string, error)
+	FindActiveUserIdByEmail(email string) (string, error)
+	FindAdminsByEmail(email string) ([]model.OrganizationAdmin, error)
+	FindById(id string) (*model.Organization, error)
+	FindDonations(organizationId string, startAt, endAt pq.NullTime, skip int64, limit int8) ([]model.Donation, error)
+	FindDonationSummary(organizationId string, startAt, endAt time.Time) ([]model.OrganizationDonationSummary, error)
+	FindOrganizations(statusId, verificationStatusId int8, skip int64, limit int8) ([]model.Organization, error)
+	FindPayoutById(id string) (*model.OrganizationPayout, error)
+	FindPayouts(organizationId string, skip int64, limit int8) ([]model.OrganizationPayout, error)
+	Insert(organization *model.Organization) error
+	InsertMember(member *model.OrganizationMember) error
+	InsertPayout(payout *model.OrganizationPayout) (bool, error)
+	IsAdmin(organizationId, userId string) (bool, error)
+	IsExistMember(organizationId, userId string) (bool, error)
+	IsExistPayout(organizationId string, periodStart time.Time) (bool, error)
+	Update(organization *model.Organization) error
 }
 
 type SubscriptionPlanRepository interface {
@@ -91,3 +213,18 @@ type SubscriptionPlanRepository interface {
 type SiteSettingRepository interface {
 	StaticContent(contentType string) (model.SiteSetting, error)
 }
+
+type AdminRepository interface {
+	AcceptInvitation(invitation model.AdminInvitation, admin model.Admin, acceptedAt time.Time) error
+	Delete(userId string) (bool, error)
+	FindActiveUserIdByEmail(email string) (string, error)
+	FindAdmins(skip int64, limit int8) ([]model.AdminDetail, error)
+	FindAuditLogs(userId string, skip int64, limit int8) ([]model.AdminAuditLog, error)
+	FindByEmail(email string) (*model.AdminDetail, error)
+	FindById(userId string) (*model.AdminDetail, error)
+	FindInvitationByToken(token string) (*model.AdminInvitation, error)
+	InsertAuditLog(auditLog model.AdminAuditLog) error
+	InsertIfNotExists(admin model.Admin) (bool, error)
+	InsertInvitation(invitation model.AdminInvitation) error
+	UpdateRoles(admin model.Admin) (bool, error)
+}
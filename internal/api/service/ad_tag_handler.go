@@ -2,6 +2,7 @@ package service
 
 import (
 	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"net/http"
@@ -20,7 +21,7 @@ func NewTagHandler(app *api.Api) AdTagHandler {
 }
 
 func (h *AdTagHandler) GetTags(r *http.Request) (success *nhttp.Success, err error) {
-	sessions, err := h.AdTagService.GetAdTags()
+	sessions, err := h.AdTagService.GetAdTags(r.Header.Get(nhttp.KeyUserId))
 	if err != nil {
 		return
 	}
@@ -31,3 +32,27 @@ func (h *AdTagHandler) GetTags(r *http.Request) (success *nhttp.Success, err err
 
 	return resp, nil
 }
+
+func (h *AdTagHandler) PutFollowedTags(r *http.Request) (success *nhttp.Success, err error) {
+	// Parse request body
+	var reqBody dto.AdTagFollowReq
+	err = nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set user id
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	respBody, err := h.AdTagService.FollowTags(reqBody)
+	if err != nil {
+		return
+	}
+
+	resp := &nhttp.Success{
+		Result: respBody,
+	}
+
+	return resp, nil
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type AdTagRepository struct {
@@ -28,3 +30,39 @@ func (r AdTagRepository) GetAdTags() (result []model.AdTag, err error) {
 
 	return result, err
 }
+
+func (r AdTagRepository) FindFollowedTags(userId string) (result []model.AdTag, err error) {
+	result = make([]model.AdTag, 0)
+	err = r.Stmt.findFollowedTags.Select(&result, userId)
+
+	return result, err
+}
+
+func (r AdTagRepository) FindTagsByName(names []string) (result []model.AdTag, err error) {
+	result = make([]model.AdTag, 0)
+	err = r.Stmt.findTagsByName.Select(&result, pq.Array(names))
+
+	return result, err
+}
+
+func (r AdTagRepository) UpdateFollowedTags(userId string, tags []model.UserAdTag) error {
+	return nsql.WithTx(r.Db, r.Logger, func(tx *sqlx.Tx) error {
+		// Remove previously followed tags
+		_, err := nsql.StmtTx(r.Stmt.deleteFollowedTags, tx).Exec(userId)
+		if err != nil {
+			r.Logger.Error("delete user ad tags", err)
+			return err
+		}
+
+		// Insert followed tags
+		for k := range tags {
+			_, err = nsql.NamedStmtTx(r.Stmt.insertFollowedTag, tx).Exec(&tags[k])
+			if err != nil {
+				r.Logger.Error("insert user ad tag", err)
+				return err
+			}
+		}
+
+		return nil
+	})
+}
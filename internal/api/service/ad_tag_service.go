@@ -3,7 +3,12 @@ package service
 import (
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	validate "github.com/go-playground/validator/v10"
+	"strings"
+	"time"
 )
 
 type AdTagService struct {
@@ -11,6 +16,7 @@ type AdTagService struct {
 	Errors          *api.Errors
 	Logger          nlog.Logger
 	AdTagRepository api.AdTagRepository
+	Validator       *validate.Validate
 }
 
 func (r *AdTagService) Init(app *api.Api) error {
@@ -18,19 +24,91 @@ func (r *AdTagService) Init(app *api.Api) error {
 	r.Errors = app.Components.Errors
 	r.Logger = app.Logger
 	r.AdTagRepository = NewAdTagRepository(app.Datasources.Db, app.Logger)
+	r.Validator = validate.New()
 	return nil
 }
 
-func (r AdTagService) GetAdTags() (resp *dto.AdTagResp, err error) {
+func (r AdTagService) GetAdTags(userId string) (resp *dto.AdTagResp, err error) {
 	result, err := r.AdTagRepository.GetAdTags()
+	if err != nil {
+		r.Logger.Error("unable to get ad tags", err)
+		return nil, err
+	}
 
 	tags := make([]string, len(result))
 	for k := range result {
 		tags[k] = result[k].Name
 	}
+
+	// Get tags followed by user
+	followed, err := r.AdTagRepository.FindFollowedTags(userId)
+	if err != nil {
+		r.Logger.Error("unable to get followed ad tags", err)
+		return nil, err
+	}
+
 	resp = &dto.AdTagResp{
-		Tags: tags,
+		Tags:     tags,
+		Followed: adTagNames(followed),
 	}
 
 	return resp, nil
 }
+
+func (r AdTagService) FollowTags(req dto.AdTagFollowReq) (resp *dto.AdTagResp, err error) {
+	// Validate request
+	err = r.Validator.Struct(&req)
+	if err != nil {
+		r.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Normalize tag names and remove duplicates
+	names := make([]string, 0, len(req.Tags))
+	exists := make(map[string]bool, len(req.Tags))
+	for _, v := range req.Tags {
+		name := strings.ToLower(strings.TrimSpace(v))
+		if name == "" || exists[name] {
+			continue
+		}
+		exists[name] = true
+		names = append(names, name)
+	}
+
+	// Find tags, all tags must be registered
+	tags, err := r.AdTagRepository.FindTagsByName(names)
+	if err != nil {
+		r.Logger.Error("unable to find ad tags by name", err)
+		return nil, err
+	}
+
+	if len(tags) != len(names) {
+		return nil, r.Errors.New("TAG001")
+	}
+
+	// Replace followed tags
+	timestamp := time.Now()
+	followed := make([]model.UserAdTag, len(tags))
+	for k, v := range tags {
+		followed[k] = model.UserAdTag{
+			UserId:    req.UserId,
+			AdTagId:   v.Id,
+			CreatedAt: timestamp,
+		}
+	}
+
+	err = r.AdTagRepository.UpdateFollowedTags(req.UserId, followed)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetAdTags(req.UserId)
+}
+
+func adTagNames(tags []model.AdTag) []string {
+	names := make([]string, len(tags))
+	for k := range tags {
+		names[k] = tags[k].Name
+	}
+	return names
+}
@@ -6,11 +6,19 @@ import (
 )
 
 type AdTagStatements struct {
-	getAdTags *sqlx.Stmt
+	deleteFollowedTags *sqlx.Stmt
+	findFollowedTags   *sqlx.Stmt
+	findTagsByName     *sqlx.Stmt
+	getAdTags          *sqlx.Stmt
+	insertFollowedTag  *sqlx.NamedStmt
 }
 
 func initAdTagStatements(db *nsql.SqlDatabase) AdTagStatements {
 	return AdTagStatements{
-		getAdTags: db.Prepare(`SELECT id, name, updated_at FROM ad_tag`),
+		deleteFollowedTags: db.Prepare(`DELETE FROM user_ad_tag WHERE user_id = $1`),
+		findFollowedTags:   db.Prepare(`SELECT t.id, t.name, t.segment, t.updated_at FROM ad_tag AS t INNER JOIN user_ad_tag AS f ON f.ad_tag_id = t.id WHERE f.user_id = $1 ORDER BY t.name`),
+		findTagsByName:     db.Prepare(`SELECT id, name, segment, updated_at FROM ad_tag WHERE LOWER(name) = ANY($1)`),
+		getAdTags:          db.Prepare(`SELECT id, name, segment, updated_at FROM ad_tag`),
+		insertFollowedTag:  db.PrepareNamed(`INSERT INTO user_ad_tag(user_id, ad_tag_id, created_at) VALUES (:user_id, :ad_tag_id, :created_at)`),
 	}
 }
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+func NewAdminHandler(app *api.Api) AdminHandler {
+	return AdminHandler{
+		AdminService: app.Services.Admin,
+		Logger:       app.Logger,
+	}
+}
+
+type AdminHandler struct {
+	AdminService api.AdminService
+	Logger       nlog.Logger
+}
+
+func (h *AdminHandler) PostLogin(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdminLoginReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+	reqBody.ClientIp = nhttp.ClientIP(r)
+
+	// Call service
+	respBody, header, err := h.AdminService.Login(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := nhttp.Success{
+		Result: respBody,
+		Header: header,
+	}
+	return &resp, nil
+}
+
+func (h *AdminHandler) PostLoginTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	reqBody, err := parseTwoFactorReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call service
+	respBody, header, err := h.AdminService.LoginByTwoFactor(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := nhttp.Success{
+		Result: respBody,
+		Header: header,
+	}
+	return &resp, nil
+}
+
+func (h *AdminHandler) PostAcceptInvitation(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdminAcceptInvitationReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Call service
+	err = h.AdminService.AcceptInvitation(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *AdminHandler) GetAdmins(r *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	skip, limit := api.Pagination(r.URL.Query())
+
+	// Call service
+	respBody, err := h.AdminService.List(dto.PageReq{
+		Skip:  skip,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdminHandler) PostInvitation(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdminInvitationReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set admin
+	reqBody.InvitedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.AdminService.Invite(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdminHandler) PutRoles(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdminRolesReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set user id and admin
+	reqBody.UserId = mux.Vars(r)["userId"]
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.AdminService.UpdateRoles(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdminHandler) DeleteAdmin(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	err := h.AdminService.Delete(dto.AdminDeleteReq{
+		UserId:     mux.Vars(r)["userId"],
+		ModifiedBy: newModifierReq(r),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *AdminHandler) GetAuditLogs(r *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	query := r.URL.Query()
+	skip, limit := api.Pagination(query)
+
+	// Call service
+	respBody, err := h.AdminService.ListAuditLogs(dto.AdminAuditLogListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		UserId: query.Get("user_id"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+// newModifierReq returns the admin that has been authorized by permission middleware
+func newModifierReq(r *http.Request) dto.ModifierReq {
+	return dto.ModifierReq{
+		Id:       r.Header.Get(nhttp.KeyUserId),
+		FullName: r.Header.Get(nhttp.KeyUserName),
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+func NewAdminRepository(db *nsql.SqlDatabase, logger nlog.Logger) api.AdminRepository {
+	r := AdminRepository{
+		Db:     db,
+		Stmt:   initAdminStatement(db),
+		Logger: logger,
+	}
+
+	return &r
+}
+
+type AdminRepository struct {
+	Db     *nsql.SqlDatabase
+	Stmt   AdminStatement
+	Logger nlog.Logger
+}
+
+func (r *AdminRepository) AcceptInvitation(invitation model.AdminInvitation, admin model.Admin, acceptedAt time.Time) error {
+	return nsql.WithTx(r.Db, r.Logger, func(tx *sqlx.Tx) error {
+		// Mark invitation as accepted. If invitation has been accepted concurrently, then no rows will be updated
+		result, err := nsql.StmtTx(r.Stmt.acceptInvitation, tx).Exec(acceptedAt, invitation.Id)
+		if err != nil {
+			return err
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+
+		// Grant roles
+		_, err = nsql.NamedStmtTx(r.Stmt.upsert, tx).Exec(&admin)
+		return err
+	})
+}
+
+func (r *AdminRepository) Delete(userId string) (bool, error) {
+	result, err := r.Stmt.delete.Exec(userId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *AdminRepository) FindActiveUserIdByEmail(email string) (string, error) {
+	var userId string
+	err := r.Stmt.findActiveUserByEmail.Get(&userId, email)
+	return userId, err
+}
+
+func (r *AdminRepository) FindAdmins(skip int64, limit int8) ([]model.AdminDetail, error) {
+	rows := make([]model.AdminDetail, 0)
+	err := r.Stmt.findAdmins.Select(&rows, limit, skip)
+	return rows, err
+}
+
+func (r *AdminRepository) FindAuditLogs(userId string, skip int64, limit int8) ([]model.AdminAuditLog, error) {
+	rows := make([]model.AdminAuditLog, 0)
+	err := r.Stmt.findAuditLogs.Select(&rows, userId, limit, skip)
+	return rows, err
+}
+
+func (r *AdminRepository) FindByEmail(email string) (*model.AdminDetail, error) {
+	var result model.AdminDetail
+	err := r.Stmt.findByEmail.Get(&result, email)
+	return &result, err
+}
+
+func (r *AdminRepository) FindById(userId string) (*model.AdminDetail, error) {
+	var result model.AdminDetail
+	err := r.Stmt.findById.Get(&result, userId)
+	return &result, err
+}
+
+func (r *AdminRepository) FindInvitationByToken(token string) (*model.AdminInvitation, error) {
+	var result model.AdminInvitation
+	err := r.Stmt.findInvitationByToken.Get(&result, token)
+	return &result, err
+}
+
+func (r *AdminRepository) InsertAuditLog(auditLog model.AdminAuditLog) error {
+	_, err := r.Stmt.insertAuditLog.Exec(&auditLog)
+	return err
+}
+
+func (r *AdminRepository) InsertIfNotExists(admin model.Admin) (bool, error) {
+	result, err := r.Stmt.insertIfNotExists.Exec(&admin)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *AdminRepository) InsertInvitation(invitation model.AdminInvitation) error {
+	_, err := r.Stmt.insertInvitation.Exec(&invitation)
+	return err
+}
+
+func (r *AdminRepository) UpdateRoles(admin model.Admin) (bool, error) {
+	result, err := r.Stmt.updateRoles.Exec(&admin)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,540 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nmailgun"
+	validate "github.com/go-playground/validator/v10"
+	gonanoid "github.com/matoous/go-nanoid"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultAdminInvitationLifetime is admin invitation lifetime in minutes if it is not configured
+const DefaultAdminInvitationLifetime = 2880
+
+type Admin struct {
+	IdGen              *api.SnowflakeGen
+	Errors             *api.Errors
+	Logger             nlog.Logger
+	Mailer             api.MailerComponent
+	Repository         api.AdminRepository
+	AuthService        api.AuthenticatorService
+	UserService        api.UserService
+	Validator          *validate.Validate
+	AccessLifetime     int
+	InvitationLifetime int
+	DashboardUrl       string
+}
+
+func (s *Admin) Init(app *api.Api) error {
+	s.IdGen = app.Components.Id
+	s.Errors = app.Components.Errors
+	s.Logger = app.Logger
+	s.Mailer = app.Components.Mailer
+	s.Repository = NewAdminRepository(app.Datasources.Db, app.Logger)
+	s.AuthService = app.Services.Auth
+	s.UserService = app.Services.User
+	s.Validator = validate.New()
+	s.AccessLifetime = app.Config.GetInt(api.ConfAdminAccessLifetime)
+	s.DashboardUrl = app.Config.GetString(api.ConfDashboardUrl)
+	s.InvitationLifetime = app.Config.GetInt(api.ConfAdminInvitationLifetime)
+	if s.InvitationLifetime <= 0 {
+		s.InvitationLifetime = DefaultAdminInvitationLifetime
+	}
+
+	// Grant super admin role to configured users, so the first admin can invite others
+	s.grantSuperAdmins(app.Config.GetStringSlice(api.ConfAdminSuperAdminEmails))
+
+	return nil
+}
+
+func (s *Admin) Login(req dto.AdminLoginReq) (*dto.AdminLoginResp, map[string]string, error) {
+	// Validate request
+	err := s.Validator.Struct(&req)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nil, nhttp.ErrBadRequest
+	}
+
+	// Validate credential
+	_, err = s.UserService.VerifyPassword(dto.UserLoginReq{
+		Email:    req.Email,
+		Password: req.Password,
+		ClientIp: req.ClientIp,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get admin, user that is not an admin is answered same as invalid password so admins can not be enumerated
+	admin, err := s.Repository.FindByEmail(req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, s.Errors.New("USR007")
+		}
+		s.Logger.Error("unable to retrieve admin by email", err)
+		return nil, nil, err
+	}
+
+	// Validate user status
+	if admin.UserStatusId != api.UserActive {
+		return nil, nil, s.Errors.New("USR002")
+	}
+
+	// If two factor is enabled, return challenge token instead of access token
+	isTwoFactor, err := s.UserService.IsTwoFactorEnabled(admin.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isTwoFactor {
+		header, err := s.UserService.NewTwoFactorChallenge(dto.TwoFactorSession{
+			UserId: admin.UserId,
+			Target: api.TwoFactorTargetAdmin,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp := dto.AdminLoginResp{
+			UserId:            admin.UserId,
+			FullName:          admin.FullName,
+			TwoFactorRequired: true,
+		}
+		return &resp, header, nil
+	}
+
+	return s.newSession(admin)
+}
+
+func (s *Admin) LoginByTwoFactor(req dto.TwoFactorReq) (*dto.AdminLoginResp, map[string]string, error) {
+	// Verify second factor
+	err := s.UserService.VerifyTwoFactor(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validate user is still an active admin
+	admin, err := s.Repository.FindById(req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, nhttp.ErrUnauthorized
+		}
+		s.Logger.Error("unable to retrieve admin", err)
+		return nil, nil, err
+	}
+
+	if admin.UserStatusId != api.UserActive {
+		return nil, nil, s.Errors.New("USR002")
+	}
+
+	return s.newSession(admin)
+}
+
+func (s *Admin) newSession(admin *model.AdminDetail) (*dto.AdminLoginResp, map[string]string, error) {
+	// Create token
+	token, err := s.AuthService.NewAdminAccessToken(dto.JWTOptReq{
+		Subject:   admin.UserId,
+		SessionId: s.IdGen.New(),
+		Lifetime:  s.AccessLifetime,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Compose response
+	resp := dto.AdminLoginResp{
+		UserId:      admin.UserId,
+		FullName:    admin.FullName,
+		Roles:       admin.Roles,
+		Permissions: rolePermissions(admin.Roles),
+	}
+	header := map[string]string{
+		api.AccessTokenKey:    token.Token,
+		api.AccessTokenExpKey: strconv.FormatInt(token.ExpiredAt, 10),
+	}
+
+	return &resp, header, nil
+}
+
+func (s *Admin) Authorize(userId, permission string) (*nhttp.Principal, error) {
+	// Get admin, roles are checked on every request so revoked roles take effect immediately
+	admin, err := s.Repository.FindById(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nhttp.ErrForbidden
+		}
+		s.Logger.Error("unable to retrieve admin", err)
+		return nil, err
+	}
+
+	// Validate user status
+	if admin.UserStatusId != api.UserActive {
+		return nil, s.Errors.New("USR002")
+	}
+
+	// Check permission
+	if !hasPermission(admin.Roles, permission) {
+		s.Logger.Debugf("admin does not have permission. UserId: %s, Permission: %s", userId, permission)
+		return nil, nhttp.ErrForbidden
+	}
+
+	return &nhttp.Principal{
+		UserId:   admin.UserId,
+		FullName: admin.FullName,
+		Roles:    admin.Roles,
+	}, nil
+}
+
+func (s *Admin) Audit(r *http.Request, principal *nhttp.Principal, permission string, err error) {
+	// Get status code
+	statusCode := http.StatusOK
+	if err != nil {
+		statusCode = nhttp.CastError(err).Status
+	}
+
+	auditLog := model.AdminAuditLog{
+		Id:         s.IdGen.New(),
+		UserId:     principal.UserId,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Permission: permission,
+		StatusCode: statusCode,
+		ClientIp:   nhttp.ClientIP(r),
+		ModifiedBy: model.ModifierMeta{
+			Id:       principal.UserId,
+			Role:     api.ModifierAdmin,
+			FullName: principal.FullName,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	// Failing to write audit log must not fail the action that has been done
+	err = s.Repository.InsertAuditLog(auditLog)
+	if err != nil {
+		s.Logger.Error("unable to persist admin audit log", err)
+	}
+}
+
+func (s *Admin) Invite(req dto.AdminInvitationReq) (*dto.AdminInvitationResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&req)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	err = s.validateRoles(req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invited user must have an active account
+	userId, err := s.Repository.FindActiveUserIdByEmail(req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("USR008")
+		}
+		s.Logger.Error("unable to retrieve user by email", err)
+		return nil, err
+	}
+
+	// Generate token
+	token, err := gonanoid.Nanoid(64)
+	if err != nil {
+		s.Logger.Error("failed to generate token", err)
+		return nil, err
+	}
+
+	// Persist invitation
+	createdAt := time.Now()
+	invitation := model.AdminInvitation{
+		Id:        s.IdGen.New(),
+		UserId:    userId,
+		Email:     req.Email,
+		Token:     token,
+		Roles:     req.Roles,
+		InvitedBy: newAdminModifier(req.InvitedBy),
+		ExpiredAt: createdAt.Add(time.Duration(s.InvitationLifetime) * time.Minute),
+		CreatedAt: createdAt,
+	}
+
+	err = s.Repository.InsertInvitation(invitation)
+	if err != nil {
+		s.Logger.Error("failed to persist admin invitation", err)
+		return nil, err
+	}
+
+	// Send invitation
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{req.Email},
+		Subject:      "Running App - Admin Invitation",
+		TemplateFile: "admin_invitation.html",
+		TemplateData: struct {
+			URL       string
+			InvitedBy string
+			Roles     []string
+		}{
+			URL:       fmt.Sprintf("%s/admin-invitation/%s", s.DashboardUrl, token),
+			InvitedBy: req.InvitedBy.FullName,
+			Roles:     req.Roles,
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send admin invitation email", err)
+		return nil, err
+	}
+
+	return &dto.AdminInvitationResp{
+		Id:        invitation.Id,
+		Email:     invitation.Email,
+		Roles:     invitation.Roles,
+		ExpiredAt: invitation.ExpiredAt.Unix(),
+	}, nil
+}
+
+func (s *Admin) AcceptInvitation(req dto.AdminAcceptInvitationReq) error {
+	// Validate request
+	err := s.Validator.Struct(&req)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Get invitation
+	invitation, err := s.Repository.FindInvitationByToken(req.Token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return s.Errors.New("ADM003")
+		}
+		s.Logger.Error("unable to retrieve admin invitation", err)
+		return err
+	}
+
+	// Validate invitation, advertiser activation shares the table and has no roles
+	timestamp := time.Now()
+	if invitation.AcceptedAt.Valid || invitation.ExpiredAt.Before(timestamp) || len(invitation.Roles) == 0 {
+		return s.Errors.New("ADM003")
+	}
+
+	// Grant roles
+	err = s.Repository.AcceptInvitation(*invitation, model.Admin{
+		UserId:     invitation.UserId,
+		Roles:      invitation.Roles,
+		CreatedAt:  timestamp,
+		UpdatedAt:  timestamp,
+		ModifiedBy: invitation.InvitedBy,
+	}, timestamp)
+	if err != nil {
+		// Invitation has been accepted by concurrent request
+		if err == sql.ErrNoRows {
+			return s.Errors.New("ADM003")
+		}
+		s.Logger.Error("unable to accept admin invitation", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Admin) List(req dto.PageReq) ([]dto.AdminResp, error) {
+	// Get admins
+	rows, err := s.Repository.FindAdmins(req.Skip, req.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve admins", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.AdminResp, len(rows))
+	for k, v := range rows {
+		resp[k] = *composeAdmin(v)
+	}
+
+	return resp, nil
+}
+
+func (s *Admin) UpdateRoles(req dto.AdminRolesReq) (*dto.AdminResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&req)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	err = s.validateRoles(req.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prevent admin from locking themselves out
+	if req.UserId == req.ModifiedBy.Id {
+		return nil, s.Errors.New("ADM004")
+	}
+
+	// Update roles
+	ok, err := s.Repository.UpdateRoles(model.Admin{
+		UserId:     req.UserId,
+		Roles:      req.Roles,
+		UpdatedAt:  time.Now(),
+		ModifiedBy: newAdminModifier(req.ModifiedBy),
+	})
+	if err != nil {
+		s.Logger.Error("unable to update admin roles", err)
+		return nil, err
+	}
+
+	if !ok {
+		return nil, s.Errors.New("ADM001")
+	}
+
+	// Get updated admin
+	admin, err := s.Repository.FindById(req.UserId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve admin", err)
+		return nil, err
+	}
+
+	return composeAdmin(*admin), nil
+}
+
+func (s *Admin) Delete(req dto.AdminDeleteReq) error {
+	// Validate request
+	err := s.Validator.Struct(&req)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Prevent admin from locking themselves out
+	if req.UserId == req.ModifiedBy.Id {
+		return s.Errors.New("ADM004")
+	}
+
+	ok, err := s.Repository.Delete(req.UserId)
+	if err != nil {
+		s.Logger.Error("unable to delete admin", err)
+		return err
+	}
+
+	if !ok {
+		return s.Errors.New("ADM001")
+	}
+
+	return nil
+}
+
+func (s *Admin) ListAuditLogs(req dto.AdminAuditLogListReq) ([]dto.AdminAuditLogResp, error) {
+	// Get audit logs
+	rows, err := s.Repository.FindAuditLogs(req.UserId, req.Skip, req.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve admin audit logs", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.AdminAuditLogResp, len(rows))
+	for k, v := range rows {
+		resp[k] = dto.AdminAuditLogResp{
+			Id:         v.Id,
+			Method:     v.Method,
+			Path:       v.Path,
+			Permission: v.Permission,
+			StatusCode: v.StatusCode,
+			ClientIp:   v.ClientIp,
+			CreatedBy:  composeModifier(&v.ModifiedBy),
+			CreatedAt:  v.CreatedAt.Unix(),
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *Admin) validateRoles(roles []string) error {
+	for _, r := range roles {
+		if _, ok := api.AdminRolePermissions[r]; !ok {
+			return s.Errors.New("ADM002")
+		}
+	}
+	return nil
+}
+
+// grantSuperAdmins grants super admin role to users by email. Existing admins are left as is, so roles that have been
+// changed from dashboard are not overridden on restart
+func (s *Admin) grantSuperAdmins(emails []string) {
+	timestamp := time.Now()
+	for _, email := range emails {
+		userId, err := s.Repository.FindActiveUserIdByEmail(email)
+		if err != nil {
+			s.Logger.Errorf("unable to find super admin user. Email: %s, Error: %s", email, err)
+			continue
+		}
+
+		ok, err := s.Repository.InsertIfNotExists(model.Admin{
+			UserId:    userId,
+			Roles:     []string{api.AdminRoleSuperAdmin},
+			CreatedAt: timestamp,
+			UpdatedAt: timestamp,
+		})
+		if err != nil {
+			s.Logger.Error("unable to grant super admin role", err)
+			continue
+		}
+
+		if ok {
+			s.Logger.Debugf("super admin role granted. UserId: %s", userId)
+		}
+	}
+}
+
+// hasPermission checks if any of roles grants permission
+func hasPermission(roles []string, permission string) bool {
+	for _, r := range roles {
+		for _, p := range api.AdminRolePermissions[r] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rolePermissions returns sorted permissions granted by roles
+func rolePermissions(roles []string) []string {
+	set := make(map[string]bool)
+	for _, r := range roles {
+		for _, p := range api.AdminRolePermissions[r] {
+			set[p] = true
+		}
+	}
+
+	result := make([]string, 0, len(set))
+	for p := range set {
+		result = append(result, p)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func composeAdmin(admin model.AdminDetail) *dto.AdminResp {
+	return &dto.AdminResp{
+		UserId:      admin.UserId,
+		Email:       admin.Email,
+		FullName:    admin.FullName,
+		Roles:       admin.Roles,
+		Permissions: rolePermissions(admin.Roles),
+		CreatedAt:   admin.CreatedAt.Unix(),
+		UpdatedAt:   admin.UpdatedAt.Unix(),
+		ModifiedBy:  composeModifier(admin.ModifiedBy),
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+)
+
+type AdminStatement struct {
+	acceptInvitation      *sqlx.Stmt
+	delete                *sqlx.Stmt
+	findActiveUserByEmail *sqlx.Stmt
+	findAdmins            *sqlx.Stmt
+	findAuditLogs         *sqlx.Stmt
+	findByEmail           *sqlx.Stmt
+	findById              *sqlx.Stmt
+	findInvitationByToken *sqlx.Stmt
+	insertAuditLog        *sqlx.NamedStmt
+	insertIfNotExists     *sqlx.NamedStmt
+	insertInvitation      *sqlx.NamedStmt
+	updateRoles           *sqlx.NamedStmt
+	upsert                *sqlx.NamedStmt
+}
+
+func initAdminStatement(db *nsql.SqlDatabase) AdminStatement {
+	return AdminStatement{
+		acceptInvitation:      db.Prepare(`update adm_invitation set accepted_at = $1 where id = $2 and accepted_at is null`),
+		delete:                db.Prepare(`delete from adm_user where user_id = $1`),
+		findActiveUserByEmail: db.Prepare(`select p.id from user_profile as p inner join user_auth as a on a.id = p.id where p.email = $1 and a.status_id = 1`),
+		findAdmins:            db.Prepare(`select a.user_id, u.username as email, u.password, u.status_id as user_status_id, p.full_name, a.roles, a.created_at, a.updated_at, a.modified_by from adm_user as a inner join user_auth as u on u.id = a.user_id inner join user_profile as p on p.id = a.user_id order by p.full_name, a.user_id limit $1 offset $2`),
+		findAuditLogs:         db.Prepare(`select id, user_id, method, path, permission, status_code, client_ip, modified_by, created_at from adm_audit_log where ($1 = '' or user_id = $1) order by created_at desc, id desc limit $2 offset $3`),
+		findByEmail:           db.Prepare(`select a.user_id, u.username as email, u.password, u.status_id as user_status_id, p.full_name, a.roles, a.created_at, a.updated_at, a.modified_by from adm_user as a inner join user_auth as u on u.id = a.user_id inner join user_profile as p on p.id = a.user_id where u.username = $1`),
+		findById:              db.Prepare(`select a.user_id, u.username as email, u.password, u.status_id as user_status_id, p.full_name, a.roles, a.created_at, a.updated_at, a.modified_by from adm_user as a inner join user_auth as u on u.id = a.user_id inner join user_profile as p on p.id = a.user_id where a.user_id = $1`),
+		findInvitationByToken: db.Prepare(`select id, user_id, email, token, roles, invited_by, accepted_at, expired_at, created_at from adm_invitation where token = $1`),
+		insertAuditLog:        db.PrepareNamed(`insert into adm_audit_log(id, user_id, method, path, permission, status_code, client_ip, modified_by, created_at) values (:id, :user_id, :method, :path, :permission, :status_code, :client_ip, :modified_by, :created_at)`),
+		insertIfNotExists:     db.PrepareNamed(`insert into adm_user(user_id, roles, created_at, updated_at, modified_by) values (:user_id, :roles, :created_at, :updated_at, :modified_by) on conflict (user_id) do nothing`),
+		insertInvitation:      db.PrepareNamed(`insert into adm_invitation(id, user_id, email, token, roles, invited_by, expired_at, created_at) values (:id, :user_id, :email, :token, :roles, :invited_by, :expired_at, :created_at)`),
+		updateRoles:           db.PrepareNamed(`update adm_user set roles = :roles, updated_at = :updated_at, modified_by = :modified_by where user_id = :user_id`),
+		upsert:                db.PrepareNamed(`insert into adm_user(user_id, roles, created_at, updated_at, modified_by) values (:user_id, :roles, :created_at, :updated_at, :modified_by) on conflict (user_id) do update set roles = excluded.roles, updated_at = excluded.updated_at, modified_by = excluded.modified_by`),
+	}
+}
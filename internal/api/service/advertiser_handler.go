@@ -0,0 +1,323 @@
+package service
+
+import (
+	"fmt"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nstr"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+func NewAdvertiserHandler(app *api.Api) AdvertiserHandler {
+	return AdvertiserHandler{
+		AdvertiserService: app.Services.Advertiser,
+		AssetService:      app.Services.Asset,
+		Logger:            app.Logger,
+	}
+}
+
+type AdvertiserHandler struct {
+	AdvertiserService api.AdvertiserService
+	AssetService      api.AssetService
+	Logger            nlog.Logger
+}
+
+func (h *AdvertiserHandler) PostLogin(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdvertiserLoginReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+	reqBody.ClientIp = nhttp.ClientIP(r)
+
+	// Call service
+	respBody, header, err := h.AdvertiserService.Login(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := nhttp.Success{
+		Result: respBody,
+		Header: header,
+	}
+	return &resp, nil
+}
+
+func (h *AdvertiserHandler) PostLoginTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	reqBody, err := parseTwoFactorReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call service
+	respBody, header, err := h.AdvertiserService.LoginByTwoFactor(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := nhttp.Success{
+		Result: respBody,
+		Header: header,
+	}
+	return &resp, nil
+}
+
+func (h *AdvertiserHandler) PostCampaign(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdCampaignReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set advertiser id
+	reqBody.AdvertiserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	respBody, err := h.AdvertiserService.CreateCampaign(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) GetCampaigns(r *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	query := r.URL.Query()
+	skip, limit := api.Pagination(query)
+
+	// Call service
+	respBody, err := h.AdvertiserService.ListCampaigns(dto.AdCampaignListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		AdvertiserId: r.Header.Get(nhttp.KeyUserId),
+		StatusId:     nstr.ParseInt8(query.Get("status_id"), 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) GetCampaign(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.AdvertiserService.GetCampaign(dto.AdCampaignReq{
+		Id:           mux.Vars(r)["id"],
+		AdvertiserId: r.Header.Get(nhttp.KeyUserId),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) PutCampaign(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdCampaignReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set campaign id and advertiser id
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.AdvertiserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	respBody, err := h.AdvertiserService.UpdateCampaign(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) PutPauseCampaign(r *http.Request) (*nhttp.Success, error) {
+	return h.updateCampaignStatus(r, api.CampaignPaused)
+}
+
+func (h *AdvertiserHandler) PutResumeCampaign(r *http.Request) (*nhttp.Success, error) {
+	return h.updateCampaignStatus(r, api.CampaignActive)
+}
+
+func (h *AdvertiserHandler) DeleteCampaign(r *http.Request) (*nhttp.Success, error) {
+	return h.updateCampaignStatus(r, api.CampaignEnded)
+}
+
+func (h *AdvertiserHandler) PostCreativeImage(r *http.Request) (*nhttp.Success, error) {
+	// Parse multipart image
+	rule := nhttp.NewImageUploadRules()[0]
+	file, err := nhttp.GetFile(r, rule.Key, rule.MaxSize, rule.MimeTypes)
+	if err != nil {
+		return nil, h.AssetService.NewImageError(err)
+	}
+
+	// Call service
+	respBody, err := h.AdvertiserService.UploadCreativeImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) PostCreative(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdCreativeReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set campaign id and advertiser id
+	reqBody.CampaignId = mux.Vars(r)["id"]
+	reqBody.AdvertiserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	respBody, err := h.AdvertiserService.CreateCreative(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) DeleteCreative(r *http.Request) (*nhttp.Success, error) {
+	vars := mux.Vars(r)
+
+	// Call service
+	err := h.AdvertiserService.DeleteCreative(dto.AdCreativeReq{
+		Id:           vars["creativeId"],
+		CampaignId:   vars["id"],
+		AdvertiserId: r.Header.Get(nhttp.KeyUserId),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *AdvertiserHandler) GetServeAd(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.AdvertiserService.ServeAd(dto.AdServeReq{
+		UserId: r.Header.Get(nhttp.KeyUserId),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) PostImpression(r *http.Request) (*nhttp.Success, error) {
+	return h.recordEvent(r, api.AdImpression)
+}
+
+func (h *AdvertiserHandler) PostClick(r *http.Request) (*nhttp.Success, error) {
+	return h.recordEvent(r, api.AdClick)
+}
+
+func (h *AdvertiserHandler) GetCampaignReport(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.AdvertiserService.GetReport(newAdReportReq(r))
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) GetExportCampaignReport(r *http.Request) (*nhttp.Success, error) {
+	reqBody := newAdReportReq(r)
+
+	// Call service
+	content, err := h.AdvertiserService.ExportReport(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send as csv file
+	resp := nhttp.Success{
+		File: &nhttp.File{
+			Name:        fmt.Sprintf("campaign-report-%s.csv", reqBody.CampaignId),
+			ContentType: nhttp.ContentTypeCSV,
+			Content:     content,
+		},
+	}
+	return &resp, nil
+}
+
+func (h *AdvertiserHandler) PutRollupStats(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.AdvertiserService.RollupStats()
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *AdvertiserHandler) recordEvent(r *http.Request, eventTypeId int8) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdEventReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set creative, user and event type
+	reqBody.CreativeId = mux.Vars(r)["id"]
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+	reqBody.EventTypeId = eventTypeId
+
+	// Call service
+	err = h.AdvertiserService.RecordEvent(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *AdvertiserHandler) updateCampaignStatus(r *http.Request, statusId int8) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.AdCampaignStatusReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set campaign id, advertiser id and status
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.AdvertiserId = r.Header.Get(nhttp.KeyUserId)
+	reqBody.StatusId = statusId
+
+	// Call service
+	respBody, err := h.AdvertiserService.UpdateCampaignStatus(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func newAdReportReq(r *http.Request) dto.AdReportReq {
+	query := r.URL.Query()
+	return dto.AdReportReq{
+		CampaignId:   mux.Vars(r)["id"],
+		AdvertiserId: r.Header.Get(nhttp.KeyUserId),
+		StartAt:      nstr.ParseInt64(query.Get("start"), 0),
+		EndAt:        nstr.ParseInt64(query.Get("end"), 0),
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"database/sql"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+func NewAdvertiserRepository(db *nsql.SqlDatabase, apiErrors *api.Errors, logger nlog.Logger) api.AdvertiserRepository {
+	r := AdvertiserRepository{
+		Errors: apiErrors,
+		Db:     db,
+		Stmt:   initAdvertiserStatement(db),
+		Logger: logger,
+	}
+
+	return &r
+}
+
+type AdvertiserRepository struct {
+	Errors *api.Errors
+	Db     *nsql.SqlDatabase
+	Stmt   AdvertiserStatement
+	Logger nlog.Logger
+}
+
+func (r *AdvertiserRepository) FindAuthById(userId string) (*model.UserAuth, error) {
+	var result model.UserAuth
+	err := r.Stmt.findAuthById.Get(&result, userId)
+	return &result, err
+}
+
+func (r *AdvertiserRepository) IsAdvertiser(userId string) (bool, error) {
+	var isAdvertiser bool
+	err := r.Stmt.isAdvertiser.Get(&isAdvertiser, userId)
+	return isAdvertiser, err
+}
+
+func (r *AdvertiserRepository) CountTagsById(ids []int64) (int, error) {
+	var count int
+	err := r.Stmt.countTagsById.Get(&count, pq.Array(ids))
+	return count, err
+}
+
+func (r *AdvertiserRepository) FindCampaignById(id, advertiserId string) (*model.AdCampaign, error) {
+	var result model.AdCampaign
+	err := r.Stmt.findCampaignById.Get(&result, id, advertiserId)
+	return &result, err
+}
+
+func (r *AdvertiserRepository) FindCampaigns(advertiserId string, statusId int8, skip int64, limit int8) (
+	[]model.AdCampaign, error) {
+	rows := make([]model.AdCampaign, 0)
+	err := r.Stmt.findCampaigns.Select(&rows, advertiserId, statusId, limit, skip)
+	return rows, err
+}
+
+func (r *AdvertiserRepository) InsertCampaign(campaign *model.AdCampaign) error {
+	_, err := r.Stmt.insertCampaign.Exec(campaign)
+	if err != nil {
+		r.Logger.Error("insert ad campaign", err)
+	}
+	return err
+}
+
+func (r *AdvertiserRepository) UpdateCampaign(campaign *model.AdCampaign) error {
+	// Update campaign
+	result, err := r.Stmt.updateCampaign.Exec(campaign)
+	if err != nil {
+		r.Logger.Error("update ad campaign", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		r.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		r.Logger.Errorf("no ad campaign update affected")
+		return r.Errors.New("ADV003")
+	}
+
+	return nil
+}
+
+func (r *AdvertiserRepository) FindCreatives(campaignId string) ([]model.AdCreative, error) {
+	rows := make([]model.AdCreative, 0)
+	err := r.Stmt.findCreatives.Select(&rows, campaignId)
+	return rows, err
+}
+
+func (r *AdvertiserRepository) InsertCreative(creative *model.AdCreative) error {
+	_, err := r.Stmt.insertCreative.Exec(creative)
+	if err != nil {
+		r.Logger.Error("insert ad creative", err)
+	}
+	return err
+}
+
+func (r *AdvertiserRepository) DeleteCreative(id, campaignId string, timestamp time.Time) (int64, error) {
+	result, err := r.Stmt.deleteCreative.Exec(id, campaignId, timestamp)
+	if err != nil {
+		r.Logger.Error("delete ad creative", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *AdvertiserRepository) FindServedCreative(userId string, segments []string, dailyCap int, dayStart time.Time) (
+	*model.AdServedCreative, error) {
+	var result model.AdServedCreative
+	err := r.Stmt.findServedCreative.Get(&result, userId, pq.Array(segments), dailyCap, dayStart)
+	return &result, err
+}
+
+func (r *AdvertiserRepository) InsertServe(serve *model.AdServe) error {
+	_, err := r.Stmt.insertServe.Exec(serve)
+	if err != nil {
+		r.Logger.Error("insert ad serve", err)
+	}
+	return err
+}
+
+func (r *AdvertiserRepository) FindServe(id, userId string) (*model.AdServe, error) {
+	var result model.AdServe
+	err := r.Stmt.findServe.Get(&result, id, userId)
+	return &result, err
+}
+
+func (r *AdvertiserRepository) FindCampaignByCreative(creativeId string) (*model.AdCampaign, error) {
+	var result model.AdCampaign
+	err := r.Stmt.findCampaignByCreative.Get(&result, creativeId)
+	return &result, err
+}
+
+func (r *AdvertiserRepository) CountUserImpressions(campaignId, userId string, since time.Time) (int, error) {
+	var count int
+	err := r.Stmt.countUserImpressions.Get(&count, campaignId, userId, since)
+	return count, err
+}
+
+func (r *AdvertiserRepository) InsertEvent(event *model.AdEvent, cost float64) (inserted bool, err error) {
+	err = nsql.WithTx(r.Db, r.Logger, func(tx *sqlx.Tx) error {
+		// Insert event. Event is skipped if it has been recorded for the same serve
+		result, err := nsql.NamedStmtTx(r.Stmt.insertEvent, tx).Exec(event)
+		if err != nil {
+			r.Logger.Error("insert ad event", err)
+			return err
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			r.Logger.Error("cannot get affected rows", err)
+			return err
+		}
+
+		inserted = count > 0
+		if !inserted || cost == 0 {
+			return nil
+		}
+
+		// Charge campaign. If campaign is no longer running or budget has been spent, event is rolled back
+		result, err = nsql.StmtTx(r.Stmt.addCampaignSpent, tx).Exec(event.CampaignId, cost)
+		if err != nil {
+			r.Logger.Error("add ad campaign spent", err)
+			return err
+		}
+
+		count, err = result.RowsAffected()
+		if err != nil {
+			r.Logger.Error("cannot get affected rows", err)
+			return err
+		}
+
+		if count == 0 {
+			inserted = false
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	return inserted, err
+}
+
+func (r *AdvertiserRepository) RollupStats(startAt, endAt time.Time) (int64, error) {
+	result, err := r.Stmt.rollupStats.Exec(startAt, endAt)
+	if err != nil {
+		r.Logger.Error("roll up ad stats", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *AdvertiserRepository) FindStats(campaignId string, startAt, endAt time.Time) ([]model.AdStat, error) {
+	rows := make([]model.AdStat, 0)
+	err := r.Stmt.findStats.Select(&rows, campaignId, startAt, endAt)
+	return rows, err
+}
+
+func (r *AdvertiserRepository) FindTagStats(campaignId string, startAt, endAt time.Time) ([]model.AdTagStat, error) {
+	rows := make([]model.AdTagStat, 0)
+	err := r.Stmt.findTagStats.Select(&rows, campaignId, startAt, endAt)
+	return rows, err
+}
+
+func (r *AdvertiserRepository) CountReach(campaignId string, startAt, endAt time.Time) (int64, error) {
+	var count int64
+	err := r.Stmt.countReach.Get(&count, campaignId, startAt, endAt)
+	return count, err
+}
@@ -0,0 +1,782 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql/pqx"
+	validate "github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"math"
+	"strconv"
+	"time"
+)
+
+// adStatLookback is the period of ad events that are rolled up on each run, so late events in previous hours
+// are still aggregated
+const adStatLookback = 3 * time.Hour
+
+// adServeLifetime is the period after an ad is served in which its impression and click are recorded
+const adServeLifetime = 24 * time.Hour
+
+type Advertiser struct {
+	IdGen          *api.SnowflakeGen
+	Errors         *api.Errors
+	Logger         nlog.Logger
+	Repository     api.AdvertiserRepository
+	AuthService    api.AuthenticatorService
+	AssetService   api.AssetService
+	UserService    api.UserService
+	Validator      *validate.Validate
+	AccessLifetime int
+	DailyCap       int
+}
+
+func (s *Advertiser) Init(app *api.Api) error {
+	s.IdGen = app.Components.Id
+	s.Errors = app.Components.Errors
+	s.Logger = app.Logger
+	s.Repository = NewAdvertiserRepository(app.Datasources.Db, app.Components.Errors, app.Logger)
+	s.AuthService = app.Services.Auth
+	s.AssetService = app.Services.Asset
+	s.UserService = app.Services.User
+	s.Validator = validate.New()
+	s.AccessLifetime = app.Config.GetInt(api.ConfAdvertiserAccessLifetime)
+	s.DailyCap = app.Config.GetInt(api.ConfAdvertiserImpressionDailyCap)
+
+	// Start hourly ad stat roll up scheduler
+	statInterval := app.Config.GetInt(api.ConfAdvertiserStatInterval)
+	if statInterval > 0 {
+		go s.runStatScheduler(time.Duration(statInterval) * time.Minute)
+	}
+
+	return nil
+}
+
+func (s *Advertiser) Login(opt dto.AdvertiserLoginReq) (*dto.AdvertiserLoginResp, map[string]string, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nil, nhttp.ErrBadRequest
+	}
+
+	// Validate credential
+	auth, err := s.UserService.VerifyPassword(dto.UserLoginReq{
+		Email:    opt.Email,
+		Password: opt.Password,
+		ClientIp: opt.ClientIp,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validate advertiser subscription
+	err = s.ValidateAdvertiser(auth.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// If two factor is enabled, return challenge token instead of access token
+	isTwoFactor, err := s.UserService.IsTwoFactorEnabled(auth.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isTwoFactor {
+		header, err := s.UserService.NewTwoFactorChallenge(dto.TwoFactorSession{
+			UserId: auth.Id,
+			Target: api.TwoFactorTargetAdvertiser,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &dto.AdvertiserLoginResp{UserId: auth.Id, TwoFactorRequired: true}, header, nil
+	}
+
+	return s.newSession(auth.Id)
+}
+
+func (s *Advertiser) LoginByTwoFactor(opt dto.TwoFactorReq) (*dto.AdvertiserLoginResp, map[string]string, error) {
+	// Verify second factor
+	err := s.UserService.VerifyTwoFactor(opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validate user status, user may have been suspended after challenge is issued
+	auth, err := s.Repository.FindAuthById(opt.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, nhttp.ErrUnauthorized
+		}
+		s.Logger.Error("unable to retrieve user auth", err)
+		return nil, nil, err
+	}
+
+	if auth.StatusId == api.UserSuspended {
+		return nil, nil, s.Errors.New("USR002")
+	}
+
+	// Validate advertiser subscription
+	err = s.ValidateAdvertiser(auth.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.newSession(auth.Id)
+}
+
+func (s *Advertiser) newSession(userId string) (*dto.AdvertiserLoginResp, map[string]string, error) {
+	// Create token
+	token, err := s.AuthService.NewAdvertiserAccessToken(dto.JWTOptReq{
+		Subject:   userId,
+		SessionId: s.IdGen.New(),
+		Lifetime:  s.AccessLifetime,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Compose response
+	resp := dto.AdvertiserLoginResp{UserId: userId}
+	header := map[string]string{
+		api.AccessTokenKey:    token.Token,
+		api.AccessTokenExpKey: strconv.FormatInt(token.ExpiredAt, 10),
+	}
+
+	return &resp, header, nil
+}
+
+func (s *Advertiser) ValidateAdvertiser(userId string) error {
+	isAdvertiser, err := s.Repository.IsAdvertiser(userId)
+	if err != nil {
+		s.Logger.Error("unable to check advertiser subscription", err)
+		return err
+	}
+
+	if !isAdvertiser {
+		return s.Errors.New("ADV001")
+	}
+
+	return nil
+}
+
+func (s *Advertiser) CreateCampaign(opt dto.AdCampaignReq) (*dto.AdCampaignResp, error) {
+	// Validate request
+	err := s.validateCampaignReq(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create campaign
+	timestamp := time.Now()
+	campaign := model.AdCampaign{
+		Id:           s.IdGen.New(),
+		AdvertiserId: opt.AdvertiserId,
+		StatusId:     api.CampaignActive,
+		CreatedAt:    timestamp,
+		UpdatedAt:    timestamp,
+		Version:      1,
+	}
+	setCampaign(&campaign, opt, timestamp)
+
+	err = s.Repository.InsertCampaign(&campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.composeCampaign(campaign, nil), nil
+}
+
+func (s *Advertiser) GetCampaign(opt dto.AdCampaignReq) (*dto.AdCampaignResp, error) {
+	// Get campaign
+	campaign, err := s.findCampaign(opt.Id, opt.AdvertiserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get creatives
+	creatives, err := s.Repository.FindCreatives(campaign.Id)
+	if err != nil {
+		s.Logger.Error("unable to retrieve ad creatives", err)
+		return nil, err
+	}
+
+	return s.composeCampaign(*campaign, creatives), nil
+}
+
+func (s *Advertiser) ListCampaigns(opt dto.AdCampaignListReq) ([]dto.AdCampaignResp, error) {
+	// Get campaigns
+	rows, err := s.Repository.FindCampaigns(opt.AdvertiserId, opt.StatusId, opt.Skip, opt.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve ad campaigns", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.AdCampaignResp, len(rows))
+	for k, v := range rows {
+		resp[k] = *s.composeCampaign(v, nil)
+	}
+
+	return resp, nil
+}
+
+func (s *Advertiser) UpdateCampaign(opt dto.AdCampaignReq) (*dto.AdCampaignResp, error) {
+	// Validate request
+	if opt.Id == "" || opt.Version == 0 {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	err := s.validateCampaignReq(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get campaign
+	campaign, err := s.findCampaign(opt.Id, opt.AdvertiserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ended campaign cannot be modified
+	if campaign.StatusId == api.CampaignEnded {
+		return nil, s.Errors.New("ADV005")
+	}
+
+	// Update campaign
+	setCampaign(campaign, opt, campaign.StartAt)
+
+	err = s.updateCampaign(campaign, opt.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.composeCampaign(*campaign, nil), nil
+}
+
+func (s *Advertiser) UpdateCampaignStatus(opt dto.AdCampaignStatusReq) (*dto.AdCampaignResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get campaign
+	campaign, err := s.findCampaign(opt.Id, opt.AdvertiserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ended campaign cannot be resumed
+	if campaign.StatusId == api.CampaignEnded {
+		return nil, s.Errors.New("ADV005")
+	}
+
+	// Update status
+	campaign.StatusId = opt.StatusId
+
+	err = s.updateCampaign(campaign, opt.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.composeCampaign(*campaign, nil), nil
+}
+
+func (s *Advertiser) UploadCreativeImage(file nhttp.MultipartFile) (*dto.UploadResp, error) {
+	return s.AssetService.UploadFile(dto.UploadReq{
+		AssetType: api.AssetAdCreative,
+		File:      file,
+	})
+}
+
+func (s *Advertiser) CreateCreative(opt dto.AdCreativeReq) (*dto.AdCreativeResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get campaign
+	campaign, err := s.findCampaign(opt.CampaignId, opt.AdvertiserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if campaign.StatusId == api.CampaignEnded {
+		return nil, s.Errors.New("ADV005")
+	}
+
+	// Create creative
+	timestamp := time.Now()
+	creative := model.AdCreative{
+		Id:         s.IdGen.New(),
+		CampaignId: campaign.Id,
+		ImageFile:  opt.ImageFile,
+		Headline:   opt.Headline,
+		ClickUrl:   opt.ClickUrl,
+		StatusId:   api.CreativeActive,
+		CreatedAt:  timestamp,
+		UpdatedAt:  timestamp,
+	}
+
+	err = s.Repository.InsertCreative(&creative)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := s.composeCreative(creative)
+	return &resp, nil
+}
+
+func (s *Advertiser) DeleteCreative(opt dto.AdCreativeReq) error {
+	if opt.Id == "" || opt.CampaignId == "" || opt.AdvertiserId == "" {
+		return nhttp.ErrBadRequest
+	}
+
+	// Ensure campaign is owned by advertiser
+	_, err := s.findCampaign(opt.CampaignId, opt.AdvertiserId)
+	if err != nil {
+		return err
+	}
+
+	// Deactivate creative
+	count, err := s.Repository.DeleteCreative(opt.Id, opt.CampaignId, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return s.Errors.New("ADV006")
+	}
+
+	return nil
+}
+
+func (s *Advertiser) ServeAd(opt dto.AdServeReq) (*dto.AdServeResp, error) {
+	// Determine user subscription segment
+	isPremium, err := s.UserService.IsPremiumRunner(opt.UserId)
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("unable to check premium runner", err)
+	}
+
+	segments := []string{api.SegmentFree}
+	if isPremium {
+		segments = []string{api.SegmentPremium}
+	}
+
+	// Pick creative from running campaigns that target user
+	creative, err := s.Repository.FindServedCreative(opt.UserId, segments, s.DailyCap, startOfDay(time.Now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		s.Logger.Error("unable to find ad creative to serve", err)
+		return nil, err
+	}
+
+	// Record serve, so only events of ads that have been served to user are recorded
+	serve := model.AdServe{
+		Id:         s.IdGen.New(),
+		UserId:     opt.UserId,
+		CampaignId: creative.CampaignId,
+		CreativeId: creative.Id,
+		AdTagId:    creative.AdTagId,
+		CreatedAt:  time.Now(),
+	}
+	err = s.Repository.InsertServe(&serve)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.AdServeResp{
+		ServeId:    serve.Id,
+		CreativeId: creative.Id,
+		CampaignId: creative.CampaignId,
+		ImageUrl:   s.AssetService.GetPublicUrl(api.AssetAdCreative, creative.ImageFile),
+		Headline:   creative.Headline,
+		ClickUrl:   creative.ClickUrl,
+	}
+	return &resp, nil
+}
+
+func (s *Advertiser) RecordEvent(opt dto.AdEventReq) error {
+	if opt.ServeId == "" || opt.CreativeId == "" || opt.UserId == "" {
+		return nhttp.ErrBadRequest
+	}
+
+	// Get serve of creative to user
+	timestamp := time.Now()
+	serve, err := s.Repository.FindServe(opt.ServeId, opt.UserId)
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("failed to FindServe", err)
+		return err
+	}
+
+	if err == sql.ErrNoRows || serve.CreativeId != opt.CreativeId || timestamp.Sub(serve.CreatedAt) > adServeLifetime {
+		return s.Errors.New("ADV007")
+	}
+
+	// Get campaign of creative
+	campaign, err := s.Repository.FindCampaignByCreative(opt.CreativeId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return s.Errors.New("ADV006")
+		}
+		s.Logger.Error("failed to FindCampaignByCreative", err)
+		return err
+	}
+
+	// Event of campaign that is no longer running is ignored
+	if !isCampaignRunning(campaign, timestamp) {
+		s.Logger.Debugf("campaign is not running. CampaignId = %s", campaign.Id)
+		return nil
+	}
+
+	// Impression beyond user daily cap is ignored and not charged to campaign
+	var cost float64
+	if opt.EventTypeId == api.AdImpression {
+		if s.DailyCap > 0 {
+			count, err := s.Repository.CountUserImpressions(campaign.Id, opt.UserId, startOfDay(timestamp))
+			if err != nil {
+				s.Logger.Error("unable to count user impressions", err)
+				return err
+			}
+
+			if count >= s.DailyCap {
+				s.Logger.Debugf("impression cap reached. CampaignId = %s, UserId = %s", campaign.Id, opt.UserId)
+				return nil
+			}
+		}
+		cost = campaign.CostPerMille / 1000
+	}
+
+	// Record event
+	_, err = s.Repository.InsertEvent(&model.AdEvent{
+		Id:          s.IdGen.New(),
+		EventTypeId: opt.EventTypeId,
+		ServeId:     serve.Id,
+		CampaignId:  campaign.Id,
+		CreativeId:  opt.CreativeId,
+		AdTagId:     serve.AdTagId,
+		UserId:      opt.UserId,
+		CreatedAt:   timestamp,
+	}, cost)
+	if err == sql.ErrNoRows {
+		// Campaign has been stopped or spent its budget concurrently
+		s.Logger.Debugf("campaign is not running. CampaignId = %s", campaign.Id)
+		return nil
+	}
+	return err
+}
+
+func (s *Advertiser) RollupStats() (*dto.AdStatRollupResp, error) {
+	// Roll up complete hours within lookback period
+	endAt := time.Now()
+	startAt := endAt.Add(-adStatLookback).Truncate(time.Hour)
+
+	count, err := s.Repository.RollupStats(startAt, endAt)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.AdStatRollupResp{
+		StartAt:  startAt.Unix(),
+		EndAt:    endAt.Unix(),
+		RowCount: count,
+	}
+	return &resp, nil
+}
+
+func (s *Advertiser) GetReport(opt dto.AdReportReq) (*dto.AdReportResp, error) {
+	// Get campaign
+	campaign, err := s.findCampaign(opt.CampaignId, opt.AdvertiserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine period, default to campaign lifetime
+	startAt := campaign.StartAt.Truncate(time.Hour)
+	if opt.StartAt > 0 {
+		startAt = time.Unix(opt.StartAt, 0)
+	}
+
+	endAt := time.Now()
+	if opt.EndAt > 0 {
+		endAt = time.Unix(opt.EndAt, 0)
+	}
+
+	if !endAt.After(startAt) {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get hourly stats
+	stats, err := s.Repository.FindStats(campaign.Id, startAt, endAt)
+	if err != nil {
+		s.Logger.Error("unable to retrieve ad stats", err)
+		return nil, err
+	}
+
+	// Get stats by ad tag
+	tagStats, err := s.Repository.FindTagStats(campaign.Id, startAt, endAt)
+	if err != nil {
+		s.Logger.Error("unable to retrieve ad tag stats", err)
+		return nil, err
+	}
+
+	// Count unique users reached in period
+	reach, err := s.Repository.CountReach(campaign.Id, startAt, endAt)
+	if err != nil {
+		s.Logger.Error("unable to count ad reach", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := dto.AdReportResp{
+		CampaignId: campaign.Id,
+		StartAt:    startAt.Unix(),
+		EndAt:      endAt.Unix(),
+		Reach:      reach,
+		Spent:      campaign.Spent,
+		Hourly:     make([]dto.AdHourlyStatResp, len(stats)),
+		Tags:       make([]dto.AdTagStatResp, len(tagStats)),
+	}
+
+	for k, v := range stats {
+		resp.Impressions += v.Impressions
+		resp.Clicks += v.Clicks
+		resp.Hourly[k] = dto.AdHourlyStatResp{
+			HourAt:      v.HourAt.Unix(),
+			Impressions: v.Impressions,
+			Clicks:      v.Clicks,
+			CTR:         clickThroughRate(v.Clicks, v.Impressions),
+			Reach:       v.Reach,
+		}
+	}
+	resp.CTR = clickThroughRate(resp.Clicks, resp.Impressions)
+
+	for k, v := range tagStats {
+		resp.Tags[k] = dto.AdTagStatResp{
+			AdTagId:     v.AdTagId,
+			AdTagName:   v.AdTagName,
+			Impressions: v.Impressions,
+			Clicks:      v.Clicks,
+			CTR:         clickThroughRate(v.Clicks, v.Impressions),
+		}
+	}
+
+	return &resp, nil
+}
+
+func (s *Advertiser) ExportReport(opt dto.AdReportReq) ([]byte, error) {
+	// Get report
+	report, err := s.GetReport(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write csv
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	records := [][]string{
+		{"Hour", "Impressions", "Clicks", "CTR (%)", "Reach"},
+	}
+	for _, v := range report.Hourly {
+		records = append(records, []string{
+			time.Unix(v.HourAt, 0).UTC().Format(time.RFC3339),
+			strconv.FormatInt(v.Impressions, 10),
+			strconv.FormatInt(v.Clicks, 10),
+			strconv.FormatFloat(v.CTR, 'f', 2, 64),
+			strconv.FormatInt(v.Reach, 10),
+		})
+	}
+	records = append(records, []string{
+		"Total",
+		strconv.FormatInt(report.Impressions, 10),
+		strconv.FormatInt(report.Clicks, 10),
+		strconv.FormatFloat(report.CTR, 'f', 2, 64),
+		strconv.FormatInt(report.Reach, 10),
+	})
+
+	err = w.WriteAll(records)
+	if err != nil {
+		s.Logger.Error("unable to write ad report csv", err)
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *Advertiser) runStatScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.RollupStats()
+		if err != nil {
+			s.Logger.Error("failed to roll up ad stats", err)
+		}
+	}
+}
+
+func (s *Advertiser) validateCampaignReq(opt dto.AdCampaignReq) error {
+	err := s.Validator.Struct(&opt)
+	if err != nil || opt.AdvertiserId == "" {
+		s.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Validate schedule
+	if opt.EndAt > 0 && opt.EndAt <= opt.StartAt {
+		return s.Errors.New("ADV004")
+	}
+
+	// Validate target tags
+	tagIds := uniqueInt64(opt.TargetTagIds)
+	if len(tagIds) == 0 {
+		return nil
+	}
+
+	count, err := s.Repository.CountTagsById(tagIds)
+	if err != nil {
+		s.Logger.Error("unable to count ad tags", err)
+		return err
+	}
+
+	if count != len(tagIds) {
+		return s.Errors.New("TAG001")
+	}
+
+	return nil
+}
+
+func (s *Advertiser) findCampaign(id, advertiserId string) (*model.AdCampaign, error) {
+	campaign, err := s.Repository.FindCampaignById(id, advertiserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("ADV002")
+		}
+		s.Logger.Error("failed to FindCampaignById", err)
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (s *Advertiser) updateCampaign(campaign *model.AdCampaign, version int64) error {
+	campaign.CurrentVersion = version
+	campaign.Version = version + 1
+	campaign.UpdatedAt = time.Now()
+
+	return s.Repository.UpdateCampaign(campaign)
+}
+
+func (s *Advertiser) composeCampaign(c model.AdCampaign, creatives []model.AdCreative) *dto.AdCampaignResp {
+	// Compose creatives
+	var creativesResp []dto.AdCreativeResp
+	if creatives != nil {
+		creativesResp = make([]dto.AdCreativeResp, len(creatives))
+		for k, v := range creatives {
+			creativesResp[k] = s.composeCreative(v)
+		}
+	}
+
+	// Ensure target tags is serialized as array
+	tagIds := []int64(c.TargetTagIds)
+	if tagIds == nil {
+		tagIds = []int64{}
+	}
+
+	return &dto.AdCampaignResp{
+		Id:           c.Id,
+		Name:         c.Name,
+		TargetTagIds: tagIds,
+		Budget:       c.Budget,
+		CostPerMille: c.CostPerMille,
+		Spent:        c.Spent,
+		StartAt:      c.StartAt.Unix(),
+		EndAt:        pqx.NullTimeUnix(c.EndAt),
+		StatusId:     c.StatusId,
+		Creatives:    creativesResp,
+		CreatedAt:    c.CreatedAt.Unix(),
+		UpdatedAt:    c.UpdatedAt.Unix(),
+		Version:      c.Version,
+	}
+}
+
+func (s *Advertiser) composeCreative(c model.AdCreative) dto.AdCreativeResp {
+	return dto.AdCreativeResp{
+		Id:         c.Id,
+		CampaignId: c.CampaignId,
+		ImageFile:  c.ImageFile,
+		ImageUrl:   s.AssetService.GetPublicUrl(api.AssetAdCreative, c.ImageFile),
+		Headline:   c.Headline,
+		ClickUrl:   c.ClickUrl,
+		CreatedAt:  c.CreatedAt.Unix(),
+	}
+}
+
+// setCampaign copies editable attributes from request to campaign. If start time is not set, defaultStartAt is used
+func setCampaign(campaign *model.AdCampaign, opt dto.AdCampaignReq, defaultStartAt time.Time) {
+	startAt := defaultStartAt
+	if opt.StartAt > 0 {
+		startAt = time.Unix(opt.StartAt, 0)
+	}
+
+	var endAt pq.NullTime
+	if opt.EndAt > 0 {
+		endAt = pq.NullTime{Time: time.Unix(opt.EndAt, 0), Valid: true}
+	}
+
+	campaign.Name = opt.Name
+	campaign.TargetTagIds = uniqueInt64(opt.TargetTagIds)
+	campaign.Budget = opt.Budget
+	campaign.CostPerMille = opt.CostPerMille
+	campaign.StartAt = startAt
+	campaign.EndAt = endAt
+}
+
+// clickThroughRate returns percentage of clicks over impressions
+func clickThroughRate(clicks, impressions int64) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return math.Round(float64(clicks)/float64(impressions)*10000) / 100
+}
+
+// startOfDay returns the beginning of day of t in UTC
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func uniqueInt64(values []int64) []int64 {
+	result := make([]int64, 0, len(values))
+	exists := make(map[int64]bool, len(values))
+	for _, v := range values {
+		if exists[v] {
+			continue
+		}
+		exists[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// isCampaignRunning checks if campaign is active, within its schedule and has remaining budget
+func isCampaignRunning(campaign *model.AdCampaign, t time.Time) bool {
+	if campaign.StatusId != api.CampaignActive || campaign.StartAt.After(t) || campaign.Spent >= campaign.Budget {
+		return false
+	}
+
+	return !campaign.EndAt.Valid || campaign.EndAt.Time.After(t)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+)
+
+type AdvertiserStatement struct {
+	addCampaignSpent       *sqlx.Stmt
+	countReach             *sqlx.Stmt
+	countTagsById          *sqlx.Stmt
+	countUserImpressions   *sqlx.Stmt
+	deleteCreative         *sqlx.Stmt
+	findAuthById           *sqlx.Stmt
+	findCampaignById       *sqlx.Stmt
+	findCampaignByCreative *sqlx.Stmt
+	findCampaigns          *sqlx.Stmt
+	findCreatives          *sqlx.Stmt
+	findServe              *sqlx.Stmt
+	findServedCreative     *sqlx.Stmt
+	findStats              *sqlx.Stmt
+	findTagStats           *sqlx.Stmt
+	insertCampaign         *sqlx.NamedStmt
+	insertCreative         *sqlx.NamedStmt
+	insertEvent            *sqlx.NamedStmt
+	insertServe            *sqlx.NamedStmt
+	isAdvertiser           *sqlx.Stmt
+	rollupStats            *sqlx.Stmt
+	updateCampaign         *sqlx.NamedStmt
+}
+
+func initAdvertiserStatement(db *nsql.SqlDatabase) AdvertiserStatement {
+	return AdvertiserStatement{
+		addCampaignSpent:       db.Prepare(`update ad_campaign set spent = spent + $2 where id = $1 and status_id = 1 and spent < budget`),
+		countReach:             db.Prepare(`select count(distinct user_id) from ad_event where campaign_id = $1 and event_type_id = 1 and created_at >= $2 and created_at < $3`),
+		countTagsById:          db.Prepare(`select count(id) from ad_tag where id = any($1)`),
+		countUserImpressions:   db.Prepare(`select count(id) from ad_event where campaign_id = $1 and user_id = $2 and event_type_id = 1 and created_at >= $3`),
+		deleteCreative:         db.Prepare(`update ad_creative set status_id = 2, updated_at = $3 where id = $1 and campaign_id = $2 and status_id = 1`),
+		findAuthById:           db.Prepare(`select id, username, password, status_id, created_at, updated_at from user_auth where id = $1`),
+		findCampaignById:       db.Prepare(`select id, advertiser_id, name, target_tag_ids, budget, cost_per_mille, spent, start_at, end_at, status_id, created_at, updated_at, "version" from ad_campaign where id = $1 and advertiser_id = $2`),
+		findCampaignByCreative: db.Prepare(`select c.id, c.advertiser_id, c.name, c.target_tag_ids, c.budget, c.cost_per_mille, c.spent, c.start_at, c.end_at, c.status_id, c.created_at, c.updated_at, c."version" from ad_campaign as c inner join ad_creative as cr on cr.campaign_id = c.id where cr.id = $1`),
+		findCampaigns:          db.Prepare(`select id, advertiser_id, name, target_tag_ids, budget, cost_per_mille, spent, start_at, end_at, status_id, created_at, updated_at, "version" from ad_campaign where advertiser_id = $1 and ($2::smallint = 0 or status_id = $2) order by created_at desc, id desc limit $3 offset $4`),
+		findCreatives:          db.Prepare(`select id, campaign_id, image_file, headline, click_url, status_id, created_at, updated_at from ad_creative where campaign_id = $1 and status_id = 1 order by created_at, id`),
+		findServe:              db.Prepare(`select id, user_id, campaign_id, creative_id, ad_tag_id, created_at from ad_serve where id = $1 and user_id = $2`),
+		findServedCreative:     db.Prepare(`select cr.id, cr.campaign_id, cr.image_file, cr.headline, cr.click_url, cr.status_id, cr.created_at, cr.updated_at, score.ad_tag_id from ad_creative as cr inner join ad_campaign as c on c.id = cr.campaign_id left join lateral (select count(t.id) as matched, coalesce((array_agg(t.id order by f.user_id is null, t.id))[1], 0) as ad_tag_id from ad_tag as t left join user_ad_tag as f on f.ad_tag_id = t.id and f.user_id = $1 where t.id = any(c.target_tag_ids) and (f.user_id is not null or t.segment = any($2))) as score on true where cr.status_id = 1 and c.status_id = 1 and c.start_at <= now() and (c.end_at is null or c.end_at > now()) and c.spent < c.budget and (coalesce(cardinality(c.target_tag_ids), 0) = 0 or score.matched > 0) and ($3 = 0 or (select count(e.id) from ad_event as e where e.campaign_id = c.id and e.user_id = $1 and e.event_type_id = 1 and e.created_at >= $4) < $3) order by score.matched desc, random() limit 1`),
+		findStats:              db.Prepare(`select campaign_id, ad_tag_id, hour_at, impressions, clicks, reach from ad_stat_hourly where campaign_id = $1 and ad_tag_id = 0 and hour_at >= $2 and hour_at < $3 order by hour_at`),
+		findTagStats:           db.Prepare(`select s.ad_tag_id, t.name as ad_tag_name, sum(s.impressions) as impressions, sum(s.clicks) as clicks from ad_stat_hourly as s inner join ad_tag as t on t.id = s.ad_tag_id where s.campaign_id = $1 and s.ad_tag_id > 0 and s.hour_at >= $2 and s.hour_at < $3 group by s.ad_tag_id, t.name order by t.name`),
+		insertCampaign:         db.PrepareNamed(`insert into ad_campaign(id, advertiser_id, name, target_tag_ids, budget, cost_per_mille, spent, start_at, end_at, status_id, created_at, updated_at, "version") values (:id, :advertiser_id, :name, :target_tag_ids, :budget, :cost_per_mille, :spent, :start_at, :end_at, :status_id, :created_at, :updated_at, :version)`),
+		insertCreative:         db.PrepareNamed(`insert into ad_creative(id, campaign_id, image_file, headline, click_url, status_id, created_at, updated_at) values (:id, :campaign_id, :image_file, :headline, :click_url, :status_id, :created_at, :updated_at)`),
+		insertEvent:            db.PrepareNamed(`insert into ad_event(id, event_type_id, serve_id, campaign_id, creative_id, ad_tag_id, user_id, created_at) values (:id, :event_type_id, :serve_id, :campaign_id, :creative_id, :ad_tag_id, :user_id, :created_at) on conflict (serve_id, event_type_id) do nothing`),
+		insertServe:            db.PrepareNamed(`insert into ad_serve(id, user_id, campaign_id, creative_id, ad_tag_id, created_at) values (:id, :user_id, :campaign_id, :creative_id, :ad_tag_id, :created_at)`),
+		isAdvertiser:           db.Prepare(`select exists(select 1 from user_subscription where user_id = $1 and plan_type_id = 2 and status_id = 1 and period_end > now())`),
+		rollupStats:            db.Prepare(`insert into ad_stat_hourly(campaign_id, ad_tag_id, hour_at, impressions, clicks, reach) select e.campaign_id, 0, date_trunc('hour', e.created_at), count(e.id) filter (where e.event_type_id = 1), count(e.id) filter (where e.event_type_id = 2), count(distinct e.user_id) filter (where e.event_type_id = 1) from ad_event as e where e.created_at >= $1 and e.created_at < $2 group by e.campaign_id, date_trunc('hour', e.created_at) union all select e.campaign_id, e.ad_tag_id, date_trunc('hour', e.created_at), count(e.id) filter (where e.event_type_id = 1), count(e.id) filter (where e.event_type_id = 2), count(distinct e.user_id) filter (where e.event_type_id = 1) from ad_event as e where e.ad_tag_id > 0 and e.created_at >= $1 and e.created_at < $2 group by e.campaign_id, e.ad_tag_id, date_trunc('hour', e.created_at) on conflict (campaign_id, ad_tag_id, hour_at) do update set impressions = excluded.impressions, clicks = excluded.clicks, reach = excluded.reach`),
+		updateCampaign:         db.PrepareNamed(`update ad_campaign set name = :name, target_tag_ids = :target_tag_ids, budget = :budget, cost_per_mille = :cost_per_mille, start_at = :start_at, end_at = :end_at, status_id = :status_id, updated_at = :updated_at, "version" = :version where id = :id and advertiser_id = :advertiser_id and "version" = :current_version`),
+	}
+}
@@ -76,6 +76,55 @@ func (s *Asset) UploadFile(req dto.UploadReq) (*dto.UploadResp, error) {
 	return &resp, nil
 }
 
+// UploadPrivateFile uploads file that is not publicly accessible, file url is a presigned url valid for req.Lifetime
+func (s *Asset) UploadPrivateFile(req dto.PrivateUploadReq) (*dto.UploadResp, error) {
+	// Determine dir
+	dir, err := s.GetDir(req.AssetType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Upload file
+	dest := dir + req.FileName
+	err = s.Datasource.Upload(req.File, req.ContentType, dest, api.AssetsPrivateScope)
+	if err != nil {
+		s.Logger.Error("unable to upload private file", err)
+		return nil, err
+	}
+
+	// Generate presigned url
+	fileUrl, err := s.Datasource.GetPresignedUrl(dest, req.Lifetime)
+	if err != nil {
+		s.Logger.Error("unable to generate presigned url", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := dto.UploadResp{
+		FileName: req.FileName,
+		FileUrl:  fileUrl,
+	}
+
+	return &resp, nil
+}
+
+func (s *Asset) DeleteFile(assetType int, fileName string) error {
+	// Determine dir
+	dir, err := s.GetDir(assetType)
+	if err != nil {
+		return err
+	}
+
+	// Delete file
+	err = s.Datasource.Delete(dir + fileName)
+	if err != nil {
+		s.Logger.Error("unable to delete file", err)
+		return err
+	}
+
+	return nil
+}
+
 func (s *Asset) buildUrl(filePath string) string {
 	return s.BaseUrl + "/" + filePath
 }
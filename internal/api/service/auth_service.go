@@ -12,17 +12,17 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/pkg/njwt"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Authenticator struct {
-	TokenIssuer           api.JWTIssuerComponent
-	IdGen                 *api.SnowflakeGen
-	Errors                *api.Errors
-	Logger                nlog.Logger
-	AppClientSecret       string
-	DashboardClientSecret string
+	TokenIssuer     api.JWTIssuerComponent
+	IdGen           *api.SnowflakeGen
+	Errors          *api.Errors
+	Logger          nlog.Logger
+	AppClientSecret string
 }
 
 func (a *Authenticator) Init(app *api.Api) error {
@@ -31,7 +31,6 @@ func (a *Authenticator) Init(app *api.Api) error {
 	a.Errors = app.Components.Errors
 	a.Logger = app.Logger
 	a.AppClientSecret = app.Config.GetString(api.ConfAppClientSecret)
-	a.DashboardClientSecret = app.Config.GetString(api.ConfDashboardClientSecret)
 	return nil
 }
 
@@ -62,6 +61,34 @@ func (a *Authenticator) ValidateVerifyEmailToken(bearer string) (*dto.VerifyEmai
 	return &resp, nil
 }
 
+func (a *Authenticator) ValidateChangeEmailToken(bearer string) (*dto.ChangeEmailSession, error) {
+	// Extract bearer token
+	token, err := a.ExtractBearerToken(bearer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify token
+	claim, err := a.TokenIssuer.Verify(token)
+	if err != nil {
+		// Convert token error and return
+		return nil, a.GetTokenError(err)
+	}
+
+	// Validate purpose
+	if claim.Purpose != api.JWTPurposeChangeEmail {
+		return nil, nhttp.ErrUnauthorized
+	}
+
+	resp := dto.ChangeEmailSession{
+		RequestId:     claim.Session,
+		UserId:        claim.Subject,
+		NewEmail:      claim.Extra[api.NewEmailKey],
+		UserSignature: claim.Extra[api.UserSignatureKey],
+	}
+	return &resp, nil
+}
+
 func (a *Authenticator) ValidateResetPasswordToken(bearer string) (*dto.ResetPasswordSession, error) {
 	// Extract bearer token
 	token, err := a.ExtractBearerToken(bearer)
@@ -89,6 +116,38 @@ func (a *Authenticator) ValidateResetPasswordToken(bearer string) (*dto.ResetPas
 	return &resp, nil
 }
 
+func (a *Authenticator) ValidateTwoFactorToken(bearer string) (*dto.TwoFactorSession, error) {
+	// Extract bearer token
+	token, err := a.ExtractBearerToken(bearer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify token
+	claim, err := a.TokenIssuer.Verify(token)
+	if err != nil {
+		// Convert token error and return
+		return nil, a.GetTokenError(err)
+	}
+
+	// Validate purpose
+	if claim.Purpose != api.JWTPurposeTwoFactor {
+		return nil, nhttp.ErrUnauthorized
+	}
+
+	// Auth provider is only set if challenge is issued on login by third party
+	authProviderId, _ := strconv.Atoi(claim.Extra[api.AuthProviderIdKey])
+
+	resp := dto.TwoFactorSession{
+		RequestId:      claim.Session,
+		UserId:         claim.Subject,
+		Target:         claim.Extra[api.TwoFactorTargetKey],
+		OrganizationId: claim.Extra[api.OrganizationIdKey],
+		AuthProviderId: authProviderId,
+	}
+	return &resp, nil
+}
+
 func (a *Authenticator) SignMd5(req dto.SignatureReq) (string, error) {
 	raw := fmt.Sprintf(req.Format, req.Args...)
 	hasher := md5.New()
@@ -104,7 +163,7 @@ func (a *Authenticator) SignMd5(req dto.SignatureReq) (string, error) {
 func (a *Authenticator) NewOneTimeToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
 	// Validate purpose
 	switch req.Purpose {
-	case api.JWTUser, api.JWTApp:
+	case api.JWTUser, api.JWTApp, api.JWTOrganizationAdmin, api.JWTAdvertiser:
 		return nil, errors.New("invalid purpose")
 	}
 
@@ -138,6 +197,8 @@ func (a *Authenticator) GetTokenError(err error) error {
 			return a.Errors.New("USR003")
 		case jwt.ValidationErrorMalformed:
 			return a.Errors.New("USR004")
+		case jwt.ValidationErrorUnverifiable, jwt.ValidationErrorSignatureInvalid:
+			return nhttp.ErrUnauthorized
 		default:
 			a.Logger.Error("unhandled jwt issuer error", err)
 		}
@@ -210,16 +271,147 @@ func (a *Authenticator) NewAccessToken(req dto.JWTOptReq) (*entity.AccessToken,
 	}, err
 }
 
-func (a *Authenticator) ValidateClient(secret string) (err error) {
-	if a.AppClientSecret == secret {
-		return nil
+func (a *Authenticator) NewOrganizationAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
+	t, err := a.TokenIssuer.New(njwt.ClaimOpt{
+		SessionId: req.SessionId,
+		Subject:   req.Subject,
+		Audience:  api.JWTAudienceOrganization,
+		Lifetime:  time.Duration(req.Lifetime),
+		Purpose:   api.JWTOrganizationAdmin,
+		Extras:    req.Extras,
+	})
+
+	if err != nil {
+		a.Logger.Error("unable to issue organization access token", err)
+		return nil, err
 	}
 
-	return nhttp.ErrUnauthorized
+	return &entity.AccessToken{
+		Token:     t.Encoded,
+		ExpiredAt: t.ExpiredAt,
+	}, err
 }
 
-func (a *Authenticator) ValidateClientDashboard(secret string) (err error) {
-	if a.DashboardClientSecret == secret {
+func (a *Authenticator) ValidateOrganizationAccess(bearer string) (*dto.OrganizationSession, error) {
+	// Extract bearer token
+	token, err := a.ExtractBearerToken(bearer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify token
+	claim, err := a.TokenIssuer.Verify(token)
+	if err != nil {
+		// Convert token error and return
+		return nil, a.GetTokenError(err)
+	}
+
+	// Verify purpose and audience
+	if claim.Purpose != api.JWTOrganizationAdmin || claim.Audience != api.JWTAudienceOrganization {
+		return nil, nhttp.ErrUnauthorized
+	}
+
+	// Get organization id
+	organizationId := claim.Extra[api.OrganizationIdKey]
+	if organizationId == "" {
+		return nil, nhttp.ErrUnauthorized
+	}
+
+	resp := dto.OrganizationSession{
+		UserId:         claim.Subject,
+		OrganizationId: organizationId,
+	}
+	return &resp, nil
+}
+
+func (a *Authenticator) NewAdvertiserAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
+	t, err := a.TokenIssuer.New(njwt.ClaimOpt{
+		SessionId: req.SessionId,
+		Subject:   req.Subject,
+		Audience:  api.JWTAudienceAdvertiser,
+		Lifetime:  time.Duration(req.Lifetime),
+		Purpose:   api.JWTAdvertiser,
+		Extras:    req.Extras,
+	})
+
+	if err != nil {
+		a.Logger.Error("unable to issue advertiser access token", err)
+		return nil, err
+	}
+
+	return &entity.AccessToken{
+		Token:     t.Encoded,
+		ExpiredAt: t.ExpiredAt,
+	}, err
+}
+
+func (a *Authenticator) ValidateAdvertiserAccess(bearer string) (string, error) {
+	// Extract bearer token
+	token, err := a.ExtractBearerToken(bearer)
+	if err != nil {
+		return "", err
+	}
+
+	// Verify token
+	claim, err := a.TokenIssuer.Verify(token)
+	if err != nil {
+		// Convert token error and return
+		return "", a.GetTokenError(err)
+	}
+
+	// Verify purpose and audience
+	if claim.Purpose != api.JWTAdvertiser || claim.Audience != api.JWTAudienceAdvertiser {
+		return "", nhttp.ErrUnauthorized
+	}
+
+	return claim.Subject, nil
+}
+
+func (a *Authenticator) NewAdminAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error) {
+	t, err := a.TokenIssuer.New(njwt.ClaimOpt{
+		SessionId: req.SessionId,
+		Subject:   req.Subject,
+		Audience:  api.JWTAudienceAdmin,
+		Lifetime:  time.Duration(req.Lifetime),
+		Purpose:   api.JWTAdmin,
+		Extras:    req.Extras,
+	})
+
+	if err != nil {
+		a.Logger.Error("unable to issue admin access token", err)
+		return nil, err
+	}
+
+	return &entity.AccessToken{
+		Token:     t.Encoded,
+		ExpiredAt: t.ExpiredAt,
+	}, err
+}
+
+func (a *Authenticator) ValidateAdminAccess(bearer string) (sessionId string, userId string, err error) {
+	// Extract bearer token
+	token, err := a.ExtractBearerToken(bearer)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Verify token
+	claim, err := a.TokenIssuer.Verify(token)
+	if err != nil {
+		// Convert token error and return
+		return "", "", a.GetTokenError(err)
+	}
+
+	// Verify purpose and audience
+	if claim.Purpose != api.JWTAdmin || claim.Audience != api.JWTAudienceAdmin {
+		return "", "", nhttp.ErrUnauthorized
+	}
+
+	return claim.Session, claim.Subject, nil
+}
+
+func (a *Authenticator) ValidateClient(secret string) (err error) {
+	if a.AppClientSecret == secret {
 		return nil
 	}
 
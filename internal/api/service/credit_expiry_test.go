@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/lib/pq"
+	"testing"
+	"time"
+)
+
+func newTestCreditLot(amount float64, expiredAt time.Time) model.UserCreditWalletTrx {
+	return model.UserCreditWalletTrx{
+		Amount:    amount,
+		ExpiredAt: pq.NullTime{Valid: !expiredAt.IsZero(), Time: expiredAt},
+	}
+}
+
+func TestAllocateExpiringBalance(t *testing.T) {
+	now := time.Date(2020, 9, 16, 10, 0, 0, 0, time.UTC)
+	day10 := now.AddDate(0, 0, 10)
+	day30 := now.AddDate(0, 0, 30)
+
+	cases := []struct {
+		name        string
+		balance     float64
+		nonExpiring float64
+		lots        []model.UserCreditWalletTrx
+		newLots     []model.UserCreditWalletTrx
+		expired     float64
+		amount      float64
+		date        time.Time
+	}{
+		{"balance held by non expiring credit", 5, 5, []model.UserCreditWalletTrx{newTestCreditLot(5, day30)},
+			nil, 0, 0, time.Time{}},
+		{"oldest credit is spent first", 7, 0,
+			[]model.UserCreditWalletTrx{newTestCreditLot(5, day30), newTestCreditLot(5, day10)},
+			nil, 0, 2, day10},
+		{"balance of legacy wallet without active credit is expired", 4, 0, nil, nil, 4, 0, time.Time{}},
+		{"balance beyond active credit is expired", 6, 1, []model.UserCreditWalletTrx{newTestCreditLot(3, day10)},
+			nil, 2, 3, day10},
+		{"credits expiring on the same day are accumulated", 6, 0,
+			[]model.UserCreditWalletTrx{newTestCreditLot(3, day10.Add(2*time.Hour)), newTestCreditLot(3, day10)},
+			nil, 0, 6, day10},
+		{"new credits are merged", 9, 0, []model.UserCreditWalletTrx{newTestCreditLot(5, day30)},
+			[]model.UserCreditWalletTrx{newTestCreditLot(2, day10), newTestCreditLot(2, time.Time{}),
+				newTestCreditLot(2, now.Add(-time.Hour))},
+			0, 2, day10},
+	}
+
+	for _, c := range cases {
+		actual := allocateExpiringBalance(c.balance, c.nonExpiring, c.lots, now, c.newLots...)
+		if actual.Expired != c.expired || actual.Amount != c.amount || actual.Date.Valid == c.date.IsZero() ||
+			!actual.Date.Time.Equal(c.date) {
+			t.Errorf("%s: expected expired %v, amount %v on %s, got %+v", c.name, c.expired, c.amount, c.date, actual)
+		}
+	}
+}
+
+func TestConsumeCreditLots(t *testing.T) {
+	now := time.Date(2020, 9, 16, 10, 0, 0, 0, time.UTC)
+	day10 := now.AddDate(0, 0, 10)
+	day30 := now.AddDate(0, 0, 30)
+	lots := []model.UserCreditWalletTrx{newTestCreditLot(5, day30), newTestCreditLot(5, day10)}
+
+	cases := []struct {
+		name        string
+		balance     float64
+		nonExpiring float64
+		amount      float64
+		expiredAt   time.Time
+		ok          bool
+	}{
+		{"credit that expires first is consumed", 10, 0, 3, day10, true},
+		{"latest expiry of consumed credits", 10, 0, 7, day30, true},
+		{"non expiring credit is consumed last", 12, 2, 11, time.Time{}, true},
+		{"balance beyond active credit has expired", 12, 0, 11, time.Time{}, false},
+	}
+
+	for _, c := range cases {
+		expiredAt, ok := consumeCreditLots(c.balance, c.nonExpiring, lots, c.amount)
+		if ok != c.ok || expiredAt.Valid == c.expiredAt.IsZero() || !expiredAt.Time.Equal(c.expiredAt) {
+			t.Errorf("%s: expected expiry %s (ok = %t), got %+v (ok = %t)", c.name, c.expiredAt, c.ok, expiredAt, ok)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nstr"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+func NewCreditHandler(app *api.Api) CreditHandler {
+	return CreditHandler{
+		CreditService: app.Services.Credit,
+		Logger:        app.Logger,
+	}
+}
+
+type CreditHandler struct {
+	CreditService api.CreditService
+	Logger        nlog.Logger
+}
+
+func (h *CreditHandler) PutExpireCredits(_ *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.CreditService.ExpireCredits(dto.CreditExpireOpt{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *CreditHandler) GetTrxHistory(r *http.Request) (*nhttp.Success, error) {
+	// Get filter
+	reqBody := newCreditTrxHistoryReq(r)
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	respBody, err := h.CreditService.ListTrxHistory(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *CreditHandler) GetExportTrxHistory(r *http.Request) (*nhttp.Success, error) {
+	// Get filter
+	reqBody := newCreditTrxHistoryReq(r)
+	reqBody.UserId = mux.Vars(r)["userId"]
+
+	// Call service
+	content, err := h.CreditService.ExportTrxHistory(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send as csv file
+	resp := nhttp.Success{
+		File: &nhttp.File{
+			Name:        fmt.Sprintf("credit-transactions-%s.csv", reqBody.UserId),
+			ContentType: nhttp.ContentTypeCSV,
+			Content:     content,
+		},
+	}
+	return &resp, nil
+}
+
+func (h *CreditHandler) PostTransfer(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.CreditTransferReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	respBody, err := h.CreditService.Transfer(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *CreditHandler) PostAdjustment(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.CreditAdjustmentReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set admin
+	reqBody.RequestedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.CreditService.RequestAdjustment(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *CreditHandler) GetAdjustments(r *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	query := r.URL.Query()
+	skip, limit := api.Pagination(query)
+
+	// Call service
+	respBody, err := h.CreditService.ListAdjustments(dto.CreditAdjustmentListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		StatusId: nstr.ParseInt8(query.Get("status_id"), 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *CreditHandler) PutApproveAdjustment(r *http.Request) (*nhttp.Success, error) {
+	return h.reviewAdjustment(r, true)
+}
+
+func (h *CreditHandler) PutRejectAdjustment(r *http.Request) (*nhttp.Success, error) {
+	return h.reviewAdjustment(r, false)
+}
+
+func (h *CreditHandler) reviewAdjustment(r *http.Request, approved bool) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.CreditAdjustmentReviewReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.Approved = approved
+	reqBody.ReviewedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.CreditService.ReviewAdjustment(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+// newCreditTrxHistoryReq parse transaction history filter from query string
+func newCreditTrxHistoryReq(r *http.Request) dto.CreditTrxHistoryReq {
+	// Get skip and limit
+	query := r.URL.Query()
+	skip, limit := api.Pagination(query)
+
+	return dto.CreditTrxHistoryReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		StatusId:    nstr.ParseInt8(query.Get("status_id"), 0),
+		EntryTypeId: nstr.ParseInt8(query.Get("entry_type_id"), 0),
+		StartAt:     nstr.ParseInt64(query.Get("start"), 0),
+		EndAt:       nstr.ParseInt64(query.Get("end"), 0),
+	}
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"database/sql"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 func NewCreditRepository(db *nsql.SqlDatabase, logger nlog.Logger, errComponent *api.Errors) api.CreditRepository {
@@ -25,35 +28,37 @@ type creditRepository struct {
 	Errors *api.Errors
 }
 
-func (c *creditRepository) FindWalletByUser(userId string) (*model.UserCreditWallet, error) {
+func (c *creditRepository) FindWalletByUser(tx *sqlx.Tx, userId string) (*model.UserCreditWallet, error) {
 	var w model.UserCreditWallet
-	err := c.Stmt.findWalletByUser.Get(&w, userId)
+	err := nsql.StmtTx(c.Stmt.findWalletByUser, tx).Get(&w, userId)
 	return &w, err
 }
 
-func (c *creditRepository) FindWalletById(walletId string) (*model.UserCreditWallet, error) {
+func (c *creditRepository) FindWalletById(tx *sqlx.Tx, walletId string) (*model.UserCreditWallet, error) {
 	var w model.UserCreditWallet
-	err := c.Stmt.findWalletById.Get(&w, walletId)
+	err := nsql.StmtTx(c.Stmt.findWalletById, tx).Get(&w, walletId)
 	return &w, err
 }
 
-func (c *creditRepository) InsertWallet(wallet *model.UserCreditWallet, initTrx *model.UserCreditWalletTrx) error {
-	// Begin transaction
-	trx, err := c.Db.Conn.Beginx()
-	if err != nil {
-		return err
+func (c *creditRepository) InsertWallet(tx *sqlx.Tx, wallet *model.UserCreditWallet, initTrx *model.UserCreditWalletTrx) (err error) {
+	// Begin transaction if it is not provided by caller
+	if tx == nil {
+		tx, err = c.Db.Conn.Beginx()
+		if err != nil {
+			return err
+		}
+		defer nsql.ReleaseTx(tx, &err, c.Logger)
 	}
-	defer nsql.ReleaseTx(trx, &err, c.Logger)
 
 	// Insert wallet
-	_, err = trx.NamedStmt(c.Stmt.insertWallet).Exec(&wallet)
+	_, err = tx.NamedStmt(c.Stmt.insertWallet).Exec(&wallet)
 	if err != nil {
 		c.Logger.Error("update credit wallet", err)
 		return err
 	}
 
 	// Add new credit transaction
-	_, err = trx.NamedStmt(c.Stmt.insertTrx).Exec(&initTrx)
+	_, err = tx.NamedStmt(c.Stmt.insertTrx).Exec(&initTrx)
 	if err != nil {
 		c.Logger.Error("insert credit trx", err)
 		return err
@@ -68,29 +73,31 @@ func (c *creditRepository) IsExistWalletByUser(userId string) (bool, error) {
 	return isExist, err
 }
 
-func (c *creditRepository) IsExistTrxRef(walletId, trxId string) (bool, error) {
+func (c *creditRepository) IsExistTrxRef(tx *sqlx.Tx, walletId, trxId string) (bool, error) {
 	var isExist bool
-	err := c.Stmt.isExistTrxRef.Get(&isExist, walletId, trxId)
+	err := nsql.StmtTx(c.Stmt.isExistTrxRef, tx).Get(&isExist, walletId, trxId)
 	return isExist, err
 }
 
-func (c *creditRepository) InsertTrx(wallet *model.UserCreditWallet, newTrx *model.UserCreditWalletTrx) error {
-	// Begin transaction
-	trx, err := c.Db.Conn.Beginx()
-	if err != nil {
-		return err
+func (c *creditRepository) InsertTrx(tx *sqlx.Tx, wallet *model.UserCreditWallet, newTrx *model.UserCreditWalletTrx) (err error) {
+	// Begin transaction if it is not provided by caller
+	if tx == nil {
+		tx, err = c.Db.Conn.Beginx()
+		if err != nil {
+			return err
+		}
+		defer nsql.ReleaseTx(tx, &err, c.Logger)
 	}
-	defer nsql.ReleaseTx(trx, &err, c.Logger)
 
 	// Add new credit transaction
-	_, err = trx.NamedStmt(c.Stmt.insertTrx).Exec(&newTrx)
+	_, err = tx.NamedStmt(c.Stmt.insertTrx).Exec(&newTrx)
 	if err != nil {
 		c.Logger.Error("insert credit trx", err)
 		return err
 	}
 
 	// Update user wallet
-	result, err := trx.NamedStmt(c.Stmt.updateWalletBalance).Exec(&wallet)
+	result, err := tx.NamedStmt(c.Stmt.updateWalletBalance).Exec(&wallet)
 	if err != nil {
 		c.Logger.Error("update credit wallet", err)
 		return err
@@ -105,20 +112,257 @@ func (c *creditRepository) InsertTrx(wallet *model.UserCreditWallet, newTrx *mod
 
 	if count == 0 {
 		c.Logger.Errorf("no wallet update affected. Rolling back")
-		return c.Errors.New("")
+		return c.Errors.New("CRD008")
 	}
 
 	return nil
 }
 
-func (c *creditRepository) FindTrxById(trxId string) (*model.UserCreditWalletTrx, error) {
+func (c *creditRepository) FindTrxByRef(tx *sqlx.Tx, walletId, refId string) ([]model.UserCreditWalletTrx, error) {
+	rows := make([]model.UserCreditWalletTrx, 0)
+	err := nsql.StmtTx(c.Stmt.findTrxByRef, tx).Select(&rows, walletId, refId)
+	return rows, err
+}
+
+func (c *creditRepository) FindTrxById(tx *sqlx.Tx, trxId string) (*model.UserCreditWalletTrx, error) {
 	var t model.UserCreditWalletTrx
-	err := c.Stmt.findTrxById.Get(&t, trxId)
+	err := nsql.StmtTx(c.Stmt.findTrxById, tx).Get(&t, trxId)
 	return &t, err
 }
 
-func (c *creditRepository) FindWalletByTrx(trxId string) (*model.UserCreditWallet, error) {
+func (c *creditRepository) FindWalletByTrx(tx *sqlx.Tx, trxId string) (*model.UserCreditWallet, error) {
 	var w model.UserCreditWallet
-	err := c.Stmt.findWalletByTrx.Get(&w, trxId)
+	err := nsql.StmtTx(c.Stmt.findWalletByTrx, tx).Get(&w, trxId)
 	return &w, err
 }
+
+func (c *creditRepository) FindExpiredPendingTrx(now time.Time, cursor string, limit int) ([]model.UserCreditWalletTrx, error) {
+	rows := make([]model.UserCreditWalletTrx, 0)
+	err := c.Stmt.findExpiredPendingTrx.Select(&rows, now, cursor, limit)
+	return rows, err
+}
+
+// FindExpiringWallets returns wallets that have balance expiring by now. Wallets that have never been refreshed by credit
+// expiry have no expiring date, so they are found by their credits that have expired since last update
+func (c *creditRepository) FindExpiringWallets(now time.Time, cursor string, limit int) ([]model.UserCreditWallet, error) {
+	rows := make([]model.UserCreditWallet, 0)
+	err := c.Stmt.findExpiringWallets.Select(&rows, now, cursor, limit)
+	return rows, err
+}
+
+func (c *creditRepository) FindActiveCreditLots(tx *sqlx.Tx, walletId string, now time.Time) ([]model.UserCreditWalletTrx, error) {
+	rows := make([]model.UserCreditWalletTrx, 0)
+	err := nsql.StmtTx(c.Stmt.findActiveCreditLots, tx).Select(&rows, walletId, now)
+	return rows, err
+}
+
+func (c *creditRepository) SumNonExpiringCredit(tx *sqlx.Tx, walletId string) (float64, error) {
+	var total float64
+	err := nsql.StmtTx(c.Stmt.sumNonExpiringCredit, tx).Get(&total, walletId)
+	return total, err
+}
+
+func (c *creditRepository) SumEarnedCredit(walletId string, startAt, endAt time.Time) (float64, error) {
+	var total float64
+	err := c.Stmt.sumEarnedCredit.Get(&total, walletId, startAt, endAt)
+	return total, err
+}
+
+func (c *creditRepository) UpdateWallet(wallet *model.UserCreditWallet) error {
+	// Update user wallet
+	result, err := c.Stmt.updateWalletBalance.Exec(&wallet)
+	if err != nil {
+		c.Logger.Error("update credit wallet", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		c.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		c.Logger.Errorf("no wallet update affected")
+		return c.Errors.New("CRD008")
+	}
+
+	return nil
+}
+
+func (c *creditRepository) ReleasePendingTrx(tx *sqlx.Tx, wallet *model.UserCreditWallet, pendingTrx,
+	releaseTrx *model.UserCreditWalletTrx) (err error) {
+	// Begin transaction if it is not provided by caller
+	if tx == nil {
+		tx, err = c.Db.Conn.Beginx()
+		if err != nil {
+			return err
+		}
+		defer nsql.ReleaseTx(tx, &err, c.Logger)
+	}
+
+	// Update pending transaction status
+	result, err := tx.NamedStmt(c.Stmt.updateTrxStatus).Exec(&pendingTrx)
+	if err != nil {
+		c.Logger.Error("update credit trx status", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		c.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		c.Logger.Errorf("no pending trx update affected. Rolling back")
+		return c.Errors.New("CRD006")
+	}
+
+	// Add release transaction
+	_, err = tx.NamedStmt(c.Stmt.insertTrx).Exec(&releaseTrx)
+	if err != nil {
+		c.Logger.Error("insert credit trx", err)
+		return err
+	}
+
+	// Update user wallet
+	result, err = tx.NamedStmt(c.Stmt.updateWalletBalance).Exec(&wallet)
+	if err != nil {
+		c.Logger.Error("update credit wallet", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err = result.RowsAffected()
+	if err != nil {
+		c.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		c.Logger.Errorf("no wallet update affected. Rolling back")
+		return c.Errors.New("CRD008")
+	}
+
+	return nil
+}
+
+func (c *creditRepository) FindTrxHistory(filter model.UserCreditWalletTrxFilter, skip int64, limit int) ([]model.UserCreditWalletTrxDetail, error) {
+	var rows []model.UserCreditWalletTrxDetail
+	err := c.Stmt.findTrxHistory.Select(&rows, filter.UserId, filter.StatusId, filter.EntryTypeId, filter.StartAt, filter.EndAt,
+		limit, skip)
+	return rows, err
+}
+
+func (c *creditRepository) CountTrxHistory(filter model.UserCreditWalletTrxFilter) (int64, error) {
+	var count int64
+	err := c.Stmt.countTrxHistory.Get(&count, filter.UserId, filter.StatusId, filter.EntryTypeId, filter.StartAt, filter.EndAt)
+	return count, err
+}
+
+func (c *creditRepository) FindActiveUserByEmail(email string) (*model.UserProfile, error) {
+	var p model.UserProfile
+	err := c.Stmt.findActiveUserByEmail.Get(&p, email)
+	return &p, err
+}
+
+func (c *creditRepository) SumTransferOut(walletId string, since time.Time) (*model.UserCreditTrxSummary, error) {
+	var summary model.UserCreditTrxSummary
+	err := c.Stmt.sumTransferOut.Get(&summary, walletId, since)
+	return &summary, err
+}
+
+func (c *creditRepository) Transfer(sender, recipient *model.UserCreditWallet, senderTrx, recipientTrx *model.UserCreditWalletTrx) error {
+	// Begin transaction
+	trx, err := c.Db.Conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer nsql.ReleaseTx(trx, &err, c.Logger)
+
+	// Add sender and recipient transaction
+	for _, t := range []*model.UserCreditWalletTrx{senderTrx, recipientTrx} {
+		_, err = trx.NamedStmt(c.Stmt.insertTrx).Exec(t)
+		if err != nil {
+			c.Logger.Error("insert credit trx", err)
+			return err
+		}
+	}
+
+	// Update wallets ordered by id to prevent deadlock on concurrent transfers
+	wallets := []*model.UserCreditWallet{sender, recipient}
+	if recipient.Id < sender.Id {
+		wallets = []*model.UserCreditWallet{recipient, sender}
+	}
+
+	for _, w := range wallets {
+		var result sql.Result
+		result, err = trx.NamedStmt(c.Stmt.updateWalletBalance).Exec(w)
+		if err != nil {
+			c.Logger.Error("update credit wallet", err)
+			return err
+		}
+
+		// Check for affected rows
+		var count int64
+		count, err = result.RowsAffected()
+		if err != nil {
+			c.Logger.Error("cannot get affected rows", err)
+			return err
+		}
+
+		if count == 0 {
+			c.Logger.Errorf("no wallet update affected. Rolling back. WalletId = %s", w.Id)
+			err = c.Errors.New("CRD008")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *creditRepository) FindAdjustmentById(tx *sqlx.Tx, id string) (*model.CreditAdjustment, error) {
+	var a model.CreditAdjustment
+	err := nsql.StmtTx(c.Stmt.findAdjustmentById, tx).Get(&a, id)
+	return &a, err
+}
+
+func (c *creditRepository) FindAdjustments(statusId int8, skip int64, limit int8) ([]model.CreditAdjustment, error) {
+	rows := make([]model.CreditAdjustment, 0)
+	err := c.Stmt.findAdjustments.Select(&rows, statusId, limit, skip)
+	return rows, err
+}
+
+func (c *creditRepository) InsertAdjustment(tx *sqlx.Tx, adjustment *model.CreditAdjustment) error {
+	_, err := nsql.NamedStmtTx(c.Stmt.insertAdjustment, tx).Exec(adjustment)
+	if err != nil {
+		c.Logger.Error("insert credit adjustment", err)
+	}
+	return err
+}
+
+func (c *creditRepository) UpdateAdjustment(tx *sqlx.Tx, adjustment *model.CreditAdjustment) error {
+	// Update adjustment
+	result, err := nsql.NamedStmtTx(c.Stmt.updateAdjustment, tx).Exec(adjustment)
+	if err != nil {
+		c.Logger.Error("update credit adjustment", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		c.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		c.Logger.Errorf("no credit adjustment update affected")
+		return c.Errors.New("CRD017")
+	}
+
+	return nil
+}
@@ -1,21 +1,46 @@
 package service
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/csv"
 	"errors"
+	"fmt"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	validate "github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// creditExpiryBatchSize is the number of rows fetched on each credit expiry iteration
+const creditExpiryBatchSize = 100
+
+// creditExportMaxRange is the longest date range of transaction history that can be exported at once
+const creditExportMaxRange = 366 * 24 * time.Hour
+
+// creditRefundGracePeriod is the shortest lifetime of refunded credit, so credit that has expired or is about to expire
+// since it was charged can still be used
+const creditRefundGracePeriod = 7 * 24 * time.Hour
+
 type CreditService struct {
-	IdGen      *api.SnowflakeGen
-	Error      *api.Errors
-	Logger     nlog.Logger
-	Repository api.CreditRepository
+	IdGen               *api.SnowflakeGen
+	Error               *api.Errors
+	Logger              nlog.Logger
+	Repository          api.CreditRepository
+	Db                  *nsql.SqlDatabase
+	Validator           *validate.Validate
+	TransferDailyLimit  float64
+	TransferDailyCount  int
+	AdjustmentThreshold float64
 }
 
 func (s *CreditService) Init(app *api.Api) error {
@@ -23,12 +48,24 @@ func (s *CreditService) Init(app *api.Api) error {
 	s.Error = app.Components.Errors
 	s.Logger = app.Logger
 	s.Repository = NewCreditRepository(app.Datasources.Db, app.Logger, app.Components.Errors)
+	s.Validator = validate.New()
+	s.TransferDailyLimit = app.Config.GetFloat64(api.ConfCreditTransferDailyLimit)
+	s.TransferDailyCount = app.Config.GetInt(api.ConfCreditTransferDailyCount)
+	s.AdjustmentThreshold = app.Config.GetFloat64(api.ConfCreditAdjustmentThreshold)
+	s.Db = app.Datasources.Db
+
+	// Start credit expiry scheduler
+	expiryInterval := app.Config.GetInt(api.ConfCreditExpiryInterval)
+	if expiryInterval > 0 {
+		go s.runExpiryScheduler(time.Duration(expiryInterval) * time.Minute)
+	}
+
 	return nil
 }
 
 func (s *CreditService) CheckChargeAmount(opt dto.CreditChargeOpt) (int, error) {
 	// Get wallet
-	wallet, err := s.GetUserWallet(opt.UserId)
+	wallet, err := s.getUserWallet(opt.Tx, opt.UserId)
 	if err != nil {
 		return 0, err
 	}
@@ -44,7 +81,7 @@ func (s *CreditService) CheckChargeAmount(opt dto.CreditChargeOpt) (int, error)
 
 func (s *CreditService) Charge(opt dto.CreditChargeOpt) (string, error) {
 	// Get user wallet
-	wallet, err := s.GetUserWallet(opt.UserId)
+	wallet, err := s.getUserWallet(opt.Tx, opt.UserId)
 	if err != nil {
 		return "", err
 	}
@@ -58,14 +95,36 @@ func (s *CreditService) Charge(opt dto.CreditChargeOpt) (string, error) {
 
 	// Insert pending transaction
 	trxId, err := s.InsertPendingTrx(dto.CreditTrxOpt{
+		Tx:        opt.Tx,
 		WalletId:  wallet.Id,
 		Amount:    opt.Amount,
 		EntryType: api.Credit,
 	})
+	if err != nil {
+		return "", err
+	}
 
 	return trxId, nil
 }
 
+// SumEarnedCredit returns total of settled credit granted to user within time range
+func (s *CreditService) SumEarnedCredit(opt dto.CreditEarnedOpt) (float64, error) {
+	// Get user wallet
+	wallet, err := s.GetUserWallet(opt.UserId)
+	if err != nil {
+		return 0, err
+	}
+
+	// Sum earned credit
+	total, err := s.Repository.SumEarnedCredit(wallet.Id, opt.StartAt, opt.EndAt)
+	if err != nil {
+		s.Logger.Error("unable to sum earned credit", err)
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (s *CreditService) GetUserBalance(userId string) (*dto.UserCreditBalanceResp, error) {
 	// Get user wallet
 	wallet, err := s.GetUserWallet(userId)
@@ -92,6 +151,115 @@ func (s *CreditService) GetUserBalance(userId string) (*dto.UserCreditBalanceRes
 	return &resp, nil
 }
 
+func (s *CreditService) ListTrxHistory(opt dto.CreditTrxHistoryReq) (*dto.CreditTrxHistoryListResp, error) {
+	// Compose filter
+	filter, err := s.newTrxHistoryFilter(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get transaction history
+	rows, err := s.Repository.FindTrxHistory(filter, opt.Skip, int(opt.Limit))
+	if err != nil {
+		s.Logger.Error("unable to retrieve credit transaction history", err)
+		return nil, err
+	}
+
+	// Count transaction history
+	count, err := s.Repository.CountTrxHistory(filter)
+	if err != nil {
+		s.Logger.Error("unable to count credit transaction history", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := dto.CreditTrxHistoryListResp{
+		Transactions: make([]dto.CreditTrxHistoryResp, len(rows)),
+		Count:        count,
+	}
+	for k, v := range rows {
+		resp.Transactions[k] = composeTrxHistory(v)
+	}
+
+	return &resp, nil
+}
+
+func (s *CreditService) ExportTrxHistory(opt dto.CreditTrxHistoryReq) ([]byte, error) {
+	// Limit export date range, export the latest range if start is not set
+	end := time.Now()
+	if opt.EndAt > 0 {
+		end = time.Unix(opt.EndAt, 0)
+	}
+	if opt.StartAt == 0 {
+		opt.StartAt = end.Add(-creditExportMaxRange).Unix()
+	}
+	if end.Sub(time.Unix(opt.StartAt, 0)) > creditExportMaxRange {
+		return nil, s.Error.New("CRD019")
+	}
+
+	// Compose filter
+	filter, err := s.newTrxHistoryFilter(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get all transaction history in range
+	rows, err := s.Repository.FindTrxHistory(filter, 0, 0)
+	if err != nil {
+		s.Logger.Error("unable to retrieve credit transaction history", err)
+		return nil, err
+	}
+
+	// Write header
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err = w.Write([]string{"id", "created_at", "entry_type", "status", "amount", "balance", "balance_pending",
+		"description", "challenge_id", "donation_id", "expired_at"})
+	if err != nil {
+		s.Logger.Error("unable to write csv header", err)
+		return nil, err
+	}
+
+	// Write rows
+	for _, v := range rows {
+		item := composeTrxHistory(v)
+
+		// Format expired at
+		var expiredAt string
+		if v.ExpiredAt.Valid {
+			expiredAt = v.ExpiredAt.Time.UTC().Format(time.RFC3339)
+		}
+
+		err = w.Write([]string{
+			item.Id,
+			v.CreatedAt.UTC().Format(time.RFC3339),
+			trxEntryTypeLabel(item.EntryTypeId),
+			trxStatusLabel(item.StatusId),
+			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+			strconv.FormatFloat(item.Balance, 'f', 2, 64),
+			strconv.FormatFloat(item.BalancePending, 'f', 2, 64),
+			csvSafe(item.Description),
+			item.ChallengeId,
+			item.DonationId,
+			expiredAt,
+		})
+		if err != nil {
+			s.Logger.Error("unable to write csv row", err)
+			return nil, err
+		}
+	}
+
+	// Flush writer
+	w.Flush()
+	err = w.Error()
+	if err != nil {
+		s.Logger.Error("unable to flush csv writer", err)
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 func (s *CreditService) InsertPendingTrx(opt dto.CreditTrxOpt) (string, error) {
 	// If amount is less
 	if opt.Amount <= 0 {
@@ -99,7 +267,7 @@ func (s *CreditService) InsertPendingTrx(opt dto.CreditTrxOpt) (string, error) {
 	}
 
 	// Get wallet
-	wallet, err := s.Repository.FindWalletById(opt.WalletId)
+	wallet, err := s.Repository.FindWalletById(opt.Tx, opt.WalletId)
 	if err != nil {
 		s.Logger.Error("unable to retrieve wallet by id", err)
 		return "", err
@@ -157,7 +325,7 @@ func (s *CreditService) InsertPendingTrx(opt dto.CreditTrxOpt) (string, error) {
 	wallet.CurrentVersion = currentVersion
 
 	// Insert trx
-	err = s.Repository.InsertTrx(wallet, &pendingTrx)
+	err = s.Repository.InsertTrx(opt.Tx, wallet, &pendingTrx)
 	if err != nil {
 		s.Logger.Error("unable to persist wallet insert", err)
 		return "", err
@@ -167,8 +335,13 @@ func (s *CreditService) InsertPendingTrx(opt dto.CreditTrxOpt) (string, error) {
 }
 
 func (s *CreditService) GetUserWallet(userId string) (*model.UserCreditWallet, error) {
+	return s.getUserWallet(nil, userId)
+}
+
+// getUserWallet retrieve user wallet, or create a new one if user does not have any
+func (s *CreditService) getUserWallet(tx *sqlx.Tx, userId string) (*model.UserCreditWallet, error) {
 	// Check if user has a wallet
-	wallet, err := s.Repository.FindWalletByUser(userId)
+	wallet, err := s.Repository.FindWalletByUser(tx, userId)
 	if err != nil && err != sql.ErrNoRows {
 		s.Logger.Error("unable to check user wallet existence", err)
 		return nil, err
@@ -206,7 +379,7 @@ func (s *CreditService) GetUserWallet(userId string) (*model.UserCreditWallet, e
 	}
 
 	// Persist wallet
-	err = s.Repository.InsertWallet(wallet, &trx)
+	err = s.Repository.InsertWallet(tx, wallet, &trx)
 	if err != nil {
 		s.Logger.Error("unable to persist wallet insert", err)
 		return nil, err
@@ -222,7 +395,7 @@ func (s *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 	}
 
 	// Get pending transaction
-	pendingTrx, err := s.Repository.FindTrxById(opt.TrxId)
+	pendingTrx, err := s.Repository.FindTrxById(opt.Tx, opt.TrxId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			err = s.Error.New("CRD001")
@@ -234,12 +407,17 @@ func (s *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 	}
 
 	// Check status
-	if pendingTrx.Status != api.TrxPending {
+	switch pendingTrx.Status {
+	case api.TrxPending:
+		break
+	case api.TrxExpired:
+		return s.Error.New("CRD009")
+	default:
 		return s.Error.New("CRD002")
 	}
 
 	// Check referenced pending transaction
-	isExist, err := s.Repository.IsExistTrxRef(pendingTrx.UserCreditWalletId, opt.TrxId)
+	isExist, err := s.Repository.IsExistTrxRef(opt.Tx, pendingTrx.UserCreditWalletId, opt.TrxId)
 	if err != nil {
 		s.Logger.Error("unable to check referenced transaction existence", err)
 		return err
@@ -250,13 +428,18 @@ func (s *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 
 	// Check expired time
 	if pendingTrx.ExpiredAt.Valid {
-		now := time.Now().Unix()
-		exp := pendingTrx.ExpiredAt.Time.Unix()
-		if now > exp {
+		now := time.Now()
+		if now.After(pendingTrx.ExpiredAt.Time) {
 			s.Logger.Errorf("Pending transaction has been expired")
+
+			// Release pending balance
+			err = s.expirePendingTrx(pendingTrx, now)
+			if err != nil {
+				s.Logger.Error("unable to expire pending transaction", err)
+			}
+
 			return s.Error.New("CRD009")
 		}
-		// TODO: Settle pending failed pending transaction
 	}
 
 	// Check transaction type
@@ -266,7 +449,7 @@ func (s *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 	}
 
 	// Get wallet by transaction id
-	wallet, err := s.Repository.FindWalletByTrx(pendingTrx.Id)
+	wallet, err := s.Repository.FindWalletByTrx(opt.Tx, pendingTrx.Id)
 	if err != nil {
 		s.Logger.Error("unable to retrieve wallet", err)
 		return err
@@ -305,6 +488,15 @@ func (s *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 		timestamp = *opt.Timestamp
 	}
 
+	// Settled charge records expiry of credits it consumes, so expiry is restored if charge is refunded
+	expiredAt := newNullTime(opt.ExpiredAt)
+	if pendingTrx.TrxEntryTypeId == api.Credit {
+		expiredAt, _, err = s.calcConsumedExpiry(opt.Tx, wallet.Id, wallet.Balance, pendingTrx.Amount, timestamp)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create Transaction
 	newTrx := model.UserCreditWalletTrx{
 		Id:                 s.IdGen.New(),
@@ -317,19 +509,31 @@ func (s *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 		Notes:              sql.NullString{Valid: opt.Notes != "", String: opt.Notes},
 		Status:             api.TrxSuccess,
 		CreatedAt:          timestamp,
-		ExpiredAt:          newNullTime(opt.ExpiredAt),
+		ExpiredAt:          expiredAt,
 		Version:            version,
 	}
 
+	// Calculate expiring balance, include settled transaction if it adds a new credit lot
+	newLots := make([]model.UserCreditWalletTrx, 0, 1)
+	if newTrx.TrxEntryTypeId == api.Debit {
+		newLots = append(newLots, newTrx)
+	}
+	expiry, err := s.calcExpiringBalance(opt.Tx, wallet.Id, balance, timestamp, newLots...)
+	if err != nil {
+		return err
+	}
+
 	// Update wallet
 	wallet.Balance = balance
 	wallet.BalancePending = pendingBalance
+	wallet.BalanceExpiring = expiry.Amount
+	wallet.BalanceExpiringDate = expiry.Date
 	wallet.UpdatedAt = timestamp
 	wallet.Version = version
 	wallet.CurrentVersion = currentVersion
 
 	// Persist updates
-	err = s.Repository.InsertTrx(wallet, &newTrx)
+	err = s.Repository.InsertTrx(opt.Tx, wallet, &newTrx)
 	if err != nil {
 		s.Logger.Error("unable to persist transaction insert", err)
 		return err
@@ -338,13 +542,1081 @@ func (s *CreditService) SettlePendingTrx(opt dto.CreditSettleOpt) error {
 	return nil
 }
 
-func newNullTime(t *time.Time) pq.NullTime {
-	if t == nil {
-		return pq.NullTime{}
+// RefundTrx reverses a charge transaction. If charge has been settled, charged amount is returned to wallet balance.
+// Otherwise, pending charge is cancelled and its amount is released from pending balance
+func (s *CreditService) RefundTrx(opt dto.CreditRefundOpt) (*model.UserCreditWalletTrx, error) {
+	// Validate trx id
+	if opt.TrxId == "" {
+		return nil, errors.New("TrxId is required")
 	}
 
-	return pq.NullTime{
-		Valid: true,
-		Time:  *t,
+	// Get charge transaction
+	chargeTrx, err := s.Repository.FindTrxById(opt.Tx, opt.TrxId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Error.New("CRD001")
+		}
+
+		s.Logger.Error("unable to retrieve credit transaction", err)
+		return nil, err
+	}
+
+	// Only charge can be refunded
+	if chargeTrx.TrxEntryTypeId != api.Credit {
+		return nil, s.Error.New("CRD003")
+	}
+
+	// Create timestamp
+	var timestamp time.Time
+	if opt.Timestamp == nil {
+		timestamp = time.Now()
+	} else {
+		timestamp = *opt.Timestamp
+	}
+
+	// Set notes
+	notes := opt.Notes
+	if notes == "" {
+		notes = "Refund"
+	}
+
+	// Find settlement and previous refund of charge
+	refs, err := s.Repository.FindTrxByRef(opt.Tx, chargeTrx.UserCreditWalletId, chargeTrx.Id)
+	if err != nil {
+		s.Logger.Error("unable to retrieve referenced transactions", err)
+		return nil, err
+	}
+
+	var settledTrx *model.UserCreditWalletTrx
+	for k := range refs {
+		switch {
+		case refs[k].TrxEntryTypeId == api.Refund:
+			return nil, s.Error.New("CRD018")
+		case refs[k].Status == api.TrxSuccess:
+			settledTrx = &refs[k]
+		}
+	}
+
+	// If charge has not been settled, cancel pending charge
+	if settledTrx == nil {
+		switch chargeTrx.Status {
+		case api.TrxPending:
+			return s.releasePendingTrx(opt.Tx, chargeTrx, api.TrxFailed, notes, timestamp)
+		case api.TrxExpired:
+			return nil, s.Error.New("CRD009")
+		default:
+			return nil, s.Error.New("CRD018")
+		}
+	}
+
+	// Get wallet
+	wallet, err := s.Repository.FindWalletById(opt.Tx, chargeTrx.UserCreditWalletId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve wallet by id", err)
+		return nil, err
+	}
+
+	// Return charged amount to balance
+	balance := roundCredit(wallet.Balance + settledTrx.Amount)
+
+	// Refunded credit expires with the credits consumed by charge, but not earlier than grace period
+	expiredAt := settledTrx.ExpiredAt
+	if minExpiredAt := timestamp.Add(creditRefundGracePeriod); expiredAt.Valid && expiredAt.Time.Before(minExpiredAt) {
+		expiredAt.Time = minExpiredAt
+	}
+
+	// Create refund transaction that refers to charge
+	refundTrx := model.UserCreditWalletTrx{
+		Id:                 s.IdGen.New(),
+		UserCreditWalletId: wallet.Id,
+		Balance:            balance,
+		BalancePending:     wallet.BalancePending,
+		Amount:             settledTrx.Amount,
+		TrxEntryTypeId:     api.Refund,
+		TrxRefId:           sql.NullString{Valid: true, String: chargeTrx.Id},
+		Notes:              sql.NullString{Valid: true, String: notes},
+		Status:             api.TrxSuccess,
+		CreatedAt:          timestamp,
+		ExpiredAt:          expiredAt,
+		Version:            wallet.Version + 1,
+	}
+
+	// Calculate expiring balance, include refund as a new credit lot
+	expiry, err := s.calcExpiringBalance(opt.Tx, wallet.Id, balance, timestamp, refundTrx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update wallet
+	wallet.Balance = balance
+	wallet.BalanceExpiring = expiry.Amount
+	wallet.BalanceExpiringDate = expiry.Date
+	wallet.UpdatedAt = timestamp
+	wallet.CurrentVersion = wallet.Version
+	wallet.Version = refundTrx.Version
+
+	// Persist transaction
+	err = s.Repository.InsertTrx(opt.Tx, wallet, &refundTrx)
+	if err != nil {
+		s.Logger.Error("unable to persist refund transaction", err)
+		return nil, err
+	}
+
+	return &refundTrx, nil
+}
+
+func (s *CreditService) Transfer(opt dto.CreditTransferReq) (*dto.CreditTransferResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Round amount to credit precision
+	amount := roundCredit(opt.Amount)
+	if amount <= 0 {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get recipient
+	recipient, err := s.Repository.FindActiveUserByEmail(opt.RecipientEmail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Error.New("CRD011")
+		}
+		s.Logger.Error("unable to retrieve recipient by email", err)
+		return nil, err
+	}
+
+	// Check if recipient is sender
+	if recipient.Id == opt.UserId {
+		return nil, s.Error.New("CRD012")
+	}
+
+	// Get sender wallet
+	sender, err := s.GetUserWallet(opt.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check against balance
+	if sender.Balance < amount {
+		return nil, s.Error.New("CRD010")
+	}
+
+	// Create timestamp
+	timestamp := time.Now()
+
+	// Check daily transfer limit
+	err = s.checkTransferLimit(sender.Id, amount, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine expiry of transferred credit
+	expiredAt, err := s.calcTransferExpiry(sender.Id, sender.Balance, amount, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get recipient wallet
+	recipientWallet, err := s.GetUserWallet(recipient.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create transactions that refer to each other
+	senderTrxId := s.IdGen.New()
+	recipientTrxId := s.IdGen.New()
+	notes := sql.NullString{Valid: opt.Message != "", String: opt.Message}
+
+	senderBalance := roundCredit(sender.Balance - amount)
+	senderTrx := model.UserCreditWalletTrx{
+		Id:                 senderTrxId,
+		UserCreditWalletId: sender.Id,
+		Balance:            senderBalance,
+		BalancePending:     sender.BalancePending,
+		Amount:             amount,
+		TrxEntryTypeId:     api.TransferOut,
+		TrxRefId:           sql.NullString{Valid: true, String: recipientTrxId},
+		Notes:              notes,
+		Status:             api.TrxSuccess,
+		CreatedAt:          timestamp,
+		Version:            sender.Version + 1,
+	}
+
+	recipientBalance := roundCredit(recipientWallet.Balance + amount)
+	recipientTrx := model.UserCreditWalletTrx{
+		Id:                 recipientTrxId,
+		UserCreditWalletId: recipientWallet.Id,
+		Balance:            recipientBalance,
+		BalancePending:     recipientWallet.BalancePending,
+		Amount:             amount,
+		TrxEntryTypeId:     api.TransferIn,
+		TrxRefId:           sql.NullString{Valid: true, String: senderTrxId},
+		Notes:              notes,
+		Status:             api.TrxSuccess,
+		CreatedAt:          timestamp,
+		ExpiredAt:          expiredAt,
+		Version:            recipientWallet.Version + 1,
+	}
+
+	// Calculate expiring balance for both wallets
+	senderExpiry, err := s.calcExpiringBalance(nil, sender.Id, senderBalance, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	recipientExpiry, err := s.calcExpiringBalance(nil, recipientWallet.Id, recipientBalance, timestamp, recipientTrx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update sender wallet
+	sender.Balance = senderBalance
+	sender.BalanceExpiring = senderExpiry.Amount
+	sender.BalanceExpiringDate = senderExpiry.Date
+	sender.UpdatedAt = timestamp
+	sender.CurrentVersion = sender.Version
+	sender.Version = senderTrx.Version
+
+	// Update recipient wallet
+	recipientWallet.Balance = recipientBalance
+	recipientWallet.BalanceExpiring = recipientExpiry.Amount
+	recipientWallet.BalanceExpiringDate = recipientExpiry.Date
+	recipientWallet.UpdatedAt = timestamp
+	recipientWallet.CurrentVersion = recipientWallet.Version
+	recipientWallet.Version = recipientTrx.Version
+
+	// Persist updates
+	err = s.Repository.Transfer(sender, recipientWallet, &senderTrx, &recipientTrx)
+	if err != nil {
+		s.Logger.Error("unable to persist credit transfer", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := dto.CreditTransferResp{
+		Id:            senderTrx.Id,
+		RecipientName: recipient.FullName,
+		Amount:        amount,
+		Balance:       sender.Balance,
+	}
+
+	return &resp, nil
+}
+
+func (s *CreditService) RequestAdjustment(opt dto.CreditAdjustmentReq) (*dto.CreditAdjustmentResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Validate reason code
+	if !isAdjustmentReasonCode(opt.ReasonCode) {
+		s.Logger.Debugf("unknown adjustment reason code. ReasonCode = %s", opt.ReasonCode)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Round amount to credit precision
+	amount := roundCredit(opt.Amount)
+	if amount <= 0 {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set expiry time for granted credit
+	var expiredAt pq.NullTime
+	if opt.EntryTypeId == api.Debit && opt.ExpiredAt > 0 {
+		expiredAt = pq.NullTime{Time: time.Unix(opt.ExpiredAt, 0), Valid: true}
+	}
+
+	// Create modifier meta
+	requestedBy := &model.ModifierMeta{
+		Id:       opt.RequestedBy.Id,
+		Role:     api.ModifierAdmin,
+		FullName: opt.RequestedBy.FullName,
+	}
+
+	// Create adjustment
+	timestamp := time.Now()
+	adjustment := model.CreditAdjustment{
+		Id:             s.IdGen.New(),
+		UserId:         opt.UserId,
+		TrxEntryTypeId: opt.EntryTypeId,
+		Amount:         amount,
+		ReasonCode:     opt.ReasonCode,
+		Notes:          nsql.NullString(opt.Notes),
+		ExpiredAt:      expiredAt,
+		StatusId:       api.AdjustmentPendingApproval,
+		RequestedBy:    requestedBy,
+		CreatedAt:      timestamp,
+		UpdatedAt:      timestamp,
+		ModifiedBy:     requestedBy,
+		Version:        1,
+	}
+
+	err = nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		// If amount does not exceed approval threshold, apply adjustment immediately
+		if amount <= s.AdjustmentThreshold {
+			err := s.applyAdjustment(tx, &adjustment, timestamp)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Persist adjustment
+		return s.Repository.InsertAdjustment(tx, &adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := composeAdjustment(adjustment)
+	return &resp, nil
+}
+
+func (s *CreditService) ReviewAdjustment(opt dto.CreditAdjustmentReviewReq) (*dto.CreditAdjustmentResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get adjustment
+	adjustment, err := s.Repository.FindAdjustmentById(nil, opt.Id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Error.New("CRD014")
+		}
+		s.Logger.Error("unable to retrieve credit adjustment", err)
+		return nil, err
+	}
+
+	// Check status
+	if adjustment.StatusId != api.AdjustmentPendingApproval {
+		return nil, s.Error.New("CRD015")
+	}
+
+	// Adjustment must be reviewed by other admin than requester
+	if adjustment.RequestedBy != nil && adjustment.RequestedBy.Id == opt.ReviewedBy.Id {
+		return nil, s.Error.New("CRD016")
+	}
+
+	// Create modifier meta
+	reviewedBy := &model.ModifierMeta{
+		Id:       opt.ReviewedBy.Id,
+		Role:     api.ModifierAdmin,
+		FullName: opt.ReviewedBy.FullName,
+	}
+
+	// Update adjustment
+	timestamp := time.Now()
+	adjustment.ReviewedBy = reviewedBy
+	adjustment.ReviewNotes = nsql.NullString(opt.Notes)
+	adjustment.UpdatedAt = timestamp
+	adjustment.ModifiedBy = reviewedBy
+	adjustment.CurrentVersion = adjustment.Version
+	adjustment.Version++
+
+	err = nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		// Apply or reject adjustment
+		if opt.Approved {
+			err := s.applyAdjustment(tx, adjustment, timestamp)
+			if err != nil {
+				return err
+			}
+		} else {
+			adjustment.StatusId = api.AdjustmentRejected
+		}
+
+		// Persist adjustment
+		return s.Repository.UpdateAdjustment(tx, adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := composeAdjustment(*adjustment)
+	return &resp, nil
+}
+
+func (s *CreditService) ListAdjustments(opt dto.CreditAdjustmentListReq) ([]dto.CreditAdjustmentResp, error) {
+	// Get adjustments
+	rows, err := s.Repository.FindAdjustments(opt.StatusId, opt.Skip, opt.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve credit adjustments", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.CreditAdjustmentResp, len(rows))
+	for k, v := range rows {
+		resp[k] = composeAdjustment(v)
+	}
+
+	return resp, nil
+}
+
+func (s *CreditService) ExpireCredits(opt dto.CreditExpireOpt) (*dto.CreditExpireResp, error) {
+	// Create timestamp
+	var timestamp time.Time
+	if opt.Timestamp == nil {
+		timestamp = time.Now()
+	} else {
+		timestamp = *opt.Timestamp
+	}
+
+	var resp dto.CreditExpireResp
+
+	// Release expired pending transactions
+	cursor := "0"
+	for {
+		rows, err := s.Repository.FindExpiredPendingTrx(timestamp, cursor, creditExpiryBatchSize)
+		if err != nil {
+			s.Logger.Error("unable to retrieve expired pending transactions", err)
+			return nil, err
+		}
+
+		for k := range rows {
+			trx := &rows[k]
+			cursor = trx.Id
+
+			err = s.expirePendingTrx(trx, timestamp)
+			if err != nil {
+				s.Logger.Errorf("unable to expire pending transaction. TrxId = %s, Error = %s", trx.Id, err)
+				continue
+			}
+			resp.ExpiredPendingTrx++
+		}
+
+		if len(rows) < creditExpiryBatchSize {
+			break
+		}
+	}
+
+	// Expire settled credits that has passed its expiry date
+	cursor = "0"
+	for {
+		rows, err := s.Repository.FindExpiringWallets(timestamp, cursor, creditExpiryBatchSize)
+		if err != nil {
+			s.Logger.Error("unable to retrieve expiring wallets", err)
+			return nil, err
+		}
+
+		for k := range rows {
+			wallet := &rows[k]
+			cursor = wallet.Id
+
+			amount, err := s.expireWalletBalance(wallet, timestamp)
+			if err != nil {
+				s.Logger.Errorf("unable to expire wallet balance. WalletId = %s, Error = %s", wallet.Id, err)
+				continue
+			}
+
+			if amount > 0 {
+				resp.ExpiredWallet++
+				resp.ExpiredAmount += amount
+			}
+		}
+
+		if len(rows) < creditExpiryBatchSize {
+			break
+		}
+	}
+
+	s.Logger.Debugf("Credit expiry done. ExpiredPendingTrx = %d, ExpiredWallet = %d, ExpiredAmount = %f",
+		resp.ExpiredPendingTrx, resp.ExpiredWallet, resp.ExpiredAmount)
+
+	return &resp, nil
+}
+
+func (s *CreditService) runExpiryScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.ExpireCredits(dto.CreditExpireOpt{})
+		if err != nil {
+			s.Logger.Error("failed to run credit expiry", err)
+		}
+	}
+}
+
+// expirePendingTrx marks pending transaction as expired and release its amount from pending balance
+func (s *CreditService) expirePendingTrx(pendingTrx *model.UserCreditWalletTrx, timestamp time.Time) error {
+	_, err := s.releasePendingTrx(nil, pendingTrx, api.TrxExpired, "Pending transaction expired", timestamp)
+	return err
+}
+
+// releasePendingTrx updates pending transaction status and release its amount from pending balance.
+// It returns the transaction that records the release
+func (s *CreditService) releasePendingTrx(tx *sqlx.Tx, pendingTrx *model.UserCreditWalletTrx, statusId int8, notes string,
+	timestamp time.Time) (*model.UserCreditWalletTrx, error) {
+	// Get wallet
+	wallet, err := s.Repository.FindWalletById(tx, pendingTrx.UserCreditWalletId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve wallet by id", err)
+		return nil, err
+	}
+
+	// Check pending balance with pending transaction amount
+	if wallet.BalancePending < pendingTrx.Amount {
+		return nil, s.Error.New("CRD005")
+	}
+
+	// Update pending balance
+	pendingBalance := wallet.BalancePending - pendingTrx.Amount
+
+	// Update version
+	currentVersion := wallet.Version
+	version := wallet.Version + 1
+
+	// Create release transaction that refers to pending transaction
+	releaseTrx := model.UserCreditWalletTrx{
+		Id:                 s.IdGen.New(),
+		UserCreditWalletId: wallet.Id,
+		Balance:            wallet.Balance,
+		BalancePending:     pendingBalance,
+		Amount:             pendingTrx.Amount,
+		TrxEntryTypeId:     pendingTrx.TrxEntryTypeId,
+		TrxRefId:           sql.NullString{Valid: true, String: pendingTrx.Id},
+		Notes:              sql.NullString{Valid: true, String: notes},
+		Status:             statusId,
+		CreatedAt:          timestamp,
+		Version:            version,
+	}
+
+	// Update pending transaction
+	pendingTrx.Status = statusId
+
+	// Update wallet
+	wallet.BalancePending = pendingBalance
+	wallet.UpdatedAt = timestamp
+	wallet.Version = version
+	wallet.CurrentVersion = currentVersion
+
+	// Persist updates
+	err = s.Repository.ReleasePendingTrx(tx, wallet, pendingTrx, &releaseTrx)
+	if err != nil {
+		s.Logger.Error("unable to persist released pending transaction", err)
+		return nil, err
+	}
+
+	return &releaseTrx, nil
+}
+
+// expireWalletBalance deducts balance that has been passed its expiry date and refresh wallet expiring balance.
+// It returns the expired amount
+func (s *CreditService) expireWalletBalance(wallet *model.UserCreditWallet, timestamp time.Time) (float64, error) {
+	// Calculate expiring balance
+	expiry, err := s.calcExpiringBalance(nil, wallet.Id, wallet.Balance, timestamp)
+	if err != nil {
+		return 0, err
+	}
+
+	// If there are no changes, return
+	if expiry.Expired == 0 &&
+		expiry.Amount == wallet.BalanceExpiring &&
+		expiry.Date.Valid == wallet.BalanceExpiringDate.Valid &&
+		expiry.Date.Time.Equal(wallet.BalanceExpiringDate.Time) {
+		return 0, nil
+	}
+
+	// Update version
+	currentVersion := wallet.Version
+	version := wallet.Version + 1
+
+	// Update wallet
+	wallet.Balance = roundCredit(wallet.Balance - expiry.Expired)
+	wallet.BalanceExpiring = expiry.Amount
+	wallet.BalanceExpiringDate = expiry.Date
+	wallet.UpdatedAt = timestamp
+	wallet.Version = version
+	wallet.CurrentVersion = currentVersion
+
+	// If nothing expired, only refresh expiring balance
+	if expiry.Expired == 0 {
+		err = s.Repository.UpdateWallet(wallet)
+		if err != nil {
+			s.Logger.Error("unable to persist wallet update", err)
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	// Create expired transaction
+	expiredTrx := model.UserCreditWalletTrx{
+		Id:                 s.IdGen.New(),
+		UserCreditWalletId: wallet.Id,
+		Balance:            wallet.Balance,
+		BalancePending:     wallet.BalancePending,
+		Amount:             expiry.Expired,
+		TrxEntryTypeId:     api.Credit,
+		TrxRefId:           sql.NullString{Valid: false},
+		Notes:              sql.NullString{Valid: true, String: "Credit expired"},
+		Status:             api.TrxExpired,
+		CreatedAt:          timestamp,
+		Version:            version,
+	}
+
+	// Persist updates
+	err = s.Repository.InsertTrx(nil, wallet, &expiredTrx)
+	if err != nil {
+		s.Logger.Error("unable to persist expired transaction", err)
+		return 0, err
+	}
+
+	return expiry.Expired, nil
+}
+
+// creditExpiry represents result of expiring balance calculation
+type creditExpiry struct {
+	// Expired is the amount of balance that has been passed its expiry date
+	Expired float64
+	// Amount is the amount of balance that will be expired on Date
+	Amount float64
+	// Date is the nearest expiry date of remaining balance
+	Date pq.NullTime
+}
+
+// calcExpiringBalance allocates balance to settled credits in First-In-First-Out manner, so that the remaining
+// balance is held by credits that expire last. Any balance left after allocated to active credits is expired
+func (s *CreditService) calcExpiringBalance(tx *sqlx.Tx, walletId string, balance float64, timestamp time.Time,
+	newLots ...model.UserCreditWalletTrx) (*creditExpiry, error) {
+	// Get credits that never expire
+	nonExpiring, err := s.Repository.SumNonExpiringCredit(tx, walletId)
+	if err != nil {
+		s.Logger.Error("unable to sum non expiring credit", err)
+		return nil, err
+	}
+
+	// Get active credits, ordered by latest expiry date
+	lots, err := s.Repository.FindActiveCreditLots(tx, walletId, timestamp)
+	if err != nil {
+		s.Logger.Error("unable to retrieve active credit lots", err)
+		return nil, err
+	}
+
+	return allocateExpiringBalance(balance, nonExpiring, lots, timestamp, newLots...), nil
+}
+
+// allocateExpiringBalance allocates balance to credits that never expire, then to active credits ordered by latest
+// expiry date. New credits that have not been persisted are merged to lots
+func allocateExpiringBalance(balance, nonExpiring float64, lots []model.UserCreditWalletTrx, timestamp time.Time,
+	newLots ...model.UserCreditWalletTrx) *creditExpiry {
+	// Merge new credits that has not been persisted
+	for _, v := range newLots {
+		if !v.ExpiredAt.Valid {
+			nonExpiring += v.Amount
+			continue
+		}
+
+		if !v.ExpiredAt.Time.After(timestamp) {
+			continue
+		}
+
+		// Insert by latest expiry date
+		i := 0
+		for i < len(lots) && lots[i].ExpiredAt.Time.After(v.ExpiredAt.Time) {
+			i++
+		}
+		lots = append(lots, model.UserCreditWalletTrx{})
+		copy(lots[i+1:], lots[i:])
+		lots[i] = v
+	}
+
+	// Allocate balance to credits that never expire
+	remaining := roundCredit(balance - nonExpiring)
+
+	// Allocate balance to active credits
+	var result creditExpiry
+	var nearest time.Time
+	for _, v := range lots {
+		if remaining <= 0 {
+			break
+		}
+
+		// Get held balance
+		held := math.Min(v.Amount, remaining)
+		remaining = roundCredit(remaining - held)
+
+		// Set nearest expiry date, amount that expire in the same day is accumulated
+		expiryDate := v.ExpiredAt.Time.Truncate(24 * time.Hour)
+		if !expiryDate.Equal(nearest) {
+			nearest = expiryDate
+			result.Amount = 0
+		}
+		result.Amount = roundCredit(result.Amount + held)
+		result.Date = v.ExpiredAt
+	}
+
+	// Set expired balance
+	if remaining > 0 {
+		result.Expired = remaining
+	}
+
+	return &result
+}
+
+// csvSafe prefixes value that starts with a formula character, so user input is not evaluated as formula when the
+// exported csv is opened by spreadsheet application
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// calcTransferExpiry determines expiry of credit that is transferred out of wallet. Transferred credit expires on the
+// latest expiry date of consumed credits, or never expires if it consumes credit that never expires. Returns CRD010 if
+// amount consumes balance that has passed its expiry date
+func (s *CreditService) calcTransferExpiry(walletId string, balance, amount float64, timestamp time.Time) (pq.NullTime,
+	error) {
+	expiredAt, ok, err := s.calcConsumedExpiry(nil, walletId, balance, amount, timestamp)
+	if err != nil {
+		return pq.NullTime{}, err
+	}
+
+	if !ok {
+		return pq.NullTime{}, s.Error.New("CRD010")
+	}
+
+	return expiredAt, nil
+}
+
+// calcConsumedExpiry determines expiry of credits that are consumed when amount is spent from wallet
+func (s *CreditService) calcConsumedExpiry(tx *sqlx.Tx, walletId string, balance, amount float64,
+	timestamp time.Time) (pq.NullTime, bool, error) {
+	// Get credits that never expire
+	nonExpiring, err := s.Repository.SumNonExpiringCredit(tx, walletId)
+	if err != nil {
+		s.Logger.Error("unable to sum non expiring credit", err)
+		return pq.NullTime{}, false, err
+	}
+
+	// Get active credits, ordered by latest expiry date
+	lots, err := s.Repository.FindActiveCreditLots(tx, walletId, timestamp)
+	if err != nil {
+		s.Logger.Error("unable to retrieve active credit lots", err)
+		return pq.NullTime{}, false, err
+	}
+
+	expiredAt, ok := consumeCreditLots(balance, nonExpiring, lots, amount)
+	return expiredAt, ok, nil
+}
+
+// consumeCreditLots allocates balance to credits that never expire, then to active credits ordered by latest expiry
+// date. Balance is spent in First-In-First-Out manner, so amount consumes held credits that expire first. It returns
+// the latest expiry date of consumed credits, or null if amount consumes credit that never expires. Returns false if
+// amount consumes balance that is not held by any active credit, since it has passed its expiry date
+func consumeCreditLots(balance, nonExpiring float64, lots []model.UserCreditWalletTrx, amount float64) (pq.NullTime,
+	bool) {
+	// Allocate balance to credits that never expire, then to active credits
+	remaining := roundCredit(balance - nonExpiring)
+	held := make([]float64, 0, len(lots))
+	for _, v := range lots {
+		if remaining <= 0 {
+			break
+		}
+
+		h := math.Min(v.Amount, remaining)
+		remaining = roundCredit(remaining - h)
+		held = append(held, h)
+	}
+
+	// Balance that is not held by any active credit has passed its expiry date
+	ok := remaining <= 0 || roundCredit(balance-remaining) >= amount
+
+	// Consume held credits from the one that expires first
+	var expiredAt pq.NullTime
+	for i := len(held) - 1; i >= 0 && amount > 0; i-- {
+		amount = roundCredit(amount - held[i])
+		expiredAt = lots[i].ExpiredAt
+	}
+
+	// If held credits are not enough, the rest is taken from credits that never expire
+	if amount > 0 {
+		return pq.NullTime{}, ok
+	}
+
+	return expiredAt, ok
+}
+
+func roundCredit(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func newNullTime(t *time.Time) pq.NullTime {
+	if t == nil {
+		return pq.NullTime{}
+	}
+
+	return pq.NullTime{
+		Valid: true,
+		Time:  *t,
+	}
+}
+
+// applyAdjustment moves credit on user wallet based on adjustment and mark adjustment as applied
+func (s *CreditService) applyAdjustment(tx *sqlx.Tx, adjustment *model.CreditAdjustment, timestamp time.Time) error {
+	// Get user wallet
+	wallet, err := s.getUserWallet(tx, adjustment.UserId)
+	if err != nil {
+		return err
+	}
+
+	// Update balance
+	balance := wallet.Balance
+	switch adjustment.TrxEntryTypeId {
+	case api.Debit:
+		balance += adjustment.Amount
+	case api.Credit:
+		if balance < adjustment.Amount {
+			return s.Error.New("CRD010")
+		}
+		balance -= adjustment.Amount
+	default:
+		return s.Error.New("CRD003")
+	}
+	balance = roundCredit(balance)
+
+	// Create transaction that refers to adjustment
+	newTrx := model.UserCreditWalletTrx{
+		Id:                 s.IdGen.New(),
+		UserCreditWalletId: wallet.Id,
+		Balance:            balance,
+		BalancePending:     wallet.BalancePending,
+		Amount:             adjustment.Amount,
+		TrxEntryTypeId:     adjustment.TrxEntryTypeId,
+		TrxRefId:           sql.NullString{Valid: true, String: adjustment.Id},
+		Notes:              sql.NullString{Valid: true, String: fmt.Sprintf("Credit adjustment (%s)", adjustment.ReasonCode)},
+		Status:             api.TrxSuccess,
+		CreatedAt:          timestamp,
+		ExpiredAt:          adjustment.ExpiredAt,
+		Version:            wallet.Version + 1,
+	}
+
+	// Calculate expiring balance, include granted credit as a new credit lot
+	newLots := make([]model.UserCreditWalletTrx, 0, 1)
+	if newTrx.TrxEntryTypeId == api.Debit {
+		newLots = append(newLots, newTrx)
+	}
+	expiry, err := s.calcExpiringBalance(tx, wallet.Id, balance, timestamp, newLots...)
+	if err != nil {
+		return err
+	}
+
+	// Update wallet
+	wallet.Balance = balance
+	wallet.BalanceExpiring = expiry.Amount
+	wallet.BalanceExpiringDate = expiry.Date
+	wallet.UpdatedAt = timestamp
+	wallet.CurrentVersion = wallet.Version
+	wallet.Version = newTrx.Version
+
+	// Persist transaction
+	err = s.Repository.InsertTrx(tx, wallet, &newTrx)
+	if err != nil {
+		s.Logger.Error("unable to persist adjustment transaction", err)
+		return err
+	}
+
+	// Mark adjustment as applied
+	adjustment.StatusId = api.AdjustmentApplied
+	adjustment.TrxRefId = sql.NullString{Valid: true, String: newTrx.Id}
+
+	return nil
+}
+
+// checkTransferLimit checks amount and number of transfers made by wallet since start of the day
+func (s *CreditService) checkTransferLimit(walletId string, amount float64, timestamp time.Time) error {
+	// If limit is not set, skip
+	if s.TransferDailyLimit <= 0 && s.TransferDailyCount <= 0 {
+		return nil
+	}
+
+	// Get today transfer summary
+	y, m, d := timestamp.UTC().Date()
+	summary, err := s.Repository.SumTransferOut(walletId, time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		s.Logger.Error("unable to sum transfer out", err)
+		return err
+	}
+
+	// Check amount limit
+	if s.TransferDailyLimit > 0 && roundCredit(summary.Amount+amount) > s.TransferDailyLimit {
+		return s.Error.New("CRD013")
+	}
+
+	// Check count limit
+	if s.TransferDailyCount > 0 && summary.Count+1 > s.TransferDailyCount {
+		return s.Error.New("CRD013")
+	}
+
+	return nil
+}
+
+// newTrxHistoryFilter validate transaction history request and convert it to repository filter
+func (s *CreditService) newTrxHistoryFilter(opt dto.CreditTrxHistoryReq) (model.UserCreditWalletTrxFilter, error) {
+	// Validate date range
+	if opt.StartAt > 0 && opt.EndAt > 0 && opt.EndAt <= opt.StartAt {
+		s.Logger.Debugf("invalid transaction history date range. Start = %d, End = %d", opt.StartAt, opt.EndAt)
+		return model.UserCreditWalletTrxFilter{}, nhttp.ErrBadRequest
+	}
+
+	// Compose filter
+	filter := model.UserCreditWalletTrxFilter{
+		UserId:      opt.UserId,
+		StatusId:    opt.StatusId,
+		EntryTypeId: opt.EntryTypeId,
+	}
+	if opt.StartAt > 0 {
+		filter.StartAt = pq.NullTime{Time: time.Unix(opt.StartAt, 0), Valid: true}
+	}
+	if opt.EndAt > 0 {
+		filter.EndAt = pq.NullTime{Time: time.Unix(opt.EndAt, 0), Valid: true}
+	}
+
+	return filter, nil
+}
+
+// composeTrxHistory convert transaction detail to response with a readable description
+func composeTrxHistory(row model.UserCreditWalletTrxDetail) dto.CreditTrxHistoryResp {
+	// Determine description from related challenge or donation
+	var description string
+	switch {
+	case row.ChallengeTitle.Valid:
+		description = fmt.Sprintf("Reward from challenge %s", row.ChallengeTitle.String)
+	case row.DonationId.Valid && row.TrxEntryTypeId == api.Refund:
+		description = fmt.Sprintf("Refund of donation to %s", row.InitiativeName.String)
+	case row.DonationId.Valid:
+		description = fmt.Sprintf("Donation to %s", row.InitiativeName.String)
+	case row.TrxEntryTypeId == api.TransferOut:
+		description = fmt.Sprintf("Gift to %s", row.CounterpartName.String)
+		if row.Notes.Valid && row.Notes.String != "" {
+			description += ": " + row.Notes.String
+		}
+	case row.TrxEntryTypeId == api.TransferIn:
+		description = fmt.Sprintf("Gift from %s", row.CounterpartName.String)
+		if row.Notes.Valid && row.Notes.String != "" {
+			description += ": " + row.Notes.String
+		}
+	case row.Notes.Valid && row.Notes.String != "":
+		description = row.Notes.String
+	default:
+		description = trxEntryTypeLabel(row.TrxEntryTypeId)
+	}
+
+	// Add status suffix for expired transaction
+	if row.Status == api.TrxExpired && (row.ChallengeTitle.Valid || row.DonationId.Valid) {
+		description += " (expired)"
+	}
+
+	// Add status suffix for cancelled donation charge
+	if row.Status == api.TrxFailed && row.DonationId.Valid {
+		description += " (cancelled)"
+	}
+
+	// Determine expire time
+	var expiredAt int64
+	if row.ExpiredAt.Valid {
+		expiredAt = row.ExpiredAt.Time.Unix()
+	}
+
+	return dto.CreditTrxHistoryResp{
+		Id:             row.Id,
+		Amount:         row.Amount,
+		Balance:        row.Balance,
+		BalancePending: row.BalancePending,
+		EntryTypeId:    row.TrxEntryTypeId,
+		StatusId:       row.Status,
+		Description:    description,
+		ChallengeId:    row.ChallengeId.String,
+		DonationId:     row.DonationId.String,
+		CreatedAt:      row.CreatedAt.Unix(),
+		ExpiredAt:      expiredAt,
+	}
+}
+
+func trxEntryTypeLabel(entryTypeId int8) string {
+	switch entryTypeId {
+	case api.InitEntryType:
+		return "Init"
+	case api.Debit:
+		return "Debit"
+	case api.Credit:
+		return "Credit"
+	case api.TransferIn:
+		return "Transfer In"
+	case api.TransferOut:
+		return "Transfer Out"
+	case api.Refund:
+		return "Refund"
+	default:
+		return "Unknown"
+	}
+}
+
+func trxStatusLabel(statusId int8) string {
+	switch statusId {
+	case api.TrxPending:
+		return "Pending"
+	case api.TrxSuccess:
+		return "Success"
+	case api.TrxFailed:
+		return "Failed"
+	case api.TrxExpired:
+		return "Expired"
+	default:
+		return "Unknown"
+	}
+}
+
+func isAdjustmentReasonCode(code string) bool {
+	for _, v := range api.AdjustmentReasonCodes {
+		if v == code {
+			return true
+		}
+	}
+	return false
+}
+
+func composeModifier(m *model.ModifierMeta) *dto.ModifierResp {
+	if m == nil {
+		return nil
+	}
+	return &dto.ModifierResp{
+		Id:       m.Id,
+		Role:     m.Role,
+		FullName: m.FullName,
+	}
+}
+
+func composeAdjustment(a model.CreditAdjustment) dto.CreditAdjustmentResp {
+	// Determine expire time
+	var expiredAt int64
+	if a.ExpiredAt.Valid {
+		expiredAt = a.ExpiredAt.Time.Unix()
+	}
+
+	return dto.CreditAdjustmentResp{
+		Id:          a.Id,
+		UserId:      a.UserId,
+		EntryTypeId: a.TrxEntryTypeId,
+		Amount:      a.Amount,
+		ReasonCode:  a.ReasonCode,
+		Notes:       a.Notes.String,
+		ExpiredAt:   expiredAt,
+		StatusId:    a.StatusId,
+		TrxId:       a.TrxRefId.String,
+		RequestedBy: composeModifier(a.RequestedBy),
+		ReviewedBy:  composeModifier(a.ReviewedBy),
+		ReviewNotes: a.ReviewNotes.String,
+		CreatedAt:   a.CreatedAt.Unix(),
+		UpdatedAt:   a.UpdatedAt.Unix(),
+		Version:     a.Version,
 	}
 }
@@ -6,27 +6,57 @@ import (
 )
 
 type creditStatements struct {
-	findTrxById         *sqlx.Stmt
-	findWalletById      *sqlx.Stmt
-	findWalletByTrx     *sqlx.Stmt
-	findWalletByUser    *sqlx.Stmt
-	insertTrx           *sqlx.NamedStmt
-	insertWallet        *sqlx.NamedStmt
-	isExistTrxRef       *sqlx.Stmt
-	isExistWalletByUser *sqlx.Stmt
-	updateWalletBalance *sqlx.NamedStmt
+	countTrxHistory       *sqlx.Stmt
+	findActiveCreditLots  *sqlx.Stmt
+	findExpiredPendingTrx *sqlx.Stmt
+	findExpiringWallets   *sqlx.Stmt
+	findActiveUserByEmail *sqlx.Stmt
+	findAdjustmentById    *sqlx.Stmt
+	findAdjustments       *sqlx.Stmt
+	findTrxById           *sqlx.Stmt
+	findTrxByRef          *sqlx.Stmt
+	findTrxHistory        *sqlx.Stmt
+	findWalletById        *sqlx.Stmt
+	findWalletByTrx       *sqlx.Stmt
+	findWalletByUser      *sqlx.Stmt
+	insertAdjustment      *sqlx.NamedStmt
+	insertTrx             *sqlx.NamedStmt
+	insertWallet          *sqlx.NamedStmt
+	isExistTrxRef         *sqlx.Stmt
+	isExistWalletByUser   *sqlx.Stmt
+	sumEarnedCredit       *sqlx.Stmt
+	sumNonExpiringCredit  *sqlx.Stmt
+	sumTransferOut        *sqlx.Stmt
+	updateAdjustment      *sqlx.NamedStmt
+	updateTrxStatus       *sqlx.NamedStmt
+	updateWalletBalance   *sqlx.NamedStmt
 }
 
 func initCreditStatement(db *nsql.SqlDatabase) creditStatements {
 	return creditStatements{
-		findTrxById:         db.Prepare(`SELECT id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version FROM user_credit_wallet_trx WHERE id = $1`),
-		findWalletById:      db.Prepare(`SELECT w.id, w.user_id, w.balance, w.balance_pending, w.balance_expiring, w.balance_expiring_date, w.created_at, w.updated_at, w.version FROM user_credit_wallet AS w WHERE w.id = $1`),
-		findWalletByTrx:     db.Prepare(`SELECT w.id, w.user_id, w.balance, w.balance_pending, w.balance_expiring, w.balance_expiring_date, w.created_at, w.updated_at, w.version FROM user_credit_wallet AS w INNER JOIN user_credit_wallet_trx t on w.id = t.user_credit_wallet_id WHERE t.id = $1`),
-		findWalletByUser:    db.Prepare(`SELECT w.id, w.user_id, w.balance, w.balance_pending, w.balance_expiring, w.balance_expiring_date, w.created_at, w.updated_at, w.version FROM user_credit_wallet AS w WHERE w.user_id = $1`),
-		insertTrx:           db.PrepareNamed(`INSERT INTO user_credit_wallet_trx(id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version) VALUES (:id, :user_credit_wallet_id, :balance, :balance_pending, :amount, :trx_entry_type_id, :trx_ref_id, :notes, :status, :created_at, :expired_at, :version)`),
-		insertWallet:        db.PrepareNamed(`INSERT INTO user_credit_wallet(id, user_id, balance, balance_pending, balance_expiring, balance_expiring_date, created_at, updated_at, version) VALUES (:id, :user_id, :balance, :balance_pending, :balance_expiring, :balance_expiring_date, :created_at, :updated_at, :version)`),
-		isExistTrxRef:       db.Prepare(`SELECT COUNT(*) > 0 as "isExist" FROM user_credit_wallet_trx WHERE user_credit_wallet_id = $1 AND trx_ref_id = $2`),
-		isExistWalletByUser: db.Prepare(`SELECT COUNT(*) > 0 as "isExist" FROM user_credit_wallet WHERE user_id = $1`),
-		updateWalletBalance: db.PrepareNamed(`UPDATE user_credit_wallet SET balance = :balance, balance_pending = :balance_pending, updated_at = :updated_at, version = :version WHERE id = :id AND version = :current_version`),
+		countTrxHistory:       db.Prepare(`SELECT COUNT(*) FROM user_credit_wallet_trx AS t INNER JOIN user_credit_wallet AS w ON w.id = t.user_credit_wallet_id WHERE w.user_id = $1 AND t.trx_entry_type_id <> 1 AND ($2::smallint = 0 OR t.status = $2) AND ($3::smallint = 0 OR t.trx_entry_type_id = $3) AND ($4::timestamptz IS NULL OR t.created_at >= $4) AND ($5::timestamptz IS NULL OR t.created_at < $5)`),
+		findActiveCreditLots:  db.Prepare(`SELECT id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version FROM user_credit_wallet_trx WHERE user_credit_wallet_id = $1 AND trx_entry_type_id IN (2, 4, 6) AND status = 2 AND expired_at > $2 ORDER BY expired_at DESC`),
+		findExpiredPendingTrx: db.Prepare(`SELECT t.id, t.user_credit_wallet_id, t.balance, t.balance_pending, t.amount, t.trx_entry_type_id, t.trx_ref_id, t.notes, t.status, t.created_at, t.expired_at, t.version FROM user_credit_wallet_trx AS t WHERE t.status = 1 AND t.expired_at <= $1 AND t.id > $2 AND NOT EXISTS(SELECT 1 FROM user_credit_wallet_trx AS r WHERE r.user_credit_wallet_id = t.user_credit_wallet_id AND r.trx_ref_id = t.id) ORDER BY t.id LIMIT $3`),
+		findExpiringWallets:   db.Prepare(`SELECT w.id, w.user_id, w.balance, w.balance_pending, w.balance_expiring, w.balance_expiring_date, w.created_at, w.updated_at, w.version FROM user_credit_wallet AS w WHERE w.balance > 0 AND (w.balance_expiring_date <= $1 OR (w.balance_expiring_date IS NULL AND EXISTS(SELECT 1 FROM user_credit_wallet_trx AS t WHERE t.user_credit_wallet_id = w.id AND t.trx_entry_type_id IN (2, 4, 6) AND t.status = 2 AND t.expired_at <= $1 AND t.expired_at > w.updated_at))) AND w.id > $2 ORDER BY w.id LIMIT $3`),
+		findActiveUserByEmail: db.Prepare(`SELECT p.id, p.full_name, p.avatar_file, p.gender_id, p.date_of_birth, p.email, p.created_at, p.updated_at, p.email_verified FROM user_profile AS p INNER JOIN user_auth AS a ON a.id = p.id WHERE p.email = $1 AND a.status_id = 1`),
+		findAdjustmentById:    db.Prepare(`SELECT id, user_id, trx_entry_type_id, amount, reason_code, notes, expired_at, status_id, trx_ref_id, requested_by, reviewed_by, review_notes, created_at, updated_at, modified_by, version FROM credit_adjustment WHERE id = $1`),
+		findAdjustments:       db.Prepare(`SELECT id, user_id, trx_entry_type_id, amount, reason_code, notes, expired_at, status_id, trx_ref_id, requested_by, reviewed_by, review_notes, created_at, updated_at, modified_by, version FROM credit_adjustment WHERE ($1::smallint = 0 OR status_id = $1) ORDER BY created_at DESC LIMIT $2 OFFSET $3`),
+		findTrxById:           db.Prepare(`SELECT id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version FROM user_credit_wallet_trx WHERE id = $1`),
+		findTrxByRef:          db.Prepare(`SELECT id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version FROM user_credit_wallet_trx WHERE user_credit_wallet_id = $1 AND trx_ref_id = $2 ORDER BY created_at`),
+		findTrxHistory:        db.Prepare(`SELECT t.id, t.user_credit_wallet_id, t.balance, t.balance_pending, t.amount, t.trx_entry_type_id, t.trx_ref_id, t.notes, t.status, t.created_at, t.expired_at, t.version, uc.challenge_id, c.title AS challenge_title, d.id AS donation_id, d.initiative_snapshot->>'name' AS initiative_name, rp.full_name AS counterpart_name FROM user_credit_wallet_trx AS t INNER JOIN user_credit_wallet AS w ON w.id = t.user_credit_wallet_id LEFT JOIN user_challenge AS uc ON uc.reward_ref_id = COALESCE(t.trx_ref_id, t.id) LEFT JOIN challenge AS c ON c.id = uc.challenge_id LEFT JOIN donation AS d ON d.payment_trx_ref = COALESCE(t.trx_ref_id, t.id) LEFT JOIN user_credit_wallet_trx AS rt ON t.trx_entry_type_id IN (4, 5) AND rt.id = t.trx_ref_id LEFT JOIN user_credit_wallet AS rw ON rw.id = rt.user_credit_wallet_id LEFT JOIN user_profile AS rp ON rp.id = rw.user_id WHERE w.user_id = $1 AND t.trx_entry_type_id <> 1 AND ($2::smallint = 0 OR t.status = $2) AND ($3::smallint = 0 OR t.trx_entry_type_id = $3) AND ($4::timestamptz IS NULL OR t.created_at >= $4) AND ($5::timestamptz IS NULL OR t.created_at < $5) ORDER BY t.created_at DESC, t.id DESC LIMIT NULLIF($6::int, 0) OFFSET $7`),
+		findWalletById:        db.Prepare(`SELECT w.id, w.user_id, w.balance, w.balance_pending, w.balance_expiring, w.balance_expiring_date, w.created_at, w.updated_at, w.version FROM user_credit_wallet AS w WHERE w.id = $1`),
+		findWalletByTrx:       db.Prepare(`SELECT w.id, w.user_id, w.balance, w.balance_pending, w.balance_expiring, w.balance_expiring_date, w.created_at, w.updated_at, w.version FROM user_credit_wallet AS w INNER JOIN user_credit_wallet_trx t on w.id = t.user_credit_wallet_id WHERE t.id = $1`),
+		findWalletByUser:      db.Prepare(`SELECT w.id, w.user_id, w.balance, w.balance_pending, w.balance_expiring, w.balance_expiring_date, w.created_at, w.updated_at, w.version FROM user_credit_wallet AS w WHERE w.user_id = $1`),
+		insertAdjustment:      db.PrepareNamed(`INSERT INTO credit_adjustment(id, user_id, trx_entry_type_id, amount, reason_code, notes, expired_at, status_id, trx_ref_id, requested_by, reviewed_by, review_notes, created_at, updated_at, modified_by, version) VALUES (:id, :user_id, :trx_entry_type_id, :amount, :reason_code, :notes, :expired_at, :status_id, :trx_ref_id, :requested_by, :reviewed_by, :review_notes, :created_at, :updated_at, :modified_by, :version)`),
+		insertTrx:             db.PrepareNamed(`INSERT INTO user_credit_wallet_trx(id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version) VALUES (:id, :user_credit_wallet_id, :balance, :balance_pending, :amount, :trx_entry_type_id, :trx_ref_id, :notes, :status, :created_at, :expired_at, :version)`),
+		insertWallet:          db.PrepareNamed(`INSERT INTO user_credit_wallet(id, user_id, balance, balance_pending, balance_expiring, balance_expiring_date, created_at, updated_at, version) VALUES (:id, :user_id, :balance, :balance_pending, :balance_expiring, :balance_expiring_date, :created_at, :updated_at, :version)`),
+		isExistTrxRef:         db.Prepare(`SELECT COUNT(*) > 0 as "isExist" FROM user_credit_wallet_trx WHERE user_credit_wallet_id = $1 AND trx_ref_id = $2`),
+		isExistWalletByUser:   db.Prepare(`SELECT COUNT(*) > 0 as "isExist" FROM user_credit_wallet WHERE user_id = $1`),
+		sumEarnedCredit:       db.Prepare(`SELECT COALESCE(SUM(amount), 0) FROM user_credit_wallet_trx WHERE user_credit_wallet_id = $1 AND trx_entry_type_id = 2 AND status = 2 AND created_at >= $2 AND created_at < $3`),
+		sumNonExpiringCredit:  db.Prepare(`SELECT COALESCE(SUM(amount), 0) FROM user_credit_wallet_trx WHERE user_credit_wallet_id = $1 AND trx_entry_type_id IN (2, 4, 6) AND status = 2 AND expired_at IS NULL`),
+		sumTransferOut:        db.Prepare(`SELECT COALESCE(SUM(amount), 0) AS amount, COUNT(*) AS count FROM user_credit_wallet_trx WHERE user_credit_wallet_id = $1 AND trx_entry_type_id = 5 AND status = 2 AND created_at >= $2`),
+		updateAdjustment:      db.PrepareNamed(`UPDATE credit_adjustment SET status_id = :status_id, trx_ref_id = :trx_ref_id, reviewed_by = :reviewed_by, review_notes = :review_notes, updated_at = :updated_at, modified_by = :modified_by, version = :version WHERE id = :id AND version = :current_version`),
+		updateTrxStatus:       db.PrepareNamed(`UPDATE user_credit_wallet_trx SET status = :status WHERE id = :id AND status = 1`),
+		updateWalletBalance:   db.PrepareNamed(`UPDATE user_credit_wallet SET balance = :balance, balance_pending = :balance_pending, balance_expiring = :balance_expiring, balance_expiring_date = :balance_expiring_date, updated_at = :updated_at, version = :version WHERE id = :id AND version = :current_version`),
 	}
 }
@@ -0,0 +1,389 @@
+package service_test
+
+import (
+	"fmt"
+	"github.com/diarikom/running-app/running-app-api/cmd/apitest"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/internal/api/mocks"
+	"github.com/diarikom/running-app/running-app-api/internal/api/service"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+const (
+	creditTestUserId         = "1267772569398808561"
+	creditTestRecipientEmail = "johndoe@email.com"
+)
+
+func TestCreditTestSuite(t *testing.T) {
+	suite.Run(t, new(CreditTestSuite))
+}
+
+type CreditTestSuite struct {
+	suite.Suite
+	App apitest.Api
+}
+
+func (s *CreditTestSuite) SetupTest() {
+	// Init app
+	s.App = apitest.InitApi()
+
+	// Setup data
+	err := s.SetupData()
+	if err != nil {
+		panic(fmt.Errorf("failed to set-up data"))
+	}
+
+	// init service
+	s.InitService()
+}
+
+func (s *CreditTestSuite) TearDownTest() {
+	// Drop credit
+	s.App.IgnoreDbExec(`DELETE FROM credit_adjustment`)
+	s.App.IgnoreDbExec(`DELETE FROM user_credit_wallet_trx`)
+	s.App.IgnoreDbExec(`DELETE FROM user_credit_wallet`)
+	// Drop users
+	s.App.IgnoreDbExec(`DELETE FROM user_auth`)
+	s.App.IgnoreDbExec(`DELETE FROM user_profile`)
+}
+
+func (s *CreditTestSuite) SetupData() error {
+	// Get instances
+	db := s.App.Datasources.Db.Conn
+	logger := s.App.Logger
+
+	// Begin Transaction
+	tx := db.MustBegin()
+	var err error
+	defer nsql.ReleaseTx(tx, &err, logger)
+
+	// Insert users data
+	_, err = db.Exec(`INSERT INTO user_profile (id, full_name, avatar_file, gender_id, date_of_birth, email, created_at, updated_at, email_verified) VALUES (1267772569398808561, 'Jane Doe', null, 1, '1999-12-31', 'janedoe@email.com', '2020-06-02 17:58:29.277934', '2020-06-02 17:58:29.277934', false);`)
+	if err != nil {
+		logger.Error("failed to insert users", err)
+		return err
+	}
+	logger.Debug("user_profile inserted")
+
+	// Insert active transfer recipient
+	_, err = db.Exec(`INSERT INTO user_profile (id, full_name, avatar_file, gender_id, date_of_birth, email, created_at, updated_at, email_verified) VALUES (1267772569398808562, 'John Doe', null, 1, '1999-12-31', 'johndoe@email.com', '2020-06-02 17:58:29.277934', '2020-06-02 17:58:29.277934', true); INSERT INTO user_auth (id, username, password, status_id, created_at, updated_at) VALUES (1267772569398808562, 'johndoe@email.com', '', 1, '2020-06-02 17:58:29.277934', '2020-06-02 17:58:29.277934');`)
+	if err != nil {
+		logger.Error("failed to insert recipient", err)
+		return err
+	}
+	logger.Debug("recipient inserted")
+
+	// Insert runner credit with non-expiring balance
+	_, err = db.Exec(`INSERT INTO public.user_credit_wallet (id, user_id, balance, balance_pending, balance_expiring, balance_expiring_date, created_at, updated_at, version) VALUES (1263038349258526720, 1267772569398808561, 4.00, 0.00, 0.00, null, '2020-05-20 16:26:23.238245', '2020-05-20 16:30:00.000000', 2); INSERT INTO public.user_credit_wallet_trx (id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version) VALUES (1263038349258526721, 1263038349258526720, 0.00, 0.00, 0.00, 1, null, 'Init wallet', 2, '2020-05-20 16:26:23.238245', null, 1), (1263038349258526722, 1263038349258526720, 4.00, 0.00, 4.00, 2, null, 'Claimed Credit', 2, '2020-05-20 16:30:00.000000', null, 2);`)
+	if err != nil {
+		logger.Error("failed to insert user credit", err)
+		return err
+	}
+	logger.Debug("user credit inserted")
+
+	err = nil
+	return nil
+}
+
+func (s *CreditTestSuite) InitService() {
+	apiTest := s.App
+
+	// Init services
+	apiTest.Services = &api.Services{
+		Asset:           &mocks.AssetService{},
+		Auth:            &mocks.AuthenticatorService{},
+		User:            &mocks.UserService{},
+		Run:             &mocks.RunService{},
+		DiscoverContent: &mocks.DiscoverContentService{},
+		Tag:             &mocks.AdTagService{},
+		Credit:          &service.CreditService{},
+	}
+
+	// Init services
+	apiTest.MustInitService("CreditService", apiTest.Services.Credit)
+}
+
+// charge creates a pending charge of amount. If settle is true, charge is settled
+func (s *CreditTestSuite) charge(amount float64, settle bool) string {
+	svc := s.App.Services.Credit
+
+	trxId, err := svc.Charge(dto.CreditChargeOpt{
+		UserId: creditTestUserId,
+		Amount: amount,
+	})
+	if err != nil {
+		s.T().Fatalf("unable to charge: %s", err)
+	}
+
+	if settle {
+		err = svc.SettlePendingTrx(dto.CreditSettleOpt{TrxId: trxId})
+		if err != nil {
+			s.T().Fatalf("unable to settle charge: %s", err)
+		}
+	}
+
+	return trxId
+}
+
+func (s *CreditTestSuite) assertBalance(balance, pending float64) {
+	resp, err := s.App.Services.Credit.GetUserBalance(creditTestUserId)
+	if err != nil {
+		s.T().Fatalf("unable to get balance: %s", err)
+	}
+
+	if resp.Balance != balance || resp.PendingBalance != pending {
+		s.T().Errorf("expected balance is %f (pending %f), got %f (pending %f)", balance, pending, resp.Balance,
+			resp.PendingBalance)
+	}
+}
+
+func (s *CreditTestSuite) TestRefundSettledCharge() {
+	// Add expiring credit, which is consumed by charge before non expiring credit
+	expiredAt := time.Now().AddDate(0, 0, 60).UTC().Truncate(time.Second)
+	_, err := s.App.Datasources.Db.Conn.Exec(`UPDATE user_credit_wallet SET balance = 6.00, version = 3 WHERE id = 1263038349258526720; INSERT INTO public.user_credit_wallet_trx (id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version) VALUES (1263038349258526723, 1263038349258526720, 6.00, 0.00, 2.00, 2, null, 'Claimed Credit', 2, now(), $1, 3);`, expiredAt)
+	if err != nil {
+		s.T().Fatalf("unable to insert expiring credit: %s", err)
+	}
+
+	trxId := s.charge(1.5, true)
+	s.assertBalance(4.5, 0)
+
+	refundTrx, err := s.App.Services.Credit.RefundTrx(dto.CreditRefundOpt{TrxId: trxId})
+	if err != nil {
+		s.T().Fatalf("unable to refund settled charge: %s", err)
+	}
+
+	if refundTrx.TrxEntryTypeId != api.Refund || refundTrx.Status != api.TrxSuccess || refundTrx.Amount != 1.5 {
+		s.T().Errorf("unexpected refund transaction: %+v", refundTrx)
+	}
+
+	// Refunded credit expires with the consumed credit
+	if refundTrx.TrxRefId.String != trxId || !refundTrx.ExpiredAt.Valid || !refundTrx.ExpiredAt.Time.Equal(expiredAt) {
+		s.T().Errorf("refund must refer to charge and expire on %s: %+v", expiredAt, refundTrx)
+	}
+
+	s.assertBalance(6, 0)
+}
+
+func (s *CreditTestSuite) TestRefundPendingCharge() {
+	trxId := s.charge(1.5, false)
+
+	releaseTrx, err := s.App.Services.Credit.RefundTrx(dto.CreditRefundOpt{TrxId: trxId})
+	if err != nil {
+		s.T().Fatalf("unable to refund pending charge: %s", err)
+	}
+
+	if releaseTrx.TrxEntryTypeId != api.Credit || releaseTrx.Status != api.TrxFailed ||
+		releaseTrx.TrxRefId.String != trxId {
+		s.T().Errorf("pending charge must be cancelled: %+v", releaseTrx)
+	}
+
+	s.assertBalance(4, 0)
+}
+
+func (s *CreditTestSuite) TestRefundTwice() {
+	for _, settle := range []bool{true, false} {
+		trxId := s.charge(1, settle)
+
+		_, err := s.App.Services.Credit.RefundTrx(dto.CreditRefundOpt{TrxId: trxId})
+		if err != nil {
+			s.T().Fatalf("unable to refund charge: %s", err)
+		}
+
+		// Second refund must be refused without changing balance
+		_, err = s.App.Services.Credit.RefundTrx(dto.CreditRefundOpt{TrxId: trxId})
+		if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "CRD018" {
+			s.T().Errorf("expected CRD018 on double refund (settled = %t), got %v", settle, err)
+		}
+
+		s.assertBalance(4, 0)
+	}
+}
+
+func (s *CreditTestSuite) TestExpireLegacyWallet() {
+	// Wallet that has never been refreshed by credit expiry has no expiring date, but holds an expired credit
+	_, err := s.App.Datasources.Db.Conn.Exec(`UPDATE user_credit_wallet SET balance = 7.00, balance_expiring_date = null, updated_at = '2020-05-21 00:00:00.000000', version = 3 WHERE id = 1263038349258526720; INSERT INTO public.user_credit_wallet_trx (id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version) VALUES (1263038349258526723, 1263038349258526720, 7.00, 0.00, 3.00, 2, null, 'Claimed Credit', 2, '2020-05-21 00:00:00.000000', '2020-08-21 00:00:00.000000', 3);`)
+	if err != nil {
+		s.T().Fatalf("unable to insert legacy wallet credit: %s", err)
+	}
+
+	resp, err := s.App.Services.Credit.ExpireCredits(dto.CreditExpireOpt{})
+	if err != nil {
+		s.T().Fatalf("unable to expire credits: %s", err)
+	}
+
+	if resp.ExpiredWallet != 1 || resp.ExpiredAmount != 3 {
+		s.T().Errorf("expected expired credit of legacy wallet, got %+v", resp)
+	}
+
+	s.assertBalance(4, 0)
+
+	// Wallet must not be expired twice
+	resp, err = s.App.Services.Credit.ExpireCredits(dto.CreditExpireOpt{})
+	if err != nil {
+		s.T().Fatalf("unable to expire credits: %s", err)
+	}
+
+	if resp.ExpiredWallet != 0 {
+		s.T().Errorf("expected no expired wallet on second run, got %+v", resp)
+	}
+	s.assertBalance(4, 0)
+}
+
+// transfer sends amount from test user to recipient
+func (s *CreditTestSuite) transfer(amount float64) (*dto.CreditTransferResp, error) {
+	return s.App.Services.Credit.Transfer(dto.CreditTransferReq{
+		UserId:         creditTestUserId,
+		RecipientEmail: creditTestRecipientEmail,
+		Amount:         amount,
+	})
+}
+
+func (s *CreditTestSuite) TestTransferLimit() {
+	svc := s.App.Services.Credit.(*service.CreditService)
+	svc.TransferDailyLimit = 2
+	svc.TransferDailyCount = 2
+
+	// Transfer within limit
+	_, err := s.transfer(1.5)
+	if err != nil {
+		s.T().Fatalf("unable to transfer: %s", err)
+	}
+
+	// Transfer that exceeds daily amount must be refused
+	_, err = s.transfer(1)
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "CRD013" {
+		s.T().Errorf("expected CRD013 on daily amount limit, got %v", err)
+	}
+
+	// Transfer that exceeds daily count must be refused
+	svc.TransferDailyLimit = 0
+	_, err = s.transfer(0.5)
+	if err != nil {
+		s.T().Fatalf("unable to transfer: %s", err)
+	}
+
+	_, err = s.transfer(0.5)
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "CRD013" {
+		s.T().Errorf("expected CRD013 on daily count limit, got %v", err)
+	}
+
+	// Transfer that exceeds balance must be refused
+	svc.TransferDailyCount = 0
+	_, err = s.transfer(3)
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "CRD010" {
+		s.T().Errorf("expected CRD010 on insufficient balance, got %v", err)
+	}
+
+	s.assertBalance(2, 0)
+}
+
+func (s *CreditTestSuite) TestTransferExpiry() {
+	// Add expiring credit, which is transferred before non expiring credit
+	expiredAt := time.Now().AddDate(0, 0, 30).UTC().Truncate(time.Second)
+	_, err := s.App.Datasources.Db.Conn.Exec(`UPDATE user_credit_wallet SET balance = 6.00, version = 3 WHERE id = 1263038349258526720; INSERT INTO public.user_credit_wallet_trx (id, user_credit_wallet_id, balance, balance_pending, amount, trx_entry_type_id, trx_ref_id, notes, status, created_at, expired_at, version) VALUES (1263038349258526723, 1263038349258526720, 6.00, 0.00, 2.00, 2, null, 'Claimed Credit', 2, now(), $1, 3);`, expiredAt)
+	if err != nil {
+		s.T().Fatalf("unable to insert expiring credit: %s", err)
+	}
+
+	cases := []struct {
+		amount    float64
+		expiredAt pq.NullTime
+	}{
+		// Transferred credit expires with the consumed sender credit
+		{1.5, pq.NullTime{Valid: true, Time: expiredAt}},
+		// Transfer that consumes non expiring credit never expires
+		{1.5, pq.NullTime{}},
+	}
+
+	for _, c := range cases {
+		resp, err := s.transfer(c.amount)
+		if err != nil {
+			s.T().Fatalf("unable to transfer: %s", err)
+		}
+
+		var actual pq.NullTime
+		err = s.App.Datasources.Db.Conn.Get(&actual,
+			`SELECT expired_at FROM user_credit_wallet_trx WHERE trx_ref_id = $1`, resp.Id)
+		if err != nil {
+			s.T().Fatalf("unable to retrieve transferred credit: %s", err)
+		}
+
+		if actual.Valid != c.expiredAt.Valid || !actual.Time.Equal(c.expiredAt.Time) {
+			s.T().Errorf("expected transferred credit expiry %+v, got %+v", c.expiredAt, actual)
+		}
+	}
+
+	s.assertBalance(3, 0)
+}
+
+// requestAdjustment requests credit grant of amount to test user
+func (s *CreditTestSuite) requestAdjustment(amount float64) *dto.CreditAdjustmentResp {
+	resp, err := s.App.Services.Credit.RequestAdjustment(dto.CreditAdjustmentReq{
+		UserId:      creditTestUserId,
+		EntryTypeId: api.Debit,
+		Amount:      amount,
+		ReasonCode:  "COMPENSATION",
+		RequestedBy: dto.ModifierReq{Id: "1", FullName: "Requester"},
+	})
+	if err != nil {
+		s.T().Fatalf("unable to request adjustment: %s", err)
+	}
+
+	return resp
+}
+
+func (s *CreditTestSuite) TestAdjustmentThreshold() {
+	s.App.Services.Credit.(*service.CreditService).AdjustmentThreshold = 5
+
+	// Adjustment within threshold is applied immediately
+	resp := s.requestAdjustment(5)
+	if resp.StatusId != api.AdjustmentApplied || resp.TrxId == "" {
+		s.T().Errorf("adjustment within threshold must be applied: %+v", resp)
+	}
+	s.assertBalance(9, 0)
+
+	// Adjustment above threshold waits for approval
+	resp = s.requestAdjustment(5.01)
+	if resp.StatusId != api.AdjustmentPendingApproval || resp.TrxId != "" {
+		s.T().Errorf("adjustment above threshold must wait for approval: %+v", resp)
+	}
+	s.assertBalance(9, 0)
+}
+
+func (s *CreditTestSuite) TestAdjustmentApproval() {
+	s.App.Services.Credit.(*service.CreditService).AdjustmentThreshold = 5
+	adjustment := s.requestAdjustment(10)
+
+	// Requester can not approve own adjustment
+	_, err := s.App.Services.Credit.ReviewAdjustment(dto.CreditAdjustmentReviewReq{
+		Id:         adjustment.Id,
+		Approved:   true,
+		ReviewedBy: dto.ModifierReq{Id: "1", FullName: "Requester"},
+	})
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "CRD016" {
+		s.T().Errorf("expected CRD016 on approval by requester, got %v", err)
+	}
+	s.assertBalance(4, 0)
+
+	// Second admin approves adjustment
+	resp, err := s.App.Services.Credit.ReviewAdjustment(dto.CreditAdjustmentReviewReq{
+		Id:         adjustment.Id,
+		Approved:   true,
+		ReviewedBy: dto.ModifierReq{Id: "2", FullName: "Approver"},
+	})
+	if err != nil {
+		s.T().Fatalf("unable to approve adjustment: %s", err)
+	}
+
+	if resp.StatusId != api.AdjustmentApplied || resp.ReviewedBy == nil || resp.ReviewedBy.Id != "2" {
+		s.T().Errorf("adjustment must be applied and reviewed by approver: %+v", resp)
+	}
+	s.assertBalance(14, 0)
+}
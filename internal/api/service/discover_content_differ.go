@@ -0,0 +1,19 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+)
+
+type discoverContentDiffer struct {
+	content *nsql.Differ
+}
+
+func initDiscoverContentDiffer() discoverContentDiffer {
+	contentDiffer := nsql.PrepareDiffer(nsql.DifferOpt{
+		Sample:    model.DiscoverContent{},
+		TableName: "discover_content",
+	})
+
+	return discoverContentDiffer{content: contentDiffer}
+}
@@ -2,21 +2,28 @@ package service
 
 import (
 	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nstr"
+	"github.com/gorilla/mux"
 	"net/http"
 )
 
 func NewDiscoverContentHandler(app *api.Api) DiscoverContentHandler {
 	return DiscoverContentHandler{
 		DiscoverContentService: app.Services.DiscoverContent,
+		AssetService:           app.Services.Asset,
 		Logger:                 app.Logger,
+		RegionHeader:           app.Config.GetString(api.ConfDiscoverRegionHeader),
 	}
 }
 
 type DiscoverContentHandler struct {
 	DiscoverContentService api.DiscoverContentService
+	AssetService           api.AssetService
 	Logger                 nlog.Logger
+	RegionHeader           string
 }
 
 func (h *DiscoverContentHandler) GetContents(r *http.Request) (success *nhttp.Success, err error) {
@@ -26,7 +33,16 @@ func (h *DiscoverContentHandler) GetContents(r *http.Request) (success *nhttp.Su
 	skip, limit := api.Pagination(q)
 
 	// Call service
-	sessions, err := h.DiscoverContentService.GetContents(skip, limit)
+	sessions, err := h.DiscoverContentService.GetContents(dto.DiscoverContentQueryReq{
+		UserResourcesReq: dto.UserResourcesReq{
+			PageReq: dto.PageReq{
+				Skip:  skip,
+				Limit: limit,
+			},
+			UserId: r.Header.Get(nhttp.KeyUserId),
+		},
+		Region: nhttp.TrustedHeader(r, h.RegionHeader),
+	})
 	if err != nil {
 		return
 	}
@@ -38,3 +54,112 @@ func (h *DiscoverContentHandler) GetContents(r *http.Request) (success *nhttp.Su
 
 	return resp, nil
 }
+
+func (h *DiscoverContentHandler) PostContent(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.DiscoverContentReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set admin
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.DiscoverContentService.Create(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *DiscoverContentHandler) GetAdminContents(r *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	query := r.URL.Query()
+	skip, limit := api.Pagination(query)
+
+	// Call service
+	respBody, err := h.DiscoverContentService.List(dto.DiscoverContentListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		StatusId: nstr.ParseInt8(query.Get("status_id"), 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *DiscoverContentHandler) GetAdminContent(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.DiscoverContentService.Get(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *DiscoverContentHandler) PutContent(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.DiscoverContentReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set content id and admin
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.DiscoverContentService.Update(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *DiscoverContentHandler) DeleteContent(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.DiscoverContentDeleteReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set content id and admin
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	err = h.DiscoverContentService.Delete(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *DiscoverContentHandler) PostImage(r *http.Request) (*nhttp.Success, error) {
+	// Parse multipart image
+	rule := nhttp.NewImageUploadRules()[0]
+	file, err := nhttp.GetFile(r, rule.Key, rule.MaxSize, rule.MimeTypes)
+	if err != nil {
+		return nil, h.AssetService.NewImageError(err)
+	}
+
+	// Call service
+	respBody, err := h.DiscoverContentService.UploadImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
@@ -5,35 +5,44 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/lib/pq"
 )
 
-func NewDiscoverContentRepository(db *nsql.SqlDatabase, logger nlog.Logger) api.DiscoverContentRepository {
+func NewDiscoverContentRepository(db *nsql.SqlDatabase, idGen *api.SnowflakeGen, apiErrors *api.Errors,
+	logger nlog.Logger) api.DiscoverContentRepository {
 	r := discoverContentRepository{
-		Db:     db,
-		Stmt:   initDiscoverContentStatements(db),
-		Logger: logger,
+		IdGen:   idGen,
+		Errors:  apiErrors,
+		Db:      db,
+		Stmt:    initDiscoverContentStatements(db),
+		Differs: initDiscoverContentDiffer(),
+		Logger:  logger,
 	}
 
 	return &r
 }
 
 type discoverContentRepository struct {
-	Db     *nsql.SqlDatabase
-	Stmt   discoverContentStatements
-	Logger nlog.Logger
+	IdGen   *api.SnowflakeGen
+	Errors  *api.Errors
+	Db      *nsql.SqlDatabase
+	Stmt    discoverContentStatements
+	Differs discoverContentDiffer
+	Logger  nlog.Logger
 }
 
-func (r discoverContentRepository) CountContents() (total int, err error) {
+func (r discoverContentRepository) CountContents(audience model.DiscoverAudience) (total int, err error) {
 	total = 0
-	err = r.Stmt.countContent.Get(&total)
+	err = r.Stmt.countContent.Get(&total, audience.Premium, audience.Region)
 	if err != nil {
 		return
 	}
 	return
 }
 
-func (r discoverContentRepository) FindContents(limit int8, skip int64) (result []model.DiscoverContent, err error) {
-	err = r.Stmt.findContent.Select(&result, limit, skip)
+func (r discoverContentRepository) FindContents(audience model.DiscoverAudience, limit int8, skip int64) (
+	result []model.DiscoverContent, err error) {
+	err = r.Stmt.findContent.Select(&result, audience.Premium, audience.Region, limit, skip)
 	if err != nil {
 		return
 	}
@@ -42,3 +51,119 @@ func (r discoverContentRepository) FindContents(limit int8, skip int64) (result
 	}
 	return
 }
+
+func (r discoverContentRepository) FindPersonalizedContents(userId string, segments []string,
+	audience model.DiscoverAudience, limit int8, skip int64) (result []model.DiscoverContent, err error) {
+	err = r.Stmt.findPersonalizedContent.Select(&result, userId, pq.Array(segments), discoverFollowedTagWeight,
+		discoverSegmentTagWeight, audience.Premium, audience.Region, limit, skip)
+	if err != nil {
+		return
+	}
+	if len(result) == 0 {
+		result = []model.DiscoverContent{}
+	}
+	return
+}
+
+func (r discoverContentRepository) FindTags(ids []int64) ([]model.AdTag, error) {
+	rows := make([]model.AdTag, 0)
+	err := r.Stmt.findTags.Select(&rows, pq.Array(ids))
+	return rows, err
+}
+
+func (r discoverContentRepository) FindById(id string) (*model.DiscoverContent, error) {
+	var result model.DiscoverContent
+	err := r.Stmt.findById.Get(&result, id)
+	return &result, err
+}
+
+func (r discoverContentRepository) FindAll(statusId int8, skip int64, limit int8) ([]model.DiscoverContent, error) {
+	rows := make([]model.DiscoverContent, 0)
+	err := r.Stmt.findContents.Select(&rows, statusId, limit, skip)
+	return rows, err
+}
+
+func (r discoverContentRepository) IsExistOrganization(organizationId string) (bool, error) {
+	var isExist bool
+	err := r.Stmt.isExistOrganization.Get(&isExist, organizationId)
+	return isExist, err
+}
+
+func (r discoverContentRepository) Insert(content *model.DiscoverContent) error {
+	_, err := r.Stmt.insert.Exec(content)
+	if err != nil {
+		r.Logger.Error("insert discover content", err)
+	}
+	return err
+}
+
+func (r discoverContentRepository) Update(oldContent, newContent model.DiscoverContent, changelog []string) (err error) {
+	// Get differ
+	differ := r.Differs.content
+
+	// Compare instance
+	diff, err := differ.Compare(oldContent, newContent, changelog)
+	if err != nil {
+		return err
+	}
+
+	// If no changes, return
+	if diff.Count == 0 {
+		r.Logger.Debug("no discover content changes detected")
+		return nil
+	}
+
+	// Generate update discover content query
+	updateQuery, updateArgs, err := differ.UpdateQuerySafe(diff, oldContent.Version)
+	if err != nil {
+		r.Logger.Error("unable to generate update discover_content query", err)
+		return err
+	}
+
+	// Generate insert discover content log query
+	insertLogQuery, insertLogArgs, err := differ.InsertLogQuery(diff, r.IdGen.New(), diff.TrackedCols)
+	if err != nil {
+		r.Logger.Error("unable to generate insert discover_content_log query", err)
+		return err
+	}
+
+	// Rebind all query
+	updateQuery = r.Db.Conn.Rebind(updateQuery)
+	insertLogQuery = r.Db.Conn.Rebind(insertLogQuery)
+
+	// Begin transaction
+	tx, err := r.Db.Conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer nsql.ReleaseTx(tx, &err, r.Logger)
+
+	// Update discover content
+	result, err := tx.Exec(updateQuery, updateArgs...)
+	if err != nil {
+		r.Logger.Error("failed to update discover_content", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		r.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		r.Logger.Errorf("no discover content update affected. Rolling back")
+		err = r.Errors.New("DSC002")
+		return err
+	}
+
+	// Insert log
+	_, err = tx.Exec(insertLogQuery, insertLogArgs...)
+	if err != nil {
+		r.Logger.Error("failed to insert discover_content_log", err)
+		return err
+	}
+
+	return nil
+}
@@ -1,10 +1,29 @@
 package service
 
 import (
+	"database/sql"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql/pqx"
+	validate "github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
 	"strings"
+	"time"
+)
+
+const (
+	// discoverFollowedTagWeight is the score added for each content tag that is followed by user
+	discoverFollowedTagWeight = 3
+	// discoverSegmentTagWeight is the score added for each content tag that targets user segment
+	discoverSegmentTagWeight = 1
+	// discoverRunWindow is the period of recent run sessions used to determine user run segment
+	discoverRunWindow = 14 * 24 * time.Hour
+	// discoverActiveRunDistance is the minimum distance within run window for an active runner, in meters
+	discoverActiveRunDistance = 20000
 )
 
 type DiscoverContent struct {
@@ -12,28 +31,45 @@ type DiscoverContent struct {
 	Errors                    *api.Errors
 	Logger                    nlog.Logger
 	DiscoverContentRepository api.DiscoverContentRepository
+	RunRepository             api.RunRepository
 	AssetService              api.AssetService
+	UserService               api.UserService
+	Validator                 *validate.Validate
 }
 
 func (r *DiscoverContent) Init(app *api.Api) error {
 	r.IdGen = app.Components.Id
 	r.Errors = app.Components.Errors
 	r.Logger = app.Logger
-	r.DiscoverContentRepository = NewDiscoverContentRepository(app.Datasources.Db, app.Logger)
+	r.DiscoverContentRepository = NewDiscoverContentRepository(app.Datasources.Db, app.Components.Id,
+		app.Components.Errors, app.Logger)
+	r.RunRepository = NewRunRepository(app.Datasources.Db, app.Logger)
 	r.AssetService = app.Services.Asset
+	r.UserService = app.Services.User
+	r.Validator = validate.New()
 	return nil
 }
 
-func (r DiscoverContent) GetContents(skip int64, limit int8) (resp *dto.DiscoverContentResp, err error) {
+func (r DiscoverContent) GetContents(opt dto.DiscoverContentQueryReq) (resp *dto.DiscoverContentResp, err error) {
+	// Resolve user audience
+	isPremium := r.isPremiumRunner(opt.UserId)
+	audience := model.DiscoverAudience{
+		Premium: api.AudienceFree,
+		Region:  strings.ToUpper(opt.Region),
+	}
+	if isPremium {
+		audience.Premium = api.AudiencePremium
+	}
+
 	// Find contents
-	contents, err := r.DiscoverContentRepository.FindContents(limit, skip)
+	contents, err := r.findContents(opt.UserResourcesReq, isPremium, audience)
 	if err != nil {
 		r.Logger.Error("unable to find contents", err)
 		return nil, err
 	}
 
 	// Count total active contents
-	count, err := r.DiscoverContentRepository.CountContents()
+	count, err := r.DiscoverContentRepository.CountContents(audience)
 	if err != nil {
 		r.Logger.Error("unable to count contents", err)
 		return nil, err
@@ -42,36 +78,7 @@ func (r DiscoverContent) GetContents(skip int64, limit int8) (resp *dto.Discover
 	// Copy model to response
 	items := make([]dto.DiscoverContentItem, len(contents))
 	for k, v := range contents {
-		// Get full image url
-		imageUrls := dto.DiscoverImageFileResp{
-			Thumbnail:  r.AssetService.GetPublicUrl(api.AssetDiscoverContent, v.ImageFiles.Thumbnail),
-			DetailPage: r.AssetService.GetPublicUrl(api.AssetDiscoverContent, v.ImageFiles.DetailPage),
-		}
-
-		// Convert tags to array
-		var tags []string
-		if v.Tags.String == "" {
-			tags = []string{}
-		} else {
-			rawTags := strings.Trim(v.Tags.String, ",")
-			tags = strings.Split(rawTags, ",")
-		}
-
-		items[k] = dto.DiscoverContentItem{
-			Id:          v.Id,
-			Title:       v.Title,
-			ContentBody: v.ContentBody,
-			ExternalUrl: v.ExternalUrl,
-			StatusId:    v.StatusId,
-			Sort:        v.Sort,
-			CreatedAt:   v.CreatedAt.Unix(),
-			UpdatedAt:   v.UpdatedAt.Unix(),
-			Version:     v.Version,
-			ModifiedBy:  v.ModifiedBy,
-			ImageFiles:  imageUrls,
-			Headline:    v.Headline.String,
-			Tags:        tags,
-		}
+		items[k] = r.composeContentItem(v)
 	}
 
 	// Create response result
@@ -82,3 +89,391 @@ func (r DiscoverContent) GetContents(skip int64, limit int8) (resp *dto.Discover
 
 	return resp, nil
 }
+
+func (r DiscoverContent) Create(opt dto.DiscoverContentReq) (*dto.DiscoverContentDetailResp, error) {
+	// Validate request
+	err := r.validateContentReq(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get tag names, content refers to tags by id
+	tags, err := r.findTags(opt.TagIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set default status
+	statusId := opt.StatusId
+	if statusId == 0 {
+		statusId = api.DiscoverContentActive
+	}
+
+	// Create content
+	timestamp := time.Now()
+	content := model.DiscoverContent{
+		Id:         r.IdGen.New(),
+		StatusId:   statusId,
+		CreatedAt:  timestamp,
+		UpdatedAt:  timestamp,
+		Version:    1,
+		ModifiedBy: newAdminModifier(opt.ModifiedBy),
+	}
+	setDiscoverContent(&content, opt)
+	content.Tags = tags
+
+	err = r.DiscoverContentRepository.Insert(&content)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.composeContentDetail(content), nil
+}
+
+func (r DiscoverContent) Get(id string) (*dto.DiscoverContentDetailResp, error) {
+	content, err := r.findById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.composeContentDetail(*content), nil
+}
+
+func (r DiscoverContent) List(opt dto.DiscoverContentListReq) ([]dto.DiscoverContentDetailResp, error) {
+	// Get contents
+	rows, err := r.DiscoverContentRepository.FindAll(opt.StatusId, opt.Skip, opt.Limit)
+	if err != nil {
+		r.Logger.Error("unable to retrieve discover contents", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.DiscoverContentDetailResp, len(rows))
+	for k, v := range rows {
+		resp[k] = *r.composeContentDetail(v)
+	}
+
+	return resp, nil
+}
+
+func (r DiscoverContent) Update(opt dto.DiscoverContentReq) (*dto.DiscoverContentDetailResp, error) {
+	// Validate request
+	if opt.Id == "" || opt.Version == 0 {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	err := r.validateContentReq(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get tag names, content refers to tags by id
+	tags, err := r.findTags(opt.TagIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get content
+	content, err := r.findById(opt.Id)
+	if err != nil {
+		return nil, err
+	}
+	oldContent := *content
+
+	// Update content
+	setDiscoverContent(content, opt)
+	content.Tags = tags
+	if opt.StatusId != 0 {
+		content.StatusId = opt.StatusId
+	}
+
+	changelog := []string{
+		"organization_id",
+		"content_body",
+		"logo_file",
+		"external_url",
+		"status_id",
+		"sort",
+		"image_files",
+		"headline",
+		"tag_ids",
+		"publish_at",
+		"unpublish_at",
+		"audience_premium",
+		"audience_regions",
+	}
+
+	err = r.update(oldContent, content, opt.Version, opt.ModifiedBy, changelog)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.composeContentDetail(*content), nil
+}
+
+func (r DiscoverContent) Delete(opt dto.DiscoverContentDeleteReq) error {
+	// Validate request
+	err := r.Validator.Struct(&opt)
+	if err != nil {
+		r.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Get content
+	content, err := r.findById(opt.Id)
+	if err != nil {
+		return err
+	}
+	oldContent := *content
+
+	// Mark content as deleted. Content row is kept for audit log
+	content.StatusId = api.DiscoverContentDeleted
+
+	return r.update(oldContent, content, opt.Version, opt.ModifiedBy, []string{"status_id"})
+}
+
+func (r DiscoverContent) UploadImage(file nhttp.MultipartFile) (*dto.UploadResp, error) {
+	return r.AssetService.UploadFile(dto.UploadReq{
+		AssetType: api.AssetDiscoverContent,
+		File:      file,
+	})
+}
+
+// findContents returns contents ranked by user followed tags and segments. If personalization is not available,
+// contents are returned in default sort order
+func (r DiscoverContent) findContents(opt dto.UserResourcesReq, isPremium bool, audience model.DiscoverAudience) (
+	[]model.DiscoverContent, error) {
+	if opt.UserId != "" {
+		contents, err := r.DiscoverContentRepository.FindPersonalizedContents(opt.UserId,
+			r.getUserSegments(opt.UserId, isPremium), audience, opt.Limit, opt.Skip)
+		if err == nil {
+			return contents, nil
+		}
+		r.Logger.Error("unable to find personalized contents, fallback to default sort", err)
+	}
+
+	return r.DiscoverContentRepository.FindContents(audience, opt.Limit, opt.Skip)
+}
+
+// isPremiumRunner returns subscription status of user. Subscription check failure is treated as free user
+func (r DiscoverContent) isPremiumRunner(userId string) bool {
+	if userId == "" {
+		return false
+	}
+
+	isPremium, err := r.UserService.IsPremiumRunner(userId)
+	if err != nil && err != sql.ErrNoRows {
+		r.Logger.Error("unable to check premium runner", err)
+	}
+
+	return isPremium
+}
+
+// getUserSegments returns segments of user based on subscription status and recent run sessions
+func (r DiscoverContent) getUserSegments(userId string, isPremium bool) []string {
+	segments := make([]string, 0, 2)
+
+	// Determine subscription segment
+	if isPremium {
+		segments = append(segments, api.SegmentPremium)
+	} else {
+		segments = append(segments, api.SegmentFree)
+	}
+
+	// Determine run segment by distance within run window
+	now := time.Now()
+	distance, err := r.RunRepository.SumRunSessionDistance(userId, now.Add(-discoverRunWindow), now)
+	if err != nil {
+		r.Logger.Error("unable to sum run session distance", err)
+		distance = 0
+	}
+
+	switch {
+	case distance <= 0:
+		segments = append(segments, api.SegmentRunInactive)
+	case distance < discoverActiveRunDistance:
+		segments = append(segments, api.SegmentRunCasual)
+	default:
+		segments = append(segments, api.SegmentRunActive)
+	}
+
+	return segments
+}
+
+func (r DiscoverContent) validateContentReq(opt dto.DiscoverContentReq) error {
+	err := r.Validator.Struct(&opt)
+	if err != nil {
+		r.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Validate schedule
+	if opt.PublishAt > 0 && opt.UnpublishAt > 0 && opt.UnpublishAt <= opt.PublishAt {
+		return r.Errors.New("DSC003")
+	}
+
+	// Validate organization
+	isExist, err := r.DiscoverContentRepository.IsExistOrganization(opt.OrganizationId)
+	if err != nil {
+		r.Logger.Error("unable to check organization", err)
+		return err
+	}
+
+	if !isExist {
+		return r.Errors.New("ORG001")
+	}
+
+	return nil
+}
+
+// findTags returns names of tags as comma separated string. Returns DSC004 if a tag is not found
+func (r DiscoverContent) findTags(ids []int64) (sql.NullString, error) {
+	if len(ids) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	tags, err := r.DiscoverContentRepository.FindTags(ids)
+	if err != nil {
+		r.Logger.Error("unable to retrieve tags", err)
+		return sql.NullString{}, err
+	}
+
+	if len(tags) != len(ids) {
+		return sql.NullString{}, r.Errors.New("DSC004")
+	}
+
+	names := make([]string, len(tags))
+	for k, v := range tags {
+		names[k] = v.Name
+	}
+
+	return nsql.NullString(strings.Join(names, ",")), nil
+}
+
+func (r DiscoverContent) findById(id string) (*model.DiscoverContent, error) {
+	content, err := r.DiscoverContentRepository.FindById(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, r.Errors.New("DSC001")
+		}
+		r.Logger.Error("failed to FindById discover content", err)
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func (r DiscoverContent) update(oldContent model.DiscoverContent, content *model.DiscoverContent, version int,
+	modifiedBy dto.ModifierReq, changelog []string) error {
+	// Reject stale version before comparing changes
+	if oldContent.Version != version {
+		return r.Errors.New("DSC002")
+	}
+
+	content.Version = version + 1
+	content.UpdatedAt = time.Now()
+	content.ModifiedBy = newAdminModifier(modifiedBy)
+
+	return r.DiscoverContentRepository.Update(oldContent, *content, changelog)
+}
+
+func (r DiscoverContent) composeContentItem(c model.DiscoverContent) dto.DiscoverContentItem {
+	// Get full image url
+	imageUrls := dto.DiscoverImageFileResp{
+		Thumbnail:  r.AssetService.GetPublicUrl(api.AssetDiscoverContent, c.ImageFiles.Thumbnail),
+		DetailPage: r.AssetService.GetPublicUrl(api.AssetDiscoverContent, c.ImageFiles.DetailPage),
+	}
+
+	// Convert tags to array
+	var tags []string
+	if c.Tags.String == "" {
+		tags = []string{}
+	} else {
+		rawTags := strings.Trim(c.Tags.String, ",")
+		tags = strings.Split(rawTags, ",")
+	}
+
+	return dto.DiscoverContentItem{
+		Id:          c.Id,
+		Title:       c.Title,
+		ContentBody: c.ContentBody,
+		ExternalUrl: c.ExternalUrl,
+		StatusId:    c.StatusId,
+		Sort:        c.Sort,
+		CreatedAt:   c.CreatedAt.Unix(),
+		UpdatedAt:   c.UpdatedAt.Unix(),
+		Version:     c.Version,
+		ModifiedBy:  composeModifier(c.ModifiedBy),
+		ImageFiles:  imageUrls,
+		Headline:    c.Headline.String,
+		Tags:        tags,
+	}
+}
+
+func (r DiscoverContent) composeContentDetail(c model.DiscoverContent) *dto.DiscoverContentDetailResp {
+	// Ensure regions is serialized as array
+	regions := []string(c.AudienceRegions)
+	if regions == nil {
+		regions = []string{}
+	}
+
+	// Ensure tag ids is serialized as array
+	tagIds := []int64(c.TagIds)
+	if tagIds == nil {
+		tagIds = []int64{}
+	}
+
+	return &dto.DiscoverContentDetailResp{
+		DiscoverContentItem: r.composeContentItem(c),
+		OrganizationId:      c.OrganizationId,
+		TagIds:              tagIds,
+		LogoFile:            c.LogoFile.String,
+		ImageFileNames: dto.DiscoverImageFileReq{
+			Thumbnail:  c.ImageFiles.Thumbnail,
+			DetailPage: c.ImageFiles.DetailPage,
+		},
+		PublishAt:       pqx.NullTimeUnix(c.PublishAt),
+		UnpublishAt:     pqx.NullTimeUnix(c.UnpublishAt),
+		AudiencePremium: c.AudiencePremium,
+		AudienceRegions: regions,
+	}
+}
+
+// setDiscoverContent copies editable attributes from request to content
+func setDiscoverContent(content *model.DiscoverContent, opt dto.DiscoverContentReq) {
+	// Normalize regions to upper case country code
+	regions := make(pq.StringArray, len(opt.AudienceRegions))
+	for k, v := range opt.AudienceRegions {
+		regions[k] = strings.ToUpper(v)
+	}
+
+	content.OrganizationId = opt.OrganizationId
+	content.ContentBody = opt.ContentBody
+	content.LogoFile = nsql.NullString(opt.LogoFile)
+	content.ExternalUrl = opt.ExternalUrl
+	content.Sort = opt.Sort
+	content.ImageFiles = model.DiscoverImageFiles{
+		Thumbnail:  opt.ImageFiles.Thumbnail,
+		DetailPage: opt.ImageFiles.DetailPage,
+	}
+	content.Headline = nsql.NullString(opt.Headline)
+	content.TagIds = opt.TagIds
+	content.PublishAt = newScheduleTime(content.PublishAt, opt.PublishAt)
+	content.UnpublishAt = newScheduleTime(content.UnpublishAt, opt.UnpublishAt)
+	content.AudiencePremium = opt.AudiencePremium
+	content.AudienceRegions = regions
+}
+
+// newScheduleTime converts epoch to schedule time. Current value is kept if it refers to the same instant, so
+// unchanged schedule is not recorded in changelog
+func newScheduleTime(current pq.NullTime, epoch int64) pq.NullTime {
+	if epoch <= 0 {
+		return pq.NullTime{}
+	}
+
+	if current.Valid && current.Time.Unix() == epoch {
+		return current
+	}
+
+	return pq.NullTime{Time: time.Unix(epoch, 0), Valid: true}
+}
@@ -6,13 +6,25 @@ import (
 )
 
 type discoverContentStatements struct {
-	countContent *sqlx.Stmt
-	findContent  *sqlx.Stmt
+	countContent            *sqlx.Stmt
+	findById                *sqlx.Stmt
+	findContent             *sqlx.Stmt
+	findContents            *sqlx.Stmt
+	findPersonalizedContent *sqlx.Stmt
+	findTags                *sqlx.Stmt
+	insert                  *sqlx.NamedStmt
+	isExistOrganization     *sqlx.Stmt
 }
 
 func initDiscoverContentStatements(db *nsql.SqlDatabase) discoverContentStatements {
 	return discoverContentStatements{
-		countContent: db.Prepare(`SELECT COUNT(id) FROM discover_content WHERE status_id = 1`),
-		findContent:  db.Prepare(`SELECT discover_content.id,COALESCE(organization.name , '') as title,discover_content.content_body,discover_content.logo_file,discover_content.external_url,discover_content.status_id,discover_content.sort,discover_content.created_at,discover_content.updated_at,discover_content.version,discover_content.modified_by,discover_content.image_files,discover_content.tags,discover_content.headline,discover_content.image_files FROM discover_content LEFT JOIN organization on discover_content.organization_id = organization.id WHERE discover_content.status_id = 1 ORDER BY sort LIMIT $1 OFFSET $2`),
+		countContent:            db.Prepare(`SELECT COUNT(id) FROM discover_content WHERE status_id = 1 AND (publish_at IS NULL OR publish_at <= NOW()) AND (unpublish_at IS NULL OR unpublish_at > NOW()) AND (audience_premium = 0 OR audience_premium = $1) AND (COALESCE(CARDINALITY(audience_regions), 0) = 0 OR $2 = ANY(audience_regions))`),
+		findById:                db.Prepare(`SELECT discover_content.id,discover_content.organization_id,COALESCE(organization.name , '') as title,discover_content.content_body,discover_content.logo_file,discover_content.external_url,discover_content.status_id,discover_content.sort,discover_content.created_at,discover_content.updated_at,discover_content.version,discover_content.modified_by,discover_content.image_files,COALESCE((SELECT STRING_AGG(t.name, ',' ORDER BY t.name) FROM ad_tag AS t WHERE t.id = ANY(discover_content.tag_ids)), '') AS tags,discover_content.tag_ids,discover_content.headline,discover_content.publish_at,discover_content.unpublish_at,discover_content.audience_premium,discover_content.audience_regions FROM discover_content LEFT JOIN organization on discover_content.organization_id = organization.id WHERE discover_content.id = $1 AND discover_content.status_id <> 3`),
+		findContent:             db.Prepare(`SELECT discover_content.id,COALESCE(organization.name , '') as title,discover_content.content_body,discover_content.logo_file,discover_content.external_url,discover_content.status_id,discover_content.sort,discover_content.created_at,discover_content.updated_at,discover_content.version,discover_content.modified_by,discover_content.image_files,COALESCE((SELECT STRING_AGG(t.name, ',' ORDER BY t.name) FROM ad_tag AS t WHERE t.id = ANY(discover_content.tag_ids)), '') AS tags,discover_content.headline FROM discover_content LEFT JOIN organization on discover_content.organization_id = organization.id WHERE discover_content.status_id = 1 AND (discover_content.publish_at IS NULL OR discover_content.publish_at <= NOW()) AND (discover_content.unpublish_at IS NULL OR discover_content.unpublish_at > NOW()) AND (discover_content.audience_premium = 0 OR discover_content.audience_premium = $1) AND (COALESCE(CARDINALITY(discover_content.audience_regions), 0) = 0 OR $2 = ANY(discover_content.audience_regions)) ORDER BY sort LIMIT $3 OFFSET $4`),
+		findContents:            db.Prepare(`SELECT discover_content.id,discover_content.organization_id,COALESCE(organization.name , '') as title,discover_content.content_body,discover_content.logo_file,discover_content.external_url,discover_content.status_id,discover_content.sort,discover_content.created_at,discover_content.updated_at,discover_content.version,discover_content.modified_by,discover_content.image_files,COALESCE((SELECT STRING_AGG(t.name, ',' ORDER BY t.name) FROM ad_tag AS t WHERE t.id = ANY(discover_content.tag_ids)), '') AS tags,discover_content.tag_ids,discover_content.headline,discover_content.publish_at,discover_content.unpublish_at,discover_content.audience_premium,discover_content.audience_regions FROM discover_content LEFT JOIN organization on discover_content.organization_id = organization.id WHERE ($1::smallint = 0 AND discover_content.status_id <> 3 OR discover_content.status_id = $1) ORDER BY discover_content.sort, discover_content.created_at DESC LIMIT $2 OFFSET $3`),
+		findPersonalizedContent: db.Prepare(`SELECT discover_content.id,COALESCE(organization.name , '') as title,discover_content.content_body,discover_content.logo_file,discover_content.external_url,discover_content.status_id,discover_content.sort,discover_content.created_at,discover_content.updated_at,discover_content.version,discover_content.modified_by,discover_content.image_files,COALESCE((SELECT STRING_AGG(t.name, ',' ORDER BY t.name) FROM ad_tag AS t WHERE t.id = ANY(discover_content.tag_ids)), '') AS tags,discover_content.headline FROM discover_content LEFT JOIN organization on discover_content.organization_id = organization.id LEFT JOIN LATERAL (SELECT COUNT(f.user_id) AS followed, COUNT(*) FILTER (WHERE t.segment = ANY($2)) AS segmented FROM ad_tag AS t LEFT JOIN user_ad_tag AS f ON f.ad_tag_id = t.id AND f.user_id = $1 WHERE t.id = ANY(discover_content.tag_ids)) AS score ON true WHERE discover_content.status_id = 1 AND (discover_content.publish_at IS NULL OR discover_content.publish_at <= NOW()) AND (discover_content.unpublish_at IS NULL OR discover_content.unpublish_at > NOW()) AND (discover_content.audience_premium = 0 OR discover_content.audience_premium = $5) AND (COALESCE(CARDINALITY(discover_content.audience_regions), 0) = 0 OR $6 = ANY(discover_content.audience_regions)) ORDER BY score.followed * $3 + score.segmented * $4 DESC, discover_content.sort LIMIT $7 OFFSET $8`),
+		findTags:                db.Prepare(`SELECT id, name, segment, updated_at FROM ad_tag WHERE id = ANY($1) ORDER BY name`),
+		insert:                  db.PrepareNamed(`INSERT INTO discover_content(id, organization_id, content_body, logo_file, external_url, status_id, sort, created_at, updated_at, version, modified_by, image_files, tag_ids, headline, publish_at, unpublish_at, audience_premium, audience_regions) VALUES (:id, :organization_id, :content_body, :logo_file, :external_url, :status_id, :sort, :created_at, :updated_at, :version, :modified_by, :image_files, :tag_ids, :headline, :publish_at, :unpublish_at, :audience_premium, :audience_regions)`),
+		isExistOrganization:     db.Prepare(`SELECT EXISTS(SELECT 1 FROM organization WHERE id = $1)`),
 	}
 }
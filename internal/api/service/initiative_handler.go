@@ -1,12 +1,15 @@
 package service
 
 import (
+	"fmt"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nstr"
 	"github.com/gorilla/mux"
 	"net/http"
+	"net/url"
 )
 
 func NewInitiativeHandler(app *api.Api) InitiativeHandler {
@@ -27,10 +30,7 @@ func (h *InitiativeHandler) List(req *http.Request) (*nhttp.Success, error) {
 	skip, limit := api.Pagination(query)
 
 	// Call service
-	respBody, err := h.InitiativeService.List(dto.PageReq{
-		Skip:  skip,
-		Limit: limit,
-	})
+	respBody, err := h.InitiativeService.List(newInitiativeListReq(query, skip, limit))
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +41,16 @@ func (h *InitiativeHandler) List(req *http.Request) (*nhttp.Success, error) {
 	return &resp, nil
 }
 
+func (h *InitiativeHandler) ListTagFacets(req *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.InitiativeService.ListTagFacets(newInitiativeListReq(req.URL.Query(), 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
 func (h *InitiativeHandler) Donate(req *http.Request) (*nhttp.Success, error) {
 	// Parse request body
 	var reqBody dto.DonateReq
@@ -87,3 +97,196 @@ func (h *InitiativeHandler) ListUserDonation(req *http.Request) (*nhttp.Success,
 	}
 	return &resp, nil
 }
+
+func (h *InitiativeHandler) PutFundingGoal(req *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.InitiativeFundingReq
+	err := nhttp.ParseJSON(&reqBody, req)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set initiative id
+	reqBody.Id = mux.Vars(req)["id"]
+
+	// Call service
+	err = h.InitiativeService.UpdateFundingGoal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *InitiativeHandler) PutCloseExpired(_ *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.InitiativeService.CloseExpired()
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *InitiativeHandler) GetDonationReceipt(req *http.Request) (*nhttp.Success, error) {
+	reqBody := dto.DonationReceiptReq{
+		UserId:     req.Header.Get(nhttp.KeyUserId),
+		DonationId: mux.Vars(req)["id"],
+	}
+
+	// If requested in json format, return receipt detail
+	if req.URL.Query().Get("format") == "json" {
+		respBody, err := h.InitiativeService.GetDonationReceipt(reqBody)
+		if err != nil {
+			return nil, err
+		}
+
+		return &nhttp.Success{Result: respBody}, nil
+	}
+
+	// Call service
+	content, err := h.InitiativeService.ExportDonationReceipt(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send as pdf file
+	resp := nhttp.Success{
+		File: &nhttp.File{
+			Name:        fmt.Sprintf("RCPT-%s.pdf", reqBody.DonationId),
+			ContentType: nhttp.ContentTypePDF,
+			Content:     content,
+		},
+	}
+	return &resp, nil
+}
+
+func (h *InitiativeHandler) PostPledge(req *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.DonationPledgeReq
+	err := nhttp.ParseJSON(&reqBody, req)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set user id
+	reqBody.UserId = req.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	respBody, err := h.InitiativeService.CreatePledge(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *InitiativeHandler) ListPledges(req *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	skip, limit := api.Pagination(req.URL.Query())
+
+	// Call service
+	respBody, err := h.InitiativeService.ListPledges(dto.UserResourcesReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		UserId: req.Header.Get(nhttp.KeyUserId),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *InitiativeHandler) PutPausePledge(req *http.Request) (*nhttp.Success, error) {
+	return h.updatePledgeStatus(req, api.PledgePaused)
+}
+
+func (h *InitiativeHandler) PutResumePledge(req *http.Request) (*nhttp.Success, error) {
+	return h.updatePledgeStatus(req, api.PledgeActive)
+}
+
+func (h *InitiativeHandler) DeletePledge(req *http.Request) (*nhttp.Success, error) {
+	return h.updatePledgeStatus(req, api.PledgeCancelled)
+}
+
+func (h *InitiativeHandler) ListPledgeRuns(req *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	skip, limit := api.Pagination(req.URL.Query())
+
+	// Call service
+	respBody, err := h.InitiativeService.ListPledgeRuns(dto.DonationPledgeRunListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		UserId:   req.Header.Get(nhttp.KeyUserId),
+		PledgeId: mux.Vars(req)["id"],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *InitiativeHandler) PutRunPledges(_ *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.InitiativeService.RunPledges()
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *InitiativeHandler) updatePledgeStatus(req *http.Request, statusId int8) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.InitiativeService.UpdatePledgeStatus(dto.DonationPledgeStatusReq{
+		Id:       mux.Vars(req)["id"],
+		UserId:   req.Header.Get(nhttp.KeyUserId),
+		StatusId: statusId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *InitiativeHandler) PutRefundDonation(req *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.DonationRefundReq
+	err := nhttp.ParseJSON(&reqBody, req)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set donation id and admin
+	reqBody.Id = mux.Vars(req)["id"]
+	reqBody.ModifiedBy = newModifierReq(req)
+
+	// Call service
+	respBody, err := h.InitiativeService.RefundDonation(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func newInitiativeListReq(query url.Values, skip int64, limit int8) dto.InitiativeListReq {
+	return dto.InitiativeListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		Query:          query.Get("q"),
+		Tag:            query.Get("tag"),
+		OrganizationId: query.Get("organization_id"),
+		StatusId:       nstr.ParseInt8(query.Get("status_id"), 0),
+		SortBy:         query.Get("sort"),
+	}
+}
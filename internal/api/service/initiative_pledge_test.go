@@ -0,0 +1,94 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"testing"
+	"time"
+)
+
+func TestNextPledgeRunAt(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	cases := []struct {
+		name     string
+		periodId int8
+		t        time.Time
+		expected time.Time
+	}{
+		{"weekly from midweek", api.PledgeWeekly, time.Date(2020, 9, 16, 10, 0, 0, 0, time.UTC),
+			time.Date(2020, 9, 21, 0, 0, 0, 0, time.UTC)},
+		{"weekly from start of monday", api.PledgeWeekly, time.Date(2020, 9, 21, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 9, 28, 0, 0, 0, 0, time.UTC)},
+		{"weekly from sunday", api.PledgeWeekly, time.Date(2020, 9, 20, 23, 59, 0, 0, time.UTC),
+			time.Date(2020, 9, 21, 0, 0, 0, 0, time.UTC)},
+		{"weekly from local monday that is sunday in utc", api.PledgeWeekly, time.Date(2020, 9, 21, 2, 0, 0, 0, wib),
+			time.Date(2020, 9, 21, 0, 0, 0, 0, time.UTC)},
+		{"monthly from end of month", api.PledgeMonthly, time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC),
+			time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"monthly from start of month", api.PledgeMonthly, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"monthly across year", api.PledgeMonthly, time.Date(2020, 12, 15, 8, 0, 0, 0, time.UTC),
+			time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		actual := nextPledgeRunAt(c.periodId, c.t)
+		if !actual.Equal(c.expected) {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestPrevPledgeRunAt(t *testing.T) {
+	cases := []struct {
+		name     string
+		periodId int8
+		t        time.Time
+		expected time.Time
+	}{
+		{"weekly", api.PledgeWeekly, time.Date(2020, 9, 21, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 9, 14, 0, 0, 0, 0, time.UTC)},
+		{"monthly", api.PledgeMonthly, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"monthly across year", api.PledgeMonthly, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		actual := prevPledgeRunAt(c.periodId, c.t)
+		if !actual.Equal(c.expected) {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, actual)
+		}
+	}
+
+	// Previous run of next run must be the start of current period
+	now := time.Date(2020, 9, 16, 10, 0, 0, 0, time.UTC)
+	for _, periodId := range []int8{api.PledgeWeekly, api.PledgeMonthly} {
+		start := prevPledgeRunAt(periodId, nextPledgeRunAt(periodId, now))
+		if start.After(now) || !nextPledgeRunAt(periodId, start).After(now) {
+			t.Errorf("period %d: %s is not the start of period of %s", periodId, start, now)
+		}
+	}
+}
+
+func TestCalcPledgeAmount(t *testing.T) {
+	cases := []struct {
+		name         string
+		amountTypeId int8
+		amount       float64
+		earnedCredit float64
+		expected     float64
+	}{
+		{"fixed amount ignores earned credit", api.PledgeFixedAmount, 50, 200, 50},
+		{"percentage of earned credit", api.PledgePercentage, 10, 250, 25},
+		{"fractional percentage", api.PledgePercentage, 12.5, 80, 10},
+		{"full percentage", api.PledgePercentage, 100, 80, 80},
+		{"percentage without earned credit", api.PledgePercentage, 10, 0, 0},
+	}
+
+	for _, c := range cases {
+		actual := calcPledgeAmount(c.amountTypeId, c.amount, c.earnedCredit)
+		if actual != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"database/sql"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 func NewInitiativeRepository(db *nsql.SqlDatabase, idGen *api.SnowflakeGen, apiErrors *api.Errors, logger nlog.Logger) api.InitiativeRepository {
@@ -35,7 +38,19 @@ func (r *InitiativeRepository) FindDonationByUser(userId string, skip int64, lim
 	return rows, err
 }
 
-func (r *InitiativeRepository) UpdateDonation(oldDonation, newDonation model.Donation, changelog []string) error {
+func (r *InitiativeRepository) FindDonationById(id, userId string) (*model.Donation, error) {
+	var result model.Donation
+	err := r.Stmt.findDonationById.Get(&result, id, userId)
+	return &result, err
+}
+
+func (r *InitiativeRepository) FindDonationLocked(tx *sqlx.Tx, id string) (*model.Donation, error) {
+	var result model.Donation
+	err := nsql.StmtTx(r.Stmt.findDonationLocked, tx).Get(&result, id)
+	return &result, err
+}
+
+func (r *InitiativeRepository) UpdateDonation(tx *sqlx.Tx, oldDonation, newDonation model.Donation, changelog []string) (err error) {
 	// Get differ
 	differ := r.Differs.donation
 
@@ -69,15 +84,17 @@ func (r *InitiativeRepository) UpdateDonation(oldDonation, newDonation model.Don
 	updateQuery = r.Db.Conn.Rebind(updateQuery)
 	insertLogQuery = r.Db.Conn.Rebind(insertLogQuery)
 
-	// Begin transaction
-	trx, err := r.Db.Conn.Beginx()
-	if err != nil {
-		return err
+	// Begin transaction if it is not provided by caller
+	if tx == nil {
+		tx, err = r.Db.Conn.Beginx()
+		if err != nil {
+			return err
+		}
+		defer nsql.ReleaseTx(tx, &err, r.Logger)
 	}
-	defer nsql.ReleaseTx(trx, &err, r.Logger)
 
 	// Update Donation
-	result, err := trx.Exec(updateQuery, updateArgs...)
+	result, err := tx.Exec(updateQuery, updateArgs...)
 	if err != nil {
 		r.Logger.Error("failed to insert donation", err)
 		return err
@@ -97,7 +114,7 @@ func (r *InitiativeRepository) UpdateDonation(oldDonation, newDonation model.Don
 	}
 
 	// Insert Log
-	_, err = trx.Exec(insertLogQuery, insertLogArgs...)
+	_, err = tx.Exec(insertLogQuery, insertLogArgs...)
 	if err != nil {
 		r.Logger.Error("failed to insert donation_log", err)
 		return err
@@ -108,23 +125,32 @@ func (r *InitiativeRepository) UpdateDonation(oldDonation, newDonation model.Don
 	return nil
 }
 
-func (r *InitiativeRepository) Insert(donation model.Donation, donationLog model.DonationLog) error {
-	// Begin transaction
-	trx, err := r.Db.Conn.Beginx()
-	if err != nil {
-		return err
+// HasDonationPayout checks whether organization payout has been generated for the period of donation
+func (r *InitiativeRepository) HasDonationPayout(tx *sqlx.Tx, initiativeId string, createdAt time.Time) (bool, error) {
+	var isExist bool
+	err := nsql.StmtTx(r.Stmt.hasDonationPayout, tx).Get(&isExist, initiativeId, createdAt)
+	return isExist, err
+}
+
+func (r *InitiativeRepository) Insert(tx *sqlx.Tx, donation model.Donation, donationLog model.DonationLog) (err error) {
+	// Begin transaction if it is not provided by caller
+	if tx == nil {
+		tx, err = r.Db.Conn.Beginx()
+		if err != nil {
+			return err
+		}
+		defer nsql.ReleaseTx(tx, &err, r.Logger)
 	}
-	defer nsql.ReleaseTx(trx, &err, r.Logger)
 
 	// Insert donation
-	_, err = trx.NamedStmt(r.Stmt.insertDonation).Exec(&donation)
+	_, err = tx.NamedStmt(r.Stmt.insertDonation).Exec(&donation)
 	if err != nil {
 		r.Logger.Error("failed to insert donation", err)
 		return err
 	}
 
 	// Insert donation log
-	_, err = trx.NamedStmt(r.Stmt.insertDonationLog).Exec(&donationLog)
+	_, err = tx.NamedStmt(r.Stmt.insertDonationLog).Exec(&donationLog)
 	if err != nil {
 		r.Logger.Error("failed to insert donation_log", err)
 		return err
@@ -139,8 +165,165 @@ func (r *InitiativeRepository) FindById(id string) (*model.Initiative, error) {
 	return &result, err
 }
 
-func (r *InitiativeRepository) FindActive(skip int64, limit int8) ([]model.Initiative, error) {
+func (r *InitiativeRepository) FindInitiatives(filter model.InitiativeFilter, skip int64, limit int8) ([]model.Initiative, error) {
 	result := make([]model.Initiative, 0)
-	err := r.Stmt.findActive.Select(&result, limit, skip)
+	err := r.Stmt.findInitiatives.Select(&result, filter.StatusId, filter.Query, filter.OrganizationId, filter.Tag,
+		filter.SortBy, limit, skip)
+	return result, err
+}
+
+func (r *InitiativeRepository) FindTagFacets(filter model.InitiativeFilter) ([]model.InitiativeTagFacet, error) {
+	result := make([]model.InitiativeTagFacet, 0)
+	err := r.Stmt.findTagFacets.Select(&result, filter.StatusId, filter.Query, filter.OrganizationId)
 	return result, err
 }
+
+func (r *InitiativeRepository) AddDonationStat(tx *sqlx.Tx, id string, amount float64, timestamp time.Time) (int8, error) {
+	var statusId int8
+	err := nsql.StmtTx(r.Stmt.addDonationStat, tx).Get(&statusId, id, amount, timestamp)
+	return statusId, err
+}
+
+// SubtractDonationStat reverts donation stat of initiative. Initiative that was closed by reaching its funding goal is
+// reopened if the goal is no longer reached before deadline. Initiative that reached its goal and was then closed for
+// another reason cannot be told apart, so it is reopened as well
+func (r *InitiativeRepository) SubtractDonationStat(tx *sqlx.Tx, id string, amount float64, timestamp time.Time) error {
+	_, err := nsql.StmtTx(r.Stmt.subDonationStat, tx).Exec(id, amount, timestamp)
+	if err != nil {
+		r.Logger.Error("failed to subtract initiative donation stat", err)
+	}
+	return err
+}
+
+func (r *InitiativeRepository) CloseExpired(timestamp time.Time) (int64, error) {
+	// Close initiatives that passed its deadline
+	result, err := r.Stmt.closeExpired.Exec(timestamp)
+	if err != nil {
+		r.Logger.Error("failed to close expired initiatives", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *InitiativeRepository) UpdateFundingGoal(initiative *model.Initiative, timestamp time.Time) error {
+	// Update funding goal
+	result, err := r.Stmt.updateFundingGoal.Exec(initiative.Id, initiative.FundingGoal, initiative.DeadlineAt,
+		timestamp, initiative.Version)
+	if err != nil {
+		r.Logger.Error("failed to update initiative funding goal", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		r.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		r.Logger.Errorf("no initiative update affected")
+		return r.Errors.New("INT006")
+	}
+
+	return nil
+}
+
+func (r *InitiativeRepository) FindDuePledges(timestamp time.Time, cursor string, limit int) ([]model.DonationPledge, error) {
+	rows := make([]model.DonationPledge, 0)
+	err := r.Stmt.findDuePledges.Select(&rows, timestamp, cursor, limit)
+	return rows, err
+}
+
+func (r *InitiativeRepository) FindActivePledge(id string) (*model.DonationPledge, error) {
+	var result model.DonationPledge
+	err := r.Stmt.findActivePledge.Get(&result, id)
+	return &result, err
+}
+
+func (r *InitiativeRepository) FindPledgeById(id, userId string) (*model.DonationPledge, error) {
+	var result model.DonationPledge
+	err := r.Stmt.findPledgeById.Get(&result, id, userId)
+	return &result, err
+}
+
+func (r *InitiativeRepository) FindPledgesByUser(userId string, skip int64, limit int8) ([]model.DonationPledge, error) {
+	rows := make([]model.DonationPledge, 0)
+	err := r.Stmt.findPledgesByUser.Select(&rows, userId, limit, skip)
+	return rows, err
+}
+
+func (r *InitiativeRepository) FindPledgeRuns(pledgeId string, skip int64, limit int8) ([]model.DonationPledgeRun, error) {
+	rows := make([]model.DonationPledgeRun, 0)
+	err := r.Stmt.findPledgeRuns.Select(&rows, pledgeId, limit, skip)
+	return rows, err
+}
+
+func (r *InitiativeRepository) FindRetryPledgeRuns(updatedBefore time.Time, maxAttempts int, cursor string, limit int) (
+	[]model.DonationPledgeRun, error) {
+	rows := make([]model.DonationPledgeRun, 0)
+	err := r.Stmt.findRetryRuns.Select(&rows, updatedBefore, maxAttempts, cursor, limit)
+	return rows, err
+}
+
+func (r *InitiativeRepository) InsertPledge(pledge *model.DonationPledge) error {
+	_, err := r.Stmt.insertPledge.Exec(pledge)
+	if err != nil {
+		r.Logger.Error("failed to insert donation_pledge", err)
+	}
+	return err
+}
+
+func (r *InitiativeRepository) InsertPledgeRun(tx *sqlx.Tx, run *model.DonationPledgeRun) error {
+	_, err := nsql.NamedStmtTx(r.Stmt.insertPledgeRun, tx).Exec(run)
+	if err != nil {
+		r.Logger.Error("failed to insert donation_pledge_run", err)
+	}
+	return err
+}
+
+func (r *InitiativeRepository) UpdatePledge(tx *sqlx.Tx, pledge *model.DonationPledge) error {
+	// Update pledge
+	result, err := nsql.NamedStmtTx(r.Stmt.updatePledge, tx).Exec(pledge)
+	if err != nil {
+		r.Logger.Error("failed to update donation_pledge", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		r.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		r.Logger.Errorf("no donation pledge update affected")
+		return r.Errors.New("INT009")
+	}
+
+	return nil
+}
+
+func (r *InitiativeRepository) UpdatePledgeRun(tx *sqlx.Tx, run *model.DonationPledgeRun) error {
+	// Update pledge run
+	result, err := nsql.NamedStmtTx(r.Stmt.updatePledgeRun, tx).Exec(run)
+	if err != nil {
+		r.Logger.Error("failed to update donation_pledge_run", err)
+		return err
+	}
+
+	// Check for affected rows. If run has been updated concurrently, then no rows will be updated
+	count, err := result.RowsAffected()
+	if err != nil {
+		r.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
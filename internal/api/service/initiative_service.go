@@ -3,25 +3,44 @@ package service
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nmailgun"
+	"github.com/diarikom/running-app/running-app-api/pkg/npdf"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
 	validate "github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"math"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// pledgeBatchSize is the number of due pledges fetched on each pledge run iteration
+const pledgeBatchSize = 100
+
+// pledgeRetryDelay is the minimum time before a failed or interrupted pledge run is retried
+const pledgeRetryDelay = time.Hour
+
+// pledgeMaxAttempts is the number of donation attempts of a pledge run before it is left as failed
+const pledgeMaxAttempts = 3
+
 type Initiative struct {
 	IdGen         *api.SnowflakeGen
 	Errors        *api.Errors
 	Logger        nlog.Logger
+	Db            *nsql.SqlDatabase
 	Repository    api.InitiativeRepository
 	AssetService  api.AssetService
 	CreditService api.CreditService
 	UserService   api.UserService
+	Mailer        api.MailerComponent
 	Validator     *validate.Validate
 }
 
@@ -30,11 +49,26 @@ func (s *Initiative) Init(app *api.Api) error {
 	s.IdGen = app.Components.Id
 	s.Errors = app.Components.Errors
 	s.Logger = app.Logger
+	s.Db = app.Datasources.Db
 	s.Repository = NewInitiativeRepository(app.Datasources.Db, app.Components.Id, app.Components.Errors, app.Logger)
 	s.AssetService = app.Services.Asset
 	s.CreditService = app.Services.Credit
 	s.UserService = app.Services.User
+	s.Mailer = app.Components.Mailer
 	s.Validator = validate.New()
+
+	// Start initiative closing scheduler
+	closeInterval := app.Config.GetInt(api.ConfInitiativeCloseInterval)
+	if closeInterval > 0 {
+		go s.runCloseScheduler(time.Duration(closeInterval) * time.Minute)
+	}
+
+	// Start donation pledge scheduler
+	pledgeInterval := app.Config.GetInt(api.ConfInitiativePledgeInterval)
+	if pledgeInterval > 0 {
+		go s.runPledgeScheduler(time.Duration(pledgeInterval) * time.Minute)
+	}
+
 	return nil
 }
 
@@ -89,16 +123,65 @@ func (s *Initiative) Donate(opt dto.DonateReq) (*dto.DonateResp, error) {
 		return nil, err
 	}
 
+	// Check if initiative accepts donation
+	err = s.checkDonatable(initiative)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create, charge and settle donation in a single transaction
+	var donation *model.Donation
+	err = nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		donation, err = s.donate(tx, initiative, opt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Send donation receipt in background
+	go s.sendDonationReceipt(donation)
+
+	balance, err := s.CreditService.GetUserBalance(opt.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.DonateResp{
+		Balance:       balance.Balance,
+		TotalDonation: donation.TotalPrice,
+	}
+	return &resp, nil
+}
+
+// checkDonatable checks if initiative is active and has not reached its funding goal or deadline
+func (s *Initiative) checkDonatable(initiative *model.Initiative) error {
 	// Check if initiative is active
-	if initiative.StatusId != api.ActiveInitiative {
-		return nil, s.Errors.New("INT001")
+	switch initiative.StatusId {
+	case api.ActiveInitiative:
+		break
+	case api.ClosedInitiative:
+		return s.Errors.New("INT004")
+	default:
+		return s.Errors.New("INT001")
+	}
+
+	// Check if funding goal or deadline has been reached
+	if isInitiativeEnded(initiative, time.Now()) {
+		return s.Errors.New("INT004")
 	}
 
+	return nil
+}
+
+// donate creates, charges and settles donation in tx
+func (s *Initiative) donate(tx *sqlx.Tx, initiative *model.Initiative, opt dto.DonateReq) (*model.Donation, error) {
 	// Calculate total donation
 	totalDonation := initiative.Price * float64(opt.Quantity)
 
 	// Check if balance is enough
 	walletVersion, err := s.CreditService.CheckChargeAmount(dto.CreditChargeOpt{
+		Tx:     tx,
 		UserId: opt.UserId,
 		Amount: totalDonation,
 	})
@@ -107,41 +190,50 @@ func (s *Initiative) Donate(opt dto.DonateReq) (*dto.DonateResp, error) {
 	}
 
 	// Insert donation
-	donation, err := s.createDonation(initiative, totalDonation, opt)
+	donation, err := s.createDonation(tx, initiative, totalDonation, opt)
 	if err != nil {
 		return nil, err
 	}
 
 	// Charge donation
-	err = s.chargeDonation(donation, walletVersion)
+	err = s.chargeDonation(tx, donation, walletVersion)
 	if err != nil {
 		return nil, err
 	}
 
 	// Settle Pending Transaction
 	err = s.CreditService.SettlePendingTrx(dto.CreditSettleOpt{
+		Tx:    tx,
 		TrxId: donation.PaymentTrxRef.String,
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Update donation status to success
-	err = s.updateDonationSuccess(donation)
+	err = s.updateDonationSuccess(tx, donation)
 	if err != nil {
 		return nil, err
 	}
 
-	balance, err := s.CreditService.GetUserBalance(opt.UserId)
+	// Add raised amount to initiative. If initiative has been closed, then refuse donation
+	statusId, err := s.Repository.AddDonationStat(tx, initiative.Id, totalDonation, time.Now())
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("INT004")
+		}
+		s.Logger.Error("unable to update initiative donation stat", err)
 		return nil, err
 	}
 
-	resp := dto.DonateResp{
-		Balance:       balance.Balance,
-		TotalDonation: totalDonation,
+	if statusId == api.ClosedInitiative {
+		s.Logger.Debugf("Initiative funding goal has been reached. InitiativeId = %s", initiative.Id)
 	}
-	return &resp, nil
+
+	return donation, nil
 }
 
-func (s *Initiative) createDonation(initiative *model.Initiative, totalDonation float64, opt dto.DonateReq) (
+func (s *Initiative) createDonation(tx *sqlx.Tx, initiative *model.Initiative, totalDonation float64, opt dto.DonateReq) (
 	*model.Donation, error) {
 	// Get user snapshot
 	userSnapshot, err := s.UserService.GetProfileSnapshot(opt.UserId)
@@ -197,7 +289,7 @@ func (s *Initiative) createDonation(initiative *model.Initiative, totalDonation
 		Version:         1,
 	}
 
-	err = s.Repository.Insert(donation, donationLog)
+	err = s.Repository.Insert(tx, donation, donationLog)
 	if err != nil {
 		return nil, err
 	}
@@ -205,9 +297,10 @@ func (s *Initiative) createDonation(initiative *model.Initiative, totalDonation
 	return &donation, nil
 }
 
-func (s *Initiative) chargeDonation(donation *model.Donation, walletVersion int) error {
+func (s *Initiative) chargeDonation(tx *sqlx.Tx, donation *model.Donation, walletVersion int) error {
 	// Charge transaction
 	trxId, err := s.CreditService.Charge(dto.CreditChargeOpt{
+		Tx:            tx,
 		UserId:        donation.UserId,
 		Amount:        donation.TotalPrice,
 		WalletVersion: walletVersion,
@@ -242,7 +335,7 @@ func (s *Initiative) chargeDonation(donation *model.Donation, walletVersion int)
 	}
 
 	// Update donation
-	err = s.Repository.UpdateDonation(*oldDonation, *donation, changelog)
+	err = s.Repository.UpdateDonation(tx, *oldDonation, *donation, changelog)
 	if err != nil {
 		return err
 	}
@@ -250,7 +343,7 @@ func (s *Initiative) chargeDonation(donation *model.Donation, walletVersion int)
 	return nil
 }
 
-func (s *Initiative) updateDonationSuccess(donation *model.Donation) error {
+func (s *Initiative) updateDonationSuccess(tx *sqlx.Tx, donation *model.Donation) error {
 	// Duplicate donation
 	oldDonation, err := model.CopyDonation(donation)
 	if err != nil {
@@ -275,7 +368,154 @@ func (s *Initiative) updateDonationSuccess(donation *model.Donation) error {
 	}
 
 	// Update donation
-	err = s.Repository.UpdateDonation(*oldDonation, *donation, changelog)
+	err = s.Repository.UpdateDonation(tx, *oldDonation, *donation, changelog)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Initiative) RefundDonation(opt dto.DonationRefundReq) (*dto.DonationRefundResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Create modifier meta
+	modifiedBy := model.ModifierMeta{
+		Id:       opt.ModifiedBy.Id,
+		Role:     api.ModifierAdmin,
+		FullName: opt.ModifiedBy.FullName,
+	}
+
+	// Reverse charge, update donation and initiative stat in a single transaction
+	timestamp := time.Now()
+	var donation *model.Donation
+	var refundedAmount float64
+	err = nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		// Get and lock donation
+		var err error
+		donation, err = s.Repository.FindDonationLocked(tx, opt.Id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return s.Errors.New("INT011")
+			}
+			s.Logger.Error("failed to FindDonationLocked", err)
+			return err
+		}
+
+		// Check donation status. Donation that has not reached success state may still hold a charge,
+		// e.g. credit has been charged but donation failed to be marked as success
+		var isPaid bool
+		switch donation.StatusId {
+		case api.DonationPaymentOK:
+			isPaid = true
+		case api.DonationCreated, api.DonationPaymentPending:
+			break
+		default:
+			return s.Errors.New("INT012")
+		}
+
+		// Donation that has been included in organization payout cannot be refunded, since credit has been paid out.
+		// Payout generated concurrently with refund may still include refunded donation
+		if isPaid {
+			hasPayout, err := s.Repository.HasDonationPayout(tx, donation.InitiativeId, donation.CreatedAt)
+			if err != nil {
+				s.Logger.Error("failed to HasDonationPayout", err)
+				return err
+			}
+			if hasPayout {
+				return s.Errors.New("INT013")
+			}
+		}
+
+		// Reverse credit charge. If charge has not been settled, donation is cancelled instead of refunded
+		var statusId int8 = api.DonationCancelled
+		refund := model.DonationRefund{
+			Reason:     opt.Reason,
+			RefundedAt: timestamp.Unix(),
+		}
+		if donation.PaymentTrxRef.Valid {
+			refundTrx, err := s.CreditService.RefundTrx(dto.CreditRefundOpt{
+				Tx:        tx,
+				TrxId:     donation.PaymentTrxRef.String,
+				Notes:     fmt.Sprintf("Refund of donation %s", donation.Id),
+				Timestamp: &timestamp,
+			})
+			if err != nil {
+				return err
+			}
+
+			refund.TrxId = refundTrx.Id
+			if refundTrx.Status == api.TrxSuccess {
+				statusId = api.DonationRefunded
+				refundedAmount = refundTrx.Amount
+			}
+		}
+
+		// Update donation status
+		err = s.updateDonationRefund(tx, donation, statusId, &refund, &modifiedBy, timestamp)
+		if err != nil {
+			return err
+		}
+
+		// Revert initiative stat. Stat is only added when donation is succeeded
+		if isPaid {
+			err = s.Repository.SubtractDonationStat(tx, donation.InitiativeId, donation.TotalPrice, timestamp)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.DonationRefundResp{
+		Id:             donation.Id,
+		StatusId:       donation.StatusId,
+		RefundedAmount: refundedAmount,
+		UpdatedAt:      donation.UpdatedAt.Unix(),
+	}
+	return &resp, nil
+}
+
+func (s *Initiative) updateDonationRefund(tx *sqlx.Tx, donation *model.Donation, statusId int8, refund *model.DonationRefund,
+	modifiedBy *model.ModifierMeta, timestamp time.Time) error {
+	// Duplicate donation
+	oldDonation, err := model.CopyDonation(donation)
+	if err != nil {
+		s.Logger.Error("failed to duplicate donation for changelog", err)
+		return err
+	}
+
+	// Record refund in payment snapshot
+	paymentSnapshot, err := json.Marshal(refund)
+	if err != nil {
+		s.Logger.Error("failed to marshal donation refund", err)
+		return err
+	}
+
+	// Update donation
+	donation.StatusId = statusId
+	donation.PaymentSnapshot = paymentSnapshot
+	donation.UpdatedAt = timestamp
+	donation.ModifiedBy = modifiedBy
+	donation.Version = oldDonation.Version + 1
+
+	// Create changelog
+	changelog := []string{
+		"status_id",
+		"payment_snapshot",
+	}
+
+	// Update donation
+	err = s.Repository.UpdateDonation(tx, *oldDonation, *donation, changelog)
 	if err != nil {
 		return err
 	}
@@ -283,9 +523,84 @@ func (s *Initiative) updateDonationSuccess(donation *model.Donation) error {
 	return nil
 }
 
-func (s *Initiative) List(opt dto.PageReq) ([]dto.InitiativeResp, error) {
+func (s *Initiative) GetDonationReceipt(opt dto.DonationReceiptReq) (*dto.DonationReceiptResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get donation
+	donation, err := s.Repository.FindDonationById(opt.DonationId, opt.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("INT007")
+		}
+		s.Logger.Error("failed to FindDonationById", err)
+		return nil, err
+	}
+
+	// Receipt is only available for paid donation
+	if donation.StatusId != api.DonationPaymentOK {
+		return nil, s.Errors.New("INT007")
+	}
+
+	return composeDonationReceipt(donation), nil
+}
+
+func (s *Initiative) ExportDonationReceipt(opt dto.DonationReceiptReq) ([]byte, error) {
+	// Get receipt
+	receipt, err := s.GetDonationReceipt(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderDonationReceipt(receipt), nil
+}
+
+func (s *Initiative) sendDonationReceipt(donation *model.Donation) {
+	// Compose receipt
+	receipt := composeDonationReceipt(donation)
+
+	// Send receipt email with pdf attachment
+	err := s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{receipt.DonorEmail},
+		Subject:      "Running App - Donation Receipt " + receipt.ReceiptNo,
+		TemplateFile: "donation_receipt.html",
+		TemplateData: struct {
+			*dto.DonationReceiptResp
+			DonatedAtStr     string
+			ItemPriceStr     string
+			TotalDonationStr string
+		}{
+			DonationReceiptResp: receipt,
+			DonatedAtStr:        formatReceiptDate(receipt.DonatedAt),
+			ItemPriceStr:        formatReceiptAmount(receipt.ItemPrice, receipt.CurrencyName),
+			TotalDonationStr:    formatReceiptAmount(receipt.TotalDonation, receipt.CurrencyName),
+		},
+		Attachments: []nmailgun.Attachment{
+			{
+				Filename: receipt.ReceiptNo + ".pdf",
+				Content:  renderDonationReceipt(receipt),
+			},
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send donation receipt email", err)
+	}
+}
+
+func (s *Initiative) List(opt dto.InitiativeListReq) ([]dto.InitiativeResp, error) {
+	// Compose filter
+	filter, err := s.newInitiativeFilter(opt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get initiative list
-	initiativeList, err := s.Repository.FindActive(opt.Skip, opt.Limit)
+	initiativeList, err := s.Repository.FindInitiatives(filter, opt.Skip, opt.Limit)
 	if err != nil {
 		s.Logger.Error("unable to retrieve initiative list", err)
 		return nil, err
@@ -304,6 +619,12 @@ func (s *Initiative) List(opt dto.PageReq) ([]dto.InitiativeResp, error) {
 		rawTags := strings.Trim(v.Tags, ",")
 		tags := strings.Split(rawTags, ",")
 
+		// Determine deadline
+		var deadlineAt int64
+		if v.DeadlineAt.Valid {
+			deadlineAt = v.DeadlineAt.Time.Unix()
+		}
+
 		// Set response
 		resp[k] = dto.InitiativeResp{
 			Id:                 v.Id,
@@ -319,7 +640,778 @@ func (s *Initiative) List(opt dto.PageReq) ([]dto.InitiativeResp, error) {
 			UpdatedAt:          v.UpdatedAt.Unix(),
 			Version:            v.Version,
 			Headline:           v.Headline.String,
+			StatusId:           v.StatusId,
+			FundingGoal:        v.FundingGoal,
+			RaisedAmount:       v.RaisedAmount,
+			Progress:           calcFundingProgress(v.RaisedAmount, v.FundingGoal),
+			DonationCount:      v.StatDonationCount,
+			DeadlineAt:         deadlineAt,
+		}
+	}
+	return resp, nil
+}
+
+func (s *Initiative) ListTagFacets(opt dto.InitiativeListReq) ([]dto.InitiativeTagFacetResp, error) {
+	// Compose filter
+	filter, err := s.newInitiativeFilter(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get tag facets. Tag filter is ignored, so other tags are still countable when a tag is selected
+	rows, err := s.Repository.FindTagFacets(filter)
+	if err != nil {
+		s.Logger.Error("unable to retrieve initiative tag facets", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.InitiativeTagFacetResp, len(rows))
+	for k, v := range rows {
+		resp[k] = dto.InitiativeTagFacetResp{
+			Tag:   v.Tag,
+			Count: v.Count,
 		}
 	}
+
 	return resp, nil
 }
+
+func (s *Initiative) newInitiativeFilter(opt dto.InitiativeListReq) (model.InitiativeFilter, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return model.InitiativeFilter{}, nhttp.ErrBadRequest
+	}
+
+	// Compose filter, by default only active initiatives are listed
+	filter := model.InitiativeFilter{
+		Query:          strings.TrimSpace(opt.Query),
+		Tag:            strings.TrimSpace(opt.Tag),
+		OrganizationId: opt.OrganizationId,
+		StatusId:       opt.StatusId,
+		SortBy:         opt.SortBy,
+	}
+	if filter.StatusId == 0 {
+		filter.StatusId = api.ActiveInitiative
+	}
+
+	// If searching without sort option, sort by relevance
+	if filter.Query != "" && filter.SortBy == "" {
+		filter.SortBy = api.InitiativeSortRelevance
+	}
+
+	return filter, nil
+}
+
+func (s *Initiative) UpdateFundingGoal(opt dto.InitiativeFundingReq) error {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Get initiative
+	initiative, err := s.Repository.FindById(opt.Id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return s.Errors.New("INT003")
+		}
+		s.Logger.Error("failed to FindById initiative", err)
+		return err
+	}
+
+	// Validate deadline
+	timestamp := time.Now()
+	var deadlineAt pq.NullTime
+	if opt.DeadlineAt > 0 {
+		deadlineAt = pq.NullTime{Time: time.Unix(opt.DeadlineAt, 0), Valid: true}
+		if !deadlineAt.Time.After(timestamp) {
+			return s.Errors.New("INT005")
+		}
+	}
+
+	// Update initiative
+	initiative.FundingGoal = opt.FundingGoal
+	initiative.DeadlineAt = deadlineAt
+	initiative.Version = opt.Version
+
+	err = s.Repository.UpdateFundingGoal(initiative, timestamp)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Initiative) CloseExpired() (*dto.InitiativeCloseResp, error) {
+	// Close initiatives that passed its deadline
+	count, err := s.Repository.CloseExpired(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Debugf("Initiative closing done. Closed = %d", count)
+
+	return &dto.InitiativeCloseResp{Closed: count}, nil
+}
+
+func (s *Initiative) runCloseScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.CloseExpired()
+		if err != nil {
+			s.Logger.Error("failed to close expired initiatives", err)
+		}
+	}
+}
+
+func (s *Initiative) CreatePledge(opt dto.DonationPledgeReq) (*dto.DonationPledgeResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Percentage pledge cannot exceed earned credits
+	if opt.AmountTypeId == api.PledgePercentage && opt.Amount > 100 {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get initiative
+	initiative, err := s.Repository.FindById(opt.InitiativeId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("INT003")
+		}
+		s.Logger.Error("failed to FindById initiative", err)
+		return nil, err
+	}
+
+	// Check if initiative is still accepting donation
+	timestamp := time.Now()
+	switch {
+	case initiative.StatusId == api.ClosedInitiative || isInitiativeEnded(initiative, timestamp):
+		return nil, s.Errors.New("INT004")
+	case initiative.StatusId != api.ActiveInitiative:
+		return nil, s.Errors.New("INT001")
+	}
+
+	// Create pledge
+	pledge := model.DonationPledge{
+		Id:           s.IdGen.New(),
+		UserId:       opt.UserId,
+		InitiativeId: initiative.Id,
+		AmountTypeId: opt.AmountTypeId,
+		Amount:       opt.Amount,
+		PeriodId:     opt.PeriodId,
+		StatusId:     api.PledgeActive,
+		Notes:        nsql.NullString(opt.Notes),
+		NextRunAt:    nextPledgeRunAt(opt.PeriodId, timestamp),
+		CreatedAt:    timestamp,
+		UpdatedAt:    timestamp,
+		Version:      1,
+	}
+
+	err = s.Repository.InsertPledge(&pledge)
+	if err != nil {
+		return nil, err
+	}
+
+	return composePledge(&pledge), nil
+}
+
+func (s *Initiative) ListPledges(opt dto.UserResourcesReq) ([]dto.DonationPledgeResp, error) {
+	// Get user pledges
+	rows, err := s.Repository.FindPledgesByUser(opt.UserId, opt.Skip, opt.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve donation pledges", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.DonationPledgeResp, len(rows))
+	for k := range rows {
+		resp[k] = *composePledge(&rows[k])
+	}
+
+	return resp, nil
+}
+
+func (s *Initiative) UpdatePledgeStatus(opt dto.DonationPledgeStatusReq) (*dto.DonationPledgeResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get pledge
+	pledge, err := s.getPledge(opt.Id, opt.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cancelled pledge cannot be changed
+	if pledge.StatusId == api.PledgeCancelled {
+		return nil, s.Errors.New("INT010")
+	}
+
+	// If status is not changed, return current pledge
+	if pledge.StatusId == opt.StatusId {
+		return composePledge(pledge), nil
+	}
+
+	// Resumed pledge will be executed on the next period, missed periods are not executed
+	timestamp := time.Now()
+	if opt.StatusId == api.PledgeActive {
+		pledge.NextRunAt = nextPledgeRunAt(pledge.PeriodId, timestamp)
+	}
+
+	// Update pledge
+	pledge.StatusId = opt.StatusId
+	pledge.UpdatedAt = timestamp
+	pledge.CurrentVersion = pledge.Version
+	pledge.Version++
+
+	err = s.Repository.UpdatePledge(nil, pledge)
+	if err != nil {
+		return nil, err
+	}
+
+	return composePledge(pledge), nil
+}
+
+func (s *Initiative) ListPledgeRuns(opt dto.DonationPledgeRunListReq) ([]dto.DonationPledgeRunResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Make sure pledge is owned by user
+	pledge, err := s.getPledge(opt.PledgeId, opt.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get pledge runs
+	rows, err := s.Repository.FindPledgeRuns(pledge.Id, opt.Skip, opt.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve donation pledge runs", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.DonationPledgeRunResp, len(rows))
+	for k, v := range rows {
+		resp[k] = dto.DonationPledgeRunResp{
+			Id:            v.Id,
+			PeriodStart:   v.PeriodStart.Unix(),
+			PeriodEnd:     v.PeriodEnd.Unix(),
+			EarnedCredit:  v.EarnedCredit,
+			Amount:        v.Amount,
+			Quantity:      v.Quantity,
+			TotalDonation: v.TotalDonation,
+			StatusId:      v.StatusId,
+			Reason:        v.Reason.String,
+			CreatedAt:     v.CreatedAt.Unix(),
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *Initiative) RunPledges() (*dto.DonationPledgeExecResp, error) {
+	timestamp := time.Now()
+
+	var resp dto.DonationPledgeExecResp
+
+	// Execute due pledges
+	cursor := "0"
+	for {
+		rows, err := s.Repository.FindDuePledges(timestamp, cursor, pledgeBatchSize)
+		if err != nil {
+			s.Logger.Error("unable to retrieve due donation pledges", err)
+			return nil, err
+		}
+
+		for k := range rows {
+			pledge := &rows[k]
+			cursor = pledge.Id
+
+			run, err := s.executePledge(pledge, timestamp)
+			if err != nil {
+				s.Logger.Errorf("unable to execute donation pledge. PledgeId = %s, Error = %s", pledge.Id, err)
+				continue
+			}
+
+			countPledgeRun(&resp, run)
+		}
+
+		if len(rows) < pledgeBatchSize {
+			break
+		}
+	}
+
+	// Retry runs that have failed or were interrupted before their result was recorded
+	cursor = "0"
+	for {
+		rows, err := s.Repository.FindRetryPledgeRuns(timestamp.Add(-pledgeRetryDelay), pledgeMaxAttempts, cursor,
+			pledgeBatchSize)
+		if err != nil {
+			s.Logger.Error("unable to retrieve donation pledge runs to retry", err)
+			return nil, err
+		}
+
+		for k := range rows {
+			run := &rows[k]
+			cursor = run.Id
+
+			result, err := s.retryPledgeRun(run, timestamp)
+			if err != nil {
+				s.Logger.Errorf("unable to retry donation pledge run. RunId = %s, Error = %s", run.Id, err)
+				continue
+			}
+
+			countPledgeRun(&resp, result)
+		}
+
+		if len(rows) < pledgeBatchSize {
+			break
+		}
+	}
+
+	s.Logger.Debugf("Donation pledge run done. Executed = %d, Skipped = %d, Failed = %d", resp.Executed,
+		resp.Skipped, resp.Failed)
+
+	return &resp, nil
+}
+
+func (s *Initiative) runPledgeScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.RunPledges()
+		if err != nil {
+			s.Logger.Error("failed to run donation pledges", err)
+		}
+	}
+}
+
+func (s *Initiative) getPledge(id, userId string) (*model.DonationPledge, error) {
+	pledge, err := s.Repository.FindPledgeById(id, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("INT008")
+		}
+		s.Logger.Error("failed to FindPledgeById", err)
+		return nil, err
+	}
+
+	return pledge, nil
+}
+
+// executePledge claims a due pledge, donates its amount for the elapsed period and records the run result.
+// If pledge has been claimed by another worker, it returns nil run
+func (s *Initiative) executePledge(pledge *model.DonationPledge, timestamp time.Time) (*model.DonationPledgeRun, error) {
+	// Determine period. Pledge created in the middle of period only counts from its creation time
+	periodEnd := pledge.NextRunAt
+	periodStart := prevPledgeRunAt(pledge.PeriodId, periodEnd)
+	if pledge.CreatedAt.After(periodStart) {
+		periodStart = pledge.CreatedAt
+	}
+
+	// Claim pledge by moving its schedule to the next period
+	pledge.NextRunAt = nextPledgeRunAt(pledge.PeriodId, timestamp)
+	pledge.LastRunAt = pq.NullTime{Time: timestamp, Valid: true}
+	pledge.UpdatedAt = timestamp
+	pledge.CurrentVersion = pledge.Version
+	pledge.Version++
+
+	run := model.DonationPledgeRun{
+		Id:          s.IdGen.New(),
+		PledgeId:    pledge.Id,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		StatusId:    api.PledgeRunPending,
+		CreatedAt:   timestamp,
+		UpdatedAt:   timestamp,
+		Version:     1,
+	}
+
+	// Claim pledge and record pending run in a single transaction, so a claimed period is always retried if the
+	// donation is interrupted
+	err := nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		err := s.Repository.UpdatePledge(tx, pledge)
+		if err != nil {
+			return err
+		}
+
+		return s.Repository.InsertPledgeRun(tx, &run)
+	})
+	if err != nil {
+		if apiErr, ok := err.(nhttp.Error); ok && apiErr.Code == "INT009" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return s.runPledge(pledge, &run, timestamp)
+}
+
+// retryPledgeRun executes a failed or interrupted run again. Run of a pledge that is no longer active is skipped
+func (s *Initiative) retryPledgeRun(run *model.DonationPledgeRun, timestamp time.Time) (*model.DonationPledgeRun, error) {
+	// Get pledge
+	pledge, err := s.Repository.FindActivePledge(run.PledgeId)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to FindActivePledge", err)
+			return nil, err
+		}
+
+		// Pledge has been paused or cancelled, skip run
+		run.StatusId = api.PledgeRunSkipped
+		run.Reason = nsql.NullString("Pledge is no longer active")
+		run.UpdatedAt = timestamp
+		run.CurrentVersion = run.Version
+		run.Version++
+
+		return s.recordPledgeRun(run)
+	}
+
+	return s.runPledge(pledge, run, timestamp)
+}
+
+// runPledge donates pledge amount for the run period. Donation and run result are written in a single transaction,
+// so a run that has been recorded as pending or failed never has its donation committed. If run has been handled by
+// another worker, it returns nil run
+func (s *Initiative) runPledge(pledge *model.DonationPledge, run *model.DonationPledgeRun, timestamp time.Time) (
+	*model.DonationPledgeRun, error) {
+	run.Attempts++
+	run.StatusId = api.PledgeRunExecuted
+	run.Reason = sql.NullString{}
+	run.UpdatedAt = timestamp
+	run.CurrentVersion = run.Version
+	run.Version++
+
+	var donation *model.Donation
+	var isHandled bool
+	err := nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		var err error
+		donation, err = s.donatePledge(tx, pledge, run)
+		if err != nil {
+			return err
+		}
+
+		err = s.Repository.UpdatePledgeRun(tx, run)
+		isHandled = err == sql.ErrNoRows
+		return err
+	})
+	if err == nil {
+		// Send donation receipt in background
+		if donation != nil {
+			go s.sendDonationReceipt(donation)
+		}
+		return run, nil
+	}
+
+	if isHandled {
+		return nil, nil
+	}
+
+	// Donation has been rolled back, record failure with the same version
+	run.StatusId = api.PledgeRunFailed
+	run.Reason = nsql.NullString(err.Error())
+	run.Quantity = 0
+	run.TotalDonation = 0
+
+	// Client errors such as insufficient balance are expected, mark run as skipped
+	if apiErr, ok := err.(nhttp.Error); ok {
+		if apiErr.Status < http.StatusInternalServerError {
+			run.StatusId = api.PledgeRunSkipped
+		}
+
+		// Stop pledge if initiative is no longer available
+		if apiErr.Code == "INT003" || apiErr.Code == "INT004" {
+			s.cancelPledge(pledge, timestamp)
+		}
+	}
+
+	return s.recordPledgeRun(run)
+}
+
+// recordPledgeRun updates run result. If run has been handled by another worker, it returns nil run
+func (s *Initiative) recordPledgeRun(run *model.DonationPledgeRun) (*model.DonationPledgeRun, error) {
+	err := s.Repository.UpdatePledgeRun(nil, run)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// donatePledge calculates pledge amount for the run period and donates it to initiative in tx. If the amount is
+// not enough for a single item, run is skipped and no donation is returned
+func (s *Initiative) donatePledge(tx *sqlx.Tx, pledge *model.DonationPledge, run *model.DonationPledgeRun) (
+	*model.Donation, error) {
+	// Get initiative price
+	initiative, err := s.Repository.FindById(pledge.InitiativeId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("INT003")
+		}
+		s.Logger.Error("failed to FindById initiative", err)
+		return nil, err
+	}
+
+	// Check if initiative accepts donation
+	err = s.checkDonatable(initiative)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate amount
+	if pledge.AmountTypeId == api.PledgePercentage {
+		earned, err := s.CreditService.SumEarnedCredit(dto.CreditEarnedOpt{
+			UserId:  pledge.UserId,
+			StartAt: run.PeriodStart,
+			EndAt:   run.PeriodEnd,
+		})
+		if err != nil {
+			return nil, err
+		}
+		run.EarnedCredit = earned
+	}
+	run.Amount = calcPledgeAmount(pledge.AmountTypeId, pledge.Amount, run.EarnedCredit)
+
+	// Convert amount to donation quantity
+	if initiative.Price <= 0 {
+		return nil, s.Errors.New("INT001")
+	}
+
+	run.Quantity = int(math.Floor(run.Amount / initiative.Price))
+	if run.Quantity < 1 {
+		run.Quantity = 0
+		run.StatusId = api.PledgeRunSkipped
+		run.Reason = nsql.NullString("Pledge amount is less than initiative price")
+		return nil, nil
+	}
+
+	// Donate
+	donation, err := s.donate(tx, initiative, dto.DonateReq{
+		UserId:       pledge.UserId,
+		InitiativeId: pledge.InitiativeId,
+		Quantity:     run.Quantity,
+		Notes:        "Recurring donation",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	run.TotalDonation = donation.TotalPrice
+	return donation, nil
+}
+
+func (s *Initiative) cancelPledge(pledge *model.DonationPledge, timestamp time.Time) {
+	pledge.StatusId = api.PledgeCancelled
+	pledge.UpdatedAt = timestamp
+	pledge.CurrentVersion = pledge.Version
+	pledge.Version++
+
+	err := s.Repository.UpdatePledge(nil, pledge)
+	if err != nil {
+		s.Logger.Errorf("unable to cancel donation pledge. PledgeId = %s, Error = %s", pledge.Id, err)
+	}
+}
+
+// countPledgeRun adds run result to pledge run summary. Run handled by another worker is not counted
+func countPledgeRun(resp *dto.DonationPledgeExecResp, run *model.DonationPledgeRun) {
+	if run == nil {
+		return
+	}
+
+	switch run.StatusId {
+	case api.PledgeRunExecuted:
+		resp.Executed++
+	case api.PledgeRunSkipped:
+		resp.Skipped++
+	default:
+		resp.Failed++
+	}
+}
+
+// calcPledgeAmount returns credit amount to donate. Percentage pledge donates a share of credit earned in the period
+func calcPledgeAmount(amountTypeId int8, amount, earnedCredit float64) float64 {
+	if amountTypeId == api.PledgePercentage {
+		return earnedCredit * amount / 100
+	}
+
+	return amount
+}
+
+// isInitiativeEnded checks if initiative has reached its funding goal or passed its deadline
+func isInitiativeEnded(initiative *model.Initiative, now time.Time) bool {
+	// Check funding goal
+	if initiative.FundingGoal > 0 && initiative.RaisedAmount >= initiative.FundingGoal {
+		return true
+	}
+
+	// Check deadline
+	return initiative.DeadlineAt.Valid && !now.Before(initiative.DeadlineAt.Time)
+}
+
+// calcFundingProgress returns raised amount percentage against funding goal, capped at 100
+func calcFundingProgress(raisedAmount, fundingGoal float64) float64 {
+	if fundingGoal <= 0 {
+		return 0
+	}
+
+	progress := math.Floor(raisedAmount/fundingGoal*10000) / 100
+	if progress > 100 {
+		return 100
+	}
+
+	return progress
+}
+
+func composeDonationReceipt(donation *model.Donation) *dto.DonationReceiptResp {
+	resp := dto.DonationReceiptResp{
+		ReceiptNo:     "RCPT-" + donation.Id,
+		DonationId:    donation.Id,
+		InitiativeId:  donation.InitiativeId,
+		Quantity:      donation.Quantity,
+		TotalDonation: donation.TotalPrice,
+		CurrencyName:  api.CurrencyName[donation.CurrencyId],
+		Notes:         donation.Notes.String,
+		DonatedAt:     donation.CreatedAt.Unix(),
+	}
+
+	// Set donor from user snapshot
+	if u := donation.UserSnapshot; u != nil && u.UserProfile != nil {
+		resp.DonorName = u.FullName
+		resp.DonorEmail = u.Email
+	}
+
+	// Set initiative from initiative snapshot
+	if i := donation.InitiativeSnapshot; i != nil && i.Initiative != nil {
+		resp.InitiativeName = i.Name
+		resp.DonationConversion = i.DonationConversion
+		resp.ItemPrice = i.Price
+	}
+
+	return &resp
+}
+
+// renderDonationReceipt renders donation receipt into a single page PDF document
+func renderDonationReceipt(receipt *dto.DonationReceiptResp) []byte {
+	doc := npdf.New()
+
+	// Write header
+	doc.Text(50, 60, 20, npdf.FontBold, "Running App")
+	doc.Text(50, 85, 14, npdf.FontRegular, "Donation Receipt")
+	doc.Line(50, 100, npdf.PageWidth-50, 100, 1)
+
+	// Write receipt detail
+	rows := [][2]string{
+		{"Receipt No", receipt.ReceiptNo},
+		{"Date", formatReceiptDate(receipt.DonatedAt)},
+		{"Donor", receipt.DonorName},
+		{"Email", receipt.DonorEmail},
+		{"Initiative", receipt.InitiativeName},
+		{"Quantity", strconv.Itoa(receipt.Quantity)},
+		{"Item Price", formatReceiptAmount(receipt.ItemPrice, receipt.CurrencyName)},
+		{"Total Donation", formatReceiptAmount(receipt.TotalDonation, receipt.CurrencyName)},
+	}
+	if receipt.DonationConversion != "" {
+		rows = append(rows, [2]string{"Impact", receipt.DonationConversion})
+	}
+	if receipt.Notes != "" {
+		rows = append(rows, [2]string{"Notes", receipt.Notes})
+	}
+
+	y := 130.0
+	for _, r := range rows {
+		doc.Text(50, y, 11, npdf.FontBold, r[0])
+		doc.Text(170, y, 11, npdf.FontRegular, r[1])
+		y += 20
+	}
+
+	// Write footer
+	doc.Line(50, y, npdf.PageWidth-50, y, 1)
+	doc.Text(50, y+25, 11, npdf.FontRegular, "Thank you for your donation.")
+
+	return doc.Bytes()
+}
+
+func composePledge(pledge *model.DonationPledge) *dto.DonationPledgeResp {
+	resp := dto.DonationPledgeResp{
+		Id:           pledge.Id,
+		InitiativeId: pledge.InitiativeId,
+		AmountTypeId: pledge.AmountTypeId,
+		Amount:       pledge.Amount,
+		PeriodId:     pledge.PeriodId,
+		StatusId:     pledge.StatusId,
+		Notes:        pledge.Notes.String,
+		CreatedAt:    pledge.CreatedAt.Unix(),
+		UpdatedAt:    pledge.UpdatedAt.Unix(),
+		Version:      pledge.Version,
+	}
+
+	// Next run is only relevant for active pledge
+	if pledge.StatusId == api.PledgeActive {
+		resp.NextRunAt = pledge.NextRunAt.Unix()
+	}
+
+	if pledge.LastRunAt.Valid {
+		resp.LastRunAt = pledge.LastRunAt.Time.Unix()
+	}
+
+	return &resp
+}
+
+// nextPledgeRunAt returns the start of next period after t in UTC. Weekly period starts on Monday and monthly
+// period starts on the first day of month
+func nextPledgeRunAt(periodId int8, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch periodId {
+	case api.PledgeWeekly:
+		offset := (8 - int(day.Weekday())) % 7
+		if offset == 0 {
+			offset = 7
+		}
+		return day.AddDate(0, 0, offset)
+	default:
+		return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// prevPledgeRunAt returns the start of period that ends on t
+func prevPledgeRunAt(periodId int8, t time.Time) time.Time {
+	switch periodId {
+	case api.PledgeWeekly:
+		return t.AddDate(0, 0, -7)
+	default:
+		return t.AddDate(0, -1, 0)
+	}
+}
+
+func formatReceiptDate(t int64) string {
+	return time.Unix(t, 0).UTC().Format("02 January 2006 15:04 MST")
+}
+
+func formatReceiptAmount(amount float64, currencyName string) string {
+	return fmt.Sprintf("%.2f %s", amount, currencyName)
+}
@@ -6,19 +6,55 @@ import (
 )
 
 type InitiativeStatement struct {
-	findActive         *sqlx.Stmt
+	addDonationStat    *sqlx.Stmt
+	closeExpired       *sqlx.Stmt
+	findActivePledge   *sqlx.Stmt
 	findById           *sqlx.Stmt
+	findDonationById   *sqlx.Stmt
 	findDonationByUser *sqlx.Stmt
+	findDonationLocked *sqlx.Stmt
+	findDuePledges     *sqlx.Stmt
+	findInitiatives    *sqlx.Stmt
+	findPledgeById     *sqlx.Stmt
+	findPledgeRuns     *sqlx.Stmt
+	findPledgesByUser  *sqlx.Stmt
+	findRetryRuns      *sqlx.Stmt
+	findTagFacets      *sqlx.Stmt
+	hasDonationPayout  *sqlx.Stmt
 	insertDonation     *sqlx.NamedStmt
 	insertDonationLog  *sqlx.NamedStmt
+	insertPledge       *sqlx.NamedStmt
+	insertPledgeRun    *sqlx.NamedStmt
+	subDonationStat    *sqlx.Stmt
+	updateFundingGoal  *sqlx.Stmt
+	updatePledge       *sqlx.NamedStmt
+	updatePledgeRun    *sqlx.NamedStmt
 }
 
 func initInitiativeStatement(db *nsql.SqlDatabase) InitiativeStatement {
 	return InitiativeStatement{
-		findActive:         db.Prepare(`select id, organization_id, name, description, image_files, external_urls, price, currency_id, donation_conversion, status_id, tags, stat_donation_count, created_at, updated_at, "version", headline from initiative where status_id = 2 order by updated_at desc limit $1 offset $2`),
-		findById:           db.Prepare(`select id, organization_id, name, description, image_files, external_urls, price, currency_id, status_id, tags, stat_donation_count, created_at, updated_at, "version", headline from initiative where id = $1`),
+		addDonationStat:    db.Prepare(`update initiative set raised_amount = raised_amount + $2, stat_donation_count = stat_donation_count + 1, status_id = case when funding_goal > 0 and raised_amount + $2 >= funding_goal then 4 else status_id end where id = $1 and status_id = 2 and (deadline_at is null or deadline_at > $3) returning status_id`),
+		closeExpired:       db.Prepare(`update initiative set status_id = 4, updated_at = $1, "version" = "version" + 1 where status_id = 2 and deadline_at <= $1`),
+		findActivePledge:   db.Prepare(`select id, user_id, initiative_id, amount_type_id, amount, period_id, status_id, notes, next_run_at, last_run_at, created_at, updated_at, "version" from donation_pledge where id = $1 and status_id = 1`),
+		findById:           db.Prepare(`select id, organization_id, name, description, image_files, external_urls, price, currency_id, donation_conversion, status_id, tags, stat_donation_count, funding_goal, raised_amount, deadline_at, created_at, updated_at, "version", headline from initiative where id = $1`),
+		findDonationById:   db.Prepare(`select id, initiative_id, initiative_snapshot, user_id, user_snapshot, payment_method_id, payment_snapshot, payment_trx_ref, qty, total_price, currency_id, status_id, notes, created_at, updated_at, modified_by, version from donation where id = $1 and user_id = $2`),
 		findDonationByUser: db.Prepare(`select id, initiative_id, initiative_snapshot, user_id, user_snapshot, payment_method_id, payment_snapshot, payment_trx_ref, qty, total_price, currency_id, status_id, notes, created_at, updated_at, modified_by, version from donation where user_id = $1 order by updated_at desc limit $2 offset $3`),
+		findDonationLocked: db.Prepare(`select id, initiative_id, initiative_snapshot, user_id, user_snapshot, payment_method_id, payment_snapshot, payment_trx_ref, qty, total_price, currency_id, status_id, notes, created_at, updated_at, modified_by, version from donation where id = $1 for update`),
+		findDuePledges:     db.Prepare(`select id, user_id, initiative_id, amount_type_id, amount, period_id, status_id, notes, next_run_at, last_run_at, created_at, updated_at, "version" from donation_pledge where status_id = 1 and next_run_at <= $1 and id > $2 order by id limit $3`),
+		findInitiatives:    db.Prepare(`select id, organization_id, name, description, image_files, external_urls, price, currency_id, donation_conversion, status_id, tags, stat_donation_count, funding_goal, raised_amount, deadline_at, created_at, updated_at, "version", headline from initiative where status_id in (2, 4) and status_id = $1 and ($1 <> 2 or deadline_at is null or deadline_at > now()) and ($2 = '' or search_vector @@ plainto_tsquery('simple', $2)) and ($3 = '' or organization_id = $3) and ($4 = '' or exists(select 1 from unnest(string_to_array(tags, ',')) as t(tag) where lower(trim(t.tag)) = lower($4))) order by case when $5 = 'popular' then stat_donation_count end desc, case when $5 = 'relevance' then ts_rank(search_vector, plainto_tsquery('simple', $2)) end desc, case when $5 = 'newest' then created_at end desc, updated_at desc, id desc limit $6 offset $7`),
+		findPledgeById:     db.Prepare(`select id, user_id, initiative_id, amount_type_id, amount, period_id, status_id, notes, next_run_at, last_run_at, created_at, updated_at, "version" from donation_pledge where id = $1 and user_id = $2`),
+		findPledgeRuns:     db.Prepare(`select id, pledge_id, period_start, period_end, earned_credit, amount, qty, total_donation, status_id, reason, created_at from donation_pledge_run where pledge_id = $1 order by period_end desc limit $2 offset $3`),
+		findPledgesByUser:  db.Prepare(`select id, user_id, initiative_id, amount_type_id, amount, period_id, status_id, notes, next_run_at, last_run_at, created_at, updated_at, "version" from donation_pledge where user_id = $1 and status_id <> 3 order by created_at desc limit $2 offset $3`),
+		findRetryRuns:      db.Prepare(`select id, pledge_id, period_start, period_end, earned_credit, amount, qty, total_donation, status_id, reason, attempts, created_at, updated_at, "version" from donation_pledge_run where status_id in (3, 4) and updated_at <= $1 and attempts < $2 and id > $3 order by id limit $4`),
+		findTagFacets:      db.Prepare(`select lower(trim(t.tag)) as tag, count(distinct id) as count from initiative, unnest(string_to_array(tags, ',')) as t(tag) where status_id in (2, 4) and status_id = $1 and ($1 <> 2 or deadline_at is null or deadline_at > now()) and ($2 = '' or search_vector @@ plainto_tsquery('simple', $2)) and ($3 = '' or organization_id = $3) and trim(t.tag) <> '' group by lower(trim(t.tag)) order by count desc, tag`),
+		hasDonationPayout:  db.Prepare(`select exists(select 1 from organization_payout as p inner join initiative as i on i.organization_id = p.organization_id where i.id = $1 and p.period_start <= $2 and p.period_end > $2)`),
 		insertDonation:     db.PrepareNamed(`INSERT INTO donation(id, initiative_id, initiative_snapshot, user_id, user_snapshot, payment_method_id, payment_snapshot, payment_trx_ref, qty, total_price, currency_id, status_id, notes, created_at, updated_at, modified_by, version) VALUES (:id, :initiative_id, :initiative_snapshot, :user_id, :user_snapshot, :payment_method_id, :payment_snapshot, :payment_trx_ref, :qty, :total_price, :currency_id, :status_id, :notes, :created_at, :updated_at, :modified_by, :version);`),
 		insertDonationLog:  db.PrepareNamed(`INSERT INTO donation_log(log_id, changelog, id, payment_method_id, payment_snapshot, payment_trx_ref, status_id, updated_at, modified_by, version, notes) VALUES (:log_id, :changelog, :id, :payment_method_id, :payment_snapshot, :payment_trx_ref, :status_id, :updated_at, :modified_by, :version, :notes);`),
+		insertPledge:       db.PrepareNamed(`insert into donation_pledge(id, user_id, initiative_id, amount_type_id, amount, period_id, status_id, notes, next_run_at, last_run_at, created_at, updated_at, "version") values (:id, :user_id, :initiative_id, :amount_type_id, :amount, :period_id, :status_id, :notes, :next_run_at, :last_run_at, :created_at, :updated_at, :version)`),
+		insertPledgeRun:    db.PrepareNamed(`insert into donation_pledge_run(id, pledge_id, period_start, period_end, earned_credit, amount, qty, total_donation, status_id, reason, attempts, created_at, updated_at, "version") values (:id, :pledge_id, :period_start, :period_end, :earned_credit, :amount, :qty, :total_donation, :status_id, :reason, :attempts, :created_at, :updated_at, :version)`),
+		subDonationStat:    db.Prepare(`update initiative set raised_amount = greatest(raised_amount - $2, 0), stat_donation_count = greatest(stat_donation_count - 1, 0), status_id = case when status_id = 4 and funding_goal > 0 and raised_amount >= funding_goal and raised_amount - $2 < funding_goal and (deadline_at is null or deadline_at > $3) then 2 else status_id end where id = $1`),
+		updateFundingGoal:  db.Prepare(`update initiative set funding_goal = $2, deadline_at = $3, status_id = case when status_id = 4 and ($2 = 0 or raised_amount < $2) and ($3::timestamptz is null or $3 > $4) then 2 else status_id end, updated_at = $4, "version" = "version" + 1 where id = $1 and "version" = $5`),
+		updatePledge:       db.PrepareNamed(`update donation_pledge set amount_type_id = :amount_type_id, amount = :amount, period_id = :period_id, status_id = :status_id, notes = :notes, next_run_at = :next_run_at, last_run_at = :last_run_at, updated_at = :updated_at, "version" = :version where id = :id and "version" = :current_version`),
+		updatePledgeRun:    db.PrepareNamed(`update donation_pledge_run set earned_credit = :earned_credit, amount = :amount, qty = :qty, total_donation = :total_donation, status_id = :status_id, reason = :reason, attempts = :attempts, updated_at = :updated_at, "version" = :version where id = :id and "version" = :current_version`),
 	}
 }
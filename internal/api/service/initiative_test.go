@@ -8,6 +8,7 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/mocks"
 	"github.com/diarikom/running-app/running-app-api/internal/api/service"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"testing"
 )
@@ -104,6 +105,11 @@ func (s *InitiativeTestSuite) InitService() {
 		Initiative:       &service.Initiative{},
 	}
 
+	// Mock donation receipt email
+	mailer := apiTest.Components.Mailer.(*mocks.MailerComponent)
+	mailer.On("GetDefaultSender").Return("no-reply@example.com")
+	mailer.On("Send", mock.Anything).Return(nil)
+
 	// Init services
 	apiTest.MustInitService("UserService", apiTest.Services.User)
 	apiTest.MustInitService("CreditService", apiTest.Services.Credit)
@@ -5,6 +5,7 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
 	"time"
 )
 
@@ -40,8 +41,8 @@ func (r MilestoneRepository) FindById(id string) (*model.Milestone, error) {
 	return &m, err
 }
 
-func (r MilestoneRepository) InsertUserChallenge(uc model.UserChallenge) error {
-	_, err := r.Stmt.insertUserChallenge.Exec(uc)
+func (r MilestoneRepository) InsertUserChallenge(tx *sqlx.Tx, uc model.UserChallenge) error {
+	_, err := nsql.NamedStmtTx(r.Stmt.insertUserChallenge, tx).Exec(uc)
 	return err
 }
 
@@ -69,7 +70,7 @@ func (r *MilestoneRepository) FindUserChallenge(userID string, challengeID strin
 	return &uc, err
 }
 
-func (r *MilestoneRepository) UpdateUserChallenge(o, n model.UserChallenge, changes []string) error {
+func (r *MilestoneRepository) UpdateUserChallenge(tx *sqlx.Tx, o, n model.UserChallenge, changes []string) error {
 	// Get differ
 	differ := r.Differs.userChallenge
 
@@ -95,8 +96,12 @@ func (r *MilestoneRepository) UpdateUserChallenge(o, n model.UserChallenge, chan
 	// Rebind query
 	q = r.Db.Conn.Rebind(q)
 
-	// Execute
-	_, err = r.Db.Conn.Exec(q, args...)
+	// Execute, use transaction if provided
+	if tx != nil {
+		_, err = tx.Exec(q, args...)
+	} else {
+		_, err = r.Db.Conn.Exec(q, args...)
+	}
 	return err
 }
 
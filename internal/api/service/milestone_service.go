@@ -16,10 +16,12 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/internal/pkg/ngrule"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
 	"github.com/hyperjumptech/grule-rule-engine/ast"
 	"github.com/hyperjumptech/grule-rule-engine/builder"
 	"github.com/hyperjumptech/grule-rule-engine/engine"
 	"github.com/hyperjumptech/grule-rule-engine/pkg"
+	"github.com/jmoiron/sqlx"
 	"strconv"
 	"time"
 )
@@ -30,6 +32,7 @@ type MilestoneService struct {
 	IdGen               *api.SnowflakeGen
 	Error               *api.Errors
 	Logger              nlog.Logger
+	Db                  *nsql.SqlDatabase
 	MilestoneRepository api.MilestoneRepository
 	RunRepository       api.RunRepository
 	CreditService       api.CreditService
@@ -48,6 +51,7 @@ func (s *MilestoneService) Init(app *api.Api) error {
 	s.IdGen = app.Components.Id
 	s.Error = app.Components.Errors
 	s.Logger = app.Logger
+	s.Db = app.Datasources.Db
 	s.MilestoneRepository = mRepo
 	s.RunRepository = rRepo
 	s.CreditService = app.Services.Credit
@@ -375,21 +379,30 @@ func (s *MilestoneService) ClaimCredit(opt dto.UserChallengeReq) (*dto.Challenge
 	// TODO: Get expired at from config
 	expiredAt := timestamp.Add(time.Duration(720) * time.Hour)
 
-	// Settle pending transaction
-	err = s.CreditService.SettlePendingTrx(dto.CreditSettleOpt{
-		TrxId:     userChallenge.RewardRefId,
-		Notes:     "Claimed Credit from Challenge " + userChallenge.Id,
-		ExpiredAt: &expiredAt,
-		Timestamp: &timestamp,
-	})
-	if err != nil {
-		return nil, err
-	}
+	// Settle pending transaction and update user challenge in a single transaction
+	err = nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		// Settle pending transaction
+		err := s.CreditService.SettlePendingTrx(dto.CreditSettleOpt{
+			Tx:        tx,
+			TrxId:     userChallenge.RewardRefId,
+			Notes:     "Claimed Credit from Challenge " + userChallenge.Id,
+			ExpiredAt: &expiredAt,
+			Timestamp: &timestamp,
+		})
+		if err != nil {
+			return err
+		}
 
-	// Update user challenge status
-	err = s.MilestoneRepository.UpdateUserChallenge(*userChallenge, newUserChallenge, []string{"status"})
+		// Update user challenge status
+		err = s.MilestoneRepository.UpdateUserChallenge(tx, *userChallenge, newUserChallenge, []string{"status"})
+		if err != nil {
+			s.Logger.Error("unable to persist user challenge update", err)
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		s.Logger.Error("unable to persist user challenge update", err)
 		return nil, err
 	}
 
@@ -401,7 +414,7 @@ func (s *MilestoneService) ClaimCredit(opt dto.UserChallengeReq) (*dto.Challenge
 	return resp, nil
 }
 
-func (s *MilestoneService) AddUserChallenge(opt dto.UserChallengeReq) error {
+func (s *MilestoneService) AddUserChallenge(tx *sqlx.Tx, opt dto.UserChallengeReq) error {
 	// Validate options
 	if opt.ChallengeId == "" {
 		return errors.New("ChallengeId is required")
@@ -473,7 +486,7 @@ func (s *MilestoneService) AddUserChallenge(opt dto.UserChallengeReq) error {
 	}
 
 	// Insert user challenge
-	err = s.MilestoneRepository.InsertUserChallenge(uc)
+	err = s.MilestoneRepository.InsertUserChallenge(tx, uc)
 	if err != nil {
 		s.Logger.Error("unable to insert user challenge", err)
 		return err
@@ -487,12 +500,6 @@ func (s *MilestoneService) UpdateUserChallengeAchievement(opt dto.UserChallengeR
 	opt.RewardRefId = s.IdGen.New()
 	opt.Timestamp = time.Now()
 
-	// Insert user challenge
-	err := s.AddUserChallenge(opt)
-	if err != nil {
-		return err
-	}
-
 	// Get wallet
 	wallet, err := s.CreditService.GetUserWallet(opt.UserId)
 	if err != nil {
@@ -506,19 +513,26 @@ func (s *MilestoneService) UpdateUserChallengeAchievement(opt dto.UserChallengeR
 	// Set expire to 7 days
 	claimExpire := timestamp.Add(time.Duration(168) * time.Hour)
 
-	_, err = s.CreditService.InsertPendingTrx(dto.CreditTrxOpt{
-		Id:        opt.RewardRefId,
-		WalletId:  wallet.Id,
-		Amount:    opt.RewardValue,
-		EntryType: api.Debit,
-		ExpiredAt: &claimExpire,
-		Timestamp: &timestamp,
-	})
-	if err != nil {
-		return err
-	}
+	// Insert user challenge and its pending reward in a single transaction
+	return nsql.WithTx(s.Db, s.Logger, func(tx *sqlx.Tx) error {
+		// Insert user challenge
+		err := s.AddUserChallenge(tx, opt)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		// Insert pending reward
+		_, err = s.CreditService.InsertPendingTrx(dto.CreditTrxOpt{
+			Tx:        tx,
+			Id:        opt.RewardRefId,
+			WalletId:  wallet.Id,
+			Amount:    opt.RewardValue,
+			EntryType: api.Debit,
+			ExpiredAt: &claimExpire,
+			Timestamp: &timestamp,
+		})
+		return err
+	})
 }
 
 func (s *MilestoneService) LoadMilestone() {
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"fmt"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nstr"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+func NewOrganizationHandler(app *api.Api) OrganizationHandler {
+	return OrganizationHandler{
+		OrganizationService: app.Services.Organization,
+		Logger:              app.Logger,
+	}
+}
+
+type OrganizationHandler struct {
+	OrganizationService api.OrganizationService
+	Logger              nlog.Logger
+}
+
+func (h *OrganizationHandler) PostOrganization(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.OrganizationReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set admin
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.OrganizationService.Create(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) GetOrganizations(r *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	query := r.URL.Query()
+	skip, limit := api.Pagination(query)
+
+	// Call service
+	respBody, err := h.OrganizationService.List(dto.OrganizationListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		StatusId:             nstr.ParseInt8(query.Get("status_id"), 0),
+		VerificationStatusId: nstr.ParseInt8(query.Get("verification_status_id"), 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) GetOrganization(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	respBody, err := h.OrganizationService.Get(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) PutOrganization(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.OrganizationReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set organization id and admin
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.OrganizationService.Update(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) DeleteOrganization(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.OrganizationDeleteReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set organization id and admin
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	err = h.OrganizationService.Delete(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *OrganizationHandler) PutVerification(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.OrganizationVerificationReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set organization id and admin
+	reqBody.Id = mux.Vars(r)["id"]
+	reqBody.ModifiedBy = newModifierReq(r)
+
+	// Call service
+	respBody, err := h.OrganizationService.UpdateVerification(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) PostMember(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.OrganizationMemberReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set organization id
+	reqBody.OrganizationId = mux.Vars(r)["id"]
+
+	// Call service
+	err = h.OrganizationService.AddMember(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *OrganizationHandler) DeleteMember(r *http.Request) (*nhttp.Success, error) {
+	vars := mux.Vars(r)
+
+	// Call service
+	err := h.OrganizationService.RemoveMember(dto.OrganizationMemberReq{
+		OrganizationId: vars["id"],
+		UserId:         vars["userId"],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *OrganizationHandler) PostGeneratePayouts(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.OrganizationPayoutGenerateReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set admin
+	modifiedBy := newModifierReq(r)
+	reqBody.ModifiedBy = &modifiedBy
+
+	// Call service
+	respBody, err := h.OrganizationService.GeneratePayouts(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) GetPayouts(r *http.Request) (*nhttp.Success, error) {
+	return h.listPayouts(r, mux.Vars(r)["id"])
+}
+
+func (h *OrganizationHandler) GetExportPayout(r *http.Request) (*nhttp.Success, error) {
+	return h.exportPayout(dto.OrganizationPayoutReq{
+		Id: mux.Vars(r)["id"],
+	})
+}
+
+func (h *OrganizationHandler) PostLogin(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.OrganizationLoginReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+	reqBody.ClientIp = nhttp.ClientIP(r)
+
+	// Call service
+	respBody, header, err := h.OrganizationService.Login(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := nhttp.Success{
+		Result: respBody,
+		Header: header,
+	}
+	return &resp, nil
+}
+
+func (h *OrganizationHandler) PostLoginTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	reqBody, err := parseTwoFactorReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call service
+	respBody, header, err := h.OrganizationService.LoginByTwoFactor(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := nhttp.Success{
+		Result: respBody,
+		Header: header,
+	}
+	return &resp, nil
+}
+
+func (h *OrganizationHandler) GetAdminDonations(r *http.Request) (*nhttp.Success, error) {
+	// Get skip and limit
+	query := r.URL.Query()
+	skip, limit := api.Pagination(query)
+
+	// Call service
+	respBody, err := h.OrganizationService.ListDonations(dto.OrganizationDonationListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		OrganizationId: r.Header.Get(api.KeyOrganizationId),
+		StartAt:        nstr.ParseInt64(query.Get("start"), 0),
+		EndAt:          nstr.ParseInt64(query.Get("end"), 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) GetAdminPayouts(r *http.Request) (*nhttp.Success, error) {
+	return h.listPayouts(r, r.Header.Get(api.KeyOrganizationId))
+}
+
+func (h *OrganizationHandler) GetAdminExportPayout(r *http.Request) (*nhttp.Success, error) {
+	return h.exportPayout(dto.OrganizationPayoutReq{
+		Id:             mux.Vars(r)["id"],
+		OrganizationId: r.Header.Get(api.KeyOrganizationId),
+	})
+}
+
+func (h *OrganizationHandler) listPayouts(r *http.Request, organizationId string) (*nhttp.Success, error) {
+	// Get skip and limit
+	skip, limit := api.Pagination(r.URL.Query())
+
+	// Call service
+	respBody, err := h.OrganizationService.ListPayouts(dto.OrganizationPayoutListReq{
+		PageReq: dto.PageReq{
+			Skip:  skip,
+			Limit: limit,
+		},
+		OrganizationId: organizationId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: respBody}, nil
+}
+
+func (h *OrganizationHandler) exportPayout(reqBody dto.OrganizationPayoutReq) (*nhttp.Success, error) {
+	// Call service
+	content, err := h.OrganizationService.ExportPayout(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send as csv file
+	resp := nhttp.Success{
+		File: &nhttp.File{
+			Name:        fmt.Sprintf("payout-%s.csv", reqBody.Id),
+			ContentType: nhttp.ContentTypeCSV,
+			Content:     content,
+		},
+	}
+	return &resp, nil
+}
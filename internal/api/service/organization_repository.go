@@ -0,0 +1,170 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/lib/pq"
+	"time"
+)
+
+func NewOrganizationRepository(db *nsql.SqlDatabase, apiErrors *api.Errors, logger nlog.Logger) api.OrganizationRepository {
+	r := OrganizationRepository{
+		Errors: apiErrors,
+		Db:     db,
+		Stmt:   initOrganizationStatement(db),
+		Logger: logger,
+	}
+
+	return &r
+}
+
+type OrganizationRepository struct {
+	Errors *api.Errors
+	Db     *nsql.SqlDatabase
+	Stmt   OrganizationStatement
+	Logger nlog.Logger
+}
+
+func (r *OrganizationRepository) FindById(id string) (*model.Organization, error) {
+	var result model.Organization
+	err := r.Stmt.findById.Get(&result, id)
+	return &result, err
+}
+
+func (r *OrganizationRepository) FindOrganizations(statusId, verificationStatusId int8, skip int64, limit int8) (
+	[]model.Organization, error) {
+	rows := make([]model.Organization, 0)
+	err := r.Stmt.findOrganizations.Select(&rows, statusId, verificationStatusId, limit, skip)
+	return rows, err
+}
+
+func (r *OrganizationRepository) FindActiveIds() ([]string, error) {
+	rows := make([]string, 0)
+	err := r.Stmt.findActiveIds.Select(&rows)
+	return rows, err
+}
+
+func (r *OrganizationRepository) Insert(organization *model.Organization) error {
+	_, err := r.Stmt.insert.Exec(organization)
+	if err != nil {
+		r.Logger.Error("insert organization", err)
+	}
+	return err
+}
+
+func (r *OrganizationRepository) Update(organization *model.Organization) error {
+	// Update organization
+	result, err := r.Stmt.update.Exec(organization)
+	if err != nil {
+		r.Logger.Error("update organization", err)
+		return err
+	}
+
+	// Check for affected rows
+	count, err := result.RowsAffected()
+	if err != nil {
+		r.Logger.Error("cannot get affected rows", err)
+		return err
+	}
+
+	if count == 0 {
+		r.Logger.Errorf("no organization update affected")
+		return r.Errors.New("ORG002")
+	}
+
+	return nil
+}
+
+func (r *OrganizationRepository) FindActiveUserIdByEmail(email string) (string, error) {
+	var userId string
+	err := r.Stmt.findActiveUserByEmail.Get(&userId, email)
+	return userId, err
+}
+
+func (r *OrganizationRepository) FindAdminsByEmail(email string) ([]model.OrganizationAdmin, error) {
+	rows := make([]model.OrganizationAdmin, 0)
+	err := r.Stmt.findAdminsByEmail.Select(&rows, email)
+	return rows, err
+}
+
+func (r *OrganizationRepository) IsAdmin(organizationId, userId string) (bool, error) {
+	var isAdmin bool
+	err := r.Stmt.isAdmin.Get(&isAdmin, organizationId, userId)
+	return isAdmin, err
+}
+
+func (r *OrganizationRepository) IsExistMember(organizationId, userId string) (bool, error) {
+	var isExist bool
+	err := r.Stmt.isExistMember.Get(&isExist, organizationId, userId)
+	return isExist, err
+}
+
+func (r *OrganizationRepository) InsertMember(member *model.OrganizationMember) error {
+	_, err := r.Stmt.insertMember.Exec(member)
+	if err != nil {
+		r.Logger.Error("insert organization member", err)
+	}
+	return err
+}
+
+func (r *OrganizationRepository) DeleteMember(organizationId, userId string) (int64, error) {
+	result, err := r.Stmt.deleteMember.Exec(organizationId, userId)
+	if err != nil {
+		r.Logger.Error("delete organization member", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *OrganizationRepository) FindDonations(organizationId string, startAt, endAt pq.NullTime, skip int64,
+	limit int8) ([]model.Donation, error) {
+	rows := make([]model.Donation, 0)
+	err := r.Stmt.findDonations.Select(&rows, organizationId, startAt, endAt, limit, skip)
+	return rows, err
+}
+
+func (r *OrganizationRepository) FindDonationSummary(organizationId string, startAt, endAt time.Time) (
+	[]model.OrganizationDonationSummary, error) {
+	rows := make([]model.OrganizationDonationSummary, 0)
+	err := r.Stmt.findDonationSummary.Select(&rows, organizationId, startAt, endAt)
+	return rows, err
+}
+
+func (r *OrganizationRepository) FindPayoutById(id string) (*model.OrganizationPayout, error) {
+	var result model.OrganizationPayout
+	err := r.Stmt.findPayoutById.Get(&result, id)
+	return &result, err
+}
+
+func (r *OrganizationRepository) FindPayouts(organizationId string, skip int64, limit int8) (
+	[]model.OrganizationPayout, error) {
+	rows := make([]model.OrganizationPayout, 0)
+	err := r.Stmt.findPayouts.Select(&rows, organizationId, limit, skip)
+	return rows, err
+}
+
+func (r *OrganizationRepository) IsExistPayout(organizationId string, periodStart time.Time) (bool, error) {
+	var isExist bool
+	err := r.Stmt.isExistPayout.Get(&isExist, organizationId, periodStart)
+	return isExist, err
+}
+
+// InsertPayout inserts payout report, returns false if report of the period has been generated. It relies on unique
+// index of organization_payout on (organization_id, period_start)
+func (r *OrganizationRepository) InsertPayout(payout *model.OrganizationPayout) (bool, error) {
+	result, err := r.Stmt.insertPayout.Exec(payout)
+	if err != nil {
+		r.Logger.Error("insert organization payout", err)
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
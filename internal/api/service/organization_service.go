@@ -0,0 +1,850 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	validate "github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const payoutMonthFormat = "2006-01"
+
+type Organization struct {
+	IdGen          *api.SnowflakeGen
+	Errors         *api.Errors
+	Logger         nlog.Logger
+	Repository     api.OrganizationRepository
+	AuthService    api.AuthenticatorService
+	UserService    api.UserService
+	Validator      *validate.Validate
+	AccessLifetime int
+}
+
+func (s *Organization) Init(app *api.Api) error {
+	// Init organization service
+	s.IdGen = app.Components.Id
+	s.Errors = app.Components.Errors
+	s.Logger = app.Logger
+	s.Repository = NewOrganizationRepository(app.Datasources.Db, app.Components.Errors, app.Logger)
+	s.AuthService = app.Services.Auth
+	s.UserService = app.Services.User
+	s.Validator = validate.New()
+	s.AccessLifetime = app.Config.GetInt(api.ConfOrganizationAccessLifetime)
+
+	// Start payout report scheduler
+	payoutInterval := app.Config.GetInt(api.ConfOrganizationPayoutInterval)
+	if payoutInterval > 0 {
+		go s.runPayoutScheduler(time.Duration(payoutInterval) * time.Minute)
+	}
+
+	return nil
+}
+
+func (s *Organization) Create(opt dto.OrganizationReq) (*dto.OrganizationResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set default status
+	statusId := opt.StatusId
+	if statusId == 0 {
+		statusId = api.OrganizationActive
+	}
+
+	// Create organization
+	timestamp := time.Now()
+	organization := model.Organization{
+		Id:                   s.IdGen.New(),
+		Name:                 opt.Name,
+		OrgTypeId:            opt.OrgTypeId,
+		Description:          nsql.NullString(opt.Description),
+		LogoFile:             nsql.NullString(opt.LogoFile),
+		Contacts:             newOrganizationContacts(opt.Contacts),
+		VerificationStatusId: api.OrganizationUnverified,
+		StatusId:             statusId,
+		CreatedAt:            timestamp,
+		UpdatedAt:            timestamp,
+		ModifiedBy:           newAdminModifier(opt.ModifiedBy),
+		Version:              1,
+	}
+
+	err = s.Repository.Insert(&organization)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeOrganization(organization), nil
+}
+
+func (s *Organization) Get(id string) (*dto.OrganizationResp, error) {
+	organization, err := s.findById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeOrganization(*organization), nil
+}
+
+func (s *Organization) List(opt dto.OrganizationListReq) ([]dto.OrganizationResp, error) {
+	// Get organizations
+	rows, err := s.Repository.FindOrganizations(opt.StatusId, opt.VerificationStatusId, opt.Skip, opt.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve organizations", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.OrganizationResp, len(rows))
+	for k, v := range rows {
+		resp[k] = *composeOrganization(v)
+	}
+
+	return resp, nil
+}
+
+func (s *Organization) Update(opt dto.OrganizationReq) (*dto.OrganizationResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil || opt.Id == "" || opt.Version == 0 {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get organization
+	organization, err := s.findById(opt.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update organization
+	organization.Name = opt.Name
+	organization.OrgTypeId = opt.OrgTypeId
+	organization.Description = nsql.NullString(opt.Description)
+	organization.LogoFile = nsql.NullString(opt.LogoFile)
+	organization.Contacts = newOrganizationContacts(opt.Contacts)
+	if opt.StatusId != 0 {
+		organization.StatusId = opt.StatusId
+	}
+
+	err = s.update(organization, opt.Version, opt.ModifiedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeOrganization(*organization), nil
+}
+
+func (s *Organization) Delete(opt dto.OrganizationDeleteReq) error {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Get organization
+	organization, err := s.findById(opt.Id)
+	if err != nil {
+		return err
+	}
+
+	// Deactivate organization. Organization is kept as it is referenced by initiatives and donations
+	organization.StatusId = api.OrganizationInactive
+
+	return s.update(organization, opt.Version, opt.ModifiedBy)
+}
+
+func (s *Organization) UpdateVerification(opt dto.OrganizationVerificationReq) (*dto.OrganizationResp, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get organization
+	organization, err := s.findById(opt.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update verification status
+	organization.VerificationStatusId = opt.VerificationStatusId
+	organization.VerificationNotes = nsql.NullString(opt.Notes)
+	if opt.VerificationStatusId == api.OrganizationVerified {
+		organization.VerifiedAt = pq.NullTime{Time: time.Now(), Valid: true}
+		organization.VerifiedBy = newAdminModifier(opt.ModifiedBy)
+	} else {
+		organization.VerifiedAt = pq.NullTime{}
+		organization.VerifiedBy = nil
+	}
+
+	err = s.update(organization, opt.Version, opt.ModifiedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeOrganization(*organization), nil
+}
+
+func (s *Organization) AddMember(opt dto.OrganizationMemberReq) error {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil || opt.Email == "" {
+		s.Logger.Error("failed to validate", err)
+		return nhttp.ErrBadRequest
+	}
+
+	// Check organization
+	_, err = s.findById(opt.OrganizationId)
+	if err != nil {
+		return err
+	}
+
+	// Get user
+	userId, err := s.Repository.FindActiveUserIdByEmail(opt.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return s.Errors.New("ORG005")
+		}
+		s.Logger.Error("unable to find user by email", err)
+		return err
+	}
+
+	// Check membership
+	isExist, err := s.Repository.IsExistMember(opt.OrganizationId, userId)
+	if err != nil {
+		s.Logger.Error("unable to check organization member", err)
+		return err
+	}
+
+	if isExist {
+		return s.Errors.New("ORG006")
+	}
+
+	// Set default role
+	memberRoleId := opt.MemberRoleId
+	if memberRoleId == 0 {
+		memberRoleId = api.OrganizationAdminRole
+	}
+
+	// Insert member
+	timestamp := time.Now()
+	return s.Repository.InsertMember(&model.OrganizationMember{
+		Id:             s.IdGen.New(),
+		OrganizationId: opt.OrganizationId,
+		UserProfileId:  userId,
+		MemberRoleId:   memberRoleId,
+		CreatedAt:      timestamp,
+		UpdatedAt:      timestamp,
+	})
+}
+
+func (s *Organization) RemoveMember(opt dto.OrganizationMemberReq) error {
+	if opt.OrganizationId == "" || opt.UserId == "" {
+		return nhttp.ErrBadRequest
+	}
+
+	// Delete member
+	count, err := s.Repository.DeleteMember(opt.OrganizationId, opt.UserId)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return s.Errors.New("ORG005")
+	}
+
+	return nil
+}
+
+func (s *Organization) Login(opt dto.OrganizationLoginReq) (*dto.OrganizationLoginResp, map[string]string, error) {
+	// Validate request
+	err := s.Validator.Struct(&opt)
+	if err != nil {
+		s.Logger.Error("failed to validate", err)
+		return nil, nil, nhttp.ErrBadRequest
+	}
+
+	// Validate credential
+	_, err = s.UserService.VerifyPassword(dto.UserLoginReq{
+		Email:    opt.Email,
+		Password: opt.Password,
+		ClientIp: opt.ClientIp,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get organizations administered by user
+	admins, err := s.Repository.FindAdminsByEmail(opt.Email)
+	if err != nil {
+		s.Logger.Error("unable to retrieve organization admins", err)
+		return nil, nil, err
+	}
+
+	// User that is not an admin is answered same as invalid password, so admins can not be enumerated
+	if len(admins) == 0 {
+		return nil, nil, s.Errors.New("USR007")
+	}
+
+	// Filter active organizations
+	candidates := make([]model.OrganizationAdmin, 0)
+	for _, a := range admins {
+		if a.OrganizationStatusId != api.OrganizationActive {
+			continue
+		}
+		if opt.OrganizationId != "" && a.OrganizationId != opt.OrganizationId {
+			continue
+		}
+		candidates = append(candidates, a)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, nil, s.Errors.New("ORG003")
+	case 1:
+		break
+	default:
+		return nil, nil, s.Errors.New("ORG004")
+	}
+	admin := candidates[0]
+
+	// If two factor is enabled, return challenge token instead of access token
+	isTwoFactor, err := s.UserService.IsTwoFactorEnabled(admin.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isTwoFactor {
+		header, err := s.UserService.NewTwoFactorChallenge(dto.TwoFactorSession{
+			UserId:         admin.UserId,
+			Target:         api.TwoFactorTargetOrganization,
+			OrganizationId: admin.OrganizationId,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp := dto.OrganizationLoginResp{
+			OrganizationId:    admin.OrganizationId,
+			OrganizationName:  admin.OrganizationName,
+			TwoFactorRequired: true,
+		}
+		return &resp, header, nil
+	}
+
+	return s.newSession(admin.UserId, admin.OrganizationId, admin.OrganizationName)
+}
+
+func (s *Organization) LoginByTwoFactor(opt dto.TwoFactorReq) (*dto.OrganizationLoginResp, map[string]string, error) {
+	// Verify second factor
+	err := s.UserService.VerifyTwoFactor(opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validate user is still an admin of organization
+	err = s.ValidateAdmin(&dto.OrganizationSession{
+		UserId:         opt.UserId,
+		OrganizationId: opt.OrganizationId,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get organization
+	organization, err := s.Repository.FindById(opt.OrganizationId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, s.Errors.New("ORG003")
+		}
+		s.Logger.Error("unable to retrieve organization", err)
+		return nil, nil, err
+	}
+
+	if organization.StatusId != api.OrganizationActive {
+		return nil, nil, s.Errors.New("ORG003")
+	}
+
+	return s.newSession(opt.UserId, organization.Id, organization.Name)
+}
+
+func (s *Organization) newSession(userId, organizationId, organizationName string) (*dto.OrganizationLoginResp,
+	map[string]string, error) {
+	// Create token
+	token, err := s.AuthService.NewOrganizationAccessToken(dto.JWTOptReq{
+		Subject:   userId,
+		SessionId: s.IdGen.New(),
+		Lifetime:  s.AccessLifetime,
+		Extras: map[string]string{
+			api.OrganizationIdKey: organizationId,
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Compose response
+	resp := dto.OrganizationLoginResp{
+		OrganizationId:   organizationId,
+		OrganizationName: organizationName,
+	}
+	header := map[string]string{
+		api.AccessTokenKey:    token.Token,
+		api.AccessTokenExpKey: strconv.FormatInt(token.ExpiredAt, 10),
+	}
+
+	return &resp, header, nil
+}
+
+func (s *Organization) ValidateAdmin(session *dto.OrganizationSession) error {
+	isAdmin, err := s.Repository.IsAdmin(session.OrganizationId, session.UserId)
+	if err != nil {
+		s.Logger.Error("unable to check organization admin", err)
+		return err
+	}
+
+	if !isAdmin {
+		return s.Errors.New("ORG003")
+	}
+
+	return nil
+}
+
+func (s *Organization) ListDonations(opt dto.OrganizationDonationListReq) ([]dto.OrganizationDonationResp, error) {
+	// Set time range filter
+	var startAt, endAt pq.NullTime
+	if opt.StartAt > 0 {
+		startAt = pq.NullTime{Time: time.Unix(opt.StartAt, 0), Valid: true}
+	}
+	if opt.EndAt > 0 {
+		endAt = pq.NullTime{Time: time.Unix(opt.EndAt, 0), Valid: true}
+	}
+
+	// Get donations
+	rows, err := s.Repository.FindDonations(opt.OrganizationId, startAt, endAt, opt.Skip, opt.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve organization donations", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.OrganizationDonationResp, len(rows))
+	for k, v := range rows {
+		item := dto.OrganizationDonationResp{
+			Id:            v.Id,
+			InitiativeId:  v.InitiativeId,
+			Quantity:      v.Quantity,
+			TotalDonation: v.TotalPrice,
+			CurrencyName:  api.CurrencyName[v.CurrencyId],
+			Notes:         v.Notes.String,
+			CreatedAt:     v.CreatedAt.Unix(),
+		}
+
+		if i := v.InitiativeSnapshot; i != nil && i.Initiative != nil {
+			item.InitiativeName = i.Name
+		}
+
+		if u := v.UserSnapshot; u != nil && u.UserProfile != nil {
+			item.DonorName = u.FullName
+		}
+
+		resp[k] = item
+	}
+
+	return resp, nil
+}
+
+func (s *Organization) GeneratePayouts(opt dto.OrganizationPayoutGenerateReq) (*dto.OrganizationPayoutGenerateResp, error) {
+	// Determine payout period. If not set, generate for previous month
+	now := time.Now().UTC()
+	var periodStart time.Time
+	if opt.Month != "" {
+		t, err := time.Parse(payoutMonthFormat, opt.Month)
+		if err != nil {
+			s.Logger.Debugf("invalid payout month. Month = %s", opt.Month)
+			return nil, nhttp.ErrBadRequest
+		}
+		periodStart = t
+	} else {
+		periodStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	}
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	// Payout report can only be generated for period that has ended
+	if periodEnd.After(now) {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Set creator
+	createdBy := &model.ModifierMeta{
+		Id:       "SYSTEM",
+		Role:     "SYSTEM",
+		FullName: "SYSTEM",
+	}
+	if opt.ModifiedBy != nil {
+		createdBy = newAdminModifier(*opt.ModifiedBy)
+	}
+
+	// Get active organizations
+	ids, err := s.Repository.FindActiveIds()
+	if err != nil {
+		s.Logger.Error("unable to retrieve active organizations", err)
+		return nil, err
+	}
+
+	resp := dto.OrganizationPayoutGenerateResp{
+		PeriodStart: periodStart.Unix(),
+		PeriodEnd:   periodEnd.Unix(),
+	}
+	// Generate payout per organization, failed organization is retried on next run
+	for _, id := range ids {
+		generated, err := s.generatePayout(id, periodStart, periodEnd, createdBy)
+		if err != nil {
+			s.Logger.Errorf("unable to generate payout report. OrganizationId = %s", id)
+			resp.Failed++
+			continue
+		}
+
+		if generated {
+			resp.Generated++
+		} else {
+			resp.Skipped++
+		}
+	}
+
+	s.Logger.Debugf("Payout report generated. Period = %s, Generated = %d, Skipped = %d, Failed = %d",
+		periodStart.Format(payoutMonthFormat), resp.Generated, resp.Skipped, resp.Failed)
+
+	return &resp, nil
+}
+
+func (s *Organization) generatePayout(organizationId string, periodStart, periodEnd time.Time,
+	createdBy *model.ModifierMeta) (bool, error) {
+	// Check if payout report has been generated for period
+	isExist, err := s.Repository.IsExistPayout(organizationId, periodStart)
+	if err != nil {
+		s.Logger.Error("unable to check organization payout", err)
+		return false, err
+	}
+
+	if isExist {
+		return false, nil
+	}
+
+	// Get collected donation per initiative
+	rows, err := s.Repository.FindDonationSummary(organizationId, periodStart, periodEnd)
+	if err != nil {
+		s.Logger.Error("unable to retrieve organization donation summary", err)
+		return false, err
+	}
+
+	if len(rows) == 0 {
+		return false, nil
+	}
+
+	// Convert collected credits to fiat
+	var totalCredit float64
+	items := make(model.PayoutItemArray, len(rows))
+	totals := make(map[string]float64)
+	for k, v := range rows {
+		item := model.PayoutItem{
+			InitiativeId:       v.InitiativeId,
+			InitiativeName:     v.InitiativeName,
+			DonationConversion: v.DonationConversion,
+			DonationCount:      v.DonationCount,
+			Quantity:           v.Quantity,
+			TotalCredit:        v.TotalCredit,
+		}
+
+		rate, currencyCode, ok := parseDonationConversion(v.DonationConversion)
+		if ok {
+			item.ConversionRate = rate
+			item.CurrencyCode = currencyCode
+			item.Amount = roundFiat(rate * float64(v.Quantity))
+			totals[currencyCode] += item.Amount
+		} else {
+			s.Logger.Debugf("unable to parse donation conversion. InitiativeId = %s, DonationConversion = %s",
+				v.InitiativeId, v.DonationConversion)
+		}
+
+		totalCredit += v.TotalCredit
+		items[k] = item
+	}
+
+	// Create payout report
+	payout := model.OrganizationPayout{
+		Id:             s.IdGen.New(),
+		OrganizationId: organizationId,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		TotalCredit:    totalCredit,
+		Totals:         newPayoutTotals(totals),
+		Items:          items,
+		CreatedAt:      time.Now(),
+		ModifiedBy:     createdBy,
+	}
+
+	// Insert payout report. If report has been generated concurrently for period, then no rows will be inserted
+	return s.Repository.InsertPayout(&payout)
+}
+
+func (s *Organization) ListPayouts(opt dto.OrganizationPayoutListReq) ([]dto.OrganizationPayoutResp, error) {
+	// Get payouts
+	rows, err := s.Repository.FindPayouts(opt.OrganizationId, opt.Skip, opt.Limit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve organization payouts", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.OrganizationPayoutResp, len(rows))
+	for k, v := range rows {
+		resp[k] = composePayout(v)
+	}
+
+	return resp, nil
+}
+
+func (s *Organization) ExportPayout(opt dto.OrganizationPayoutReq) ([]byte, error) {
+	// Get payout
+	payout, err := s.Repository.FindPayoutById(opt.Id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("ORG007")
+		}
+		s.Logger.Error("unable to retrieve organization payout", err)
+		return nil, err
+	}
+
+	// If requested by organization admin, payout must belong to organization
+	if opt.OrganizationId != "" && payout.OrganizationId != opt.OrganizationId {
+		return nil, s.Errors.New("ORG007")
+	}
+
+	// Write csv
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	records := [][]string{
+		{"Initiative Id", "Initiative", "Donations", "Quantity", "Total Credit", "Donation Conversion",
+			"Conversion Rate", "Currency", "Amount"},
+	}
+	for _, v := range payout.Items {
+		records = append(records, []string{
+			v.InitiativeId,
+			csvSafe(v.InitiativeName),
+			strconv.FormatInt(v.DonationCount, 10),
+			strconv.FormatInt(v.Quantity, 10),
+			strconv.FormatFloat(v.TotalCredit, 'f', 2, 64),
+			v.DonationConversion,
+			strconv.FormatFloat(v.ConversionRate, 'f', 2, 64),
+			v.CurrencyCode,
+			strconv.FormatFloat(v.Amount, 'f', 2, 64),
+		})
+	}
+	for _, v := range payout.Totals {
+		records = append(records, []string{
+			"", "Total", "", "", "", "", "", v.CurrencyCode, strconv.FormatFloat(v.Amount, 'f', 2, 64),
+		})
+	}
+
+	err = w.WriteAll(records)
+	if err != nil {
+		s.Logger.Error("unable to write payout csv", err)
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *Organization) runPayoutScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.GeneratePayouts(dto.OrganizationPayoutGenerateReq{})
+		if err != nil {
+			s.Logger.Error("failed to generate organization payout reports", err)
+		}
+	}
+}
+
+func (s *Organization) findById(id string) (*model.Organization, error) {
+	organization, err := s.Repository.FindById(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("ORG001")
+		}
+		s.Logger.Error("failed to FindById organization", err)
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func (s *Organization) update(organization *model.Organization, version int64, modifiedBy dto.ModifierReq) error {
+	organization.CurrentVersion = version
+	organization.Version = version + 1
+	organization.UpdatedAt = time.Now()
+	organization.ModifiedBy = newAdminModifier(modifiedBy)
+
+	return s.Repository.Update(organization)
+}
+
+// parseDonationConversion parses fiat value of a single donation item, e.g. "15000 IDR" or "USD 1.50"
+func parseDonationConversion(conversion string) (rate float64, currencyCode string, ok bool) {
+	fields := strings.Fields(conversion)
+	if len(fields) != 2 {
+		return 0, "", false
+	}
+
+	// Amount can be written before or after currency code
+	for k, v := range fields {
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+		if err != nil || amount < 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+			continue
+		}
+
+		code := strings.ToUpper(fields[1-k])
+		if len(code) != 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+			return 0, "", false
+		}
+
+		return amount, code, true
+	}
+
+	return 0, "", false
+}
+
+func roundFiat(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func newAdminModifier(m dto.ModifierReq) *model.ModifierMeta {
+	return &model.ModifierMeta{
+		Id:       m.Id,
+		Role:     api.ModifierAdmin,
+		FullName: m.FullName,
+	}
+}
+
+func newOrganizationContacts(contacts []dto.OrganizationContact) model.OrganizationContactArray {
+	result := make(model.OrganizationContactArray, len(contacts))
+	for k, v := range contacts {
+		result[k] = model.OrganizationContact{
+			Name:     v.Name,
+			Email:    v.Email,
+			Phone:    v.Phone,
+			Position: v.Position,
+		}
+	}
+	return result
+}
+
+func newPayoutTotals(totals map[string]float64) model.PayoutTotalArray {
+	// Sort currency code for stable output
+	codes := make([]string, 0, len(totals))
+	for k := range totals {
+		codes = append(codes, k)
+	}
+	sort.Strings(codes)
+
+	result := make(model.PayoutTotalArray, len(codes))
+	for k, v := range codes {
+		result[k] = model.PayoutTotal{
+			CurrencyCode: v,
+			Amount:       roundFiat(totals[v]),
+		}
+	}
+	return result
+}
+
+func composeOrganization(o model.Organization) *dto.OrganizationResp {
+	// Determine verified time
+	var verifiedAt int64
+	if o.VerifiedAt.Valid {
+		verifiedAt = o.VerifiedAt.Time.Unix()
+	}
+
+	// Compose contacts
+	contacts := make([]dto.OrganizationContact, len(o.Contacts))
+	for k, v := range o.Contacts {
+		contacts[k] = dto.OrganizationContact{
+			Name:     v.Name,
+			Email:    v.Email,
+			Phone:    v.Phone,
+			Position: v.Position,
+		}
+	}
+
+	return &dto.OrganizationResp{
+		Id:                   o.Id,
+		Name:                 o.Name,
+		OrgTypeId:            o.OrgTypeId,
+		Description:          o.Description.String,
+		LogoFile:             o.LogoFile.String,
+		Contacts:             contacts,
+		VerificationStatusId: o.VerificationStatusId,
+		VerificationNotes:    o.VerificationNotes.String,
+		VerifiedAt:           verifiedAt,
+		VerifiedBy:           composeModifier(o.VerifiedBy),
+		StatusId:             o.StatusId,
+		CreatedAt:            o.CreatedAt.Unix(),
+		UpdatedAt:            o.UpdatedAt.Unix(),
+		ModifiedBy:           composeModifier(o.ModifiedBy),
+		Version:              o.Version,
+	}
+}
+
+func composePayout(p model.OrganizationPayout) dto.OrganizationPayoutResp {
+	items := make([]dto.OrganizationPayoutItemResp, len(p.Items))
+	for k, v := range p.Items {
+		items[k] = dto.OrganizationPayoutItemResp{
+			InitiativeId:       v.InitiativeId,
+			InitiativeName:     v.InitiativeName,
+			DonationConversion: v.DonationConversion,
+			DonationCount:      v.DonationCount,
+			Quantity:           v.Quantity,
+			TotalCredit:        v.TotalCredit,
+			ConversionRate:     v.ConversionRate,
+			CurrencyCode:       v.CurrencyCode,
+			Amount:             v.Amount,
+		}
+	}
+
+	totals := make([]dto.OrganizationPayoutTotalResp, len(p.Totals))
+	for k, v := range p.Totals {
+		totals[k] = dto.OrganizationPayoutTotalResp{
+			CurrencyCode: v.CurrencyCode,
+			Amount:       v.Amount,
+		}
+	}
+
+	return dto.OrganizationPayoutResp{
+		Id:             p.Id,
+		OrganizationId: p.OrganizationId,
+		PeriodStart:    p.PeriodStart.Unix(),
+		PeriodEnd:      p.PeriodEnd.Unix(),
+		TotalCredit:    p.TotalCredit,
+		Totals:         totals,
+		Items:          items,
+		CreatedAt:      p.CreatedAt.Unix(),
+		CreatedBy:      composeModifier(p.ModifiedBy),
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+)
+
+type OrganizationStatement struct {
+	deleteMember          *sqlx.Stmt
+	findActiveIds         *sqlx.Stmt
+	findActiveUserByEmail *sqlx.Stmt
+	findAdminsByEmail     *sqlx.Stmt
+	findById              *sqlx.Stmt
+	findDonations         *sqlx.Stmt
+	findDonationSummary   *sqlx.Stmt
+	findOrganizations     *sqlx.Stmt
+	findPayoutById        *sqlx.Stmt
+	findPayouts           *sqlx.Stmt
+	insert                *sqlx.NamedStmt
+	insertMember          *sqlx.NamedStmt
+	insertPayout          *sqlx.NamedStmt
+	isAdmin               *sqlx.Stmt
+	isExistMember         *sqlx.Stmt
+	isExistPayout         *sqlx.Stmt
+	update                *sqlx.NamedStmt
+}
+
+func initOrganizationStatement(db *nsql.SqlDatabase) OrganizationStatement {
+	return OrganizationStatement{
+		deleteMember:          db.Prepare(`delete from organization_member where organization_id = $1 and user_profile_id = $2`),
+		findActiveIds:         db.Prepare(`select id from organization where status_id = 1 order by id`),
+		findActiveUserByEmail: db.Prepare(`select p.id from user_profile as p inner join user_auth as a on a.id = p.id where p.email = $1 and a.status_id = 1`),
+		findAdminsByEmail:     db.Prepare(`select a.id as user_id, a.password, a.status_id as user_status_id, o.id as organization_id, o.name as organization_name, o.status_id as organization_status_id from user_auth as a inner join organization_member as m on m.user_profile_id = a.id inner join organization as o on o.id = m.organization_id where a.username = $1 and m.member_role_id = 1 order by o.name`),
+		findById:              db.Prepare(`select id, name, org_type_id, description, logo_file, contacts, verification_status_id, verification_notes, verified_at, verified_by, status_id, created_at, updated_at, modified_by, "version" from organization where id = $1`),
+		findDonations:         db.Prepare(`select d.id, d.initiative_id, d.initiative_snapshot, d.user_id, d.user_snapshot, d.payment_method_id, d.payment_snapshot, d.payment_trx_ref, d.qty, d.total_price, d.currency_id, d.status_id, d.notes, d.created_at, d.updated_at, d.modified_by, d.version from donation as d inner join initiative as i on i.id = d.initiative_id where i.organization_id = $1 and d.status_id = 4 and ($2::timestamptz is null or d.created_at >= $2) and ($3::timestamptz is null or d.created_at < $3) order by d.created_at desc limit $4 offset $5`),
+		findDonationSummary:   db.Prepare(`select i.id as initiative_id, i.name as initiative_name, coalesce(i.donation_conversion, '') as donation_conversion, count(d.id) as donation_count, coalesce(sum(d.qty), 0) as qty, coalesce(sum(d.total_price), 0) as total_credit from donation as d inner join initiative as i on i.id = d.initiative_id where i.organization_id = $1 and d.status_id = 4 and d.created_at >= $2 and d.created_at < $3 group by i.id, i.name, i.donation_conversion order by i.name`),
+		findOrganizations:     db.Prepare(`select id, name, org_type_id, description, logo_file, contacts, verification_status_id, verification_notes, verified_at, verified_by, status_id, created_at, updated_at, modified_by, "version" from organization where ($1::smallint = 0 or status_id = $1) and ($2::smallint = 0 or verification_status_id = $2) order by created_at desc, id desc limit $3 offset $4`),
+		findPayoutById:        db.Prepare(`select id, organization_id, period_start, period_end, total_credit, totals, items, created_at, modified_by from organization_payout where id = $1`),
+		findPayouts:           db.Prepare(`select id, organization_id, period_start, period_end, total_credit, totals, items, created_at, modified_by from organization_payout where organization_id = $1 order by period_start desc limit $2 offset $3`),
+		insert:                db.PrepareNamed(`insert into organization(id, name, org_type_id, description, logo_file, contacts, verification_status_id, verification_notes, verified_at, verified_by, status_id, created_at, updated_at, modified_by, "version") values (:id, :name, :org_type_id, :description, :logo_file, :contacts, :verification_status_id, :verification_notes, :verified_at, :verified_by, :status_id, :created_at, :updated_at, :modified_by, :version)`),
+		insertMember:          db.PrepareNamed(`insert into organization_member(id, organization_id, user_profile_id, member_role_id, created_at, updated_at) values (:id, :organization_id, :user_profile_id, :member_role_id, :created_at, :updated_at)`),
+		insertPayout:          db.PrepareNamed(`insert into organization_payout(id, organization_id, period_start, period_end, total_credit, totals, items, created_at, modified_by) values (:id, :organization_id, :period_start, :period_end, :total_credit, :totals, :items, :created_at, :modified_by) on conflict (organization_id, period_start) do nothing`),
+		isAdmin:               db.Prepare(`select exists(select 1 from organization_member as m inner join organization as o on o.id = m.organization_id where m.organization_id = $1 and m.user_profile_id = $2 and m.member_role_id = 1 and o.status_id = 1)`),
+		isExistMember:         db.Prepare(`select exists(select 1 from organization_member where organization_id = $1 and user_profile_id = $2)`),
+		isExistPayout:         db.Prepare(`select exists(select 1 from organization_payout where organization_id = $1 and period_start = $2)`),
+		update:                db.PrepareNamed(`update organization set name = :name, org_type_id = :org_type_id, description = :description, logo_file = :logo_file, contacts = :contacts, verification_status_id = :verification_status_id, verification_notes = :verification_notes, verified_at = :verified_at, verified_by = :verified_by, status_id = :status_id, updated_at = :updated_at, modified_by = :modified_by, "version" = :version where id = :id and "version" = :current_version`),
+	}
+}
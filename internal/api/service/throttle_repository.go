@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/internal/pkg/nthrottle"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/lib/pq"
+	"time"
+)
+
+// NewThrottleRepository creates a throttle store backed by Postgres, so throttle state is shared across instances
+func NewThrottleRepository(db *nsql.SqlDatabase, logger nlog.Logger) nthrottle.Store {
+	r := ThrottleRepository{
+		Db:     db,
+		Stmt:   initThrottleStatement(db),
+		Logger: logger,
+	}
+
+	return &r
+}
+
+type ThrottleRepository struct {
+	Db     *nsql.SqlDatabase
+	Stmt   ThrottleStatement
+	Logger nlog.Logger
+}
+
+func (r *ThrottleRepository) Find(keys []string) ([]nthrottle.Entry, error) {
+	var result []nthrottle.Entry
+	err := r.Stmt.find.Select(&result, pq.Array(keys))
+	return result, err
+}
+
+func (r *ThrottleRepository) Increment(key string, now, resetBefore time.Time) (*nthrottle.Entry, error) {
+	var result nthrottle.Entry
+	err := r.Stmt.increment.Get(&result, key, now, resetBefore)
+	return &result, err
+}
+
+func (r *ThrottleRepository) Block(key string, until time.Time) error {
+	_, err := r.Stmt.block.Exec(key, until)
+	return err
+}
+
+func (r *ThrottleRepository) Delete(keys []string) error {
+	_, err := r.Stmt.delete.Exec(pq.Array(keys))
+	return err
+}
+
+func (r *ThrottleRepository) Purge(before time.Time) (int64, error) {
+	result, err := r.Stmt.purge.Exec(before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
+)
+
+type ThrottleStatement struct {
+	block     *sqlx.Stmt
+	delete    *sqlx.Stmt
+	find      *sqlx.Stmt
+	increment *sqlx.Stmt
+	purge     *sqlx.Stmt
+}
+
+func initThrottleStatement(db *nsql.SqlDatabase) ThrottleStatement {
+	return ThrottleStatement{
+		block:     db.Prepare(`INSERT INTO auth_throttle(key, failures, last_failure_at, blocked_until) VALUES ($1, 0, to_timestamp(0), $2) ON CONFLICT (key) DO UPDATE SET blocked_until = EXCLUDED.blocked_until`),
+		delete:    db.Prepare(`DELETE FROM auth_throttle WHERE key = ANY($1)`),
+		find:      db.Prepare(`SELECT key, failures, last_failure_at, blocked_until FROM auth_throttle WHERE key = ANY($1)`),
+		increment: db.Prepare(`INSERT INTO auth_throttle(key, failures, last_failure_at, blocked_until) VALUES ($1, 1, $2, to_timestamp(0)) ON CONFLICT (key) DO UPDATE SET failures = CASE WHEN auth_throttle.last_failure_at < $3 THEN 1 ELSE auth_throttle.failures + 1 END, last_failure_at = EXCLUDED.last_failure_at RETURNING key, failures, last_failure_at, blocked_until`),
+		purge:     db.Prepare(`DELETE FROM auth_throttle WHERE last_failure_at < $1 AND blocked_until < $1`),
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/diarikom/running-app/running-app-api/pkg/nstr"
 	"github.com/gorilla/mux"
 	"net/http"
+	"strconv"
 )
 
 func NewUserHandler(app *api.Api) UserHandler {
@@ -56,8 +57,8 @@ func (h *UserHandler) PostRefreshToken(r *http.Request) (*nhttp.Success, error)
 
 	// Get dto
 	payload := dto.UserRefreshSession{
-		SessionId:  r.Header.Get(nhttp.KeySessionId),
-		DeviceInfo: reqBody,
+		RefreshToken: r.Header.Get(api.RefreshTokenKey),
+		DeviceInfo:   reqBody,
 	}
 
 	// Call service
@@ -90,6 +91,42 @@ func (h *UserHandler) GetVerifyEmail(_ *http.Request) (*nhttp.Success, error) {
 	return nhttp.OK(), nil
 }
 
+func (h *UserHandler) GetChangeEmail(_ *http.Request) (*nhttp.Success, error) {
+	return nhttp.OK(), nil
+}
+
+func (h *UserHandler) PostChangeEmail(r *http.Request) (*nhttp.Success, error) {
+	// Get new email
+	var reqBody dto.UserChangeEmailReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+	reqBody.ClientIp = nhttp.ClientIP(r)
+
+	// Call service
+	err = h.UserService.RequestEmailChange(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *UserHandler) PutChangeEmail(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	err := h.UserService.ChangeEmail(dto.UserChangeEmailReq{
+		UserId: r.Header.Get(nhttp.KeyUserId),
+		Email:  r.Header.Get(api.KeyNewEmail),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
 func (h *UserHandler) GetResetPassword(_ *http.Request) (*nhttp.Success, error) {
 	return nhttp.OK(), nil
 }
@@ -131,7 +168,7 @@ func (h *UserHandler) PostResetPassword(r *http.Request) (*nhttp.Success, error)
 	}
 
 	// Call service
-	err = h.UserService.RequestResetPassword(reqBody.Email)
+	err = h.UserService.RequestResetPassword(reqBody.Email, nhttp.ClientIP(r))
 	if err != nil {
 		return nil, err
 	}
@@ -179,11 +216,12 @@ func (h *UserHandler) GetProfile(r *http.Request) (*nhttp.Success, error) {
 }
 
 func (h *UserHandler) DeleteLogout(r *http.Request) (*nhttp.Success, error) {
-	// Get user id
+	// Get session
 	userId := r.Header.Get(nhttp.KeyUserId)
+	sessionId := r.Header.Get(nhttp.KeySessionId)
 
 	// Call service
-	err := h.UserService.Logout(userId)
+	err := h.UserService.Logout(sessionId, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -192,6 +230,98 @@ func (h *UserHandler) DeleteLogout(r *http.Request) (*nhttp.Success, error) {
 	return nhttp.OK(), nil
 }
 
+func (h *UserHandler) GetSessions(r *http.Request) (*nhttp.Success, error) {
+	// Get session
+	userId := r.Header.Get(nhttp.KeyUserId)
+	sessionId := r.Header.Get(nhttp.KeySessionId)
+
+	// Call service
+	resp, err := h.UserService.ListSessions(sessionId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) DeleteSession(r *http.Request) (*nhttp.Success, error) {
+	// Get session id
+	vars := mux.Vars(r)
+
+	// Call service
+	err := h.UserService.RevokeSession(dto.UserRevokeSessionReq{
+		Id:     vars["id"],
+		UserId: r.Header.Get(nhttp.KeyUserId),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *UserHandler) DeleteOtherSessions(r *http.Request) (*nhttp.Success, error) {
+	// Get session
+	userId := r.Header.Get(nhttp.KeyUserId)
+	sessionId := r.Header.Get(nhttp.KeySessionId)
+
+	// Call service
+	err := h.UserService.RevokeOtherSessions(sessionId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *UserHandler) PostDataExport(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	err := h.UserService.RequestDataExport(r.Header.Get(nhttp.KeyUserId))
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *UserHandler) PostDeletion(r *http.Request) (*nhttp.Success, error) {
+	// Get password confirmation
+	var reqBody dto.UserDeletionReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	resp, err := h.UserService.RequestDeletion(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) DeleteDeletion(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	err := h.UserService.CancelDeletion(r.Header.Get(nhttp.KeyUserId))
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *UserHandler) PutRunDeletions(_ *http.Request) (*nhttp.Success, error) {
+	// Call service
+	resp, err := h.UserService.DeleteDueAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
 func (h *UserHandler) GetCheckEmail(r *http.Request) (*nhttp.Success, error) {
 	// Get email
 	email := r.URL.Query().Get("email")
@@ -200,7 +330,7 @@ func (h *UserHandler) GetCheckEmail(r *http.Request) (*nhttp.Success, error) {
 	}
 
 	// Call service
-	respBody, err := h.UserService.IsEmailExists(email)
+	respBody, err := h.UserService.IsEmailExists(email, nhttp.ClientIP(r))
 	if err != nil {
 		return nil, err
 	}
@@ -237,6 +367,9 @@ func (h *UserHandler) PostLogin(r *http.Request) (*nhttp.Success, error) {
 		return nil, nhttp.ErrBadRequest
 	}
 
+	// Get client address for throttling
+	reqBody.ClientIp = nhttp.ClientIP(r)
+
 	// Call service
 	header, err := h.UserService.Login(reqBody)
 	if err != nil {
@@ -285,6 +418,143 @@ func (h *UserHandler) GetCreditBalance(r *http.Request) (*nhttp.Success, error)
 	return &nhttp.Success{Result: resp}, nil
 }
 
+func (h *UserHandler) GetAuthProviders(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	resp, err := h.UserService.ListAuthProviders(r.Header.Get(nhttp.KeyUserId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) PostLinkAuthProvider(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.UserAuthProviderReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get user id
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+
+	// Call service
+	resp, err := h.UserService.LinkAuthProvider(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) DeleteUnlinkAuthProvider(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	resp, err := h.UserService.UnlinkAuthProvider(dto.UserAuthProviderReq{
+		UserId:         r.Header.Get(nhttp.KeyUserId),
+		AuthProviderId: nstr.ParseInt(mux.Vars(r)["providerId"], 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) PostLoginTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	var reqBody dto.UserTwoFactorLoginReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get user id and auth provider id from challenge
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+	reqBody.AuthProviderId, _ = strconv.Atoi(r.Header.Get(api.KeyAuthProviderId))
+	reqBody.DeviceInfo.ClientIp = nhttp.ClientIP(r)
+
+	// Call service
+	header, err := h.UserService.LoginByTwoFactor(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compose response
+	resp := nhttp.OK()
+	resp.Header = header
+
+	return resp, nil
+}
+
+func (h *UserHandler) GetTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	resp, err := h.UserService.GetTwoFactor(r.Header.Get(nhttp.KeyUserId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) PostEnrollTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Call service
+	resp, err := h.UserService.EnrollTwoFactor(r.Header.Get(nhttp.KeyUserId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) PutEnableTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	reqBody, err := parseTwoFactorReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call service
+	resp, err := h.UserService.EnableTwoFactor(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
+func (h *UserHandler) DeleteTwoFactor(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	reqBody, err := parseTwoFactorReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call service
+	err = h.UserService.DisableTwoFactor(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return nhttp.OK(), nil
+}
+
+func (h *UserHandler) PostRecoveryCodes(r *http.Request) (*nhttp.Success, error) {
+	// Parse request body
+	reqBody, err := parseTwoFactorReq(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call service
+	resp, err := h.UserService.RegenerateRecoveryCodes(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nhttp.Success{Result: resp}, nil
+}
+
 func (h *UserHandler) GetUserProviderRefId(r *http.Request) (*nhttp.Success, error) {
 	// Get user id
 	reqBody := dto.UserSubscriptionReq{
@@ -407,3 +677,16 @@ func (h *UserHandler) GetValidateVoucher(r *http.Request) (*nhttp.Success, error
 		Result: resp,
 	}, nil
 }
+
+// parseTwoFactorReq parse second factor code from request body, user id and organization id are set by middleware
+func parseTwoFactorReq(r *http.Request) (dto.TwoFactorReq, error) {
+	var reqBody dto.TwoFactorReq
+	err := nhttp.ParseJSON(&reqBody, r)
+	if err != nil {
+		return reqBody, nhttp.ErrBadRequest
+	}
+
+	reqBody.UserId = r.Header.Get(nhttp.KeyUserId)
+	reqBody.OrganizationId = r.Header.Get(api.KeyOrganizationId)
+	return reqBody, nil
+}
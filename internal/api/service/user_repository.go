@@ -1,10 +1,12 @@
 package service
 
 import (
+	"database/sql"
 	"github.com/diarikom/running-app/running-app-api/internal/api"
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"github.com/diarikom/running-app/running-app-api/pkg/nsql"
+	"github.com/jmoiron/sqlx"
 	"time"
 )
 
@@ -157,6 +159,58 @@ func (u *userRepository) InsertSession(userSession model.UserSession) error {
 	return err
 }
 
+func (u *userRepository) FindSessionByRefreshToken(tokenHash string) (*model.UserSession, error) {
+	var session model.UserSession
+	err := u.Stmt.findSessionByRefreshToken.Get(&session, tokenHash)
+	return &session, err
+}
+
+func (u *userRepository) FindSpentRefreshToken(tokenHash string) (*model.UserRefreshToken, error) {
+	var token model.UserRefreshToken
+	err := u.Stmt.findSpentRefreshToken.Get(&token, tokenHash)
+	return &token, err
+}
+
+func (u *userRepository) DeleteSessionFamily(familyId string) error {
+	_, err := u.Stmt.deleteSessionFamily.Exec(familyId)
+	return err
+}
+
+func (u *userRepository) RotateSession(oldSession model.UserSession, newSession model.UserSession, usedAt time.Time) error {
+	return nsql.WithTx(u.Db, u.Logger, func(tx *sqlx.Tx) error {
+		// Delete old session. If refresh token has been rotated concurrently, then no rows will be deleted
+		result, err := nsql.StmtTx(u.Stmt.deleteRotatedSession, tx).Exec(oldSession.Id, oldSession.RefreshTokenHash)
+		if err != nil {
+			return err
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+
+		// Mark refresh token as spent
+		_, err = nsql.NamedStmtTx(u.Stmt.insertSpentRefreshToken, tx).Exec(&model.UserRefreshToken{
+			TokenHash: oldSession.RefreshTokenHash,
+			FamilyId:  oldSession.FamilyId,
+			UserId:    oldSession.UserId,
+			ExpiredAt: oldSession.ExpiredAt,
+			UsedAt:    usedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Insert new session
+		_, err = nsql.NamedStmtTx(u.Stmt.insertUserSession, tx).Exec(&newSession)
+		return err
+	})
+}
+
 func (u *userRepository) FindProfileByEmail(email string) (*model.UserProfile, error) {
 	var profile model.UserProfile
 	err := u.Stmt.findProfileByEmail.Get(&profile, email)
@@ -227,6 +281,31 @@ func (u *userRepository) InsertWithThirdParty(userProfile model.UserProfile, use
 	return nil
 }
 
+func (u *userRepository) InsertThirdParty(thirdParty model.UserAuthThirdParty) error {
+	_, err := u.Stmt.insertUserAuthThirdParty.Exec(&thirdParty)
+	return err
+}
+
+func (u *userRepository) FindThirdPartiesByUser(userId string) ([]model.UserAuthThirdParty, error) {
+	var result []model.UserAuthThirdParty
+	err := u.Stmt.findThirdPartiesByUser.Select(&result, userId)
+	return result, err
+}
+
+func (u *userRepository) DeleteThirdParty(userId string, providerId int) (bool, error) {
+	result, err := u.Stmt.deleteThirdParty.Exec(userId, providerId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 func (u *userRepository) IsExistByEmail(email string) (bool, error) {
 	var isExist bool
 	err := u.Stmt.isExistByEmail.Get(&isExist, email)
@@ -238,3 +317,311 @@ func (u *userRepository) IsExistBy3rdPartyAcc(authProviderId int, accessKey stri
 	err := u.Stmt.isExistBy3rdPartyAcc.Get(&isExist, accessKey, authProviderId)
 	return isExist, err
 }
+
+func (u *userRepository) FindTwoFactor(userId string) (*model.UserTwoFactor, error) {
+	var result model.UserTwoFactor
+	err := u.Stmt.findTwoFactor.Get(&result, userId)
+	return &result, err
+}
+
+func (u *userRepository) UpsertTwoFactor(twoFactor model.UserTwoFactor) error {
+	_, err := u.Stmt.upsertTwoFactor.Exec(&twoFactor)
+	return err
+}
+
+func (u *userRepository) EnableTwoFactor(twoFactor model.UserTwoFactor, codes []model.UserRecoveryCode) error {
+	return nsql.WithTx(u.Db, u.Logger, func(tx *sqlx.Tx) error {
+		// Update status
+		_, err := nsql.NamedStmtTx(u.Stmt.updateTwoFactorStatus, tx).Exec(&twoFactor)
+		if err != nil {
+			return err
+		}
+
+		// Replace recovery codes
+		return u.replaceRecoveryCodes(tx, twoFactor.UserId, codes)
+	})
+}
+
+func (u *userRepository) ReplaceRecoveryCodes(userId string, codes []model.UserRecoveryCode) error {
+	return nsql.WithTx(u.Db, u.Logger, func(tx *sqlx.Tx) error {
+		return u.replaceRecoveryCodes(tx, userId, codes)
+	})
+}
+
+func (u *userRepository) replaceRecoveryCodes(tx *sqlx.Tx, userId string, codes []model.UserRecoveryCode) error {
+	// Delete existing codes
+	_, err := nsql.StmtTx(u.Stmt.deleteRecoveryCodes, tx).Exec(userId)
+	if err != nil {
+		return err
+	}
+
+	// Insert new codes
+	stmt := nsql.NamedStmtTx(u.Stmt.insertRecoveryCode, tx)
+	for _, c := range codes {
+		_, err = stmt.Exec(&c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *userRepository) DeleteTwoFactor(userId string) error {
+	return nsql.WithTx(u.Db, u.Logger, func(tx *sqlx.Tx) error {
+		_, err := nsql.StmtTx(u.Stmt.deleteRecoveryCodes, tx).Exec(userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = nsql.StmtTx(u.Stmt.deleteTwoFactor, tx).Exec(userId)
+		return err
+	})
+}
+
+func (u *userRepository) UpdateTwoFactorStep(userId string, step int64, timestamp time.Time) (bool, error) {
+	// Step is only updated if greater than last used step, so a code can not be used twice
+	result, err := u.Stmt.updateTwoFactorStep.Exec(step, timestamp, userId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userRepository) UseRecoveryCode(userId, codeHash string, usedAt time.Time) (bool, error) {
+	result, err := u.Stmt.useRecoveryCode.Exec(usedAt, userId, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userRepository) CountRecoveryCodes(userId string) (int, error) {
+	var count int
+	err := u.Stmt.countRecoveryCodes.Get(&count, userId)
+	return count, err
+}
+
+func (u *userRepository) UpdateAuthStatus(userId string, fromStatusId, toStatusId int) (bool, error) {
+	// Updated at is not changed, since it is used to sign reset password request
+	result, err := u.Stmt.updateAuthStatus.Exec(toStatusId, userId, fromStatusId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userRepository) CountDevices(userId string) (int, error) {
+	var count int
+	err := u.Stmt.countDevices.Get(&count, userId)
+	return count, err
+}
+
+func (u *userRepository) DeleteDeviceSession(userId, deviceId string) error {
+	_, err := u.Stmt.deleteDeviceSession.Exec(userId, deviceId)
+	return err
+}
+
+func (u *userRepository) DeleteOtherSessions(userId, familyId string) error {
+	_, err := u.Stmt.deleteOtherSessions.Exec(userId, familyId)
+	return err
+}
+
+func (u *userRepository) DeleteUserSessionFamily(userId, familyId string) (bool, error) {
+	result, err := u.Stmt.deleteUserSessionFamily.Exec(userId, familyId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userRepository) FindSessionsByUser(userId string, now time.Time) ([]model.UserSession, error) {
+	var result []model.UserSession
+	err := u.Stmt.findSessionsByUser.Select(&result, userId, now)
+	return result, err
+}
+
+func (u *userRepository) InsertDevice(device model.UserDevice) (bool, error) {
+	// If device is already known, then no rows will be inserted
+	result, err := u.Stmt.insertDevice.Exec(&device)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userRepository) UpdateSessionLastSeen(sessionId string, timestamp time.Time) error {
+	_, err := u.Stmt.updateSessionLastSeen.Exec(timestamp, sessionId)
+	return err
+}
+
+func (u *userRepository) AnonymizeUser(profile model.UserProfile, auth model.UserAuth, snapshot model.UserSnapshot) error {
+	return nsql.WithTx(u.Db, u.Logger, func(tx *sqlx.Tx) error {
+		// Claim deletion request, so it can no longer be cancelled. If deletion has been cancelled concurrently, then
+		// no rows will be updated
+		result, err := nsql.StmtTx(u.Stmt.claimUserDeletion, tx).Exec(profile.Id, profile.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+
+		// Replace personal data in profile and credential
+		_, err = nsql.NamedStmtTx(u.Stmt.anonymizeProfile, tx).Exec(&profile)
+		if err != nil {
+			return err
+		}
+
+		_, err = nsql.NamedStmtTx(u.Stmt.anonymizeAuth, tx).Exec(&auth)
+		if err != nil {
+			return err
+		}
+
+		// Donations are kept as financial records, only user snapshot is replaced
+		_, err = nsql.StmtTx(u.Stmt.anonymizeDonations, tx).Exec(snapshot, profile.Id)
+		if err != nil {
+			return err
+		}
+
+		// Cancel pledges, so no more donations are made on behalf of deleted user
+		_, err = nsql.StmtTx(u.Stmt.cancelPledges, tx).Exec(profile.Id, profile.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		// Delete remaining personal data
+		stmts := []*sqlx.Stmt{
+			u.Stmt.deleteAllSession,
+			u.Stmt.deleteDevices,
+			u.Stmt.deleteFollowedTags,
+			u.Stmt.deleteRecoveryCodes,
+			u.Stmt.deleteRefreshTokens,
+			u.Stmt.deleteRunSessions,
+			u.Stmt.deleteThirdParties,
+			u.Stmt.deleteTwoFactor,
+		}
+		for _, stmt := range stmts {
+			_, err = nsql.StmtTx(stmt, tx).Exec(profile.Id)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (u *userRepository) DeleteUserDeletion(userId string, statusId int8) (bool, error) {
+	result, err := u.Stmt.deleteUserDeletion.Exec(userId, statusId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userRepository) RescheduleUserDeletion(userId string, scheduledAt time.Time) error {
+	_, err := u.Stmt.rescheduleUserDeletion.Exec(userId, scheduledAt)
+	return err
+}
+
+func (u *userRepository) FindChallengesByUser(userId string) ([]model.UserChallenge, error) {
+	var result []model.UserChallenge
+	err := u.Stmt.findChallengesByUser.Select(&result, userId)
+	return result, err
+}
+
+func (u *userRepository) FindDueDeletions(now time.Time, limit int) ([]model.UserDeletion, error) {
+	var result []model.UserDeletion
+	err := u.Stmt.findDueDeletions.Select(&result, now, limit)
+	return result, err
+}
+
+func (u *userRepository) FindSubscriptionsByUser(userId string) ([]model.UserSubscription, error) {
+	var result []model.UserSubscription
+	err := u.Stmt.findSubscriptionsByUser.Select(&result, userId)
+	return result, err
+}
+
+func (u *userRepository) InsertUserDeletion(deletion model.UserDeletion) (bool, error) {
+	// If deletion has been requested, then no rows will be inserted
+	result, err := u.Stmt.insertUserDeletion.Exec(&deletion)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userRepository) UpdateEmail(userId, email string, timestamp time.Time) error {
+	return nsql.WithTx(u.Db, u.Logger, func(tx *sqlx.Tx) error {
+		// Update email, new email is verified since it is confirmed from a link sent to the address
+		_, err := nsql.StmtTx(u.Stmt.updateProfileEmail, tx).Exec(email, timestamp, userId)
+		if err != nil {
+			return err
+		}
+
+		// Update username, so user can log in with the new email
+		_, err = nsql.StmtTx(u.Stmt.updateAuthUsername, tx).Exec(email, timestamp, userId)
+		if err != nil {
+			return err
+		}
+
+		// Revoke sessions, so user must log in again with the new email
+		for _, stmt := range []*sqlx.Stmt{u.Stmt.deleteAllSession, u.Stmt.deleteRefreshTokens} {
+			_, err = nsql.StmtTx(stmt, tx).Exec(userId)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/gob"
 	"encoding/hex"
@@ -17,6 +19,8 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
 	"github.com/diarikom/running-app/running-app-api/internal/api/entity"
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/internal/pkg/notp"
+	"github.com/diarikom/running-app/running-app-api/internal/pkg/nthrottle"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"github.com/diarikom/running-app/running-app-api/pkg/nmailgun"
@@ -32,7 +36,9 @@ import (
 	stripePromotionCode "github.com/stripe/stripe-go/v71/promotioncode"
 	stripeSubscription "github.com/stripe/stripe-go/v71/sub"
 	"golang.org/x/crypto/bcrypt"
+	"net/mail"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -49,28 +55,79 @@ const (
 	/// RequestId, UserId, Email, EmailVerifiedStatus, Salt
 	RawFormatVerifyEmailUserSignature = "VerifyEmail-%s-%s-%s-%d-%s"
 
+	/// RawFormatChangeEmailUserSignature represents formatting for raw signature hashing
+	/// Passing argument must be in order:
+	/// RequestId, UserId, CurrentEmail, NewEmail, Salt
+	RawFormatChangeEmailUserSignature = "ChangeEmail-%s-%s-%s-%s-%s"
+
 	// Default unset password
 	UnsetPassword = "-"
 
+	// Two factor authentication
+	DefaultTwoFactorIssuer = "Running App"
+	TwoFactorSkew          = 1
+	RecoveryCodeCount      = 10
+	RecoveryCodeAlphabet   = "23456789abcdefghjkmnpqrstuvwxyz"
+	RecoveryCodeLength     = 10
+
+	// Throttle key prefixes
+	ThrottleKeyIp        = "ip:"
+	ThrottleKeyEmail     = "email:"
+	ThrottleKeyDevice    = "device:"
+	ThrottleKeyLookupIp  = "lookup_ip:"
+	ThrottleKeyResetMail = "reset_email:"
+	ThrottleKeyTwoFactor = "two_factor:"
+	ThrottleKeyExport    = "data_export:"
+
+	// SessionLastSeenInterval is minimum interval between updates of session last seen time
+	SessionLastSeenInterval = 5 * time.Minute
+
+	// Account deletion
+	DeletedUserFullName    = "Deleted User"
+	DeletedUserEmailFormat = "deleted-%s@users.invalid"
+	DeletionBatchLimit     = 100
+	// DeletionRetryDelay is delay before retrying to cancel subscription of an anonymised account
+	DeletionRetryDelay = time.Hour
+
+	// DataExportPageLimit is page size used to collect paginated resources of personal data export
+	DataExportPageLimit int8 = 100
+
 	// PubSub Topic
 	TopicSendAdvertiserActivationEmail = "send_advertiser_activation_email"
+	TopicExportUserData                = "export_user_data"
 )
 
 type User struct {
 	IdGen                             *api.SnowflakeGen
 	Errors                            *api.Errors
 	Facebook                          api.FacebookProviderComponent
+	Google                            api.IdTokenProviderComponent
+	Apple                             api.IdTokenProviderComponent
 	Mailer                            api.MailerComponent
 	Logger                            nlog.Logger
 	Config                            *viper.Viper
 	BaseUrl                           string
 	UserAccessLifetime                int
+	UserRefreshLifetime               int
 	ResetPasswordTokenLifetime        int
 	VerifyEmailTokenLifetime          int
+	ChangeEmailTokenLifetime          int
+	TwoFactorTokenLifetime            int
+	TwoFactorIssuer                   string
+	LockoutAttempts                   int
+	LockoutDuration                   int
+	DeletionGracePeriod               int
+	DataExportLifetime                int
+	DataExportCooldown                int
+	Throttler                         *nthrottle.Throttler
 	SignatureSaltResetPasswordSubject string
 	SignatureSaltVerifyEmailSubject   string
+	SignatureSaltChangeEmailSubject   string
 	AuthService                       api.AuthenticatorService
 	AssetService                      api.AssetService
+	CreditService                     api.CreditService
+	InitiativeService                 api.InitiativeService
+	RunService                        api.RunService
 	UserRepository                    api.UserRepository
 	PubSub                            *gochannel.GoChannel
 }
@@ -79,19 +136,57 @@ func (s *User) Init(app *api.Api) error {
 	s.IdGen = app.Components.Id
 	s.Errors = app.Components.Errors
 	s.Facebook = app.Components.Facebook
+	s.Google = app.Components.Google
+	s.Apple = app.Components.Apple
 	s.Mailer = app.Components.Mailer
 	s.Logger = app.Logger
 	s.Config = app.Config
 	s.BaseUrl = app.BaseUrl.String()
 	s.UserAccessLifetime = app.Config.GetInt(api.ConfUserAccessLifetime)
+	s.UserRefreshLifetime = app.Config.GetInt(api.ConfUserRefreshLifetime)
 	s.ResetPasswordTokenLifetime = app.Config.GetInt(api.ConfResetPasswordTokenLifetime)
 	s.VerifyEmailTokenLifetime = app.Config.GetInt(api.ConfVerifyEmailTokenLifetime)
+	s.ChangeEmailTokenLifetime = app.Config.GetInt(api.ConfChangeEmailTokenLifetime)
+	s.TwoFactorTokenLifetime = app.Config.GetInt(api.ConfTwoFactorTokenLifetime)
+	s.TwoFactorIssuer = app.Config.GetString(api.ConfTwoFactorIssuer)
+	if s.TwoFactorIssuer == "" {
+		s.TwoFactorIssuer = DefaultTwoFactorIssuer
+	}
 	s.SignatureSaltResetPasswordSubject = app.Config.GetString(api.ConfSignatureSaltResetPasswordSubject)
 	s.SignatureSaltVerifyEmailSubject = app.Config.GetString(api.ConfSignatureSaltEmailVerifySubject)
+	s.SignatureSaltChangeEmailSubject = app.Config.GetString(api.ConfSignatureSaltChangeEmailSubject)
 	s.AuthService = app.Services.Auth
 	s.AssetService = app.Services.Asset
+	s.CreditService = app.Services.Credit
+	s.InitiativeService = app.Services.Initiative
+	s.RunService = app.Services.Run
 	s.UserRepository = NewUserRepository(app.Datasources.Db, app.Logger)
 
+	// Init login throttler
+	s.LockoutAttempts = app.Config.GetInt(api.ConfThrottleLockoutAttempts)
+	s.LockoutDuration = app.Config.GetInt(api.ConfThrottleLockoutDuration)
+	s.Throttler = nthrottle.New(NewThrottleRepository(app.Datasources.Db, app.Logger), nthrottle.Policy{
+		FreeAttempts: app.Config.GetInt(api.ConfThrottleFreeAttempts),
+		BaseDelay:    time.Duration(app.Config.GetInt(api.ConfThrottleBaseDelay)) * time.Second,
+		MaxDelay:     time.Duration(app.Config.GetInt(api.ConfThrottleMaxDelay)) * time.Second,
+		Window:       time.Duration(app.Config.GetInt(api.ConfThrottleWindow)) * time.Minute,
+	})
+
+	// Start throttle purge scheduler
+	if purgeInterval := app.Config.GetInt(api.ConfThrottlePurgeInterval); purgeInterval > 0 {
+		go s.runThrottlePurgeScheduler(time.Duration(purgeInterval) * time.Minute)
+	}
+
+	// Start account deletion scheduler
+	s.DeletionGracePeriod = app.Config.GetInt(api.ConfUserDeletionGracePeriod)
+	if deletionInterval := app.Config.GetInt(api.ConfUserDeletionInterval); deletionInterval > 0 {
+		go s.runDeletionScheduler(time.Duration(deletionInterval) * time.Minute)
+	}
+
+	// Set personal data export
+	s.DataExportLifetime = app.Config.GetInt(api.ConfUserDataExportLifetime)
+	s.DataExportCooldown = app.Config.GetInt(api.ConfUserDataExportCooldown)
+
 	// Set stripe secret key
 	stripe.Key = app.Config.GetString(api.ConfStripeSecretKey)
 
@@ -105,6 +200,13 @@ func (s *User) Init(app *api.Api) error {
 	}
 	go s.handleSendAdvertiserActivationEmail(msg)
 
+	// Subscribe to export user data
+	msg, err = s.PubSub.Subscribe(context.Background(), TopicExportUserData)
+	if err != nil {
+		return err
+	}
+	go s.handleExportUserData(msg)
+
 	return nil
 }
 
@@ -761,7 +863,8 @@ func (s *User) RefreshSession(payload dto.UserRefreshSession) (map[string]string
 	d := payload.DeviceInfo
 
 	// Validate input
-	if d.DevicePlatformId == 0 ||
+	if payload.RefreshToken == "" ||
+		d.DevicePlatformId == 0 ||
 		d.DeviceId == "" ||
 		d.DeviceModel == "" ||
 		d.DeviceManufacturer == "" ||
@@ -770,13 +873,22 @@ func (s *User) RefreshSession(payload dto.UserRefreshSession) (map[string]string
 		return nil, nhttp.ErrBadRequest
 	}
 
-	// Get existing session
-	session, err := s.UserRepository.FindSessionById(payload.SessionId)
+	// Get session by refresh token
+	tokenHash := hashRefreshToken(payload.RefreshToken)
+	session, err := s.UserRepository.FindSessionByRefreshToken(tokenHash)
 	if err != nil {
-		s.Logger.Error("unable to find session by id", err)
+		if err == sql.ErrNoRows {
+			return nil, s.revokeSpentRefreshToken(tokenHash)
+		}
+		s.Logger.Error("unable to find session by refresh token", err)
 		return nil, err
 	}
 
+	// Check expiry
+	if session.ExpiredAt.Before(time.Now()) {
+		return nil, s.Errors.New("USR003")
+	}
+
 	// Validate device
 	if d.DeviceId != session.DeviceId ||
 		d.DevicePlatformId != session.DevicePlatformId ||
@@ -786,29 +898,54 @@ func (s *User) RefreshSession(payload dto.UserRefreshSession) (map[string]string
 		return nil, err
 	}
 
-	// Delete current session
-	err = s.UserRepository.DeleteSessionById(session.Id)
+	// Set auth provider
+	d.AuthProviderId = session.AuthProviderId
+
+	// Create new session in the same family
+	newSession, token, err := s.newSession(session.UserId, session.FamilyId, d)
 	if err != nil {
-		s.Logger.Error("unable to delete session by id", err)
 		return nil, err
 	}
 
-	// Set auth provider
-	d.AuthProviderId = session.AuthProviderId
+	// Keep login time of the family, so it is listed as a single session
+	newSession.CreatedAt = session.CreatedAt
 
-	// Create session request body
-	token, err := s.NewSession(session.UserId, d)
+	// Replace current session and spend refresh token
+	err = s.UserRepository.RotateSession(*session, *newSession, newSession.UpdatedAt)
 	if err != nil {
+		// Refresh token has been spent by concurrent request
+		if err == sql.ErrNoRows {
+			return nil, s.revokeSpentRefreshToken(tokenHash)
+		}
+		s.Logger.Error("unable to rotate session", err)
 		return nil, err
 	}
 
-	// Return header
-	header := map[string]string{
-		api.AccessTokenKey:    token.Token,
-		api.AccessTokenExpKey: strconv.FormatInt(token.ExpiredAt, 10),
+	return composeSessionHeader(token), nil
+}
+
+// revokeSpentRefreshToken revokes every session in the family of a refresh token that has already been used.
+// A spent refresh token can only be presented again if it has been leaked, so the whole family is no longer trusted
+func (s *User) revokeSpentRefreshToken(tokenHash string) error {
+	spent, err := s.UserRepository.FindSpentRefreshToken(tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nhttp.ErrUnauthorized
+		}
+		s.Logger.Error("unable to find spent refresh token", err)
+		return err
+	}
+
+	s.Logger.Warnf("refresh token reused, revoking session family. UserId = %s, FamilyId = %s", spent.UserId,
+		spent.FamilyId)
+
+	err = s.UserRepository.DeleteSessionFamily(spent.FamilyId)
+	if err != nil {
+		s.Logger.Error("unable to delete session family", err)
+		return err
 	}
 
-	return header, err
+	return s.Errors.New("USR020")
 }
 
 func (s *User) UpdateProfile(req dto.UserUpdateProfileReq) error {
@@ -925,12 +1062,216 @@ func (s *User) ValidateVerifyEmailSignature(session *dto.VerifyEmailSession) (st
 	return user.Id, nil
 }
 
-func (s *User) RequestResetPassword(email string) error {
+// RequestEmailChange sends confirmation link to the new email and a notice to the current email. Email is changed
+// once the link is confirmed
+func (s *User) RequestEmailChange(req dto.UserChangeEmailReq) error {
+	// Validate email. Only bare address is accepted, not address with display name
+	req.Email = strings.TrimSpace(req.Email)
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil || addr.Address != req.Email {
+		return nhttp.ErrBadRequest
+	}
+
+	// Count request, so change email can not be used to look up registered emails
+	err = s.throttleLookup(req.ClientIp)
+	if err != nil {
+		return err
+	}
+
+	// Get user auth and profile
+	auth, err := s.UserRepository.FindAuthById(req.UserId)
+	if err != nil {
+		s.Logger.Error("unable to find user auth by id", err)
+		return err
+	}
+
+	profile, err := s.UserRepository.FindProfileById(req.UserId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve user profile", err)
+		return err
+	}
+
+	// If password is set, confirm password
+	if auth.Password != UnsetPassword {
+		err = bcrypt.CompareHashAndPassword([]byte(auth.Password), []byte(req.Password))
+		if err != nil {
+			return s.Errors.New("USR007")
+		}
+	}
+
+	if strings.EqualFold(req.Email, profile.Email) {
+		return s.Errors.New("USR033")
+	}
+
+	// Validate email is exist
+	isExist, err := s.UserRepository.IsExistByEmail(req.Email)
+	if err != nil {
+		s.Logger.Error("unable to check email is exist", err)
+		return err
+	}
+	if isExist {
+		return s.Errors.New("USR001")
+	}
+
+	// Create request id as session
+	reqId := s.IdGen.New()
+
+	// Sign user with current email, so token can not be used once email has changed
+	signature, err := s.AuthService.SignMd5(dto.SignatureReq{
+		Format: RawFormatChangeEmailUserSignature,
+		Args: []interface{}{
+			reqId,
+			profile.Id,
+			profile.Email,
+			req.Email,
+			s.SignatureSaltChangeEmailSubject,
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to sign subject for change email", err)
+		return err
+	}
+
+	// Create purpose token
+	token, err := s.AuthService.NewOneTimeToken(dto.JWTOptReq{
+		Subject:   profile.Id,
+		SessionId: reqId,
+		Lifetime:  s.ChangeEmailTokenLifetime,
+		Purpose:   api.JWTPurposeChangeEmail,
+		Extras: map[string]string{
+			api.NewEmailKey:      req.Email,
+			api.UserSignatureKey: signature,
+		}})
+	if err != nil {
+		return err
+	}
+
+	// Send confirmation to the new email
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{req.Email},
+		Subject:      "Running App - Confirm Email Change",
+		TemplateFile: "change_email.html",
+		TemplateData: struct {
+			URL string
+		}{
+			URL: fmt.Sprintf("%s/users/email-change?t=%s", s.BaseUrl, token.Token),
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send change email confirmation", err)
+		return err
+	}
+
+	// Send notice to the current email
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{profile.Email},
+		Subject:      "Running App - Email Change Requested",
+		TemplateFile: "email_change_requested.html",
+		TemplateData: struct {
+			NewEmail string
+		}{
+			NewEmail: req.Email,
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send email change notice", err)
+	}
+
+	return nil
+}
+
+func (s *User) ValidateChangeEmailSignature(session *dto.ChangeEmailSession) (string, error) {
+	// Check session
+	if session.UserId == "" || session.NewEmail == "" {
+		return "", nhttp.ErrBadRequest
+	}
+
+	// Get profile
+	user, err := s.UserRepository.FindProfileById(session.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", s.Errors.New("USR008")
+		}
+		s.Logger.Error("unable to retrieve user profile", err)
+		return "", err
+	}
+
+	// Generate signature
+	signature, err := s.AuthService.SignMd5(dto.SignatureReq{
+		Format: RawFormatChangeEmailUserSignature,
+		Args: []interface{}{
+			session.RequestId,
+			user.Id,
+			user.Email,
+			session.NewEmail,
+			s.SignatureSaltChangeEmailSubject,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Compare signature
+	if signature != session.UserSignature {
+		return "", s.Errors.New("USR010")
+	}
+
+	return user.Id, nil
+}
+
+// ChangeEmail swaps user email to the confirmed new email and sync the email to Stripe customer. Email change is
+// confirmed from a link instead of a session, so all sessions of user are revoked
+func (s *User) ChangeEmail(req dto.UserChangeEmailReq) error {
+	// Email may have been registered after change is requested
+	isExist, err := s.UserRepository.IsExistByEmail(req.Email)
+	if err != nil {
+		s.Logger.Error("unable to check email is exist", err)
+		return err
+	}
+	if isExist {
+		return s.Errors.New("USR001")
+	}
+
+	// Update Stripe customer first, so email change can be retried if it fails
+	refId, err := s.UserRepository.FindProviderRefId(api.ProviderStripe, req.UserId)
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("unable to find user reference id in provider", err)
+		return err
+	}
+
+	if refId != "" {
+		_, err = stripeCustomer.Update(refId, &stripe.CustomerParams{Email: stripe.String(req.Email)})
+		if err != nil {
+			s.Logger.Error("unable to update Stripe Customer email", err)
+			return err
+		}
+	}
+
+	// Persist new email and revoke sessions. Reset password token of the old email is no longer valid, since it is
+	// signed with the old email and the credential update time
+	err = s.UserRepository.UpdateEmail(req.UserId, req.Email, time.Now())
+	if err != nil {
+		s.Logger.Error("unable to persist email change", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *User) RequestResetPassword(email string, clientIp string) error {
 	// Check email
 	if email == "" {
 		return nhttp.ErrBadRequest
 	}
 
+	// Every request is counted, to prevent email enumeration and flooding a mailbox
+	err := s.throttleLookup(clientIp, ThrottleKeyResetMail+normalizeEmail(email))
+	if err != nil {
+		return err
+	}
+
 	// Check if email exist
 	user, err := s.UserRepository.FindAuthByEmail(email)
 	if err != nil {
@@ -1051,6 +1392,14 @@ func (s *User) ChangePassword(req dto.ChangePasswordReq) error {
 		return err
 	}
 
+	// Password reset proves ownership of email, unlock account
+	if req.Reset && auth.StatusId == api.UserLocked {
+		err = s.unlockAccount(auth)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1110,10 +1459,98 @@ func (s *User) GetProfile(userId string) (*dto.UserProfileResp, error) {
 	return &respBody, nil
 }
 
-func (s *User) Logout(userId string) error {
-	// Clear user session
-	err := s.UserRepository.DeleteAllSession(userId)
-	return err
+func (s *User) Logout(sessionId, userId string) error {
+	// Revoke current session only, sessions on other devices remain active
+	session, err := s.findUserSession(sessionId, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.UserRepository.DeleteSessionFamily(session.FamilyId)
+	if err != nil {
+		s.Logger.Error("unable to delete session", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *User) ListSessions(sessionId, userId string) ([]dto.UserSessionResp, error) {
+	// Get current session
+	current, err := s.findUserSession(sessionId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get active sessions
+	sessions, err := s.UserRepository.FindSessionsByUser(userId, time.Now())
+	if err != nil {
+		s.Logger.Error("unable to retrieve user sessions", err)
+		return nil, err
+	}
+
+	// Compose response
+	resp := make([]dto.UserSessionResp, len(sessions))
+	for i, v := range sessions {
+		resp[i] = composeUserSession(v, v.FamilyId == current.FamilyId)
+	}
+
+	return resp, nil
+}
+
+func (s *User) RevokeSession(req dto.UserRevokeSessionReq) error {
+	// Validate request
+	if req.Id == "" {
+		return nhttp.ErrBadRequest
+	}
+
+	// Session id in response is family id, since session id changes on every refresh
+	ok, err := s.UserRepository.DeleteUserSessionFamily(req.UserId, req.Id)
+	if err != nil {
+		s.Logger.Error("unable to delete session", err)
+		return err
+	}
+
+	if !ok {
+		return s.Errors.New("USR029")
+	}
+
+	return nil
+}
+
+func (s *User) RevokeOtherSessions(sessionId, userId string) error {
+	// Get current session
+	current, err := s.findUserSession(sessionId, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.UserRepository.DeleteOtherSessions(userId, current.FamilyId)
+	if err != nil {
+		s.Logger.Error("unable to delete other sessions", err)
+		return err
+	}
+
+	return nil
+}
+
+// findUserSession retrieves session by id and make sure it belongs to user
+func (s *User) findUserSession(sessionId, userId string) (*model.UserSession, error) {
+	session, err := s.UserRepository.FindSessionById(sessionId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nhttp.ErrUnauthorized
+		}
+
+		s.Logger.Error("unable to find session", err)
+		return nil, err
+	}
+
+	if session.UserId != userId {
+		return nil, nhttp.ErrUnauthorized
+	}
+
+	return session, nil
 }
 
 func (s *User) ValidateSession(sessionId, userId string) error {
@@ -1134,26 +1571,120 @@ func (s *User) ValidateSession(sessionId, userId string) error {
 	}
 
 	// Check expiry
-	if session.ExpiredAt.Unix() < time.Now().Unix() {
+	now := time.Now()
+	if session.ExpiredAt.Unix() < now.Unix() {
 		s.Logger.Debugf("token has expired. UserId: %s", userId)
 		return s.Errors.New("USR003")
 	}
 
+	// Update last seen, throttled to avoid a write on every request
+	if now.Sub(session.LastSeenAt) > SessionLastSeenInterval {
+		err = s.UserRepository.UpdateSessionLastSeen(session.Id, now)
+		if err != nil {
+			s.Logger.Error("unable to update session last seen", err)
+		}
+	}
+
 	return nil
 }
 
-func (s *User) NewSession(userId string, req dto.UserLoginReq) (*entity.AccessToken, error) {
-	// New session id
+func (s *User) NewSession(auth *model.UserAuth, req dto.UserLoginReq) (*entity.SessionToken, error) {
+	// Create session in a new family
+	session, token, err := s.newSession(auth.Id, "", req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace previous session of the same device
+	err = s.UserRepository.DeleteDeviceSession(auth.Id, req.DeviceId)
+	if err != nil {
+		s.Logger.Error("unable to clear device session", err)
+		return nil, err
+	}
+
+	// Persist session
+	err = s.UserRepository.InsertSession(*session)
+	if err != nil {
+		s.Logger.Error("unable to persist new session", err)
+		return nil, err
+	}
+
+	// Remember device and notify owner if it is a new one
+	s.registerDevice(auth, session, req.ClientIp)
+
+	return token, nil
+}
+
+// registerDevice stores device of session and send notification if user logs in from an unknown device. First device
+// of user does not trigger notification. Errors are only logged, so it does not fail the login
+func (s *User) registerDevice(auth *model.UserAuth, session *model.UserSession, clientIp string) {
+	// Count known devices before the new one is stored
+	count, err := s.UserRepository.CountDevices(auth.Id)
+	if err != nil {
+		s.Logger.Error("unable to count user devices", err)
+		return
+	}
+
+	isNew, err := s.UserRepository.InsertDevice(model.UserDevice{
+		UserId:             auth.Id,
+		Signature:          session.Signature,
+		DevicePlatformId:   session.DevicePlatformId,
+		DeviceManufacturer: session.DeviceManufacturer,
+		DeviceModel:        session.DeviceModel,
+		CreatedAt:          session.CreatedAt,
+	})
+	if err != nil {
+		s.Logger.Error("unable to persist user device", err)
+		return
+	}
+
+	if !isNew || count == 0 || auth.Username == "" {
+		return
+	}
+
+	if clientIp == "" {
+		clientIp = "Unknown"
+	}
+
+	// Send notification
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{auth.Username},
+		Subject:      "Running App - New Device Login",
+		TemplateFile: "new_device_login.html",
+		TemplateData: struct {
+			Device    string
+			IpAddress string
+			LoginAt   string
+		}{
+			Device:    fmt.Sprintf("%s %s", session.DeviceManufacturer, session.DeviceModel),
+			IpAddress: clientIp,
+			LoginAt:   session.CreatedAt.UTC().Format("02 Jan 2006 15:04 MST"),
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send new device login email", err)
+	}
+}
+
+// newSession creates a session with a new access token and refresh token. If familyId is empty, then session starts a
+// new family
+func (s *User) newSession(userId, familyId string, req dto.UserLoginReq) (*model.UserSession, *entity.SessionToken,
+	error) {
+	// New session id
 	sessionId := s.IdGen.New()
+	if familyId == "" {
+		familyId = sessionId
+	}
 
 	// Create token
-	token, err := s.AuthService.NewAccessToken(dto.JWTOptReq{
+	accessToken, err := s.AuthService.NewAccessToken(dto.JWTOptReq{
 		Subject:   userId,
 		SessionId: sessionId,
 		Lifetime:  s.UserAccessLifetime,
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Init timestamp
@@ -1164,11 +1695,17 @@ func (s *User) NewSession(userId string, req dto.UserLoginReq) (*entity.AccessTo
 	hasher := md5.New()
 	_, err = hasher.Write([]byte(signatureRaw))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	signature := hex.EncodeToString(hasher.Sum(nil))
 
-	// TODO: Create refresh token
+	// Create refresh token, only the hash is stored
+	refreshToken, err := gonanoid.Nanoid(64)
+	if err != nil {
+		s.Logger.Error("unable to generate refresh token", err)
+		return nil, nil, err
+	}
+	refreshExpiredAt := timestamp.Add(time.Duration(s.UserRefreshLifetime) * time.Minute)
 
 	// Create session
 	session := model.UserSession{
@@ -1182,19 +1719,21 @@ func (s *User) NewSession(userId string, req dto.UserLoginReq) (*entity.AccessTo
 		NotificationChannelId: req.NotificationChannel,
 		NotificationToken:     req.NotificationToken,
 		Signature:             signature,
-		ExpiredAt:             time.Unix(token.ExpiredAt, 0),
+		FamilyId:              familyId,
+		RefreshTokenHash:      hashRefreshToken(refreshToken),
+		ExpiredAt:             refreshExpiredAt,
+		LastSeenAt:            timestamp,
 		CreatedAt:             timestamp,
 		UpdatedAt:             timestamp,
 	}
 
-	// Persist session
-	err = s.UserRepository.InsertSession(session)
-	if err != nil {
-		s.Logger.Error("unable to persist new session", err)
-		return nil, err
+	token := entity.SessionToken{
+		AccessToken:      *accessToken,
+		RefreshToken:     refreshToken,
+		RefreshExpiredAt: refreshExpiredAt.Unix(),
 	}
 
-	return token, nil
+	return &session, &token, nil
 }
 
 func (s *User) Login(req dto.UserLoginReq) (map[string]string, error) {
@@ -1212,6 +1751,10 @@ func (s *User) Login(req dto.UserLoginReq) (map[string]string, error) {
 	switch req.AuthProviderId {
 	case api.FacebookAuthProvider:
 		return s.LoginByFacebook(req)
+	case api.GoogleAuthProvider:
+		return s.LoginByGoogle(req)
+	case api.AppleAuthProvider:
+		return s.LoginByApple(req)
 	default:
 		return s.LoginByEmail(req)
 	}
@@ -1224,25 +1767,78 @@ func (s *User) LoginByEmail(req dto.UserLoginReq) (map[string]string, error) {
 		return nil, nhttp.ErrBadRequest
 	}
 
+	// Validate credential
+	auth, err := s.VerifyPassword(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// If two factor is enabled, return challenge token instead of session
+	isTwoFactor, err := s.IsTwoFactorEnabled(auth.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTwoFactor {
+		return s.NewTwoFactorChallenge(dto.TwoFactorSession{
+			UserId: auth.Id,
+			Target: api.TwoFactorTargetUser,
+		})
+	}
+
+	// Set auth provider
+	req.AuthProviderId = api.AppAuthProvider
+
+	// Generate Session
+	token, err := s.NewSession(auth, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeSessionHeader(token), nil
+}
+
+// VerifyPassword validates email and password of a login attempt. Attempts are throttled by email, device and client ip,
+// and a locked account is unlocked once its lockout has expired. Unknown email, account without password and invalid
+// password are answered with the same USR007 error, so registered emails can not be enumerated
+func (s *User) VerifyPassword(req dto.UserLoginReq) (*model.UserAuth, error) {
+	// Check if attempt is throttled
+	emailKey := ThrottleKeyEmail + normalizeEmail(req.Email)
+	keys := []string{emailKey}
+	if req.DeviceId != "" {
+		keys = append(keys, ThrottleKeyDevice+req.DeviceId)
+	}
+	if req.ClientIp != "" {
+		keys = append(keys, ThrottleKeyIp+req.ClientIp)
+	}
+
+	err := s.checkThrottle(keys...)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get user auth by email
 	auth, err := s.UserRepository.FindAuthByEmail(req.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nhttp.ErrUnauthorized
+			s.failLogin(keys, nil)
+			return nil, s.Errors.New("USR007")
 		}
 
 		s.Logger.Error("unable to retrieve UserAuth", err)
 		return nil, err
 	}
 
-	// Check if password unset
+	// Check if password unset. Account is not locked since it can not be logged in by password
 	if auth.Password == UnsetPassword {
-		return nil, s.Errors.New("USR011")
+		s.failLogin(keys, nil)
+		return nil, s.Errors.New("USR007")
 	}
 
 	// Validate password
 	err = bcrypt.CompareHashAndPassword([]byte(auth.Password), []byte(req.Password))
 	if err != nil {
+		s.failLogin(keys, auth)
 		return nil, s.Errors.New("USR007")
 	}
 
@@ -1251,50 +1847,113 @@ func (s *User) LoginByEmail(req dto.UserLoginReq) (map[string]string, error) {
 		return nil, s.Errors.New("USR002")
 	}
 
-	// Set auth provider
-	req.AuthProviderId = api.AppAuthProvider
+	// Lockout has expired since email is no longer throttled, unlock account
+	if auth.StatusId == api.UserLocked {
+		err = s.unlockAccount(auth)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// Clear user session
-	err = s.UserRepository.DeleteAllSession(auth.Id)
+	// Clear failures of email. Failures of ip and device are kept, so a valid account can not be used to reset them
+	err = s.Throttler.Reset(emailKey)
 	if err != nil {
-		s.Logger.Error("unable to clear user session", err)
+		s.Logger.Error("unable to reset login throttle", err)
 		return nil, err
 	}
 
-	// Generate Session
-	token, err := s.NewSession(auth.Id, req)
+	return auth, nil
+}
+
+func (s *User) LoginByTwoFactor(req dto.UserTwoFactorLoginReq) (map[string]string, error) {
+	// Validate device info
+	device := req.DeviceInfo
+	if device.DevicePlatformId == 0 ||
+		device.DeviceId == "" ||
+		device.DeviceModel == "" ||
+		device.DeviceManufacturer == "" ||
+		device.NotificationChannel == 0 ||
+		device.NotificationToken == "" {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Verify second factor
+	err := s.VerifyTwoFactor(dto.TwoFactorReq{UserId: req.UserId, Code: req.Code})
 	if err != nil {
 		return nil, err
 	}
 
-	// Return header
-	header := map[string]string{
-		api.AccessTokenKey:    token.Token,
-		api.AccessTokenExpKey: strconv.FormatInt(token.ExpiredAt, 10),
+	// Validate user status, user may have been suspended after challenge is issued
+	auth, err := s.UserRepository.FindAuthById(req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nhttp.ErrUnauthorized
+		}
+
+		s.Logger.Error("unable to retrieve UserAuth", err)
+		return nil, err
 	}
 
-	return header, nil
+	if auth.StatusId == api.UserSuspended {
+		return nil, s.Errors.New("USR002")
+	}
+
+	// Set auth provider of login that is challenged
+	device.AuthProviderId = req.AuthProviderId
+	if device.AuthProviderId == 0 {
+		device.AuthProviderId = api.AppAuthProvider
+	}
+
+	// Generate Session
+	token, err := s.NewSession(auth, device)
+	if err != nil {
+		return nil, err
+	}
+
+	return composeSessionHeader(token), nil
 }
 
 func (s *User) LoginByFacebook(req dto.UserLoginReq) (map[string]string, error) {
-	// Validate required fields
-	if req.ThirdPartyToken == "" {
-		return nil, nhttp.ErrBadRequest
-	}
+	// Set auth provider
+	req.AuthProviderId = api.FacebookAuthProvider
+	return s.loginByThirdParty(req)
+}
+
+func (s *User) LoginByGoogle(req dto.UserLoginReq) (map[string]string, error) {
+	// Set auth provider
+	req.AuthProviderId = api.GoogleAuthProvider
+	return s.loginByThirdParty(req)
+}
+
+func (s *User) LoginByApple(req dto.UserLoginReq) (map[string]string, error) {
+	// Set auth provider
+	req.AuthProviderId = api.AppleAuthProvider
+	return s.loginByThirdParty(req)
+}
 
-	facebookUser, err := s.Facebook.InspectToken(req.ThirdPartyToken)
+// loginByThirdParty creates session for user that is linked to third party account of req.AuthProviderId. If third
+// party account has not been linked but its verified email is registered, then it is linked to the registered user
+func (s *User) loginByThirdParty(req dto.UserLoginReq) (map[string]string, error) {
+	// Verify third party token
+	identity, err := s.verifyThirdParty(req.AuthProviderId, req.ThirdPartyToken, req.Nonce)
 	if err != nil {
-		s.Logger.Error("unable to inspect facebook user token", err)
-		return nil, nhttp.ErrUnauthorized
+		return nil, err
 	}
 
-	// Get user auth by facebook id
-	auth, err := s.UserRepository.FindAuthByThirdParty(facebookUser.UserId, api.FacebookAuthProvider)
+	// Get user auth by third party account id
+	auth, err := s.UserRepository.FindAuthByThirdParty(identity.AccessKey, req.AuthProviderId)
+	if err == sql.ErrNoRows {
+		auth, err = s.mergeThirdParty(req.AuthProviderId, identity)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, s.Errors.New("USR008")
 		}
 
+		if _, ok := err.(nhttp.Error); ok {
+			return nil, err
+		}
+
 		s.Logger.Error("unable to retrieve UserAuth", err)
 		return nil, err
 	}
@@ -1304,26 +1963,102 @@ func (s *User) LoginByFacebook(req dto.UserLoginReq) (map[string]string, error)
 		return nil, s.Errors.New("USR002")
 	}
 
-	// Clear user session
-	err = s.UserRepository.DeleteAllSession(auth.Id)
+	// If two factor is enabled, return challenge token instead of session
+	isTwoFactor, err := s.IsTwoFactorEnabled(auth.Id)
 	if err != nil {
-		s.Logger.Error("unable to clear user session", err)
 		return nil, err
 	}
 
+	if isTwoFactor {
+		return s.NewTwoFactorChallenge(dto.TwoFactorSession{
+			UserId:         auth.Id,
+			Target:         api.TwoFactorTargetUser,
+			AuthProviderId: req.AuthProviderId,
+		})
+	}
+
 	// Generate Session
-	token, err := s.NewSession(auth.Id, req)
+	token, err := s.NewSession(auth, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return header
-	header := map[string]string{
-		api.AccessTokenKey:    token.Token,
-		api.AccessTokenExpKey: strconv.FormatInt(token.ExpiredAt, 10),
+	return composeSessionHeader(token), nil
+}
+
+// mergeThirdParty links third party account to user that is registered with the same verified email. Returns
+// sql.ErrNoRows if there is no matching user, or USR034 if email of the registered user has not been verified
+func (s *User) mergeThirdParty(providerId int, identity *entity.ThirdPartyIdentity) (*model.UserAuth, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, sql.ErrNoRows
 	}
 
-	return header, nil
+	// Get user by email
+	auth, err := s.UserRepository.FindAuthByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only merge if registered user has verified the email, otherwise email may be claimed by someone else. User must
+	// sign in and link the account with LinkAuthProvider instead
+	profile, err := s.UserRepository.FindProfileById(auth.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !profile.EmailVerified {
+		return nil, s.Errors.New("USR034")
+	}
+
+	// Link third party account
+	err = s.UserRepository.InsertThirdParty(s.newUserAuthThirdParty(auth.Id, providerId, identity.AccessKey))
+	if err != nil {
+		return nil, err
+	}
+
+	s.Logger.Debugf("third party account merged by email. UserId = %s, AuthProviderId = %d", auth.Id, providerId)
+	return auth, nil
+}
+
+// verifyThirdParty verifies ownership of third party account by its token and returns the account identity
+func (s *User) verifyThirdParty(providerId int, token string, nonce string) (*entity.ThirdPartyIdentity, error) {
+	if token == "" {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	switch providerId {
+	case api.FacebookAuthProvider:
+		fbUser, err := s.Facebook.InspectToken(token)
+		if err != nil {
+			s.Logger.Error("unable to inspect facebook user token", err)
+			return nil, nhttp.ErrUnauthorized
+		}
+		return &entity.ThirdPartyIdentity{AccessKey: fbUser.UserId}, nil
+	case api.GoogleAuthProvider, api.AppleAuthProvider:
+		if nonce == "" {
+			return nil, nhttp.ErrBadRequest
+		}
+
+		provider := s.Google
+		if providerId == api.AppleAuthProvider {
+			provider = s.Apple
+		}
+
+		idToken, err := provider.VerifyIdToken(token, nonce)
+		if err != nil {
+			s.Logger.Errorf("unable to verify id token. AuthProviderId = %d, Error = %s", providerId, err)
+			return nil, nhttp.ErrUnauthorized
+		}
+
+		identity := entity.ThirdPartyIdentity{
+			AccessKey:     idToken.Subject,
+			Email:         idToken.Email,
+			EmailVerified: idToken.EmailVerified,
+		}
+		return &identity, nil
+	default:
+		return nil, nhttp.ErrBadRequest
+	}
 }
 
 func (s *User) Register(req dto.UserProfileReq) error {
@@ -1349,6 +2084,10 @@ func (s *User) Register(req dto.UserProfileReq) error {
 	switch req.AuthProviderId {
 	case api.FacebookAuthProvider:
 		return s.RegisterByFacebook(req)
+	case api.GoogleAuthProvider:
+		return s.RegisterByGoogle(req)
+	case api.AppleAuthProvider:
+		return s.RegisterByApple(req)
 	default:
 		return s.RegisterByEmail(req)
 	}
@@ -1404,20 +2143,38 @@ func (s *User) RegisterByEmail(req dto.UserProfileReq) error {
 }
 
 func (s *User) RegisterByFacebook(req dto.UserProfileReq) error {
-	// Validate token input
-	if req.ThirdPartyToken == "" {
-		return nhttp.ErrBadRequest
-	}
+	// Set auth provider
+	req.AuthProviderId = api.FacebookAuthProvider
+	return s.registerByThirdParty(req)
+}
 
-	// Validate token to Facebook
-	fbUser, err := s.Facebook.InspectToken(req.ThirdPartyToken)
+func (s *User) RegisterByGoogle(req dto.UserProfileReq) error {
+	// Set auth provider
+	req.AuthProviderId = api.GoogleAuthProvider
+	return s.registerByThirdParty(req)
+}
+
+func (s *User) RegisterByApple(req dto.UserProfileReq) error {
+	// Set auth provider
+	req.AuthProviderId = api.AppleAuthProvider
+	return s.registerByThirdParty(req)
+}
+
+// registerByThirdParty creates user that is linked to third party account of req.AuthProviderId
+func (s *User) registerByThirdParty(req dto.UserProfileReq) error {
+	// Verify third party token
+	identity, err := s.verifyThirdParty(req.AuthProviderId, req.ThirdPartyToken, req.Nonce)
 	if err != nil {
-		s.Logger.Error("unable to inspect token", err)
-		return nhttp.ErrUnauthorized
+		return err
 	}
 
-	// Validate existing facebook id
-	isExist, err := s.UserRepository.IsExistBy3rdPartyAcc(api.FacebookAuthProvider, fbUser.UserId)
+	// Ensure registered email is owned by third party account, if provided
+	if identity.Email != "" && !strings.EqualFold(req.Email, identity.Email) {
+		return s.Errors.New("USR021")
+	}
+
+	// Validate existing third party account
+	isExist, err := s.UserRepository.IsExistBy3rdPartyAcc(req.AuthProviderId, identity.AccessKey)
 	if err != nil && err != sql.ErrNoRows {
 		s.Logger.Error("unable to retrieve user by third party", err)
 		return err
@@ -1432,13 +2189,14 @@ func (s *User) RegisterByFacebook(req dto.UserProfileReq) error {
 
 	// Create user profile
 	userProfile := model.UserProfile{
-		Id:          s.IdGen.New(),
-		FullName:    req.FullName,
-		GenderId:    req.Gender,
-		DateOfBirth: pqx.ParseDate(pqx.DateOpt{Input: req.DOB}),
-		Email:       req.Email,
-		CreatedAt:   timestamp,
-		UpdatedAt:   timestamp,
+		Id:            s.IdGen.New(),
+		FullName:      req.FullName,
+		GenderId:      req.Gender,
+		DateOfBirth:   pqx.ParseDate(pqx.DateOpt{Input: req.DOB}),
+		Email:         req.Email,
+		EmailVerified: identity.EmailVerified,
+		CreatedAt:     timestamp,
+		UpdatedAt:     timestamp,
 	}
 
 	// Create user auth
@@ -1451,17 +2209,9 @@ func (s *User) RegisterByFacebook(req dto.UserProfileReq) error {
 		UpdatedAt: timestamp,
 	}
 
-	userAuthThirdParty := model.UserAuthThirdParty{
-		Id:             s.IdGen.New(),
-		UserId:         userProfile.Id,
-		AuthProviderId: req.AuthProviderId,
-		AccessKey:      fbUser.UserId,
-		CreatedAt:      timestamp,
-		UpdatedAt:      timestamp,
-	}
-
 	// Persist user with third party
-	err = s.UserRepository.InsertWithThirdParty(userProfile, userAuth, userAuthThirdParty)
+	err = s.UserRepository.InsertWithThirdParty(userProfile, userAuth,
+		s.newUserAuthThirdParty(userProfile.Id, req.AuthProviderId, identity.AccessKey))
 	if err != nil {
 		s.Logger.Error("unable to persist user by 3rd party", err)
 		return err
@@ -1470,72 +2220,1275 @@ func (s *User) RegisterByFacebook(req dto.UserProfileReq) error {
 	return nil
 }
 
-func (s *User) SendEmailVerification(profile model.UserProfile) error {
-	// Create request id as session
-	reqId := s.IdGen.New()
-
-	// Sign user
-	signature, err := s.AuthService.SignMd5(dto.SignatureReq{
-		Format: RawFormatVerifyEmailUserSignature,
-		Args: []interface{}{
-			reqId,
-			profile.Id,
-			profile.Email,
-			profile.EmailVerified,
-			s.SignatureSaltVerifyEmailSubject,
-		},
-	})
+func (s *User) ListAuthProviders(userId string) (*dto.UserAuthProvidersResp, error) {
+	// Get user auth
+	auth, err := s.UserRepository.FindAuthById(userId)
 	if err != nil {
-		s.Logger.Error("unable to sign subject for verify email", err)
-		return err
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("USR008")
+		}
+		s.Logger.Error("unable to retrieve UserAuth", err)
+		return nil, err
 	}
 
-	// Create purpose token
-	token, err := s.AuthService.NewOneTimeToken(dto.JWTOptReq{
-		Subject:   profile.Email,
-		SessionId: reqId,
-		Lifetime:  s.VerifyEmailTokenLifetime,
-		Purpose:   api.JWTPurposeVerifyEmail,
-		Extras: map[string]string{
-			api.UserSignatureKey: signature,
-		}})
+	// Get linked third party accounts
+	rows, err := s.UserRepository.FindThirdPartiesByUser(userId)
 	if err != nil {
-		return err
+		s.Logger.Error("unable to retrieve UserAuthThirdParty", err)
+		return nil, err
 	}
 
-	// Create verify email url
-	verifyEmailUrl := fmt.Sprintf("%s/users/verify-email?t=%s", s.BaseUrl, token.Token)
-
-	// Send email verification
-	err = s.Mailer.Send(nmailgun.SendOpt{
-		Sender:       s.Mailer.GetDefaultSender(),
-		Recipients:   []string{profile.Email},
-		Subject:      "Running App - Email Verification",
-		TemplateFile: "verify_email.html",
-		TemplateData: struct {
-			URL string
-		}{
-			URL: verifyEmailUrl,
-		},
-	})
-	if err != nil {
-		s.Logger.Error("unable to send email verification to user", err)
-		return err
+	// Compose response
+	resp := dto.UserAuthProvidersResp{
+		HasPassword: auth.Password != UnsetPassword,
+		Providers:   make([]dto.UserAuthProviderResp, len(rows)),
+	}
+	for k, v := range rows {
+		resp.Providers[k] = dto.UserAuthProviderResp{
+			AuthProviderId: v.AuthProviderId,
+			LinkedAt:       v.CreatedAt.Unix(),
+		}
 	}
 
-	return nil
+	return &resp, nil
 }
 
-func (s *User) IsEmailExists(email string) (interface{}, error) {
-	isExist, err := s.UserRepository.IsExistByEmail(email)
+func (s *User) LinkAuthProvider(req dto.UserAuthProviderReq) (*dto.UserAuthProvidersResp, error) {
+	// Verify third party token
+	identity, err := s.verifyThirdParty(req.AuthProviderId, req.ThirdPartyToken, req.Nonce)
 	if err != nil {
 		return nil, err
 	}
 
-	// Compose response
-	resp := map[string]bool{
+	// Check if third party account has been linked
+	auth, err := s.UserRepository.FindAuthByThirdParty(identity.AccessKey, req.AuthProviderId)
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("unable to retrieve UserAuth by third party", err)
+		return nil, err
+	}
+
+	if err == nil {
+		// If linked to the same user, then there is nothing to do
+		if auth.Id == req.UserId {
+			return s.ListAuthProviders(req.UserId)
+		}
+		return nil, s.Errors.New("USR012")
+	}
+
+	// Check if user has linked other account of the same provider
+	rows, err := s.UserRepository.FindThirdPartiesByUser(req.UserId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve UserAuthThirdParty", err)
+		return nil, err
+	}
+
+	for _, v := range rows {
+		if v.AuthProviderId == req.AuthProviderId {
+			return nil, s.Errors.New("USR022")
+		}
+	}
+
+	// Link third party account
+	err = s.UserRepository.InsertThirdParty(s.newUserAuthThirdParty(req.UserId, req.AuthProviderId,
+		identity.AccessKey))
+	if err != nil {
+		s.Logger.Error("unable to persist UserAuthThirdParty", err)
+		return nil, err
+	}
+
+	return s.ListAuthProviders(req.UserId)
+}
+
+func (s *User) UnlinkAuthProvider(req dto.UserAuthProviderReq) (*dto.UserAuthProvidersResp, error) {
+	// Get current login methods
+	providers, err := s.ListAuthProviders(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if provider is linked
+	isLinked := false
+	for _, v := range providers.Providers {
+		if v.AuthProviderId == req.AuthProviderId {
+			isLinked = true
+			break
+		}
+	}
+
+	if !isLinked {
+		return nil, s.Errors.New("USR023")
+	}
+
+	// Prevent removing the last login method
+	if !providers.HasPassword && len(providers.Providers) <= 1 {
+		return nil, s.Errors.New("USR024")
+	}
+
+	// Unlink, deletion is guarded in case other login method is removed concurrently
+	isDeleted, err := s.UserRepository.DeleteThirdParty(req.UserId, req.AuthProviderId)
+	if err != nil {
+		s.Logger.Error("unable to delete UserAuthThirdParty", err)
+		return nil, err
+	}
+
+	if !isDeleted {
+		return nil, s.Errors.New("USR024")
+	}
+
+	return s.ListAuthProviders(req.UserId)
+}
+
+func (s *User) newUserAuthThirdParty(userId string, providerId int, accessKey string) model.UserAuthThirdParty {
+	timestamp := time.Now()
+	return model.UserAuthThirdParty{
+		Id:             s.IdGen.New(),
+		UserId:         userId,
+		AuthProviderId: providerId,
+		AccessKey:      accessKey,
+		CreatedAt:      timestamp,
+		UpdatedAt:      timestamp,
+	}
+}
+
+func (s *User) GetTwoFactor(userId string) (*dto.UserTwoFactorResp, error) {
+	// Check two factor status
+	isEnabled, err := s.IsTwoFactorEnabled(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isEnabled {
+		return &dto.UserTwoFactorResp{}, nil
+	}
+
+	// Count remaining recovery codes
+	count, err := s.UserRepository.CountRecoveryCodes(userId)
+	if err != nil {
+		s.Logger.Error("unable to count recovery codes", err)
+		return nil, err
+	}
+
+	resp := dto.UserTwoFactorResp{
+		Enabled:           true,
+		RecoveryCodesLeft: count,
+	}
+	return &resp, nil
+}
+
+func (s *User) IsTwoFactorEnabled(userId string) (bool, error) {
+	twoFactor, err := s.UserRepository.FindTwoFactor(userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		s.Logger.Error("unable to retrieve two factor", err)
+		return false, err
+	}
+
+	return twoFactor.StatusId == api.TwoFactorEnabled, nil
+}
+
+// EnrollTwoFactor generates a new secret for user. Two factor is not enabled until a code generated from secret is
+// verified by EnableTwoFactor
+func (s *User) EnrollTwoFactor(userId string) (*dto.UserTwoFactorEnrollResp, error) {
+	// Check if two factor has been enabled
+	isEnabled, err := s.IsTwoFactorEnabled(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if isEnabled {
+		return nil, s.Errors.New("USR025")
+	}
+
+	// Get email as account name
+	email, err := s.UserRepository.FindEmailById(userId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve user email", err)
+		return nil, err
+	}
+
+	// Generate secret
+	secret, err := notp.GenerateSecret()
+	if err != nil {
+		s.Logger.Error("unable to generate two factor secret", err)
+		return nil, err
+	}
+
+	// Persist pending two factor, replace previous enrollment if any
+	timestamp := time.Now()
+	err = s.UserRepository.UpsertTwoFactor(model.UserTwoFactor{
+		UserId:    userId,
+		Secret:    secret,
+		StatusId:  api.TwoFactorPending,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	})
+	if err != nil {
+		s.Logger.Error("unable to persist two factor", err)
+		return nil, err
+	}
+
+	resp := dto.UserTwoFactorEnrollResp{
+		Secret:          secret,
+		ProvisioningUri: notp.ProvisioningURI(s.TwoFactorIssuer, email, secret),
+	}
+	return &resp, nil
+}
+
+// EnableTwoFactor verifies code of enrolled secret and enable two factor. Recovery codes are only returned once
+func (s *User) EnableTwoFactor(req dto.TwoFactorReq) (*dto.UserRecoveryCodesResp, error) {
+	// Validate request
+	if req.Code == "" {
+		return nil, nhttp.ErrBadRequest
+	}
+
+	// Get enrolled two factor
+	twoFactor, err := s.UserRepository.FindTwoFactor(req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, s.Errors.New("USR026")
+		}
+
+		s.Logger.Error("unable to retrieve two factor", err)
+		return nil, err
+	}
+
+	if twoFactor.StatusId == api.TwoFactorEnabled {
+		return nil, s.Errors.New("USR025")
+	}
+
+	// Validate code
+	timestamp := time.Now()
+	step, ok := notp.Validate(twoFactor.Secret, req.Code, timestamp, TwoFactorSkew)
+	if !ok {
+		return nil, s.Errors.New("USR027")
+	}
+
+	// Generate recovery codes
+	codes, rows, err := s.newRecoveryCodes(req.UserId, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Enable two factor
+	twoFactor.StatusId = api.TwoFactorEnabled
+	twoFactor.LastUsedStep = step
+	twoFactor.UpdatedAt = timestamp
+	err = s.UserRepository.EnableTwoFactor(*twoFactor, rows)
+	if err != nil {
+		s.Logger.Error("unable to enable two factor", err)
+		return nil, err
+	}
+
+	return &dto.UserRecoveryCodesResp{RecoveryCodes: codes}, nil
+}
+
+func (s *User) DisableTwoFactor(req dto.TwoFactorReq) error {
+	// Verify second factor
+	err := s.VerifyTwoFactor(req)
+	if err != nil {
+		return err
+	}
+
+	// Delete two factor and recovery codes
+	err = s.UserRepository.DeleteTwoFactor(req.UserId)
+	if err != nil {
+		s.Logger.Error("unable to delete two factor", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *User) RegenerateRecoveryCodes(req dto.TwoFactorReq) (*dto.UserRecoveryCodesResp, error) {
+	// Verify second factor
+	err := s.VerifyTwoFactor(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate recovery codes
+	codes, rows, err := s.newRecoveryCodes(req.UserId, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace existing codes
+	err = s.UserRepository.ReplaceRecoveryCodes(req.UserId, rows)
+	if err != nil {
+		s.Logger.Error("unable to replace recovery codes", err)
+		return nil, err
+	}
+
+	return &dto.UserRecoveryCodesResp{RecoveryCodes: codes}, nil
+}
+
+// VerifyTwoFactor verifies a TOTP code or a recovery code of user. Each code can only be used once
+func (s *User) VerifyTwoFactor(req dto.TwoFactorReq) error {
+	// Validate request
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return nhttp.ErrBadRequest
+	}
+
+	// Get two factor
+	twoFactor, err := s.UserRepository.FindTwoFactor(req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return s.Errors.New("USR026")
+		}
+
+		s.Logger.Error("unable to retrieve two factor", err)
+		return err
+	}
+
+	if twoFactor.StatusId != api.TwoFactorEnabled {
+		return s.Errors.New("USR026")
+	}
+
+	// Check if attempt is throttled
+	key := ThrottleKeyTwoFactor + req.UserId
+	err = s.checkThrottle(key)
+	if err != nil {
+		return err
+	}
+
+	// Verify code
+	ok, err := s.verifySecondFactor(twoFactor, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		_, err = s.Throttler.Fail(key)
+		if err != nil {
+			s.Logger.Error("unable to record failed two factor attempt", err)
+		}
+		return s.Errors.New("USR027")
+	}
+
+	err = s.Throttler.Reset(key)
+	if err != nil {
+		s.Logger.Error("unable to reset two factor throttle", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *User) verifySecondFactor(twoFactor *model.UserTwoFactor, code string) (bool, error) {
+	timestamp := time.Now()
+
+	// If code is not a TOTP code, then verify as recovery code
+	if len(code) != notp.Digits {
+		ok, err := s.UserRepository.UseRecoveryCode(twoFactor.UserId, hashRecoveryCode(code), timestamp)
+		if err != nil {
+			s.Logger.Error("unable to use recovery code", err)
+			return false, err
+		}
+
+		return ok, nil
+	}
+
+	// Validate TOTP code
+	step, ok := notp.Validate(twoFactor.Secret, code, timestamp, TwoFactorSkew)
+	if !ok {
+		return false, nil
+	}
+
+	// Mark step as used. If step is not greater than last used step, then code has been replayed
+	ok, err := s.UserRepository.UpdateTwoFactorStep(twoFactor.UserId, step, timestamp)
+	if err != nil {
+		s.Logger.Error("unable to update two factor step", err)
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// NewTwoFactorChallenge issues a one time token that must be exchanged with a second factor code to complete login
+func (s *User) NewTwoFactorChallenge(session dto.TwoFactorSession) (map[string]string, error) {
+	// Set challenge target
+	extras := map[string]string{
+		api.TwoFactorTargetKey: session.Target,
+	}
+	if session.OrganizationId != "" {
+		extras[api.OrganizationIdKey] = session.OrganizationId
+	}
+	if session.AuthProviderId != 0 {
+		extras[api.AuthProviderIdKey] = strconv.Itoa(session.AuthProviderId)
+	}
+
+	// Create purpose token
+	token, err := s.AuthService.NewOneTimeToken(dto.JWTOptReq{
+		Subject:   session.UserId,
+		SessionId: s.IdGen.New(),
+		Lifetime:  s.TwoFactorTokenLifetime,
+		Purpose:   api.JWTPurposeTwoFactor,
+		Extras:    extras,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	header := map[string]string{
+		api.TwoFactorTokenKey:    token.Token,
+		api.TwoFactorTokenExpKey: strconv.FormatInt(token.ExpiredAt, 10),
+	}
+	return header, nil
+}
+
+// newRecoveryCodes generates recovery codes and returns the plain codes and hashed rows to be stored
+func (s *User) newRecoveryCodes(userId string, timestamp time.Time) ([]string, []model.UserRecoveryCode, error) {
+	codes := make([]string, RecoveryCodeCount)
+	rows := make([]model.UserRecoveryCode, RecoveryCodeCount)
+	for i := range codes {
+		code, err := gonanoid.Generate(RecoveryCodeAlphabet, RecoveryCodeLength)
+		if err != nil {
+			s.Logger.Error("unable to generate recovery code", err)
+			return nil, nil, err
+		}
+
+		// Format code in 2 groups for readability
+		codes[i] = code[:RecoveryCodeLength/2] + "-" + code[RecoveryCodeLength/2:]
+		rows[i] = model.UserRecoveryCode{
+			Id:        s.IdGen.New(),
+			UserId:    userId,
+			CodeHash:  hashRecoveryCode(code),
+			CreatedAt: timestamp,
+		}
+	}
+
+	return codes, rows, nil
+}
+
+func (s *User) SendEmailVerification(profile model.UserProfile) error {
+	// Create request id as session
+	reqId := s.IdGen.New()
+
+	// Sign user
+	signature, err := s.AuthService.SignMd5(dto.SignatureReq{
+		Format: RawFormatVerifyEmailUserSignature,
+		Args: []interface{}{
+			reqId,
+			profile.Id,
+			profile.Email,
+			profile.EmailVerified,
+			s.SignatureSaltVerifyEmailSubject,
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to sign subject for verify email", err)
+		return err
+	}
+
+	// Create purpose token
+	token, err := s.AuthService.NewOneTimeToken(dto.JWTOptReq{
+		Subject:   profile.Email,
+		SessionId: reqId,
+		Lifetime:  s.VerifyEmailTokenLifetime,
+		Purpose:   api.JWTPurposeVerifyEmail,
+		Extras: map[string]string{
+			api.UserSignatureKey: signature,
+		}})
+	if err != nil {
+		return err
+	}
+
+	// Create verify email url
+	verifyEmailUrl := fmt.Sprintf("%s/users/verify-email?t=%s", s.BaseUrl, token.Token)
+
+	// Send email verification
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{profile.Email},
+		Subject:      "Running App - Email Verification",
+		TemplateFile: "verify_email.html",
+		TemplateData: struct {
+			URL string
+		}{
+			URL: verifyEmailUrl,
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send email verification to user", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *User) IsEmailExists(email string, clientIp string) (interface{}, error) {
+	// Every request is counted, to prevent email enumeration
+	err := s.throttleLookup(clientIp)
+	if err != nil {
+		return nil, err
+	}
+
+	isExist, err := s.UserRepository.IsExistByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compose response
+	resp := map[string]bool{
 		"is_exist": isExist,
 	}
 
-	return resp, nil
+	return resp, nil
+}
+
+// RequestDataExport queues export of user personal data. Download link is sent by email once export is ready
+func (s *User) RequestDataExport(userId string) error {
+	// Check if export has been requested recently
+	key := ThrottleKeyExport + userId
+	err := s.Throttler.Check(key)
+	if err != nil {
+		if _, ok := err.(*nthrottle.ThrottledError); ok {
+			return s.Errors.New("USR032")
+		}
+
+		s.Logger.Error("unable to check data export throttle", err)
+		return err
+	}
+
+	// Encode to gob
+	var w bytes.Buffer
+	enc := gob.NewEncoder(&w)
+	err = enc.Encode(dto.UserDataExportReq{UserId: userId})
+	if err != nil {
+		s.Logger.Error("failed to encode dto.UserDataExportReq payload", err)
+		return err
+	}
+
+	// Publish
+	msg := message.NewMessage(watermill.NewUUID(), w.Bytes())
+	err = s.PubSub.Publish(TopicExportUserData, msg)
+	if err != nil {
+		s.Logger.Error("failed to publish to "+TopicExportUserData, err)
+		return err
+	}
+
+	// Block next request until cooldown has passed
+	err = s.Throttler.Block(key, time.Duration(s.DataExportCooldown)*time.Minute)
+	if err != nil {
+		s.Logger.Error("unable to block data export request", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *User) handleExportUserData(messages <-chan *message.Message) {
+	for msg := range messages {
+		s.Logger.Debugf("Received message. Id = %s", msg.UUID)
+
+		// Parse payload
+		w := bytes.NewBuffer(msg.Payload)
+		dec := gob.NewDecoder(w)
+		var payload dto.UserDataExportReq
+		err := dec.Decode(&payload)
+		if err != nil {
+			s.Logger.Error("failed to parse payload", err)
+			msg.Ack()
+			continue
+		}
+
+		// Export user data
+		err = s.ExportUserData(payload.UserId)
+		if err != nil {
+			s.Logger.Errorf("unable to export user data. UserId = %s", payload.UserId)
+		}
+
+		s.Logger.Debug("Done handleExportUserData")
+		msg.Ack()
+	}
+}
+
+// ExportUserData assembles user personal data into a zip archive and sends download link to user email
+func (s *User) ExportUserData(userId string) error {
+	// Get profile
+	profile, err := s.UserRepository.FindProfileById(userId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve user profile", err)
+		return err
+	}
+
+	// Write archive
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err = s.writeUserData(zw, userId)
+	if err != nil {
+		return err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		s.Logger.Error("unable to close data export archive", err)
+		return err
+	}
+
+	// Upload archive
+	lifetime := time.Duration(s.DataExportLifetime) * time.Minute
+	upload, err := s.AssetService.UploadPrivateFile(dto.PrivateUploadReq{
+		AssetType:   api.AssetDataExport,
+		FileName:    fmt.Sprintf("%s-%s.zip", userId, s.IdGen.New()),
+		ContentType: "application/zip",
+		File:        &buf,
+		Lifetime:    lifetime,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Send download link
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{profile.Email},
+		Subject:      "Running App - Your Personal Data Export",
+		TemplateFile: "data_export.html",
+		TemplateData: struct {
+			FullName    string
+			DownloadUrl string
+			ExpiredAt   string
+		}{
+			FullName:    profile.FullName,
+			DownloadUrl: upload.FileUrl,
+			ExpiredAt:   time.Now().Add(lifetime).UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send data export email", err)
+		return err
+	}
+
+	return nil
+}
+
+// writeUserData writes each kind of user personal data as a file in archive
+func (s *User) writeUserData(zw *zip.Writer, userId string) error {
+	// Write profile
+	profile, err := s.GetProfile(userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.writeExportJSON(zw, "profile.json", profile)
+	if err != nil {
+		return err
+	}
+
+	// Write active sessions
+	sessionRows, err := s.UserRepository.FindSessionsByUser(userId, time.Now())
+	if err != nil {
+		s.Logger.Error("unable to retrieve user sessions", err)
+		return err
+	}
+
+	sessions := make([]dto.UserSessionResp, len(sessionRows))
+	for i, v := range sessionRows {
+		sessions[i] = composeUserSession(v, false)
+	}
+
+	err = s.writeExportJSON(zw, "sessions.json", sessions)
+	if err != nil {
+		return err
+	}
+
+	// Write run sessions
+	runs := make([]dto.RunSessionHistoryItem, 0)
+	for skip := int64(0); ; skip += int64(DataExportPageLimit) {
+		page, err := s.RunService.GetRunSessionHistory(userId, skip, DataExportPageLimit)
+		if err != nil {
+			return err
+		}
+
+		runs = append(runs, page.RunSessions...)
+		if len(page.RunSessions) < int(DataExportPageLimit) {
+			break
+		}
+	}
+
+	err = s.writeExportJSON(zw, "runs.json", runs)
+	if err != nil {
+		return err
+	}
+
+	// Write challenges
+	challengeRows, err := s.UserRepository.FindChallengesByUser(userId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve user challenges", err)
+		return err
+	}
+
+	challenges := make([]dto.UserChallengeExportResp, len(challengeRows))
+	for i, v := range challengeRows {
+		challenges[i] = dto.UserChallengeExportResp{
+			Id:              v.Id,
+			MilestoneId:     v.MilestoneId,
+			Milestone:       v.MilestoneSnapshot,
+			ChallengeId:     v.ChallengeId,
+			Challenge:       v.ChallengeSnapshot,
+			ChallengeResult: v.ChallengeResultSnapshot,
+			Reward:          v.RewardSnapshot,
+			RewardTypeId:    v.RewardTypeId,
+			RewardValue:     v.RewardValue,
+			StatusId:        v.Status,
+			UpdatedAt:       v.UpdatedAt.Unix(),
+		}
+	}
+
+	err = s.writeExportJSON(zw, "challenges.json", challenges)
+	if err != nil {
+		return err
+	}
+
+	// Write wallet transactions. Export range is limited, so history is exported range by range from the latest
+	wallet, err := s.CreditService.GetUserWallet(userId)
+	if err != nil {
+		return err
+	}
+
+	var transactions []byte
+	for end := time.Now().Add(time.Second); end.After(wallet.CreatedAt); end = end.Add(-creditExportMaxRange) {
+		content, err := s.CreditService.ExportTrxHistory(dto.CreditTrxHistoryReq{
+			UserId:  userId,
+			StartAt: end.Add(-creditExportMaxRange).Unix(),
+			EndAt:   end.Unix(),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Keep csv header from the first range only
+		if len(transactions) > 0 {
+			content = content[bytes.IndexByte(content, '\n')+1:]
+		}
+		transactions = append(transactions, content...)
+	}
+
+	err = s.writeExportFile(zw, "wallet_transactions.csv", transactions)
+	if err != nil {
+		return err
+	}
+
+	// Write donations
+	donations := make([]dto.DonationHistoryResp, 0)
+	for skip := int64(0); ; skip += int64(DataExportPageLimit) {
+		page, err := s.InitiativeService.ListUserDonation(dto.UserResourcesReq{
+			PageReq: dto.PageReq{Skip: skip, Limit: DataExportPageLimit},
+			UserId:  userId,
+		})
+		if err != nil {
+			return err
+		}
+
+		donations = append(donations, page...)
+		if len(page) < int(DataExportPageLimit) {
+			break
+		}
+	}
+
+	err = s.writeExportJSON(zw, "donations.json", donations)
+	if err != nil {
+		return err
+	}
+
+	// Write recurring donations
+	pledges := make([]dto.DonationPledgeResp, 0)
+	for skip := int64(0); ; skip += int64(DataExportPageLimit) {
+		page, err := s.InitiativeService.ListPledges(dto.UserResourcesReq{
+			PageReq: dto.PageReq{Skip: skip, Limit: DataExportPageLimit},
+			UserId:  userId,
+		})
+		if err != nil {
+			return err
+		}
+
+		pledges = append(pledges, page...)
+		if len(page) < int(DataExportPageLimit) {
+			break
+		}
+	}
+
+	err = s.writeExportJSON(zw, "donation_pledges.json", pledges)
+	if err != nil {
+		return err
+	}
+
+	// Write subscriptions
+	subscriptionRows, err := s.UserRepository.FindSubscriptionsByUser(userId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve user subscriptions", err)
+		return err
+	}
+
+	subscriptions := make([]dto.UserSubscriptionExportResp, len(subscriptionRows))
+	for i, v := range subscriptionRows {
+		subscriptions[i] = dto.UserSubscriptionExportResp{
+			Id:          v.Id,
+			PlanTypeId:  v.PlanTypeId,
+			ProviderId:  v.ProviderId,
+			PeriodStart: v.PeriodStart.Unix(),
+			PeriodEnd:   v.PeriodEnd.Unix(),
+			StatusId:    v.StatusId,
+			CreatedAt:   v.CreatedAt.Unix(),
+			UpdatedAt:   v.UpdatedAt.Unix(),
+		}
+	}
+
+	return s.writeExportJSON(zw, "subscriptions.json", subscriptions)
+}
+
+func (s *User) writeExportJSON(zw *zip.Writer, name string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		s.Logger.Errorf("unable to encode %s", name)
+		return err
+	}
+
+	return s.writeExportFile(zw, name, content)
+}
+
+func (s *User) writeExportFile(zw *zip.Writer, name string, content []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		s.Logger.Errorf("unable to create %s in data export archive", name)
+		return err
+	}
+
+	_, err = f.Write(content)
+	if err != nil {
+		s.Logger.Errorf("unable to write %s in data export archive", name)
+		return err
+	}
+
+	return nil
+}
+
+// RequestDeletion schedules account deletion after grace period. Deletion can be cancelled until then
+func (s *User) RequestDeletion(req dto.UserDeletionReq) (*dto.UserDeletionResp, error) {
+	// Get user auth by id
+	auth, err := s.UserRepository.FindAuthById(req.UserId)
+	if err != nil {
+		s.Logger.Error("unable to find user auth by id", err)
+		return nil, err
+	}
+
+	// If password is set, confirm password
+	if auth.Password != UnsetPassword {
+		err = bcrypt.CompareHashAndPassword([]byte(auth.Password), []byte(req.Password))
+		if err != nil {
+			return nil, s.Errors.New("USR007")
+		}
+	}
+
+	// Schedule deletion
+	timestamp := time.Now()
+	deletion := model.UserDeletion{
+		UserId:      req.UserId,
+		StatusId:    api.DeletionScheduled,
+		ScheduledAt: timestamp.AddDate(0, 0, s.DeletionGracePeriod),
+		CreatedAt:   timestamp,
+	}
+
+	ok, err := s.UserRepository.InsertUserDeletion(deletion)
+	if err != nil {
+		s.Logger.Error("unable to insert user deletion", err)
+		return nil, err
+	}
+
+	if !ok {
+		return nil, s.Errors.New("USR030")
+	}
+
+	// Send notification
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{auth.Username},
+		Subject:      "Running App - Account Deletion Scheduled",
+		TemplateFile: "account_deletion_scheduled.html",
+		TemplateData: struct {
+			ScheduledAt string
+		}{
+			ScheduledAt: deletion.ScheduledAt.UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send account deletion scheduled email", err)
+	}
+
+	return &dto.UserDeletionResp{ScheduledAt: deletion.ScheduledAt.Unix()}, nil
+}
+
+func (s *User) CancelDeletion(userId string) error {
+	// Only deletion that has not been started can be cancelled
+	ok, err := s.UserRepository.DeleteUserDeletion(userId, api.DeletionScheduled)
+	if err != nil {
+		s.Logger.Error("unable to delete user deletion", err)
+		return err
+	}
+
+	if !ok {
+		return s.Errors.New("USR031")
+	}
+
+	return nil
+}
+
+// DeleteDueAccounts deletes accounts that have passed deletion grace period
+func (s *User) DeleteDueAccounts() (*dto.UserDeletionRunResp, error) {
+	deletions, err := s.UserRepository.FindDueDeletions(time.Now(), DeletionBatchLimit)
+	if err != nil {
+		s.Logger.Error("unable to retrieve due user deletions", err)
+		return nil, err
+	}
+
+	// Delete accounts, failed deletion is retried on next run
+	var resp dto.UserDeletionRunResp
+	for _, d := range deletions {
+		// If account has been anonymised, only retry to cancel subscription
+		if d.StatusId == api.DeletionAnonymized {
+			err = s.completeDeletion(d.UserId, time.Now())
+		} else {
+			err = s.DeleteAccount(d.UserId)
+		}
+		if err != nil {
+			s.Logger.Errorf("unable to delete account. UserId = %s", d.UserId)
+			resp.Failed++
+			continue
+		}
+
+		resp.Deleted++
+	}
+
+	return &resp, nil
+}
+
+// DeleteAccount anonymises user profile and removes personal data. Financial records are kept with pseudonymised
+// user snapshot. Subscription is cancelled once account is anonymised, so it is not cancelled if deletion is
+// cancelled concurrently
+func (s *User) DeleteAccount(userId string) error {
+	// Get profile and auth
+	profile, err := s.UserRepository.FindProfileById(userId)
+	if err != nil {
+		s.Logger.Error("unable to retrieve user profile", err)
+		return err
+	}
+
+	auth, err := s.UserRepository.FindAuthById(userId)
+	if err != nil {
+		s.Logger.Error("unable to find user auth by id", err)
+		return err
+	}
+
+	// Anonymise profile and auth
+	timestamp := time.Now()
+	anonymous := model.UserProfile{
+		Id:        profile.Id,
+		FullName:  DeletedUserFullName,
+		Email:     fmt.Sprintf(DeletedUserEmailFormat, profile.Id),
+		CreatedAt: profile.CreatedAt,
+		UpdatedAt: timestamp,
+	}
+
+	auth.Username = anonymous.Email
+	auth.Password = UnsetPassword
+	auth.StatusId = api.UserDeleted
+	auth.UpdatedAt = timestamp
+
+	err = s.UserRepository.AnonymizeUser(anonymous, *auth, model.NewUserSnapshot(&anonymous))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			s.Logger.Debugf("account deletion has been cancelled. UserId = %s", userId)
+			return nil
+		}
+
+		s.Logger.Error("unable to anonymize user", err)
+		return err
+	}
+
+	// Cancel subscription, so user is no longer charged
+	err = s.completeDeletion(userId, timestamp)
+	if err != nil {
+		return err
+	}
+
+	// Delete avatar
+	if profile.AvatarFile.Valid && profile.AvatarFile.String != "" {
+		err = s.AssetService.DeleteFile(api.AssetAvatarProfile, profile.AvatarFile.String)
+		if err != nil {
+			s.Logger.Errorf("unable to delete avatar of deleted account. UserId = %s", userId)
+		}
+	}
+
+	// Send confirmation to the original email
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{profile.Email},
+		Subject:      "Running App - Account Deleted",
+		TemplateFile: "account_deleted.html",
+		TemplateData: struct {
+			FullName string
+		}{
+			FullName: profile.FullName,
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send account deleted email", err)
+	}
+
+	return nil
+}
+
+// completeDeletion cancels subscription of anonymised account and removes its deletion request. If cancellation fails,
+// deletion is rescheduled so cancellation is retried on next run
+func (s *User) completeDeletion(userId string, timestamp time.Time) error {
+	err := s.cancelProviderSubscription(userId, timestamp)
+	if err != nil {
+		errReschedule := s.UserRepository.RescheduleUserDeletion(userId, timestamp.Add(DeletionRetryDelay))
+		if errReschedule != nil {
+			s.Logger.Error("unable to reschedule user deletion", errReschedule)
+		}
+		return err
+	}
+
+	_, err = s.UserRepository.DeleteUserDeletion(userId, api.DeletionAnonymized)
+	if err != nil {
+		s.Logger.Error("unable to delete user deletion", err)
+		return err
+	}
+
+	return nil
+}
+
+// cancelProviderSubscription cancels subscription of user that is still active in Stripe
+func (s *User) cancelProviderSubscription(userId string, timestamp time.Time) error {
+	// Get latest subscription
+	userSubscription, err := s.UserRepository.FindActiveSubscription(userId, timestamp)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+
+		s.Logger.Error("unable to find latest subscription by user", err)
+		return err
+	}
+
+	if userSubscription.ProviderId != api.ProviderStripe || userSubscription.StatusId == api.SubscriptionCanceled {
+		return nil
+	}
+
+	// Cancel subscription if it is not cancelled in Stripe
+	subscription, err := stripeSubscription.Get(userSubscription.ProviderSubscriptionRef, nil)
+	if err != nil {
+		s.Logger.Error("unable to retrieve subscription from provider Stripe", err)
+		return err
+	}
+
+	if subscription.Status != stripe.SubscriptionStatusCanceled {
+		_, err = stripeSubscription.Cancel(subscription.ID, nil)
+		if err != nil {
+			s.Logger.Error("unable to cancel Stripe subscription", err)
+			return err
+		}
+	}
+
+	// Update user subscription
+	userSubscription.StatusId = api.SubscriptionCanceled
+	userSubscription.UpdatedAt = timestamp
+	userSubscription.ModifiedBy = model.ModifierMeta{
+		Id:   userId,
+		Role: api.ModifierUser,
+	}
+	err = s.UserRepository.UpdateSubscriptionStatus(*userSubscription)
+	if err != nil {
+		s.Logger.Error("unable to update user subscription status", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *User) runDeletionScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.DeleteDueAccounts()
+		if err != nil {
+			s.Logger.Error("failed to delete due accounts", err)
+		}
+	}
+}
+
+// checkThrottle returns USR028 if any of keys is throttled
+func (s *User) checkThrottle(keys ...string) error {
+	err := s.Throttler.Check(keys...)
+	if err != nil {
+		if e, ok := err.(*nthrottle.ThrottledError); ok {
+			s.Logger.Debugf("attempt is throttled. Key: %s, RetryAfter: %s", e.Key, e.RetryAfter)
+			return s.Errors.New("USR028")
+		}
+
+		s.Logger.Error("unable to check throttle", err)
+		return err
+	}
+
+	return nil
+}
+
+// throttleLookup checks and counts a lookup request of client ip and other keys
+func (s *User) throttleLookup(clientIp string, keys ...string) error {
+	if clientIp != "" {
+		keys = append(keys, ThrottleKeyLookupIp+clientIp)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := s.checkThrottle(keys...)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Throttler.Fail(keys...)
+	if err != nil {
+		s.Logger.Error("unable to count lookup attempt", err)
+		return err
+	}
+
+	return nil
+}
+
+// failLogin records failed login of keys. If failures of account email reach lockout attempts, account is locked
+func (s *User) failLogin(keys []string, auth *model.UserAuth) {
+	entries, err := s.Throttler.Fail(keys...)
+	if err != nil {
+		s.Logger.Error("unable to record failed login", err)
+		return
+	}
+
+	if auth == nil || s.LockoutAttempts <= 0 || auth.StatusId != api.UserActive {
+		return
+	}
+
+	emailKey := ThrottleKeyEmail + normalizeEmail(auth.Username)
+	for _, e := range entries {
+		if e.Key == emailKey && e.Failures >= s.LockoutAttempts {
+			s.lockAccount(auth)
+			return
+		}
+	}
+}
+
+// lockAccount locks account for lockout duration and notify the owner by email
+func (s *User) lockAccount(auth *model.UserAuth) {
+	// Block email until lockout is over
+	err := s.Throttler.Block(ThrottleKeyEmail+normalizeEmail(auth.Username),
+		time.Duration(s.LockoutDuration)*time.Minute)
+	if err != nil {
+		s.Logger.Error("unable to block locked account", err)
+		return
+	}
+
+	// Update status, skip notification if account has been locked concurrently
+	ok, err := s.UserRepository.UpdateAuthStatus(auth.Id, api.UserActive, api.UserLocked)
+	if err != nil {
+		s.Logger.Error("unable to lock account", err)
+		return
+	}
+
+	if !ok {
+		return
+	}
+
+	// Send notification
+	err = s.Mailer.Send(nmailgun.SendOpt{
+		Sender:       s.Mailer.GetDefaultSender(),
+		Recipients:   []string{auth.Username},
+		Subject:      "Running App - Account Locked",
+		TemplateFile: "account_locked.html",
+		TemplateData: struct {
+			LockoutDuration string
+		}{
+			LockoutDuration: fmt.Sprintf("%d minutes", s.LockoutDuration),
+		},
+	})
+	if err != nil {
+		s.Logger.Error("unable to send account locked email", err)
+	}
+}
+
+func (s *User) unlockAccount(auth *model.UserAuth) error {
+	_, err := s.UserRepository.UpdateAuthStatus(auth.Id, api.UserLocked, api.UserActive)
+	if err != nil {
+		s.Logger.Error("unable to unlock account", err)
+		return err
+	}
+
+	err = s.Throttler.Reset(ThrottleKeyEmail + normalizeEmail(auth.Username))
+	if err != nil {
+		s.Logger.Error("unable to reset login throttle", err)
+		return err
+	}
+
+	auth.StatusId = api.UserActive
+	return nil
+}
+
+func (s *User) runThrottlePurgeScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.Throttler.Purge()
+		if err != nil {
+			s.Logger.Error("failed to purge throttle entries", err)
+		}
+	}
+}
+
+func composeUserSession(session model.UserSession, isCurrent bool) dto.UserSessionResp {
+	return dto.UserSessionResp{
+		Id:                 session.FamilyId,
+		AuthProviderId:     session.AuthProviderId,
+		DevicePlatformId:   session.DevicePlatformId,
+		DeviceManufacturer: session.DeviceManufacturer,
+		DeviceModel:        session.DeviceModel,
+		IsCurrent:          isCurrent,
+		CreatedAt:          session.CreatedAt.Unix(),
+		LastSeenAt:         session.LastSeenAt.Unix(),
+	}
+}
+
+// composeSessionHeader returns response header that contains access token and refresh token of user session
+func composeSessionHeader(token *entity.SessionToken) map[string]string {
+	return map[string]string{
+		api.AccessTokenKey:     token.Token,
+		api.AccessTokenExpKey:  strconv.FormatInt(token.ExpiredAt, 10),
+		api.RefreshTokenKey:    token.RefreshToken,
+		api.RefreshTokenExpKey: strconv.FormatInt(token.RefreshExpiredAt, 10),
+	}
+}
+
+// hashRefreshToken returns hex encoded sha256 hash of an opaque refresh token
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashRecoveryCode normalize recovery code input before hashing, so code is accepted with or without separator
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail returns lower cased email, so throttle key can not be bypassed by changing letter case
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
@@ -32,6 +32,48 @@ type userStatements struct {
 	findActiveSubscription                *sqlx.Stmt
 	insertAdminInvitation                 *sqlx.NamedStmt
 	isUserHasSubscribed                   *sqlx.Stmt
+	deleteRotatedSession                  *sqlx.Stmt
+	deleteSessionFamily                   *sqlx.Stmt
+	findSessionByRefreshToken             *sqlx.Stmt
+	findSpentRefreshToken                 *sqlx.Stmt
+	insertSpentRefreshToken               *sqlx.NamedStmt
+	deleteThirdParty                      *sqlx.Stmt
+	findThirdPartiesByUser                *sqlx.Stmt
+	deleteRecoveryCodes                   *sqlx.Stmt
+	deleteTwoFactor                       *sqlx.Stmt
+	findTwoFactor                         *sqlx.Stmt
+	countRecoveryCodes                    *sqlx.Stmt
+	insertRecoveryCode                    *sqlx.NamedStmt
+	upsertTwoFactor                       *sqlx.NamedStmt
+	updateTwoFactorStatus                 *sqlx.NamedStmt
+	updateTwoFactorStep                   *sqlx.Stmt
+	useRecoveryCode                       *sqlx.Stmt
+	updateAuthStatus                      *sqlx.Stmt
+	countDevices                          *sqlx.Stmt
+	deleteDeviceSession                   *sqlx.Stmt
+	deleteOtherSessions                   *sqlx.Stmt
+	deleteUserSessionFamily               *sqlx.Stmt
+	findSessionsByUser                    *sqlx.Stmt
+	insertDevice                          *sqlx.NamedStmt
+	updateSessionLastSeen                 *sqlx.Stmt
+	anonymizeAuth                         *sqlx.NamedStmt
+	anonymizeDonations                    *sqlx.Stmt
+	anonymizeProfile                      *sqlx.NamedStmt
+	cancelPledges                         *sqlx.Stmt
+	deleteDevices                         *sqlx.Stmt
+	deleteFollowedTags                    *sqlx.Stmt
+	deleteRefreshTokens                   *sqlx.Stmt
+	deleteRunSessions                     *sqlx.Stmt
+	deleteThirdParties                    *sqlx.Stmt
+	claimUserDeletion                     *sqlx.Stmt
+	deleteUserDeletion                    *sqlx.Stmt
+	findChallengesByUser                  *sqlx.Stmt
+	findDueDeletions                      *sqlx.Stmt
+	findSubscriptionsByUser               *sqlx.Stmt
+	insertUserDeletion                    *sqlx.NamedStmt
+	rescheduleUserDeletion                *sqlx.Stmt
+	updateAuthUsername                    *sqlx.Stmt
+	updateProfileEmail                    *sqlx.Stmt
 }
 
 func initUserStatement(db *nsql.SqlDatabase) userStatements {
@@ -45,13 +87,13 @@ func initUserStatement(db *nsql.SqlDatabase) userStatements {
 		findProfileByEmail:                    db.Prepare(`SELECT id, full_name, avatar_file, gender_id, date_of_birth, email, created_at, updated_at, email_verified FROM user_profile WHERE email = $1`),
 		findProfileById:                       db.Prepare(`SELECT id, full_name, avatar_file, gender_id, date_of_birth, email, created_at, updated_at, email_verified FROM user_profile WHERE id = $1`),
 		findProviderRefId:                     db.Prepare(`SELECT provider_ref FROM provider_user_mapping WHERE provider_id = $1 AND user_id = $2`),
-		findSessionById:                       db.Prepare(`SELECT id, user_id, auth_provider_id, device_platform_id, device_id, device_manufacturer, device_model, notification_channel_id, notification_token, signature, expired_at, created_at, updated_at FROM user_session WHERE id = $1`),
+		findSessionById:                       db.Prepare(`SELECT id, user_id, auth_provider_id, device_platform_id, device_id, device_manufacturer, device_model, notification_channel_id, notification_token, signature, family_id, refresh_token_hash, expired_at, last_seen_at, created_at, updated_at FROM user_session WHERE id = $1`),
 		isExistByEmail:                        db.Prepare(`SELECT COUNT(id) > 0 "is_exist" FROM user_profile WHERE email = $1`),
 		isExistBy3rdPartyAcc:                  db.Prepare(`SELECT COUNT(id) > 0 "is_exist" FROM user_auth_third_party WHERE access_key = $1 AND auth_provider_id = $2`),
 		insertUserAuth:                        db.PrepareNamed(`INSERT INTO user_auth(id, username, password, status_id, created_at, updated_at) VALUES (:id, :username, :password, :status_id, :created_at, :updated_at)`),
 		insertUserAuthThirdParty:              db.PrepareNamed(`INSERT INTO user_auth_third_party(id, user_id, auth_provider_id, access_key, created_at, updated_at) VALUES (:id, :user_id, :auth_provider_id, :access_key, :created_at, :updated_at)`),
-		insertUserProfile:                     db.PrepareNamed(`INSERT INTO user_profile(id, full_name, avatar_file, gender_id, date_of_birth, email, created_at, updated_at) VALUES (:id, :full_name, :avatar_file, :gender_id, :date_of_birth, :email, :created_at, :updated_at)`),
-		insertUserSession:                     db.PrepareNamed(`INSERT INTO user_session(id, user_id, auth_provider_id, device_platform_id, device_id, device_manufacturer, device_model, notification_channel_id, notification_token, signature, expired_at, created_at, updated_at) VALUES (:id, :user_id, :auth_provider_id, :device_platform_id, :device_id, :device_manufacturer, :device_model, :notification_channel_id, :notification_token, :signature, :expired_at, :created_at, :updated_at)`),
+		insertUserProfile:                     db.PrepareNamed(`INSERT INTO user_profile(id, full_name, avatar_file, gender_id, date_of_birth, email, email_verified, created_at, updated_at) VALUES (:id, :full_name, :avatar_file, :gender_id, :date_of_birth, :email, :email_verified, :created_at, :updated_at)`),
+		insertUserSession:                     db.PrepareNamed(`INSERT INTO user_session(id, user_id, auth_provider_id, device_platform_id, device_id, device_manufacturer, device_model, notification_channel_id, notification_token, signature, family_id, refresh_token_hash, expired_at, last_seen_at, created_at, updated_at) VALUES (:id, :user_id, :auth_provider_id, :device_platform_id, :device_id, :device_manufacturer, :device_model, :notification_channel_id, :notification_token, :signature, :family_id, :refresh_token_hash, :expired_at, :last_seen_at, :created_at, :updated_at)`),
 		updateEmailVerified:                   db.Prepare(`UPDATE user_profile SET email_verified = $1, updated_at = $2 WHERE id = $3`),
 		updatePassword:                        db.Prepare(`UPDATE user_auth SET password = $1, updated_at = $2 WHERE id = $3`),
 		findProviderSubscriptionPlanTypeRefId: db.Prepare(`SELECT provider_trx_ref FROM provider_subscription_plan WHERE provider_id = $1 AND plan_type_id = $2`),
@@ -62,5 +104,47 @@ func initUserStatement(db *nsql.SqlDatabase) userStatements {
 		findActiveSubscription:                db.Prepare(`SELECT id, user_id, plan_type_id, provider_id, provider_subscription_ref, provider_options, period_start, period_end, status_id, metadata, created_at, updated_at, modified_by FROM user_subscription WHERE user_id = $1 AND period_end > $2 ORDER BY status_id, created_at DESC LIMIT 1`),
 		insertAdminInvitation:                 db.PrepareNamed(`INSERT INTO adm_invitation(id, user_id, email, token, expired_at, created_at) VALUES (:id, :user_id, :email, :token, :expired_at, :created_at);`),
 		isUserHasSubscribed:                   db.Prepare(`SELECT COUNT(id) > 0 as has_subscribed FROM user_subscription WHERE user_id = $1 AND provider_id = $2 AND status_id IN (1,2,3) LIMIT 1;`),
+		deleteRotatedSession:                  db.Prepare(`DELETE FROM user_session WHERE id = $1 AND refresh_token_hash = $2`),
+		deleteSessionFamily:                   db.Prepare(`DELETE FROM user_session WHERE family_id = $1`),
+		findSessionByRefreshToken:             db.Prepare(`SELECT id, user_id, auth_provider_id, device_platform_id, device_id, device_manufacturer, device_model, notification_channel_id, notification_token, signature, family_id, refresh_token_hash, expired_at, last_seen_at, created_at, updated_at FROM user_session WHERE refresh_token_hash = $1`),
+		findSpentRefreshToken:                 db.Prepare(`SELECT token_hash, family_id, user_id, expired_at, used_at FROM user_refresh_token WHERE token_hash = $1 AND expired_at > now()`),
+		insertSpentRefreshToken:               db.PrepareNamed(`INSERT INTO user_refresh_token(token_hash, family_id, user_id, expired_at, used_at) VALUES (:token_hash, :family_id, :user_id, :expired_at, :used_at)`),
+		deleteThirdParty:                      db.Prepare(`DELETE FROM user_auth_third_party WHERE user_id = $1 AND auth_provider_id = $2 AND ((SELECT password FROM user_auth WHERE id = $1) <> '-' OR (SELECT COUNT(id) FROM user_auth_third_party WHERE user_id = $1) > 1)`),
+		findThirdPartiesByUser:                db.Prepare(`SELECT id, user_id, auth_provider_id, access_key, created_at, updated_at FROM user_auth_third_party WHERE user_id = $1 ORDER BY created_at`),
+		deleteRecoveryCodes:                   db.Prepare(`DELETE FROM user_recovery_code WHERE user_id = $1`),
+		deleteTwoFactor:                       db.Prepare(`DELETE FROM user_two_factor WHERE user_id = $1`),
+		findTwoFactor:                         db.Prepare(`SELECT user_id, secret, status_id, last_used_step, created_at, updated_at FROM user_two_factor WHERE user_id = $1`),
+		countRecoveryCodes:                    db.Prepare(`SELECT COUNT(id) FROM user_recovery_code WHERE user_id = $1 AND used_at IS NULL`),
+		insertRecoveryCode:                    db.PrepareNamed(`INSERT INTO user_recovery_code(id, user_id, code_hash, used_at, created_at) VALUES (:id, :user_id, :code_hash, :used_at, :created_at)`),
+		upsertTwoFactor:                       db.PrepareNamed(`INSERT INTO user_two_factor(user_id, secret, status_id, last_used_step, created_at, updated_at) VALUES (:user_id, :secret, :status_id, :last_used_step, :created_at, :updated_at) ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, status_id = EXCLUDED.status_id, last_used_step = EXCLUDED.last_used_step, updated_at = EXCLUDED.updated_at`),
+		updateTwoFactorStatus:                 db.PrepareNamed(`UPDATE user_two_factor SET status_id = :status_id, last_used_step = :last_used_step, updated_at = :updated_at WHERE user_id = :user_id`),
+		updateTwoFactorStep:                   db.Prepare(`UPDATE user_two_factor SET last_used_step = $1, updated_at = $2 WHERE user_id = $3 AND last_used_step < $1`),
+		useRecoveryCode:                       db.Prepare(`UPDATE user_recovery_code SET used_at = $1 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`),
+		updateAuthStatus:                      db.Prepare(`UPDATE user_auth SET status_id = $1 WHERE id = $2 AND status_id = $3`),
+		countDevices:                          db.Prepare(`SELECT COUNT(signature) FROM user_device WHERE user_id = $1`),
+		deleteDeviceSession:                   db.Prepare(`DELETE FROM user_session WHERE user_id = $1 AND device_id = $2`),
+		deleteOtherSessions:                   db.Prepare(`DELETE FROM user_session WHERE user_id = $1 AND family_id <> $2`),
+		deleteUserSessionFamily:               db.Prepare(`DELETE FROM user_session WHERE user_id = $1 AND family_id = $2`),
+		findSessionsByUser:                    db.Prepare(`SELECT id, user_id, auth_provider_id, device_platform_id, device_id, device_manufacturer, device_model, notification_channel_id, notification_token, signature, family_id, refresh_token_hash, expired_at, last_seen_at, created_at, updated_at FROM user_session WHERE user_id = $1 AND expired_at > $2 ORDER BY last_seen_at DESC`),
+		insertDevice:                          db.PrepareNamed(`INSERT INTO user_device(user_id, signature, device_platform_id, device_manufacturer, device_model, created_at) VALUES (:user_id, :signature, :device_platform_id, :device_manufacturer, :device_model, :created_at) ON CONFLICT (user_id, signature) DO NOTHING`),
+		updateSessionLastSeen:                 db.Prepare(`UPDATE user_session SET last_seen_at = $1 WHERE id = $2`),
+		anonymizeAuth:                         db.PrepareNamed(`UPDATE user_auth SET username = :username, password = :password, status_id = :status_id, updated_at = :updated_at WHERE id = :id`),
+		anonymizeDonations:                    db.Prepare(`UPDATE donation SET user_snapshot = $1 WHERE user_id = $2`),
+		anonymizeProfile:                      db.PrepareNamed(`UPDATE user_profile SET full_name = :full_name, avatar_file = :avatar_file, gender_id = :gender_id, date_of_birth = :date_of_birth, email = :email, email_verified = :email_verified, updated_at = :updated_at WHERE id = :id`),
+		cancelPledges:                         db.Prepare(`UPDATE donation_pledge SET status_id = 3, updated_at = $2, "version" = "version" + 1 WHERE user_id = $1 AND status_id IN (1, 2)`),
+		deleteDevices:                         db.Prepare(`DELETE FROM user_device WHERE user_id = $1`),
+		deleteFollowedTags:                    db.Prepare(`DELETE FROM user_ad_tag WHERE user_id = $1`),
+		deleteRefreshTokens:                   db.Prepare(`DELETE FROM user_refresh_token WHERE user_id = $1`),
+		deleteRunSessions:                     db.Prepare(`DELETE FROM run_session WHERE user_id = $1`),
+		deleteThirdParties:                    db.Prepare(`DELETE FROM user_auth_third_party WHERE user_id = $1`),
+		claimUserDeletion:                     db.Prepare(`UPDATE user_deletion SET status_id = 2, scheduled_at = $2 WHERE user_id = $1 AND status_id = 1`),
+		deleteUserDeletion:                    db.Prepare(`DELETE FROM user_deletion WHERE user_id = $1 AND status_id = $2`),
+		findChallengesByUser:                  db.Prepare(`SELECT id, user_id, milestone_id, milestone_snapshot, milestone_version, challenge_id, challenge_snapshot, challenge_version, challenge_result_snapshot, reward_snapshot, reward_type_id, reward_ref_id, reward_value, status, updated_at FROM user_challenge WHERE user_id = $1 ORDER BY updated_at DESC`),
+		findDueDeletions:                      db.Prepare(`SELECT user_id, status_id, scheduled_at, created_at FROM user_deletion WHERE scheduled_at <= $1 ORDER BY scheduled_at LIMIT $2`),
+		findSubscriptionsByUser:               db.Prepare(`SELECT id, user_id, plan_type_id, provider_id, provider_subscription_ref, provider_options, period_start, period_end, status_id, metadata, created_at, updated_at, modified_by FROM user_subscription WHERE user_id = $1 ORDER BY created_at DESC`),
+		insertUserDeletion:                    db.PrepareNamed(`INSERT INTO user_deletion(user_id, status_id, scheduled_at, created_at) VALUES (:user_id, :status_id, :scheduled_at, :created_at) ON CONFLICT (user_id) DO NOTHING`),
+		rescheduleUserDeletion:                db.Prepare(`UPDATE user_deletion SET scheduled_at = $2 WHERE user_id = $1`),
+		updateAuthUsername:                    db.Prepare(`UPDATE user_auth SET username = $1, updated_at = $2 WHERE id = $3`),
+		updateProfileEmail:                    db.Prepare(`UPDATE user_profile SET email = $1, email_verified = true, updated_at = $2 WHERE id = $3`),
 	}
 }
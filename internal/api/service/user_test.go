@@ -0,0 +1,316 @@
+package service_test
+
+import (
+	"fmt"
+	"github.com/diarikom/running-app/running-app-api/cmd/apitest"
+	"github.com/diarikom/running-app/running-app-api/internal/api"
+	"github.com/diarikom/running-app/running-app-api/internal/api/dto"
+	"github.com/diarikom/running-app/running-app-api/internal/api/entity"
+	"github.com/diarikom/running-app/running-app-api/internal/api/mocks"
+	"github.com/diarikom/running-app/running-app-api/internal/api/model"
+	"github.com/diarikom/running-app/running-app-api/internal/api/service"
+	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+const (
+	userTestId    = "1267772569398808571"
+	userTestEmail = "janedoe@email.com"
+)
+
+func TestUserTestSuite(t *testing.T) {
+	suite.Run(t, new(UserTestSuite))
+}
+
+type UserTestSuite struct {
+	suite.Suite
+	App     apitest.Api
+	Service *service.User
+}
+
+func (s *UserTestSuite) SetupTest() {
+	// Init app
+	s.App = apitest.InitApi()
+
+	// Setup data
+	err := s.SetupData()
+	if err != nil {
+		panic(fmt.Errorf("failed to set-up data"))
+	}
+
+	// init service
+	s.InitService()
+}
+
+func (s *UserTestSuite) TearDownTest() {
+	// Drop sessions
+	s.App.IgnoreDbExec(`DELETE FROM user_refresh_token`)
+	s.App.IgnoreDbExec(`DELETE FROM user_session`)
+	s.App.IgnoreDbExec(`DELETE FROM user_device`)
+	// Drop users
+	s.App.IgnoreDbExec(`DELETE FROM user_deletion`)
+	s.App.IgnoreDbExec(`DELETE FROM user_auth_third_party`)
+	s.App.IgnoreDbExec(`DELETE FROM user_auth`)
+	s.App.IgnoreDbExec(`DELETE FROM user_profile`)
+}
+
+func (s *UserTestSuite) SetupData() error {
+	// Get instances
+	db := s.App.Datasources.Db.Conn
+	logger := s.App.Logger
+
+	// Insert user without password
+	_, err := db.Exec(`INSERT INTO user_profile (id, full_name, avatar_file, gender_id, date_of_birth, email, created_at, updated_at, email_verified) VALUES (1267772569398808571, 'Jane Doe', null, 1, '1999-12-31', 'janedoe@email.com', '2020-06-02 17:58:29.277934', '2020-06-02 17:58:29.277934', true); INSERT INTO user_auth (id, username, password, status_id, created_at, updated_at) VALUES (1267772569398808571, 'janedoe@email.com', '-', 1, '2020-06-02 17:58:29.277934', '2020-06-02 17:58:29.277934');`)
+	if err != nil {
+		logger.Error("failed to insert user", err)
+		return err
+	}
+	logger.Debug("user inserted")
+
+	return nil
+}
+
+func (s *UserTestSuite) InitService() {
+	apiTest := s.App
+
+	// Mock access token and signature
+	auth := &mocks.AuthenticatorService{}
+	auth.On("NewAccessToken", mock.Anything).Return(&entity.AccessToken{Token: "access"}, nil)
+	auth.On("SignMd5", mock.Anything).Return(func(req dto.SignatureReq) string {
+		return fmt.Sprintf(req.Format, req.Args...)
+	}, nil)
+
+	// Init services
+	s.Service = &service.User{}
+	apiTest.Services = &api.Services{
+		Asset:      &mocks.AssetService{},
+		Auth:       auth,
+		User:       s.Service,
+		Run:        &mocks.RunService{},
+		Credit:     &mocks.CreditService{},
+		Initiative: &mocks.InitiativeService{},
+	}
+
+	// Init services
+	apiTest.MustInitService("UserService", apiTest.Services.User)
+}
+
+// login creates a new session on device
+func (s *UserTestSuite) login(deviceId string) string {
+	token, err := s.Service.NewSession(&model.UserAuth{Id: userTestId}, newTestDevice(deviceId))
+	if err != nil {
+		s.T().Fatalf("unable to create session: %s", err)
+	}
+
+	return token.RefreshToken
+}
+
+// findSessionIds returns id of user sessions ordered by device id
+func (s *UserTestSuite) findSessionIds() []string {
+	ids := make([]string, 0)
+	err := s.App.Datasources.Db.Conn.Select(&ids, `SELECT id FROM user_session WHERE user_id = $1 ORDER BY device_id`,
+		userTestId)
+	if err != nil {
+		s.T().Fatalf("unable to retrieve sessions: %s", err)
+	}
+
+	return ids
+}
+
+func newTestDevice(deviceId string) dto.UserLoginReq {
+	return dto.UserLoginReq{
+		DevicePlatformId:    1,
+		DeviceId:            deviceId,
+		DeviceModel:         "Pixel",
+		DeviceManufacturer:  "Google",
+		NotificationChannel: 1,
+		NotificationToken:   "notification",
+		AuthProviderId:      api.AppAuthProvider,
+	}
+}
+
+func (s *UserTestSuite) TestRefreshTokenReuse() {
+	svc := s.Service
+	refreshToken := s.login("device-1")
+
+	// Rotate refresh token
+	_, err := svc.RefreshSession(dto.UserRefreshSession{
+		RefreshToken: refreshToken,
+		DeviceInfo:   newTestDevice("device-1"),
+	})
+	if err != nil {
+		s.T().Fatalf("unable to refresh session: %s", err)
+	}
+
+	// Reused refresh token must be refused and revoke the whole session family
+	_, err = svc.RefreshSession(dto.UserRefreshSession{
+		RefreshToken: refreshToken,
+		DeviceInfo:   newTestDevice("device-1"),
+	})
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "USR020" {
+		s.T().Errorf("expected USR020 on refresh token reuse, got %v", err)
+	}
+
+	if ids := s.findSessionIds(); len(ids) != 0 {
+		s.T().Errorf("expected session family to be revoked, got %v", ids)
+	}
+}
+
+func (s *UserTestSuite) TestUnlinkLastLoginMethod() {
+	// Link Google and Facebook account to user without password
+	_, err := s.App.Datasources.Db.Conn.Exec(`INSERT INTO user_auth_third_party (id, user_id, auth_provider_id, access_key, created_at, updated_at) VALUES (1267772569398808581, 1267772569398808571, 2, 'google-subject', '2020-06-02 17:58:29.277934', '2020-06-02 17:58:29.277934'), (1267772569398808582, 1267772569398808571, 3, 'facebook-subject', '2020-06-02 17:58:29.277934', '2020-06-02 17:58:29.277934');`)
+	if err != nil {
+		s.T().Fatalf("unable to insert third party accounts: %s", err)
+	}
+
+	// Unlink one of login methods
+	resp, err := s.Service.UnlinkAuthProvider(dto.UserAuthProviderReq{
+		UserId:         userTestId,
+		AuthProviderId: api.FacebookAuthProvider,
+	})
+	if err != nil {
+		s.T().Fatalf("unable to unlink auth provider: %s", err)
+	}
+
+	if len(resp.Providers) != 1 || resp.Providers[0].AuthProviderId != api.GoogleAuthProvider {
+		s.T().Errorf("expected only Google account to be linked, got %+v", resp)
+	}
+
+	// Last login method must not be unlinked
+	_, err = s.Service.UnlinkAuthProvider(dto.UserAuthProviderReq{
+		UserId:         userTestId,
+		AuthProviderId: api.GoogleAuthProvider,
+	})
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "USR024" {
+		s.T().Errorf("expected USR024 on unlinking last login method, got %v", err)
+	}
+}
+
+func (s *UserTestSuite) TestRevokeOtherSessions() {
+	s.login("device-1")
+	s.login("device-2")
+	ids := s.findSessionIds()
+	if len(ids) != 2 {
+		s.T().Fatalf("expected 2 sessions, got %v", ids)
+	}
+
+	// Session of other user must not revoke sessions
+	err := s.Service.RevokeOtherSessions(ids[0], "1267772569398808572")
+	if err != nhttp.ErrUnauthorized {
+		s.T().Errorf("expected unauthorized on session of other user, got %v", err)
+	}
+
+	// Only current session is kept
+	err = s.Service.RevokeOtherSessions(ids[0], userTestId)
+	if err != nil {
+		s.T().Fatalf("unable to revoke other sessions: %s", err)
+	}
+
+	if actual := s.findSessionIds(); len(actual) != 1 || actual[0] != ids[0] {
+		s.T().Errorf("expected only session %s to be kept, got %v", ids[0], actual)
+	}
+}
+
+func (s *UserTestSuite) TestCancelledDeletion() {
+	// Schedule deletion that is due
+	_, err := s.App.Datasources.Db.Conn.Exec(`INSERT INTO user_deletion (user_id, status_id, scheduled_at, created_at) VALUES (1267772569398808571, 1, '2020-06-30 00:00:00.000000', '2020-06-02 17:58:29.277934');`)
+	if err != nil {
+		s.T().Fatalf("unable to insert user deletion: %s", err)
+	}
+
+	err = s.Service.CancelDeletion(userTestId)
+	if err != nil {
+		s.T().Fatalf("unable to cancel deletion: %s", err)
+	}
+
+	// Cancelled deletion is no longer due
+	resp, err := s.Service.DeleteDueAccounts()
+	if err != nil {
+		s.T().Fatalf("unable to delete due accounts: %s", err)
+	}
+
+	if resp.Deleted != 0 || resp.Failed != 0 {
+		s.T().Errorf("expected no account to be deleted, got %+v", resp)
+	}
+
+	// Deletion that started before cancellation must not anonymise account
+	err = s.Service.DeleteAccount(userTestId)
+	if err != nil {
+		s.T().Fatalf("unable to delete account: %s", err)
+	}
+
+	var profile model.UserProfile
+	err = s.App.Datasources.Db.Conn.Get(&profile, `SELECT id, full_name, email FROM user_profile WHERE id = $1`,
+		userTestId)
+	if err != nil {
+		s.T().Fatalf("unable to retrieve user profile: %s", err)
+	}
+
+	if profile.FullName != "Jane Doe" || profile.Email != "janedoe@email.com" {
+		s.T().Errorf("cancelled deletion must not anonymise account, got %+v", profile)
+	}
+}
+
+func (s *UserTestSuite) TestChangeEmailInvalidatesTokens() {
+	newEmail := "jane@email.com"
+	s.login("device-1")
+
+	// Create change email session of the current email
+	changeSession := dto.ChangeEmailSession{
+		RequestId: "1267772569398808591",
+		UserId:    userTestId,
+		NewEmail:  newEmail,
+	}
+	changeSession.UserSignature = fmt.Sprintf(service.RawFormatChangeEmailUserSignature, changeSession.RequestId,
+		userTestId, userTestEmail, newEmail, s.Service.SignatureSaltChangeEmailSubject)
+
+	// Create reset password session of the current email
+	var updatedAt time.Time
+	err := s.App.Datasources.Db.Conn.Get(&updatedAt, `SELECT updated_at FROM user_auth WHERE id = $1`, userTestId)
+	if err != nil {
+		s.T().Fatalf("unable to retrieve user auth: %s", err)
+	}
+
+	resetSession := dto.ResetPasswordSession{
+		RequestId: "1267772569398808592",
+		Email:     userTestEmail,
+	}
+	resetSession.UserSignature = fmt.Sprintf(service.RawFormatResetPasswordSubjectSignature, resetSession.RequestId,
+		userTestId, userTestEmail, updatedAt.Unix(), s.Service.SignatureSaltResetPasswordSubject)
+
+	_, err = s.Service.ValidateResetPasswordSignature(&resetSession)
+	if err != nil {
+		s.T().Fatalf("unable to validate reset password session: %s", err)
+	}
+
+	// Confirm email change
+	_, err = s.Service.ValidateChangeEmailSignature(&changeSession)
+	if err != nil {
+		s.T().Fatalf("unable to validate change email session: %s", err)
+	}
+
+	err = s.Service.ChangeEmail(dto.UserChangeEmailReq{UserId: userTestId, Email: newEmail})
+	if err != nil {
+		s.T().Fatalf("unable to change email: %s", err)
+	}
+
+	// Change email token must not be used twice
+	_, err = s.Service.ValidateChangeEmailSignature(&changeSession)
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "USR010" {
+		s.T().Errorf("expected USR010 on used change email token, got %v", err)
+	}
+
+	// Reset password token of the old email is no longer valid
+	_, err = s.Service.ValidateResetPasswordSignature(&resetSession)
+	if apiErr, ok := err.(nhttp.Error); !ok || apiErr.Code != "USR008" {
+		s.T().Errorf("expected USR008 on reset password token of old email, got %v", err)
+	}
+
+	// Sessions are revoked
+	if ids := s.findSessionIds(); len(ids) != 0 {
+		s.T().Errorf("expected sessions to be revoked, got %v", ids)
+	}
+}
@@ -5,31 +5,61 @@ import (
 	"github.com/diarikom/running-app/running-app-api/internal/api/entity"
 	"github.com/diarikom/running-app/running-app-api/internal/api/model"
 	"github.com/diarikom/running-app/running-app-api/pkg/nhttp"
+	"net/http"
 )
 
 type ServiceInitiator interface {
 	Init(app *Api) error
 }
 
+type AdminService interface {
+	AcceptInvitation(req dto.AdminAcceptInvitationReq) error
+	Audit(r *http.Request, principal *nhttp.Principal, permission string, err error)
+	Authorize(userId, permission string) (*nhttp.Principal, error)
+	Delete(req dto.AdminDeleteReq) error
+	Invite(req dto.AdminInvitationReq) (*dto.AdminInvitationResp, error)
+	List(req dto.PageReq) ([]dto.AdminResp, error)
+	ListAuditLogs(req dto.AdminAuditLogListReq) ([]dto.AdminAuditLogResp, error)
+	Login(req dto.AdminLoginReq) (*dto.AdminLoginResp, map[string]string, error)
+	LoginByTwoFactor(req dto.TwoFactorReq) (*dto.AdminLoginResp, map[string]string, error)
+	UpdateRoles(req dto.AdminRolesReq) (*dto.AdminResp, error)
+}
+
 type AssetService interface {
+	DeleteFile(assetType int, fileName string) error
 	GetPublicUrl(assetType int, fileName string) string
 	GetUploadRule(assetType int) (*nhttp.UploadRule, error)
+	NewImageError(err error) error
 	UploadFile(req dto.UploadReq) (*dto.UploadResp, error)
+	UploadPrivateFile(req dto.PrivateUploadReq) (*dto.UploadResp, error)
 }
 
 type AuthenticatorService interface {
 	NewAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error)
+	NewAdminAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error)
 	NewOneTimeToken(req dto.JWTOptReq) (*entity.AccessToken, error)
+	NewAdvertiserAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error)
+	NewOrganizationAccessToken(req dto.JWTOptReq) (*entity.AccessToken, error)
 	SignMd5(req dto.SignatureReq) (string, error)
 	ValidateUserAccess(bearer string) (sessionId, userId string, err error)
 	ValidateResetPasswordToken(token string) (*dto.ResetPasswordSession, error)
 	ValidateVerifyEmailToken(token string) (*dto.VerifyEmailSession, error)
+	ValidateChangeEmailToken(token string) (*dto.ChangeEmailSession, error)
+	ValidateTwoFactorToken(token string) (*dto.TwoFactorSession, error)
 	ValidateClient(secret string) (err error)
-	ValidateClientDashboard(secret string) (err error)
+	ValidateAdminAccess(bearer string) (sessionId, userId string, err error)
+	ValidateAdvertiserAccess(bearer string) (string, error)
+	ValidateOrganizationAccess(bearer string) (*dto.OrganizationSession, error)
 }
 
 type DiscoverContentService interface {
-	GetContents(skip int64, limit int8) (resp *dto.DiscoverContentResp, err error)
+	Create(opt dto.DiscoverContentReq) (*dto.DiscoverContentDetailResp, error)
+	Delete(opt dto.DiscoverContentDeleteReq) error
+	Get(id string) (*dto.DiscoverContentDetailResp, error)
+	GetContents(opt dto.DiscoverContentQueryReq) (resp *dto.DiscoverContentResp, err error)
+	List(opt dto.DiscoverContentListReq) ([]dto.DiscoverContentDetailResp, error)
+	Update(opt dto.DiscoverContentReq) (*dto.DiscoverContentDetailResp, error)
+	UploadImage(file nhttp.MultipartFile) (*dto.UploadResp, error)
 }
 
 type RunService interface {
@@ -39,21 +69,46 @@ type RunService interface {
 }
 
 type UserService interface {
+	CancelDeletion(userId string) error
+	ChangeEmail(req dto.UserChangeEmailReq) error
 	ChangePassword(req dto.ChangePasswordReq) error
+	DeleteDueAccounts() (*dto.UserDeletionRunResp, error)
+	DisableTwoFactor(req dto.TwoFactorReq) error
+	EnableTwoFactor(req dto.TwoFactorReq) (*dto.UserRecoveryCodesResp, error)
+	EnrollTwoFactor(userId string) (*dto.UserTwoFactorEnrollResp, error)
 	GetProfile(userId string) (*dto.UserProfileResp, error)
 	GetProfileSnapshot(userId string) (*model.UserSnapshot, error)
-	IsEmailExists(email string) (interface{}, error)
+	GetTwoFactor(userId string) (*dto.UserTwoFactorResp, error)
+	IsEmailExists(email string, clientIp string) (interface{}, error)
+	IsTwoFactorEnabled(userId string) (bool, error)
+	LinkAuthProvider(req dto.UserAuthProviderReq) (*dto.UserAuthProvidersResp, error)
+	ListAuthProviders(userId string) (*dto.UserAuthProvidersResp, error)
+	ListSessions(sessionId, userId string) ([]dto.UserSessionResp, error)
 	Login(req dto.UserLoginReq) (map[string]string, error)
+	LoginByApple(req dto.UserLoginReq) (map[string]string, error)
 	LoginByFacebook(req dto.UserLoginReq) (map[string]string, error)
-	Logout(userId string) error
+	LoginByGoogle(req dto.UserLoginReq) (map[string]string, error)
+	LoginByTwoFactor(req dto.UserTwoFactorLoginReq) (map[string]string, error)
+	Logout(sessionId, userId string) error
+	NewTwoFactorChallenge(session dto.TwoFactorSession) (map[string]string, error)
 	Register(req dto.UserProfileReq) error
 	RefreshSession(req dto.UserRefreshSession) (map[string]string, error)
-	RequestResetPassword(email string) error
+	RegenerateRecoveryCodes(req dto.TwoFactorReq) (*dto.UserRecoveryCodesResp, error)
+	RequestDataExport(userId string) error
+	RequestDeletion(req dto.UserDeletionReq) (*dto.UserDeletionResp, error)
+	RequestEmailChange(req dto.UserChangeEmailReq) error
+	RequestResetPassword(email string, clientIp string) error
+	RevokeOtherSessions(sessionId, userId string) error
+	RevokeSession(req dto.UserRevokeSessionReq) error
+	UnlinkAuthProvider(req dto.UserAuthProviderReq) (*dto.UserAuthProvidersResp, error)
 	UpdateProfile(req dto.UserUpdateProfileReq) error
 	UpdateVerifyEmail(userId string) error
 	ValidateSession(sessionId, userId string) error
 	ValidateResetPasswordSignature(session *dto.ResetPasswordSession) (string, error)
 	ValidateVerifyEmailSignature(session *dto.VerifyEmailSession) (string, error)
+	ValidateChangeEmailSignature(session *dto.ChangeEmailSession) (string, error)
+	VerifyPassword(req dto.UserLoginReq) (*model.UserAuth, error)
+	VerifyTwoFactor(req dto.TwoFactorReq) error
 	GetUserProviderRefId(req dto.UserSubscriptionReq) (*dto.UserSubscriptionRequestResp, error)
 	Subscribe(req dto.UserSubscriptionReq) (*dto.UserSubscribeResp, error)
 	CancelSubscription(req dto.UserSubscriptionReq) error
@@ -64,7 +119,8 @@ type UserService interface {
 }
 
 type AdTagService interface {
-	GetAdTags() (resp *dto.AdTagResp, err error)
+	FollowTags(req dto.AdTagFollowReq) (resp *dto.AdTagResp, err error)
+	GetAdTags(userId string) (resp *dto.AdTagResp, err error)
 }
 
 type MilestoneService interface {
@@ -79,16 +135,73 @@ type MilestoneService interface {
 type CreditService interface {
 	Charge(opt dto.CreditChargeOpt) (string, error)
 	CheckChargeAmount(opt dto.CreditChargeOpt) (int, error)
+	ExpireCredits(opt dto.CreditExpireOpt) (*dto.CreditExpireResp, error)
+	ExportTrxHistory(opt dto.CreditTrxHistoryReq) ([]byte, error)
 	GetUserWallet(userId string) (*model.UserCreditWallet, error)
 	GetUserBalance(userId string) (*dto.UserCreditBalanceResp, error)
 	InsertPendingTrx(opt dto.CreditTrxOpt) (string, error)
+	ListAdjustments(opt dto.CreditAdjustmentListReq) ([]dto.CreditAdjustmentResp, error)
+	ListTrxHistory(opt dto.CreditTrxHistoryReq) (*dto.CreditTrxHistoryListResp, error)
+	RequestAdjustment(opt dto.CreditAdjustmentReq) (*dto.CreditAdjustmentResp, error)
+	RefundTrx(opt dto.CreditRefundOpt) (*model.UserCreditWalletTrx, error)
+	ReviewAdjustment(opt dto.CreditAdjustmentReviewReq) (*dto.CreditAdjustmentResp, error)
 	SettlePendingTrx(opt dto.CreditSettleOpt) error
+	SumEarnedCredit(opt dto.CreditEarnedOpt) (float64, error)
+	Transfer(opt dto.CreditTransferReq) (*dto.CreditTransferResp, error)
 }
 
 type InitiativeService interface {
+	CloseExpired() (*dto.InitiativeCloseResp, error)
+	CreatePledge(opt dto.DonationPledgeReq) (*dto.DonationPledgeResp, error)
 	Donate(opt dto.DonateReq) (*dto.DonateResp, error)
-	List(opt dto.PageReq) ([]dto.InitiativeResp, error)
+	ExportDonationReceipt(opt dto.DonationReceiptReq) ([]byte, error)
+	GetDonationReceipt(opt dto.DonationReceiptReq) (*dto.DonationReceiptResp, error)
+	List(opt dto.InitiativeListReq) ([]dto.InitiativeResp, error)
+	ListPledgeRuns(opt dto.DonationPledgeRunListReq) ([]dto.DonationPledgeRunResp, error)
+	ListPledges(opt dto.UserResourcesReq) ([]dto.DonationPledgeResp, error)
+	ListTagFacets(opt dto.InitiativeListReq) ([]dto.InitiativeTagFacetResp, error)
 	ListUserDonation(opt dto.UserResourcesReq) ([]dto.DonationHistoryResp, error)
+	RefundDonation(opt dto.DonationRefundReq) (*dto.DonationRefundResp, error)
+	RunPledges() (*dto.DonationPledgeExecResp, error)
+	UpdateFundingGoal(opt dto.InitiativeFundingReq) error
+	UpdatePledgeStatus(opt dto.DonationPledgeStatusReq) (*dto.DonationPledgeResp, error)
+}
+
+type AdvertiserService interface {
+	CreateCampaign(opt dto.AdCampaignReq) (*dto.AdCampaignResp, error)
+	CreateCreative(opt dto.AdCreativeReq) (*dto.AdCreativeResp, error)
+	DeleteCreative(opt dto.AdCreativeReq) error
+	ExportReport(opt dto.AdReportReq) ([]byte, error)
+	GetCampaign(opt dto.AdCampaignReq) (*dto.AdCampaignResp, error)
+	GetReport(opt dto.AdReportReq) (*dto.AdReportResp, error)
+	ListCampaigns(opt dto.AdCampaignListReq) ([]dto.AdCampaignResp, error)
+	Login(opt dto.AdvertiserLoginReq) (*dto.AdvertiserLoginResp, map[string]string, error)
+	LoginByTwoFactor(opt dto.TwoFactorReq) (*dto.AdvertiserLoginResp, map[string]string, error)
+	RecordEvent(opt dto.AdEventReq) error
+	RollupStats() (*dto.AdStatRollupResp, error)
+	ServeAd(opt dto.AdServeReq) (*dto.AdServeResp, error)
+	UpdateCampaign(opt dto.AdCampaignReq) (*dto.AdCampaignResp, error)
+	UpdateCampaignStatus(opt dto.AdCampaignStatusReq) (*dto.AdCampaignResp, error)
+	UploadCreativeImage(file nhttp.MultipartFile) (*dto.UploadResp, error)
+	ValidateAdvertiser(userId string) error
+}
+
+type OrganizationService interface {
+	AddMember(opt dto.OrganizationMemberReq) error
+	Create(opt dto.OrganizationReq) (*dto.OrganizationResp, error)
+	Delete(opt dto.OrganizationDeleteReq) error
+	ExportPayout(opt dto.OrganizationPayoutReq) ([]byte, error)
+	GeneratePayouts(opt dto.OrganizationPayoutGenerateReq) (*dto.OrganizationPayoutGenerateResp, error)
+	Get(id string) (*dto.OrganizationResp, error)
+	List(opt dto.OrganizationListReq) ([]dto.OrganizationResp, error)
+	ListDonations(opt dto.OrganizationDonationListReq) ([]dto.OrganizationDonationResp, error)
+	ListPayouts(opt dto.OrganizationPayoutListReq) ([]dto.OrganizationPayoutResp, error)
+	Login(opt dto.OrganizationLoginReq) (*dto.OrganizationLoginResp, map[string]string, error)
+	LoginByTwoFactor(opt dto.TwoFactorReq) (*dto.OrganizationLoginResp, map[string]string, error)
+	RemoveMember(opt dto.OrganizationMemberReq) error
+	Update(opt dto.OrganizationReq) (*dto.OrganizationResp, error)
+	UpdateVerification(opt dto.OrganizationVerificationReq) (*dto.OrganizationResp, error)
+	ValidateAdmin(session *dto.OrganizationSession) error
 }
 
 type SubscriptionPlanService interface {
@@ -6,7 +6,13 @@ import (
 )
 
 type Issuer struct {
-	Key             []byte
+	// Key is shared secret to sign token with HS256 when there is no active asymmetric key
+	Key []byte
+	// Keys are asymmetric keys that must be sorted by activation time
+	Keys []*Key
+	// RejectSharedKey rejects tokens signed with shared secret once an asymmetric key is active, so a leaked secret
+	// cannot be used to forge tokens after migration
+	RejectSharedKey bool
 	DefaultLifetime time.Duration
 	Issuer          string
 }
@@ -47,9 +53,20 @@ func (j *Issuer) New(opt ClaimOpt) (*Token, error) {
 		},
 	}
 
-	// Added Claims to Token
-	payload := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err := payload.SignedString(j.Key)
+	// Sign token with active key, or fallback to shared secret
+	var token string
+	var err error
+	if k := j.SigningKey(t); k != nil {
+		payload := jwt.NewWithClaims(k.method, claims)
+		payload.Header["kid"] = k.Id
+		token, err = payload.SignedString(k.privateKey)
+	} else if len(j.Key) > 0 {
+		payload := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token, err = payload.SignedString(j.Key)
+	} else {
+		err = ErrNoSigningKey
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -65,10 +82,29 @@ func (j *Issuer) New(opt ClaimOpt) (*Token, error) {
 
 func (j *Issuer) Verify(input string) (*Claim, error) {
 	token, err := jwt.ParseWithClaims(input, &Claim{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// If key id is not set, then token is signed with shared secret
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || len(j.Key) == 0 {
+				return nil, ErrInvalidSignMethod
+			}
+
+			if j.RejectSharedKey && j.SigningKey(time.Now()) != nil {
+				return nil, ErrInvalidSignMethod
+			}
+			return j.Key, nil
+		}
+
+		// Get key that has not been retired
+		k := j.VerifyingKey(kid, time.Now())
+		if k == nil {
+			return nil, ErrUnknownKey
+		}
+
+		if token.Method.Alg() != k.method.Alg() {
 			return nil, ErrInvalidSignMethod
 		}
-		return j.Key, nil
+		return k.publicKey, nil
 	})
 
 	// Check parsing err
@@ -83,3 +119,38 @@ func (j *Issuer) Verify(input string) (*Claim, error) {
 	}
 	return claims, nil
 }
+
+// SigningKey returns the latest key that is active at t, or nil if there is no active key
+func (j *Issuer) SigningKey(t time.Time) *Key {
+	for i := len(j.Keys) - 1; i >= 0; i-- {
+		if j.Keys[i].IsActive(t) {
+			return j.Keys[i]
+		}
+	}
+	return nil
+}
+
+// VerifyingKey returns key by id if it has not been retired at t, or nil if not found
+func (j *Issuer) VerifyingKey(kid string, t time.Time) *Key {
+	for _, k := range j.Keys {
+		if k.Id == kid && !k.IsRetired(t) {
+			return k
+		}
+	}
+	return nil
+}
+
+// JWKS returns public keys that have not been retired, including keys that are scheduled to be active so
+// verifiers can fetch them before they are used
+func (j *Issuer) JWKS() *JWKS {
+	now := time.Now()
+	result := JWKS{
+		Keys: make([]JWK, 0, len(j.Keys)),
+	}
+	for _, k := range j.Keys {
+		if !k.IsRetired(now) {
+			result.Keys = append(result.Keys, k.JWK())
+		}
+	}
+	return &result
+}
@@ -0,0 +1,188 @@
+package njwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func newTestKey(t *testing.T, opt KeyOpt) *Key {
+	var block pem.Block
+	switch opt.Algorithm {
+	case AlgorithmRS256:
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}
+	case AlgorithmES256:
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalECPrivateKey(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	}
+
+	k, err := ParseKey(opt, pem.EncodeToMemory(&block))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestIssuerKeyRotation(t *testing.T) {
+	now := time.Now().UTC()
+	oldKey := newTestKey(t, KeyOpt{
+		Id:        "old",
+		Algorithm: AlgorithmRS256,
+		ActiveAt:  now.Add(-48 * time.Hour).Format(time.RFC3339),
+	})
+	currentKey := newTestKey(t, KeyOpt{
+		Id:        "current",
+		Algorithm: AlgorithmES256,
+		ActiveAt:  now.Add(-time.Hour).Format(time.RFC3339),
+	})
+	nextKey := newTestKey(t, KeyOpt{
+		Id:        "next",
+		Algorithm: AlgorithmRS256,
+		ActiveAt:  now.Add(24 * time.Hour).Format(time.RFC3339),
+	})
+
+	keys := []*Key{nextKey, currentKey, oldKey}
+	SortKeys(keys)
+	issuer := Issuer{
+		Keys:            keys,
+		DefaultLifetime: time.Minute,
+		Issuer:          "test",
+	}
+
+	// Token must be signed with latest active key
+	token, err := issuer.New(ClaimOpt{Subject: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claim, err := issuer.Verify(token.Encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claim.Subject != "1" {
+		t.Errorf("unexpected subject. Actual: %s", claim.Subject)
+	}
+
+	if k := issuer.SigningKey(now); k == nil || k.Id != "current" {
+		t.Errorf("current key must be used for signing")
+	}
+
+	// All keys that are not retired must be published
+	if n := len(issuer.JWKS().Keys); n != 3 {
+		t.Errorf("unexpected published keys. Expected: 3, Actual: %d", n)
+	}
+
+	// Retired key must be rejected
+	currentKey.RetireAt = now.Add(-time.Minute)
+	_, err = issuer.Verify(token.Encoded)
+	if err == nil {
+		t.Errorf("token signed with retired key must be rejected")
+	}
+
+	if n := len(issuer.JWKS().Keys); n != 2 {
+		t.Errorf("retired key must not be published. Actual: %d", n)
+	}
+}
+
+func TestIssuerSharedSecretFallback(t *testing.T) {
+	issuer := Issuer{
+		Key:             []byte("secret"),
+		DefaultLifetime: time.Minute,
+		Issuer:          "test",
+	}
+
+	token, err := issuer.New(ClaimOpt{Subject: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = issuer.Verify(token.Encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Token signed with shared secret must be rejected once the secret is removed
+	issuer.Key = nil
+	_, err = issuer.Verify(token.Encoded)
+	if err == nil {
+		t.Errorf("token signed with shared secret must be rejected if secret is unset")
+	}
+
+	_, err = issuer.New(ClaimOpt{Subject: "1"})
+	if err != ErrNoSigningKey {
+		t.Errorf("unexpected error. Expected: %s, Actual: %v", ErrNoSigningKey, err)
+	}
+}
+
+func TestIssuerRejectSharedKey(t *testing.T) {
+	issuer := Issuer{
+		Key:             []byte("secret"),
+		RejectSharedKey: true,
+		DefaultLifetime: time.Minute,
+		Issuer:          "test",
+	}
+
+	// Token signed with shared secret is accepted while there is no active key
+	token, err := issuer.New(ClaimOpt{Subject: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = issuer.Verify(token.Encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Token signed with shared secret must be rejected once an asymmetric key is active
+	issuer.Keys = []*Key{newTestKey(t, KeyOpt{
+		Id:        "current",
+		Algorithm: AlgorithmES256,
+		ActiveAt:  time.Now().UTC().Add(-time.Hour).Format(time.RFC3339),
+	})}
+	_, err = issuer.Verify(token.Encoded)
+	if err == nil {
+		t.Errorf("token signed with shared secret must be rejected if an asymmetric key is active")
+	}
+
+	// Token signed with asymmetric key is still accepted
+	token, err = issuer.New(ClaimOpt{Subject: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = issuer.Verify(token.Encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyJWK(t *testing.T) {
+	k := newTestKey(t, KeyOpt{Id: "ec", Algorithm: AlgorithmES256})
+	jwk := k.JWK()
+
+	if jwk.KeyType != "EC" || jwk.Curve != "P-256" || jwk.KeyId != "ec" || jwk.Algorithm != AlgorithmES256 {
+		t.Errorf("unexpected jwk: %+v", jwk)
+	}
+
+	// Coordinates must be 32 bytes, encoded to 43 chars
+	if len(jwk.X) != 43 || len(jwk.Y) != 43 {
+		t.Errorf("unexpected coordinate length. X: %d, Y: %d", len(jwk.X), len(jwk.Y))
+	}
+}
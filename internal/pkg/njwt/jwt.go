@@ -8,6 +8,8 @@ import (
 // Errors
 var ErrInvalidClaim = errors.New("njwt: invalid token claims")
 var ErrInvalidSignMethod = errors.New("njwt: unexpected signing method")
+var ErrNoSigningKey = errors.New("njwt: no active signing key")
+var ErrUnknownKey = errors.New("njwt: unknown or retired key")
 
 type Claim struct {
 	Extra   map[string]string `json:"ext,omitempty"`
@@ -0,0 +1,189 @@
+package njwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"time"
+)
+
+const (
+	// Supported asymmetric algorithms
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// KeyOpt represents configuration of a signing key and its rotation schedule
+type KeyOpt struct {
+	Id             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	// ActiveAt is the time key starts to be used for signing, in RFC3339 format
+	ActiveAt string `mapstructure:"active_at"`
+	// RetireAt is the time key is no longer accepted for verification, in RFC3339 format. Empty means never retired
+	RetireAt string `mapstructure:"retire_at"`
+}
+
+// Key represents an asymmetric key pair that is identified by kid in token header
+type Key struct {
+	Id        string
+	Algorithm string
+	ActiveAt  time.Time
+	RetireAt  time.Time
+	// Private Fields
+	method     jwt.SigningMethod
+	privateKey interface{}
+	publicKey  interface{}
+}
+
+// IsActive returns true if key can be used to sign token at t
+func (k *Key) IsActive(t time.Time) bool {
+	return !k.ActiveAt.After(t) && !k.IsRetired(t)
+}
+
+// IsRetired returns true if key is no longer accepted to verify token at t
+func (k *Key) IsRetired(t time.Time) bool {
+	return !k.RetireAt.IsZero() && !k.RetireAt.After(t)
+}
+
+// NewKey loads private key from file and parse rotation schedule
+func NewKey(opt KeyOpt) (*Key, error) {
+	if opt.Id == "" {
+		return nil, fmt.Errorf("njwt: key id is required")
+	}
+
+	// Read private key
+	pem, err := ioutil.ReadFile(opt.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("njwt: unable to read private key of %s (%s)", opt.Id, err)
+	}
+
+	return ParseKey(opt, pem)
+}
+
+// ParseKey parse PEM encoded private key and rotation schedule
+func ParseKey(opt KeyOpt, pem []byte) (*Key, error) {
+	k := Key{
+		Id:        opt.Id,
+		Algorithm: opt.Algorithm,
+	}
+
+	// Parse private key
+	switch opt.Algorithm {
+	case AlgorithmRS256:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("njwt: unable to parse private key of %s (%s)", opt.Id, err)
+		}
+		k.method = jwt.SigningMethodRS256
+		k.privateKey = privateKey
+		k.publicKey = &privateKey.PublicKey
+	case AlgorithmES256:
+		privateKey, err := jwt.ParseECPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("njwt: unable to parse private key of %s (%s)", opt.Id, err)
+		}
+
+		if privateKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("njwt: key %s must use P-256 curve for ES256", opt.Id)
+		}
+		k.method = jwt.SigningMethodES256
+		k.privateKey = privateKey
+		k.publicKey = &privateKey.PublicKey
+	default:
+		return nil, fmt.Errorf("njwt: unsupported algorithm %s for key %s", opt.Algorithm, opt.Id)
+	}
+
+	// Parse schedule
+	var err error
+	if opt.ActiveAt != "" {
+		k.ActiveAt, err = time.Parse(time.RFC3339, opt.ActiveAt)
+		if err != nil {
+			return nil, fmt.Errorf("njwt: invalid active_at of key %s (%s)", opt.Id, err)
+		}
+	}
+
+	if opt.RetireAt != "" {
+		k.RetireAt, err = time.Parse(time.RFC3339, opt.RetireAt)
+		if err != nil {
+			return nil, fmt.Errorf("njwt: invalid retire_at of key %s (%s)", opt.Id, err)
+		}
+
+		if !k.RetireAt.After(k.ActiveAt) {
+			return nil, fmt.Errorf("njwt: retire_at of key %s must be after active_at", opt.Id)
+		}
+	}
+
+	return &k, nil
+}
+
+// SortKeys sorts keys by their activation time, so the latest active key is used for signing
+func SortKeys(keys []*Key) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].ActiveAt.Before(keys[j].ActiveAt)
+	})
+}
+
+// JWKS represents JSON Web Key Set as defined in RFC 7517
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK represents public key of a Key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+	// RSA public key parameters
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC public key parameters
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWK returns public key in JSON Web Key format
+func (k *Key) JWK() JWK {
+	jwk := JWK{
+		Use:       "sig",
+		Algorithm: k.Algorithm,
+		KeyId:     k.Id,
+	}
+
+	switch publicKey := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeBase64Url(publicKey.N.Bytes())
+		jwk.E = encodeBase64Url(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to curve size as required by RFC 7518
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = publicKey.Curve.Params().Name
+		jwk.X = encodeBase64Url(padBytes(publicKey.X.Bytes(), size))
+		jwk.Y = encodeBase64Url(padBytes(publicKey.Y.Bytes(), size))
+	}
+
+	return jwk
+}
+
+func encodeBase64Url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
@@ -0,0 +1,34 @@
+package noidc
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"strconv"
+	"strings"
+)
+
+type IdToken struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type IdTokenClaim struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Nonce         string   `json:"nonce"`
+	jwt.StandardClaims
+}
+
+// flexBool unmarshal boolean that may be encoded as string, Apple sends email_verified as "true" or "false"
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*b = flexBool(v)
+	return nil
+}
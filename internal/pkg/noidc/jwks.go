@@ -0,0 +1,166 @@
+package noidc
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCacheTTL is used when JWKS response does not specify max-age
+	defaultCacheTTL = time.Hour
+	// minRefreshInterval limits refresh on unknown key id, so invalid tokens can not be used to flood key endpoint
+	minRefreshInterval = time.Minute
+)
+
+var maxAgePattern = regexp.MustCompile(`max-age=(\d+)`)
+
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyId   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// KeySet fetch public keys from a JWKS endpoint and cache them until expired
+type KeySet struct {
+	Url    string
+	Client *http.Client
+	// Private Fields
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	expiredAt   time.Time
+	refreshedAt time.Time
+}
+
+// Key returns public key by key id. Key set is refreshed if cache has expired or key id is unknown
+func (s *KeySet) Key(kid string) (*rsa.PublicKey, error) {
+	now := time.Now()
+
+	// Get from cache
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	expired := now.After(s.expiredAt)
+	canRefresh := now.Sub(s.refreshedAt) >= minRefreshInterval
+	s.mu.RUnlock()
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	// If key is unknown but key set has just been refreshed, skip refresh
+	if !expired && !canRefresh {
+		return nil, ErrUnknownKey
+	}
+
+	err := s.refresh(now)
+	if err != nil {
+		// Use cached key if refresh failed, provider may rotate keys slowly
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok = s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (s *KeySet) refresh(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Make request to JWKS endpoint
+	resp, err := s.Client.Get(s.Url)
+	s.refreshedAt = now
+	if err != nil {
+		return fmt.Errorf("noidc: unable to request keys (%s)", err)
+	}
+	defer closeResp(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("noidc: unexpected status on request keys (%d)", resp.StatusCode)
+	}
+
+	// Read response body
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("noidc: unable to read keys response (%s)", err)
+	}
+
+	// Parse response json
+	var respBody struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.Unmarshal(body, &respBody)
+	if err != nil {
+		return fmt.Errorf("noidc: unable to unmarshal keys response (%s)", err)
+	}
+
+	// Parse RSA keys, other key types are not used by supported providers
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range respBody.Keys {
+		if k.KeyType != "RSA" {
+			continue
+		}
+
+		key, err := parseRSAKey(k)
+		if err != nil {
+			return err
+		}
+		keys[k.KeyId] = key
+	}
+
+	s.keys = keys
+	s.expiredAt = now.Add(cacheTTL(resp.Header.Get("Cache-Control")))
+	return nil
+}
+
+func parseRSAKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("noidc: invalid modulus of key %s (%s)", k.KeyId, err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("noidc: invalid exponent of key %s (%s)", k.KeyId, err)
+	}
+
+	key := rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}
+	return &key, nil
+}
+
+// cacheTTL returns max-age of Cache-Control header, or default if not set
+func cacheTTL(cacheControl string) time.Duration {
+	match := maxAgePattern.FindStringSubmatch(cacheControl)
+	if match == nil {
+		return defaultCacheTTL
+	}
+
+	maxAge, err := strconv.Atoi(match[1])
+	if err != nil || maxAge <= 0 {
+		return defaultCacheTTL
+	}
+	return time.Duration(maxAge) * time.Second
+}
+
+func closeResp(resp *http.Response) {
+	_ = resp.Body.Close()
+}
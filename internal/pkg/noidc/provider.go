@@ -0,0 +1,139 @@
+package noidc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"net/http"
+	"time"
+)
+
+// Errors
+var ErrInvalidAudience = errors.New("noidc: invalid token audience")
+var ErrInvalidClaim = errors.New("noidc: invalid token claims")
+var ErrInvalidIssuer = errors.New("noidc: invalid token issuer")
+var ErrInvalidNonce = errors.New("noidc: invalid token nonce")
+var ErrInvalidSignMethod = errors.New("noidc: unexpected signing method")
+var ErrUnknownKey = errors.New("noidc: unknown key id")
+
+type ProviderOpt struct {
+	// ClientIds are accepted audiences of ID token
+	ClientIds []string
+	// Issuers are accepted issuers of ID token
+	Issuers []string
+	JWKSUrl string
+	// HashNonce must be true if client sends sha256 of nonce to provider, so token contains the hashed nonce
+	HashNonce bool
+}
+
+// NewGoogleProvider creates provider that verifies ID token issued by Sign in with Google
+func NewGoogleProvider(clientIds []string) (*Provider, error) {
+	return NewProvider(ProviderOpt{
+		ClientIds: clientIds,
+		Issuers:   []string{"https://accounts.google.com", "accounts.google.com"},
+		JWKSUrl:   "https://www.googleapis.com/oauth2/v3/certs",
+	})
+}
+
+// NewAppleProvider creates provider that verifies ID token issued by Sign in with Apple
+func NewAppleProvider(clientIds []string) (*Provider, error) {
+	return NewProvider(ProviderOpt{
+		ClientIds: clientIds,
+		Issuers:   []string{"https://appleid.apple.com"},
+		JWKSUrl:   "https://appleid.apple.com/auth/keys",
+		HashNonce: true,
+	})
+}
+
+func NewProvider(opt ProviderOpt) (*Provider, error) {
+	// Check client ids and issuers
+	if len(opt.ClientIds) == 0 || len(opt.Issuers) == 0 || opt.JWKSUrl == "" {
+		return nil, fmt.Errorf("noidc: ClientIds, Issuers and JWKSUrl is required")
+	}
+
+	// Create provider instance
+	p := Provider{
+		ClientIds: opt.ClientIds,
+		Issuers:   opt.Issuers,
+		HashNonce: opt.HashNonce,
+		keySet: &KeySet{
+			Url:    opt.JWKSUrl,
+			Client: &http.Client{Timeout: 10 * time.Second},
+		},
+	}
+
+	return &p, nil
+}
+
+type Provider struct {
+	ClientIds []string
+	Issuers   []string
+	HashNonce bool
+	// Private Fields
+	keySet *KeySet
+}
+
+// VerifyIdToken verifies signature, issuer, audience, expiry and nonce of an ID token
+func (p *Provider) VerifyIdToken(token string, nonce string) (*IdToken, error) {
+	// Parse and verify signature
+	t, err := jwt.ParseWithClaims(token, &IdTokenClaim{}, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+			return nil, ErrInvalidSignMethod
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		return p.keySet.Key(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claim, ok := t.Claims.(*IdTokenClaim)
+	if !ok || !t.Valid {
+		return nil, ErrInvalidClaim
+	}
+
+	// Validate issuer
+	if !contains(p.Issuers, claim.Issuer) {
+		return nil, ErrInvalidIssuer
+	}
+
+	// Validate audience
+	if !contains(p.ClientIds, claim.Audience) {
+		return nil, ErrInvalidAudience
+	}
+
+	// Validate nonce binds token to the sign in request of client. Nonce is generated by client, so it does not
+	// prevent replay of a stolen token together with its nonce
+	if nonce == "" {
+		return nil, ErrInvalidNonce
+	}
+
+	if p.HashNonce {
+		sum := sha256.Sum256([]byte(nonce))
+		nonce = hex.EncodeToString(sum[:])
+	}
+
+	if claim.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+
+	result := IdToken{
+		Subject:       claim.Subject,
+		Email:         claim.Email,
+		EmailVerified: bool(claim.EmailVerified),
+		Name:          claim.Name,
+	}
+	return &result, nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package noidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testClientId = "com.diarikom.running"
+
+func newTestProvider(t *testing.T, hashNonce bool) (*Provider, *rsa.PrivateKey, func()) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve public key as JWKS
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jwk{{
+				KeyType: "RSA",
+				KeyId:   "test",
+				N:       base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+				E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+			}},
+		})
+	}))
+
+	p, err := NewProvider(ProviderOpt{
+		ClientIds: []string{testClientId},
+		Issuers:   []string{"https://issuer.test"},
+		JWKSUrl:   srv.URL,
+		HashNonce: hashNonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p, privateKey, srv.Close
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, claim IdTokenClaim) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claim)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func newTestClaim(nonce string) IdTokenClaim {
+	return IdTokenClaim{
+		Email:         "runner@example.com",
+		EmailVerified: true,
+		Nonce:         nonce,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   "001",
+			Issuer:    "https://issuer.test",
+			Audience:  testClientId,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		},
+	}
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	p, key, closeSrv := newTestProvider(t, false)
+	defer closeSrv()
+
+	idToken, err := p.VerifyIdToken(signTestToken(t, key, newTestClaim("n0nce")), "n0nce")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idToken.Subject != "001" || idToken.Email != "runner@example.com" || !idToken.EmailVerified {
+		t.Errorf("unexpected id token: %+v", idToken)
+	}
+}
+
+func TestVerifyIdTokenHashedNonce(t *testing.T) {
+	p, key, closeSrv := newTestProvider(t, true)
+	defer closeSrv()
+
+	sum := sha256.Sum256([]byte("n0nce"))
+	token := signTestToken(t, key, newTestClaim(hex.EncodeToString(sum[:])))
+
+	_, err := p.VerifyIdToken(token, "n0nce")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyIdTokenInvalid(t *testing.T) {
+	p, key, closeSrv := newTestProvider(t, false)
+	defer closeSrv()
+
+	// Invalid nonce
+	_, err := p.VerifyIdToken(signTestToken(t, key, newTestClaim("n0nce")), "other")
+	if err != ErrInvalidNonce {
+		t.Errorf("unexpected error. Expected: %s, Actual: %v", ErrInvalidNonce, err)
+	}
+
+	// Invalid audience
+	claim := newTestClaim("n0nce")
+	claim.Audience = "other.app"
+	_, err = p.VerifyIdToken(signTestToken(t, key, claim), "n0nce")
+	if err != ErrInvalidAudience {
+		t.Errorf("unexpected error. Expected: %s, Actual: %v", ErrInvalidAudience, err)
+	}
+
+	// Invalid issuer
+	claim = newTestClaim("n0nce")
+	claim.Issuer = "https://other.test"
+	_, err = p.VerifyIdToken(signTestToken(t, key, claim), "n0nce")
+	if err != ErrInvalidIssuer {
+		t.Errorf("unexpected error. Expected: %s, Actual: %v", ErrInvalidIssuer, err)
+	}
+
+	// Expired
+	claim = newTestClaim("n0nce")
+	claim.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	_, err = p.VerifyIdToken(signTestToken(t, key, claim), "n0nce")
+	if err == nil {
+		t.Errorf("expired token must be rejected")
+	}
+}
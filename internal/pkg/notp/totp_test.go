@@ -0,0 +1,87 @@
+package notp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// testSecret is base32 of ASCII "12345678901234567890", the SHA1 secret in RFC 6238 test vectors
+const testSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// Last 6 digits of RFC 6238 test vectors
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for ts, expected := range vectors {
+		code, err := Code(testSecret, Step(time.Unix(ts, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code != expected {
+			t.Errorf("unexpected code at %d. Expected: %s, Actual: %s", ts, expected, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	// Code of previous step is accepted with skew
+	step, ok := Validate(testSecret, "081804", now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("previous code must be accepted. Step: %d, Ok: %t", step, ok)
+	}
+
+	// Code of previous step is rejected without skew
+	_, ok = Validate(testSecret, "081804", now, 0)
+	if ok {
+		t.Errorf("previous code must be rejected without skew")
+	}
+
+	// Malformed code
+	_, ok = Validate(testSecret, "50471", now, 1)
+	if ok {
+		t.Errorf("malformed code must be rejected")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 20 bytes is encoded to 32 chars
+	if len(secret) != 32 {
+		t.Errorf("unexpected secret length. Actual: %d", len(secret))
+	}
+
+	_, err = Code(secret, 1)
+	if err != nil {
+		t.Errorf("generated secret must be valid: %s", err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	u, err := url.Parse(ProvisioningURI("Running App", "runner@example.com", testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Running App:runner@example.com" {
+		t.Errorf("unexpected uri: %s", u)
+	}
+
+	if q := u.Query(); q.Get("secret") != testSecret || q.Get("issuer") != "Running App" {
+		t.Errorf("unexpected query: %s", u.RawQuery)
+	}
+}
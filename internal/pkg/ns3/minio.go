@@ -3,6 +3,7 @@ package ns3
 import (
 	"github.com/minio/minio-go/v6"
 	"io"
+	"time"
 )
 
 type MinioOpt struct {
@@ -45,3 +46,16 @@ func (m *Minio) Upload(file io.Reader, contentType, dest string, scope int) erro
 
 	return nil
 }
+
+func (m *Minio) GetPresignedUrl(dest string, lifetime time.Duration) (string, error) {
+	u, err := m.Client.PresignedGetObject(m.BucketName, dest, lifetime, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func (m *Minio) Delete(dest string) error {
+	return m.Client.RemoveObject(m.BucketName, dest)
+}
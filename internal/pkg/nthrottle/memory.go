@@ -0,0 +1,83 @@
+package nthrottle
+
+import (
+	"sync"
+	"time"
+)
+
+// NewMemoryStore creates a Store that keeps entries in memory. Entries are not shared between instances, so it should
+// only be used in tests or single instance deployment
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func (s *MemoryStore) Find(keys []string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Entry, 0, len(keys))
+	for _, k := range keys {
+		if e, ok := s.entries[k]; ok {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) Increment(key string, now, resetBefore time.Time) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.LastFailureAt.Before(resetBefore) {
+		e = Entry{Key: key, BlockedUntil: e.BlockedUntil}
+	}
+
+	e.Failures++
+	e.LastFailureAt = now
+	s.entries[key] = e
+	return &e, nil
+}
+
+func (s *MemoryStore) Block(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = Entry{Key: key}
+	}
+
+	e.BlockedUntil = until
+	s.entries[key] = e
+	return nil
+}
+
+func (s *MemoryStore) Delete(keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range keys {
+		delete(s.entries, k)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Purge(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for k, e := range s.entries {
+		if e.LastFailureAt.Before(before) && e.BlockedUntil.Before(before) {
+			delete(s.entries, k)
+			count++
+		}
+	}
+	return count, nil
+}
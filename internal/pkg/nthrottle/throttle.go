@@ -0,0 +1,160 @@
+package nthrottle
+
+import (
+	"fmt"
+	"time"
+)
+
+const defaultMaxDelay = 24 * time.Hour
+
+// Entry is failure counter of a throttle key
+type Entry struct {
+	Key           string    `db:"key"`
+	Failures      int       `db:"failures"`
+	LastFailureAt time.Time `db:"last_failure_at"`
+	BlockedUntil  time.Time `db:"blocked_until"`
+}
+
+// Store persists throttle entries. Implementation must be safe for concurrent use
+type Store interface {
+	// Find returns entry of keys, keys without entry are omitted
+	Find(keys []string) ([]Entry, error)
+	// Increment atomically increments failures of key. If last failure is before resetBefore, failures restart from 1
+	Increment(key string, now, resetBefore time.Time) (*Entry, error)
+	// Block prevents attempt of key until the given time
+	Block(key string, until time.Time) error
+	// Delete removes entries of keys
+	Delete(keys []string) error
+	// Purge removes entries that have neither failure nor block after before
+	Purge(before time.Time) (int64, error)
+}
+
+// Policy configures exponential backoff of failed attempts
+type Policy struct {
+	// FreeAttempts is number of failures allowed before attempts are delayed
+	FreeAttempts int
+	// BaseDelay is delay after the first failure that exceeds free attempts, doubled on each next failure
+	BaseDelay time.Duration
+	// MaxDelay caps the delay. Default is 24 hours
+	MaxDelay time.Duration
+	// Window is duration of inactivity before failures are forgotten
+	Window time.Duration
+}
+
+// ThrottledError is returned if an attempt is made before backoff delay has passed
+type ThrottledError struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("nthrottle: %s is throttled, retry after %s", e.Key, e.RetryAfter)
+}
+
+func New(store Store, policy Policy) *Throttler {
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaultMaxDelay
+	}
+
+	return &Throttler{
+		Store:  store,
+		Policy: policy,
+		now:    time.Now,
+	}
+}
+
+type Throttler struct {
+	Store  Store
+	Policy Policy
+	// Private Fields
+	now func() time.Time
+}
+
+// Check returns ThrottledError if any of keys is still blocked
+func (t *Throttler) Check(keys ...string) error {
+	entries, err := t.Store.Find(keys)
+	if err != nil {
+		return err
+	}
+
+	// Find the longest remaining delay
+	now := t.now()
+	var throttled *ThrottledError
+	for _, e := range entries {
+		if !e.BlockedUntil.After(now) {
+			continue
+		}
+
+		retryAfter := e.BlockedUntil.Sub(now)
+		if throttled == nil || retryAfter > throttled.RetryAfter {
+			throttled = &ThrottledError{Key: e.Key, RetryAfter: retryAfter}
+		}
+	}
+
+	if throttled != nil {
+		return throttled
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt of keys and blocks keys that exceed free attempts. Returns updated entries
+func (t *Throttler) Fail(keys ...string) ([]Entry, error) {
+	now := t.now()
+	resetBefore := now.Add(-t.Policy.Window)
+
+	entries := make([]Entry, 0, len(keys))
+	for _, k := range keys {
+		e, err := t.Store.Increment(k, now, resetBefore)
+		if err != nil {
+			return nil, err
+		}
+
+		// Block key until delay has passed, an existing longer block is kept
+		if d := t.Delay(e.Failures); d > 0 && now.Add(d).After(e.BlockedUntil) {
+			e.BlockedUntil = now.Add(d)
+			err = t.Store.Block(k, e.BlockedUntil)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		entries = append(entries, *e)
+	}
+
+	return entries, nil
+}
+
+// Block prevents attempts of key for duration d, regardless of backoff policy
+func (t *Throttler) Block(key string, d time.Duration) error {
+	return t.Store.Block(key, t.now().Add(d))
+}
+
+// Reset clears failures of keys, usually called after a successful attempt
+func (t *Throttler) Reset(keys ...string) error {
+	return t.Store.Delete(keys)
+}
+
+// Purge removes entries that have passed window and are no longer blocked
+func (t *Throttler) Purge() (int64, error) {
+	return t.Store.Purge(t.now().Add(-t.Policy.Window))
+}
+
+// Delay returns backoff delay after n failures
+func (t *Throttler) Delay(failures int) time.Duration {
+	n := failures - t.Policy.FreeAttempts
+	if n <= 0 {
+		return 0
+	}
+
+	// Double delay on each failure, stop once it reaches max delay so it does not overflow
+	d := t.Policy.BaseDelay
+	for i := 1; i < n && d < t.Policy.MaxDelay; i++ {
+		d *= 2
+	}
+
+	if d > t.Policy.MaxDelay {
+		return t.Policy.MaxDelay
+	}
+	return d
+}
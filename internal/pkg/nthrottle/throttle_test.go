@@ -0,0 +1,124 @@
+package nthrottle
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestThrottler(now *time.Time) *Throttler {
+	t := New(NewMemoryStore(), Policy{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		Window:       15 * time.Minute,
+	})
+	t.now = func() time.Time { return *now }
+	return t
+}
+
+func TestThrottlerBackoff(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	th := newTestThrottler(&now)
+
+	// Free attempts must not be blocked
+	for i := 0; i < 3; i++ {
+		_, err := th.Fail("ip:127.0.0.1", "email:runner@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := th.Check("ip:127.0.0.1"); err != nil {
+		t.Errorf("free attempts must not be throttled: %s", err)
+	}
+
+	// Next failure is blocked for base delay
+	_, err := th.Fail("ip:127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = th.Check("email:runner@example.com", "ip:127.0.0.1")
+	e, ok := err.(*ThrottledError)
+	if !ok || e.Key != "ip:127.0.0.1" || e.RetryAfter != time.Second {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Block is lifted after delay
+	now = now.Add(time.Second)
+	if err = th.Check("ip:127.0.0.1"); err != nil {
+		t.Errorf("key must not be throttled after delay: %s", err)
+	}
+
+	// Failures are forgotten after window
+	now = now.Add(16 * time.Minute)
+	entries, err := th.Fail("ip:127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entries[0].Failures != 1 {
+		t.Errorf("failures must be reset after window. Actual: %d", entries[0].Failures)
+	}
+}
+
+func TestThrottlerReset(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	th := newTestThrottler(&now)
+
+	for i := 0; i < 5; i++ {
+		_, _ = th.Fail("email:runner@example.com")
+	}
+
+	if th.Check("email:runner@example.com") == nil {
+		t.Fatalf("key must be throttled")
+	}
+
+	err := th.Reset("email:runner@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = th.Check("email:runner@example.com"); err != nil {
+		t.Errorf("key must not be throttled after reset: %s", err)
+	}
+}
+
+func TestThrottlerDelay(t *testing.T) {
+	th := New(NewMemoryStore(), Policy{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: 10 * time.Second})
+
+	expected := map[int]time.Duration{
+		1:   0,
+		2:   0,
+		3:   time.Second,
+		4:   2 * time.Second,
+		5:   4 * time.Second,
+		7:   10 * time.Second,
+		100: 10 * time.Second,
+	}
+
+	for failures, d := range expected {
+		if actual := th.Delay(failures); actual != d {
+			t.Errorf("unexpected delay of %d failures. Expected: %s, Actual: %s", failures, d, actual)
+		}
+	}
+}
+
+func TestMemoryStorePurge(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	th := newTestThrottler(&now)
+
+	_, _ = th.Fail("ip:1")
+	_ = th.Block("ip:2", time.Hour)
+
+	now = now.Add(20 * time.Minute)
+	count, err := th.Purge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocked key must be kept
+	if count != 1 {
+		t.Errorf("unexpected purged entries. Expected: 1, Actual: %d", count)
+	}
+}
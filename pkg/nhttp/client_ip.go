@@ -0,0 +1,103 @@
+package nhttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const KeyForwardedFor = "X-Forwarded-For"
+
+// trustedProxies is the networks of reverse proxies that are trusted to forward client address
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies sets networks of reverse proxies that are trusted to forward client address. Network is written in
+// CIDR notation, a single address is trusted as a network of itself
+func SetTrustedProxies(addrs []string) error {
+	networks := make([]*net.IPNet, 0, len(addrs))
+	for _, v := range addrs {
+		v = strings.TrimSpace(v)
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return fmt.Errorf("nhttp: invalid trusted proxy address %s", v)
+			}
+
+			if ip.To4() != nil {
+				v += "/32"
+			} else {
+				v += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(v)
+		if err != nil {
+			return fmt.Errorf("nhttp: invalid trusted proxy network %s", v)
+		}
+		networks = append(networks, network)
+	}
+
+	trustedProxies = networks
+	return nil
+}
+
+// ClientIP returns address of client. X-Forwarded-For is only honoured if request is sent by a trusted proxy, then the
+// last address that is not a trusted proxy is used because addresses before it can be spoofed by client
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	// Walk forwarded addresses from the nearest proxy
+	addrs := strings.Split(strings.Join(r.Header[KeyForwardedFor], ","), ",")
+	for i := len(addrs) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(addrs[i])
+		if ip == "" {
+			continue
+		}
+
+		if !isTrustedProxy(ip) {
+			return ip
+		}
+		host = ip
+	}
+
+	return host
+}
+
+// TrustedHeader returns value of header key that is set by reverse proxy, such as client geolocation. If request is not
+// sent by a trusted proxy, then the header can be spoofed by client and empty string is returned
+func TrustedHeader(r *http.Request, key string) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if key == "" || !isTrustedProxy(host) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.Header.Get(key))
+}
+
+// isTrustedProxy checks if addr is in trusted proxy networks
+func isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,75 @@
+package nhttp
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	err := SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		trustedProxies = nil
+	}()
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}{
+		{"direct request", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"forwarded header from untrusted peer", "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"forwarded by trusted proxy", "10.1.2.3:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"spoofed address before client", "10.1.2.3:5000", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:5000", []string{"198.51.100.1, 192.168.1.1", "10.0.0.5"},
+			"198.51.100.1"},
+		{"trusted proxy without forwarded header", "192.168.1.1:5000", nil, "192.168.1.1"},
+	}
+
+	for _, c := range cases {
+		r := &http.Request{RemoteAddr: c.remoteAddr, Header: http.Header{}}
+		for _, v := range c.forwarded {
+			r.Header.Add(KeyForwardedFor, v)
+		}
+
+		if actual := ClientIP(r); actual != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestSetTrustedProxiesInvalid(t *testing.T) {
+	defer func() {
+		trustedProxies = nil
+	}()
+
+	for _, v := range []string{"proxy.local", "10.0.0.0/33"} {
+		if err := SetTrustedProxies([]string{v}); err == nil {
+			t.Errorf("expected error on %s", v)
+		}
+	}
+}
+
+func TestTrustedHeader(t *testing.T) {
+	err := SetTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		trustedProxies = nil
+	}()
+
+	r := &http.Request{RemoteAddr: "10.1.2.3:5000", Header: http.Header{}}
+	r.Header.Set("CF-IPCountry", "ID")
+	if v := TrustedHeader(r, "CF-IPCountry"); v != "ID" {
+		t.Errorf("expected header from trusted proxy, got %s", v)
+	}
+
+	r.RemoteAddr = "203.0.113.7:5000"
+	if v := TrustedHeader(r, "CF-IPCountry"); v != "" {
+		t.Errorf("expected header from untrusted peer to be ignored, got %s", v)
+	}
+}
@@ -2,6 +2,7 @@ package nhttp
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
 	"html"
 	"net/http"
@@ -14,6 +15,8 @@ const (
 	ContentTypeJSON  = "application/json; charset=utf-8"
 	ContentTypeXML   = "application/xml; charset=utf-8"
 	ContentTypeHTML  = "text/html; charset=utf-8"
+	ContentTypeCSV   = "text/csv; charset=utf-8"
+	ContentTypePDF   = "application/pdf"
 )
 
 type HandlerFunc func(*http.Request) (*Success, error)
@@ -46,8 +49,12 @@ func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				w.Header().Set(k, v)
 			}
 		}
-		// send json success
-		httpStatus = h.sendJSON(w, http.StatusOK, result)
+		// if file exist, send file. Else, send json success
+		if result.File != nil {
+			httpStatus = h.sendFile(w, http.StatusOK, result.File)
+		} else {
+			httpStatus = h.sendJSON(w, http.StatusOK, result)
+		}
 	}
 
 	// Log elapsed time
@@ -70,6 +77,24 @@ func (h Handler) sendJSON(w http.ResponseWriter, httpStatus int, obj interface{}
 	return httpStatus
 }
 
+// sendFile write file content as response body
+func (h Handler) sendFile(w http.ResponseWriter, httpStatus int, file *File) int {
+	// Add content type and file name
+	w.Header().Add(KeyContentType, file.ContentType)
+	if file.Name != "" {
+		w.Header().Add("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	}
+	// Write http status
+	w.WriteHeader(httpStatus)
+	// Write file content
+	_, err := w.Write(file.Content)
+	if err != nil {
+		h.Logger.Error("unable to write file response", err)
+	}
+	// Return httpStatus
+	return httpStatus
+}
+
 // sendErrorJSON write error response in JSON
 func (h Handler) sendErrorJSON(w http.ResponseWriter, err error) int {
 	// CastError error to Error
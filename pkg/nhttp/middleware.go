@@ -8,6 +8,7 @@ import (
 const (
 	KeyUserId    = "AUTH_USER_ID"
 	KeySessionId = "AUTH_SESSION_ID"
+	KeyUserName  = "AUTH_USER_NAME"
 )
 
 // validateSessionFn is a function to validate session that has been extracted and make sure session is belongs to user
@@ -19,6 +20,20 @@ type ValidateUserTokenFn func(token string) (sessionId, userId string, err error
 // validateUserTokenFn is a contract function to validate token
 type ValidateTokenFn func(token string) (err error)
 
+// Principal is an authenticated user that has been granted a permission
+type Principal struct {
+	UserId   string
+	FullName string
+	Roles    []string
+}
+
+// AuthorizeFn is a function to check whether user has been granted the permission
+type AuthorizeFn func(userId, permission string) (*Principal, error)
+
+// AuditFn is a function to record an action made by principal. err is the error returned by handler, nil if the
+// action has succeeded
+type AuditFn func(r *http.Request, principal *Principal, permission string, err error)
+
 // Middleware is a function that is able to chain between Handlers
 type Middleware func(h Handler) Handler
 
@@ -78,3 +93,42 @@ func NewUserAuthMiddleware(vFn ValidateUserTokenFn, sFn ValidateSessionFn, authK
 		return Handler{Fn: fn, Logger: logger}
 	}
 }
+
+// NewPermissionMiddleware creates a middleware that validate user access token and make sure user has the permission
+// before calling handler function. Every authorized request is recorded by audit function
+func NewPermissionMiddleware(vFn ValidateUserTokenFn, aFn AuthorizeFn, auditFn AuditFn, permission string,
+	authKey string, logger nlog.Logger) Middleware {
+	// Return Middleware
+	return func(next Handler) Handler {
+		// Prepare function for permission handling
+		fn := func(r *http.Request) (*Success, error) {
+			// Get token
+			authValue := r.Header.Get(authKey)
+
+			// Validate token
+			sessionId, userId, err := vFn(authValue)
+			if err != nil {
+				return nil, err
+			}
+
+			// Check permission
+			principal, err := aFn(userId, permission)
+			if err != nil {
+				return nil, err
+			}
+
+			// Set user id, session id and name to header
+			r.Header.Set(KeyUserId, principal.UserId)
+			r.Header.Set(KeySessionId, sessionId)
+			r.Header.Set(KeyUserName, principal.FullName)
+
+			// Call next handler and record the action
+			resp, err := next.Fn(r)
+			auditFn(r, principal, permission, err)
+
+			return resp, err
+		}
+
+		return Handler{Fn: fn, Logger: logger}
+	}
+}
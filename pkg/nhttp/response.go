@@ -9,6 +9,14 @@ type Success struct {
 	Result   interface{}       `json:"data"`
 	Metadata interface{}       `json:"_metadata,omitempty"`
 	Header   map[string]string `json:"-"`
+	File     *File             `json:"-"`
+}
+
+// File represents a file that is written as response body instead of JSON
+type File struct {
+	Name        string
+	ContentType string
+	Content     []byte
 }
 
 func OK() *Success {
@@ -24,6 +24,12 @@ type SendOpt struct {
 	Text         string
 	TemplateFile string
 	TemplateData interface{}
+	Attachments  []Attachment
+}
+
+type Attachment struct {
+	Filename string
+	Content  []byte
 }
 
 type Mailer struct {
@@ -53,6 +59,11 @@ func (m *Mailer) Send(opt SendOpt) error {
 	message := m.client.NewMessage(opt.Sender, opt.Subject, opt.Text, opt.Recipients...)
 	message.SetHtml(html)
 
+	// Add attachments
+	for _, a := range opt.Attachments {
+		message.AddBufferAttachment(a.Filename, a.Content)
+	}
+
 	// Init context
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
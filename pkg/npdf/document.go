@@ -0,0 +1,118 @@
+package npdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// A4 page size in points
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+const (
+	FontRegular = "F1"
+	FontBold    = "F2"
+)
+
+// Document is a minimal single font family PDF writer that supports text and lines on multiple pages
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+func New() *Document {
+	d := Document{}
+	d.AddPage()
+	return &d
+}
+
+// AddPage appends a new A4 page and set it as current page
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+}
+
+// Text writes text on current page. Position is measured in points from top-left corner of the page
+func (d *Document) Text(x, y, size float64, font string, text string) {
+	_, _ = fmt.Fprintf(d.current(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y,
+		escapeText(text))
+}
+
+// Line draws a straight line on current page. Position is measured in points from top-left corner of the page
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	_, _ = fmt.Fprintf(d.current(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2,
+		PageHeight-y2)
+}
+
+// Bytes renders document into PDF file content
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	offsets := make([]int, 0)
+
+	// Write object and record its offset for cross-reference table
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		_, _ = fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Write header
+	buf.WriteString("%PDF-1.4\n")
+
+	// Object 1 is catalog, 2 is page tree, 3 and 4 are fonts. Pages and its content start from object 5
+	pageCount := len(d.pages)
+	kids := make([]string, pageCount)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pageCount))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, p := range d.pages {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, FontRegular, FontBold, 6+i*2))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", p.Len(), p.String()))
+	}
+
+	// Write cross-reference table
+	xrefOffset := buf.Len()
+	_, _ = fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		_, _ = fmt.Fprintf(&buf, "%010d 00000 n \n", o)
+	}
+
+	// Write trailer
+	_, _ = fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1,
+		xrefOffset)
+
+	return buf.Bytes()
+}
+
+func (d *Document) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// escapeText escapes PDF string delimiter and replace characters that is not supported by WinAnsiEncoding
+func escapeText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteRune(' ')
+		case r < 32 || r > 255:
+			b.WriteRune('?')
+		case r > 126:
+			_, _ = fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
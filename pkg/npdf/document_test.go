@@ -0,0 +1,70 @@
+package npdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestDocument_Bytes(t *testing.T) {
+	doc := New()
+	doc.Text(50, 50, 18, FontBold, "Donation Receipt")
+	doc.Line(50, 60, 545, 60, 1)
+	doc.AddPage()
+	doc.Text(50, 50, 12, FontRegular, "Page 2")
+	content := doc.Bytes()
+
+	// Check header and footer
+	if !bytes.HasPrefix(content, []byte("%PDF-1.4\n")) {
+		t.Errorf("unexpected header")
+	}
+	if !bytes.HasSuffix(content, []byte("%%EOF\n")) {
+		t.Errorf("unexpected footer")
+	}
+
+	// Check page count
+	if !bytes.Contains(content, []byte("/Count 2")) {
+		t.Errorf("unexpected page count")
+	}
+
+	// Check startxref points to xref table
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(content)
+	if m == nil {
+		t.Fatalf("startxref not found")
+	}
+	offset, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(content[offset:], []byte("xref\n")) {
+		t.Errorf("startxref does not point to xref table")
+	}
+
+	// Check every object offset in xref table
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(content, -1)
+	if len(entries) != 8 {
+		t.Fatalf("unexpected object count. Expected = 8, Actual = %d", len(entries))
+	}
+	for i, e := range entries {
+		o, _ := strconv.Atoi(string(e[1]))
+		prefix := fmt.Sprintf("%d 0 obj\n", i+1)
+		if !bytes.HasPrefix(content[o:], []byte(prefix)) {
+			t.Errorf("object %d offset is invalid", i+1)
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	cases := map[string]string{
+		"Receipt (copy)": `Receipt \(copy\)`,
+		`C:\path`:        `C:\\path`,
+		"line\nbreak":    "line break",
+		"Café":           `Caf\351`,
+		"Run 🏃":          "Run ?",
+	}
+
+	for input, expected := range cases {
+		if actual := escapeText(input); actual != expected {
+			t.Errorf("unexpected escaped text. Input = %s, Expected = %s, Actual = %s", input, expected, actual)
+		}
+	}
+}
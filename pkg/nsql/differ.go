@@ -411,9 +411,11 @@ func (d *Differ) compareFields(oldVal, newVal reflect.Value, changedCols []strin
 
 		if ncOk && ocOk {
 			isDiff = oc.DiffValue() != nc.DiffValue()
-		} else {
-			// Else
+		} else if newFieldValue.Type().Comparable() {
 			isDiff = oldInstance != newInstance
+		} else {
+			// Uncomparable types (e.g. slices or maps) are compared deeply
+			isDiff = !reflect.DeepEqual(oldInstance, newInstance)
 		}
 
 		// If old and new value has differences, push to delta
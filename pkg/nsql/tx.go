@@ -21,7 +21,8 @@ func StmtTx(s *sqlx.Stmt, tx *sqlx.Tx) *sqlx.Stmt {
 	return s
 }
 
-// ReleaseTx clean db transaction by commit if no error, or rollback if an error occurred
+// ReleaseTx clean db transaction by commit if no error, or rollback if an error occurred. If commit fails, the error
+// is assigned to err so caller does not report success of a unit of work that has not been persisted
 func ReleaseTx(tx *sqlx.Tx, err *error, log nlog.Logger) {
 	if *err != nil {
 		// If an error occurred, rollback transaction
@@ -37,5 +38,21 @@ func ReleaseTx(tx *sqlx.Tx, err *error, log nlog.Logger) {
 	errCommit := tx.Commit()
 	if errCommit != nil {
 		log.Error("Unable to commit transaction", errCommit)
+		*err = errCommit
 	}
 }
+
+// WithTx run fn in a db transaction. Transaction is committed if fn returns no error, or rolled back otherwise.
+// Returns commit error if transaction fails to commit
+func WithTx(db *SqlDatabase, log nlog.Logger, fn func(tx *sqlx.Tx) error) (err error) {
+	// Begin transaction
+	tx, err := db.Conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer ReleaseTx(tx, &err, log)
+
+	// Run unit of work
+	err = fn(tx)
+	return err
+}
@@ -0,0 +1,97 @@
+package nsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/diarikom/running-app/running-app-api/pkg/nlog"
+	"github.com/jmoiron/sqlx"
+	"io/ioutil"
+	stdLog "log"
+	"testing"
+)
+
+var errTestCommit = errors.New("commit failed")
+
+// testDriver is a database driver that only supports transactions, commit fails if failCommit is true
+type testDriver struct {
+	failCommit bool
+	rollbacks  int
+}
+
+func (d *testDriver) Open(string) (driver.Conn, error) {
+	return &testConn{driver: d}, nil
+}
+
+type testConn struct {
+	driver *testDriver
+}
+
+func (c *testConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	return &testTx{driver: c.driver}, nil
+}
+
+type testTx struct {
+	driver *testDriver
+}
+
+func (t *testTx) Commit() error {
+	if t.driver.failCommit {
+		return errTestCommit
+	}
+	return nil
+}
+
+func (t *testTx) Rollback() error {
+	t.driver.rollbacks++
+	return nil
+}
+
+func newTestDatabase(t *testing.T, name string, d *testDriver) *SqlDatabase {
+	sql.Register(name, d)
+	conn, err := sqlx.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &SqlDatabase{Conn: conn}
+}
+
+func TestWithTx(t *testing.T) {
+	log := nlog.NewStdLogger(nlog.LevelError, ioutil.Discard, "", stdLog.LstdFlags)
+	d := testDriver{}
+	db := newTestDatabase(t, "nsql_test_commit", &d)
+
+	// Committed unit of work returns no error
+	err := WithTx(db, log, func(tx *sqlx.Tx) error {
+		return nil
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// Error of unit of work is returned and transaction is rolled back
+	errFn := errors.New("unit of work failed")
+	err = WithTx(db, log, func(tx *sqlx.Tx) error {
+		return errFn
+	})
+	if err != errFn || d.rollbacks != 1 {
+		t.Errorf("expected rolled back with error %s, got %v (rollbacks = %d)", errFn, err, d.rollbacks)
+	}
+
+	// Failed commit must be returned
+	d.failCommit = true
+	err = WithTx(db, log, func(tx *sqlx.Tx) error {
+		return nil
+	})
+	if err != errTestCommit {
+		t.Errorf("expected commit error, got %v", err)
+	}
+}